@@ -0,0 +1,285 @@
+// Command seed fills the configured database with demo users, leaves and attendance.
+// It is safe to rerun: existing users (by email), leaves and attendance records are left alone.
+package main
+
+import (
+	"campus-backend/internal/api"
+	"campus-backend/internal/attendance"
+	"campus-backend/internal/auth"
+	"campus-backend/internal/core"
+	"campus-backend/internal/leaves"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"campus-backend/pkg/validation"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// seedUser describes a demo account; it goes through the same validation as registration
+type seedUser struct {
+	Name      string
+	Email     string
+	Role      string
+	Dept      string
+	Hostel    string
+	StudentID string
+}
+
+var (
+	hostelA = "Hostel A"
+	hostelB = "Hostel B"
+)
+
+var staff = []seedUser{
+	{Name: "Campus Admin", Email: "admin@campus.edu", Role: users.RoleAdmin, Dept: "Administration"},
+	{Name: "Asha Rao", Email: "asha.rao@campus.edu", Role: users.RoleFaculty, Dept: "CS"},
+	{Name: "Vikram Sen", Email: "vikram.sen@campus.edu", Role: users.RoleFaculty, Dept: "EE"},
+	{Name: "Meera Iyer", Email: "meera.iyer@campus.edu", Role: users.RoleWarden, Dept: "Administration", Hostel: hostelA},
+	{Name: "Rahul Das", Email: "rahul.das@campus.edu", Role: users.RoleWarden, Dept: "Administration", Hostel: hostelB},
+}
+
+var studentNames = []string{
+	"Aarav Shah", "Diya Patel", "Kabir Nair", "Isha Gupta", "Rohan Mehta",
+	"Ananya Bose", "Arjun Reddy", "Sara Khan", "Dev Malhotra", "Nisha Verma",
+}
+
+func main() {
+	config := core.LoadConfig()
+	if err := timeutil.SetLocation(config.Campus.Timezone); err != nil {
+		log.Fatalf("Invalid CAMPUS_TIMEZONE %q: %v", config.Campus.Timezone, err)
+	}
+	if err := validation.SetPhonePattern(config.Campus.PhonePattern); err != nil {
+		log.Fatalf("Invalid PHONE_PATTERN %q: %v", config.Campus.PhonePattern, err)
+	}
+
+	db.Connect()
+	if err := api.Migrate(); err != nil {
+		log.Fatalf("❌ Database migration failed: %v", err)
+	}
+
+	password := os.Getenv("SEED_PASSWORD")
+	if password == "" {
+		password = "campus123"
+	}
+
+	accounts := append([]seedUser{}, staff...)
+	for i, name := range studentNames {
+		dept, hostel := "CS", hostelA
+		if i%2 == 1 {
+			dept, hostel = "EE", hostelB
+		}
+		accounts = append(accounts, seedUser{
+			Name:      name,
+			Email:     fmt.Sprintf("student%02d@campus.edu", i+1),
+			Role:      users.RoleStudent,
+			Dept:      dept,
+			Hostel:    hostel,
+			StudentID: fmt.Sprintf("%s2024%03d", dept, i+1),
+		})
+	}
+
+	byEmail := make(map[string]users.User)
+	for _, account := range accounts {
+		user, created, err := ensureUser(account, password)
+		if err != nil {
+			log.Fatalf("Failed to seed user %s: %v", account.Email, err)
+		}
+		if created {
+			log.Printf("Created %s %s", user.Role, user.Email)
+		}
+		byEmail[user.Email] = user
+	}
+
+	faculty := map[string]users.User{"CS": byEmail["asha.rao@campus.edu"], "EE": byEmail["vikram.sen@campus.edu"]}
+	wardens := map[string]users.User{hostelA: byEmail["meera.iyer@campus.edu"], hostelB: byEmail["rahul.das@campus.edu"]}
+
+	var leaveCount, attendanceCount int
+	for i := range studentNames {
+		student := byEmail[fmt.Sprintf("student%02d@campus.edu", i+1)]
+
+		n, err := seedLeaves(student, wardens[*student.Hostel], i)
+		if err != nil {
+			log.Fatalf("Failed to seed leaves for %s: %v", student.Email, err)
+		}
+		leaveCount += n
+
+		n, err = seedAttendance(student, faculty[student.Dept], i)
+		if err != nil {
+			log.Fatalf("Failed to seed attendance for %s: %v", student.Email, err)
+		}
+		attendanceCount += n
+	}
+
+	log.Printf("✅ Seed complete: %d users, %d new leaves, %d new attendance records", len(byEmail), leaveCount, attendanceCount)
+	log.Printf("All seeded accounts use the password %q", password)
+}
+
+// ensureUser returns the user with the account's email, creating it if missing
+func ensureUser(account seedUser, password string) (users.User, bool, error) {
+	var existing users.User
+	if err := db.DB.Where("email = ?", account.Email).Limit(1).Find(&existing).Error; err != nil {
+		return users.User{}, false, err
+	}
+	if existing.ID != 0 {
+		return existing, false, nil
+	}
+
+	req := auth.RegisterRequest{
+		Name:      account.Name,
+		Email:     account.Email,
+		Password:  password,
+		Role:      account.Role,
+		Dept:      account.Dept,
+		Hostel:    optional(account.Hostel),
+		StudentID: optional(account.StudentID),
+	}
+	if err := validation.ValidateStruct(req); err != nil {
+		return users.User{}, false, fmt.Errorf("validation failed: %v", validation.FormatValidationErrors(err))
+	}
+
+	hashedPassword, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return users.User{}, false, err
+	}
+
+	user := users.User{
+		Name:      req.Name,
+		Email:     req.Email,
+		Password:  hashedPassword,
+		Role:      req.Role,
+		Dept:      req.Dept,
+		Hostel:    req.Hostel,
+		StudentID: req.StudentID,
+		IsActive:  true,
+	}
+	if err := db.DB.Create(&user).Error; err != nil {
+		return users.User{}, false, err
+	}
+	return user, true, nil
+}
+
+// seedLeaves gives a student with no leaves an upcoming pending request and, for every other
+// student, a past leave decided by their warden
+func seedLeaves(student, warden users.User, index int) (int, error) {
+	var existing int64
+	if err := db.DB.Model(&leaves.LeaveRequest{}).Where("student_id = ?", student.ID).Count(&existing).Error; err != nil {
+		return 0, err
+	}
+	if existing > 0 {
+		return 0, nil
+	}
+
+	leaveConfig := core.GetConfig().Leave
+	leaveType := leaveConfig.AllowedTypes[index%len(leaveConfig.AllowedTypes)]
+
+	// Upcoming leave, validated like an application
+	start := timeutil.Today().AddDate(0, 0, 7+index)
+	input := leaves.ApplyLeaveRequest{
+		LeaveType: leaveType,
+		Reason:    "Visiting family for a wedding",
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, 1),
+	}
+	if err := validation.ValidateStruct(input); err != nil {
+		return 0, fmt.Errorf("validation failed: %v", validation.FormatValidationErrors(err))
+	}
+
+	requests := []leaves.LeaveRequest{{
+		StudentID: student.ID,
+		LeaveType: input.LeaveType,
+		Reason:    input.Reason,
+		StartDate: input.StartDate,
+		EndDate:   input.EndDate,
+		Status:    "pending",
+		Dept:      student.Dept,
+		Hostel:    student.Hostel,
+		Days:      int(input.EndDate.Sub(input.StartDate).Hours()/24) + 1,
+	}}
+
+	// Past leave already decided, so history and analytics have data
+	if index%2 == 0 {
+		pastStart := timeutil.Today().AddDate(0, 0, -20+index)
+		status, remarks := "approved", "Approved, travel safely"
+		if index%4 == 2 {
+			status, remarks = "rejected", "Clashes with mid-term exams"
+		}
+		requests = append(requests, leaves.LeaveRequest{
+			StudentID:  student.ID,
+			LeaveType:  leaveType,
+			Reason:     "Medical check-up in home town",
+			StartDate:  pastStart,
+			EndDate:    pastStart.AddDate(0, 0, 2),
+			Status:     status,
+			ApprovedBy: &warden.ID,
+			Remarks:    &remarks,
+			Dept:       student.Dept,
+			Hostel:     student.Hostel,
+			Days:       3,
+			Version:    2,
+		})
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range requests {
+			leave := &requests[i]
+			if err := tx.Create(leave).Error; err != nil {
+				return err
+			}
+			events := []leaves.LeaveEvent{{LeaveID: leave.ID, ActorID: &student.ID, ToStatus: "pending"}}
+			if leave.Status != "pending" {
+				events = append(events, leaves.LeaveEvent{LeaveID: leave.ID, ActorID: &warden.ID, FromStatus: "pending", ToStatus: leave.Status, Remarks: leave.Remarks})
+			}
+			if err := tx.Create(&events).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(requests), nil
+}
+
+// seedAttendance marks the last two weeks of weekdays for a student, skipping days already marked
+func seedAttendance(student, marker users.User, index int) (int, error) {
+	statuses := []string{attendance.StatusPresent, attendance.StatusPresent, attendance.StatusPresent, attendance.StatusLate, attendance.StatusAbsent}
+	subject := "General"
+
+	created := 0
+	for offset := 14; offset >= 1; offset-- {
+		date := timeutil.StartOfDay(timeutil.Today().AddDate(0, 0, -offset))
+		if weekday := date.In(timeutil.Location()).Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+			continue
+		}
+
+		status := statuses[(index+offset)%len(statuses)]
+		record := attendance.Attendance{
+			StudentID: student.ID,
+			Date:      date,
+			Status:    status,
+			Present:   attendance.CountsAsPresent(status),
+			MarkedBy:  marker.ID,
+			Subject:   &subject,
+		}
+		result := db.DB.Where(attendance.Attendance{StudentID: student.ID, Date: date}).FirstOrCreate(&record)
+		if result.Error != nil {
+			return created, result.Error
+		}
+		created += int(result.RowsAffected)
+	}
+	return created, nil
+}
+
+// optional returns nil for an empty string
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -24,11 +24,21 @@ import (
 	_ "campus-backend/docs" // Import docs for Swagger
 	"campus-backend/internal/api"
 	"campus-backend/internal/attendance"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/auth"
 	"campus-backend/internal/core"
 	"campus-backend/internal/leaves"
+	"campus-backend/internal/metrics"
 	"campus-backend/internal/notifications"
-	"campus-backend/internal/users"
+	"campus-backend/internal/retention"
+	"campus-backend/internal/scheduler"
+	"campus-backend/internal/settings"
 	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"campus-backend/pkg/validation"
+	"context"
+	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -39,17 +49,96 @@ func main() {
 	// Load configuration
 	config := core.LoadConfig()
 
+	// Compute "today"/"tomorrow" in the campus timezone
+	if err := timeutil.SetLocation(config.Campus.Timezone); err != nil {
+		log.Fatalf("Invalid CAMPUS_TIMEZONE %q: %v", config.Campus.Timezone, err)
+	}
+	if err := validation.SetPhonePattern(config.Campus.PhonePattern); err != nil {
+		log.Fatalf("Invalid PHONE_PATTERN %q: %v", config.Campus.PhonePattern, err)
+	}
+	validation.SetPasswordPolicySource(auth.PasswordPolicy)
+	if config.Retention.ActiveTermStart != "" {
+		if _, err := timeutil.ParseDate(config.Retention.ActiveTermStart); err != nil {
+			log.Fatalf("Invalid RETENTION_ACTIVE_TERM_START %q: %v", config.Retention.ActiveTermStart, err)
+		}
+	}
+	if err := notifications.LoadTemplates(config.Email.TemplateDir); err != nil {
+		log.Fatalf("Invalid email templates in EMAIL_TEMPLATE_DIR %q: %v", config.Email.TemplateDir, err)
+	}
+
 	// Set Gin mode from config
+	if err := core.ValidateGinMode(config.Server.GinMode); err != nil {
+		log.Fatalf("Invalid GIN_MODE: %v", err)
+	}
 	gin.SetMode(config.Server.GinMode)
 
 	// Connect to database
 	db.Connect()
 
-	// Auto migrate tables - this creates tables automatically
-	db.DB.AutoMigrate(&users.User{}, &leaves.LeaveRequest{}, &attendance.Attendance{}, &notifications.Notification{})
+	// Auto migrate tables - refuse to start on a schema we could not migrate
+	if err := api.Migrate(); err != nil {
+		log.Fatalf("❌ Database migration failed: %v", err)
+	}
+
+	// Admin overrides of campus-policy settings take precedence over env values
+	if err := settings.Load(); err != nil {
+		log.Fatalf("❌ Loading settings failed: %v", err)
+	}
+
+	// Start background jobs
+	jobs := scheduler.New()
+	jobs.Add(scheduler.Job{
+		Name:     "stale_leaves",
+		Interval: time.Duration(config.Leave.StaleCheckIntervalMinutes) * time.Minute,
+		Run: func() error {
+			_, err := leaves.ProcessStaleLeaves(audit.SystemActorID)
+			return err
+		},
+	})
+	jobs.Add(scheduler.Job{
+		Name:     "leave_escalations",
+		Interval: time.Duration(config.Leave.EscalationCheckIntervalMinutes) * time.Minute,
+		Run:      leaves.EscalateOverdueLeavesJob,
+	})
+	jobs.Add(scheduler.Job{
+		Name:     "leave_absences",
+		Interval: time.Duration(config.Attendance.LeaveAbsenceIntervalMinutes) * time.Minute,
+		Run:      attendance.GenerateYesterdaysLeaveAbsences,
+	})
+	jobs.Add(scheduler.Job{
+		Name:     "attendance_summaries",
+		Interval: time.Duration(config.Attendance.SummaryRecomputeIntervalMinutes) * time.Minute,
+		Run:      attendance.RecomputeAllSummaries,
+	})
+	jobs.Add(scheduler.Job{
+		Name:     "retention_purge",
+		Interval: time.Duration(config.Retention.CheckIntervalMinutes) * time.Minute,
+		Run:      retention.PurgeExpired,
+	})
+	// Terms start and end at midnight; reloading moves the settings on to the term now in effect
+	jobs.Add(scheduler.Job{
+		Name:     "settings_reload",
+		Interval: time.Hour,
+		Run:      settings.Load,
+	})
+	jobs.Start(context.Background())
 
 	// Create router
 	r := gin.Default()
+	if err := core.ApplyTrustedProxies(r, config.Server.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
+	// Expose Prometheus metrics (unauthenticated, disable with METRICS_ENABLED=false)
+	if config.Server.MetricsEnabled {
+		r.Use(metrics.Middleware())
+		if sqlDB, err := db.DB.DB(); err == nil {
+			if err := metrics.RegisterDBStats(sqlDB); err != nil {
+				log.Printf("Failed to register DB metrics: %v", err)
+			}
+		}
+		r.GET("/metrics", metrics.Handler())
+	}
 
 	// Setup all API routes using the api package
 	api.SetupRoutes(r)
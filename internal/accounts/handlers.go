@@ -0,0 +1,211 @@
+package accounts
+
+import (
+	"campus-backend/internal/attendance"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/leaves"
+	"campus-backend/internal/notifications"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// UserDataExport bundles everything stored about a user
+type UserDataExport struct {
+	ExportedAt    time.Time                    `json:"exported_at"`
+	Profile       users.User                   `json:"profile"`
+	LeaveRequests []leaves.LeaveRequest        `json:"leave_requests"`
+	Attendance    []attendance.Attendance      `json:"attendance"`
+	Notifications []notifications.Notification `json:"notifications"`
+}
+
+// ExportMyData godoc
+// @Summary Export my data
+// @Description Download a JSON bundle of the current user's profile, leave requests, attendance and notifications
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} UserDataExport "User data export"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/me/export [get]
+func ExportMyData(c *gin.Context) {
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	writeExport(c, userIDVal.(uint))
+}
+
+// ExportUserData godoc
+// @Summary Export a user's data
+// @Description Admin downloads a JSON bundle of any user's profile, leave requests, attendance and notifications
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} UserDataExport "User data export"
+// @Failure 400 {object} map[string]interface{} "Invalid user ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/{id}/export [get]
+func ExportUserData(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	writeExport(c, uint(userID))
+}
+
+// writeExport collects the user's data and sends it as a downloadable JSON file
+func writeExport(c *gin.Context, userID uint) {
+	var user users.User
+	if err := db.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	user.Password = "" // Never include credentials
+
+	export := UserDataExport{
+		ExportedAt: time.Now().UTC(),
+		Profile:    user,
+	}
+
+	if err := db.DB.Where("student_id = ?", userID).Order("created_at ASC").Find(&export.LeaveRequests).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export leave requests"})
+		return
+	}
+	if err := db.DB.Where("student_id = ?", userID).Order("date ASC").Find(&export.Attendance).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export attendance"})
+		return
+	}
+	if err := db.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&export.Notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export notifications"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=user-%d-export.json", userID))
+	c.JSON(http.StatusOK, export)
+}
+
+// DeleteUser godoc
+// @Summary Delete a user account
+// @Description Admin soft-deletes a user with their leave requests, attendance and notifications, and drops their cached attendance summary. Personal details are anonymized so records that reference the user (e.g. as approver) stay valid.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param confirm query bool true "Must be true to confirm deletion"
+// @Success 200 {object} map[string]interface{} "User deleted"
+// @Failure 400 {object} map[string]interface{} "Missing confirmation or last admin"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/{id} [delete]
+func DeleteUser(c *gin.Context) {
+	if c.Query("confirm") != "true" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Deletion must be confirmed with ?confirm=true"})
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var user users.User
+	if err := db.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Never leave the system without an administrator
+	if user.Role == users.RoleAdmin {
+		var adminCount int64
+		if err := db.DB.Model(&users.User{}).Where("role = ?", users.RoleAdmin).Count(&adminCount).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count admins"})
+			return
+		}
+		if adminCount <= 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete the last admin"})
+			return
+		}
+	}
+
+	actorIDVal, _ := c.Get("userID")
+	actorID := actorIDVal.(uint)
+
+	var deleted struct {
+		LeaveRequests int64 `json:"leave_requests"`
+		Attendance    int64 `json:"attendance"`
+		Notifications int64 `json:"notifications"`
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("student_id = ?", user.ID).Delete(&leaves.LeaveRequest{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted.LeaveRequests = result.RowsAffected
+
+		result = tx.Where("student_id = ?", user.ID).Delete(&attendance.Attendance{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted.Attendance = result.RowsAffected
+		if err := tx.Where("student_id = ?", user.ID).Delete(&attendance.AttendanceSummary{}).Error; err != nil {
+			return err
+		}
+
+		result = tx.Where("user_id = ?", user.ID).Delete(&notifications.Notification{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted.Notifications = result.RowsAffected
+
+		// Other users' records may still point at this user (approved_by, marked_by),
+		// so keep the row but scrub personal data and free the unique email/student ID
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"name":                    "Deleted User",
+			"email":                   fmt.Sprintf("deleted-user-%d@deleted.invalid", user.ID),
+			"phone":                   nil,
+			"hostel":                  nil,
+			"student_id":              nil,
+			"emergency_contact_name":  nil,
+			"emergency_contact_phone": nil,
+			"is_active":               false,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+
+		details := fmt.Sprintf("deleted %s user: %d leave requests, %d attendance records, %d notifications",
+			user.Role, deleted.LeaveRequests, deleted.Attendance, deleted.Notifications)
+		return audit.Record(tx, actorID, "user_delete", "user", user.ID, details)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User deleted successfully",
+		"user_id": user.ID,
+		"deleted": deleted,
+	})
+}
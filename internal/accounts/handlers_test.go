@@ -0,0 +1,155 @@
+package accounts
+
+import (
+	"campus-backend/internal/attendance"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/leaves"
+	"campus-backend/internal/notifications"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteUser(t *testing.T) {
+	setupTestDB(t)
+	create := func(name, role string) users.User {
+		user := users.User{Name: name, Email: name + "@example.com", Password: "hashed", Role: role, Dept: "CS", IsActive: true}
+		if err := db.DB.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+	admin := create("admin", users.RoleAdmin)
+	student := create("student", users.RoleStudent)
+	other := create("other", users.RoleStudent)
+	contactName, contactPhone := "Parent", "+15550001111"
+	db.DB.Model(&student).Updates(users.User{EmergencyContactName: &contactName, EmergencyContactPhone: &contactPhone})
+
+	// Both students get a leave, a day of attendance, a notification and a cached summary
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	for _, id := range []uint{student.ID, other.ID} {
+		db.DB.Create(&leaves.LeaveRequest{StudentID: id, LeaveType: "personal", Reason: "Family function", StartDate: day, EndDate: day, Status: "pending", Dept: "CS", Days: 1})
+		db.DB.Create(&attendance.Attendance{StudentID: id, Date: day, Status: attendance.StatusPresent, Present: true, MarkedBy: admin.ID})
+		db.DB.Create(&notifications.Notification{UserID: id, Title: "Hello", Message: "Welcome", Type: "system"})
+	}
+	if err := attendance.RefreshSummaries([]uint{student.ID, other.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	router := newTestRouter(admin)
+	remove := func(id uint, query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/users/"+strconv.Itoa(int(id))+query, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusBadRequest, remove(student.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, remove(9999, "?confirm=true").Code)
+	rec := remove(admin.ID, "?confirm=true")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "last admin")
+
+	rec = remove(student.ID, "?confirm=true")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"deleted":{"leave_requests":1,"attendance":1,"notifications":1}`)
+
+	// The row stays for references but is deleted and scrubbed
+	var stored users.User
+	assert.Error(t, db.DB.First(&stored, student.ID).Error)
+	db.DB.Unscoped().First(&stored, student.ID)
+	assert.Equal(t, "Deleted User", stored.Name)
+	assert.Equal(t, "deleted-user-"+strconv.Itoa(int(student.ID))+"@deleted.invalid", stored.Email)
+	assert.False(t, stored.IsActive)
+	assert.Nil(t, stored.EmergencyContactName)
+	assert.Nil(t, stored.EmergencyContactPhone)
+
+	// Only the deleted user's records go
+	count := func(model interface{}, column string, id uint) int64 {
+		var n int64
+		db.DB.Model(model).Where(column+" = ?", id).Count(&n)
+		return n
+	}
+	for _, id := range []uint{student.ID, other.ID} {
+		want := int64(1)
+		if id == student.ID {
+			want = 0
+		}
+		assert.Equal(t, want, count(&leaves.LeaveRequest{}, "student_id", id))
+		assert.Equal(t, want, count(&attendance.Attendance{}, "student_id", id))
+		assert.Equal(t, want, count(&notifications.Notification{}, "user_id", id))
+		assert.Equal(t, want, count(&attendance.AttendanceSummary{}, "student_id", id))
+	}
+
+	var entry audit.AuditLog
+	if assert.NoError(t, db.DB.Where("action = ?", "user_delete").First(&entry).Error) {
+		assert.Equal(t, admin.ID, entry.ActorID)
+		assert.Equal(t, student.ID, entry.EntityID)
+		assert.Equal(t, "deleted student user: 1 leave requests, 1 attendance records, 1 notifications", entry.Details)
+	}
+
+	// With a second admin the first can go
+	create("second", users.RoleAdmin)
+	assert.Equal(t, http.StatusOK, remove(admin.ID, "?confirm=true").Code)
+}
+
+func TestExportUserData(t *testing.T) {
+	setupTestDB(t)
+	create := func(name, role string) users.User {
+		user := users.User{Name: name, Email: name + "@example.com", Password: "$2a$12$secret-hash", Role: role, Dept: "CS", IsActive: true}
+		if err := db.DB.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+	admin := create("admin", users.RoleAdmin)
+	student := create("student", users.RoleStudent)
+	other := create("other", users.RoleStudent)
+
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	for _, id := range []uint{student.ID, other.ID} {
+		db.DB.Create(&leaves.LeaveRequest{StudentID: id, LeaveType: "personal", Reason: "Family function", StartDate: day, EndDate: day, Status: "pending", Dept: "CS", Days: 1})
+		db.DB.Create(&attendance.Attendance{StudentID: id, Date: day, Status: attendance.StatusPresent, Present: true, MarkedBy: admin.ID})
+		db.DB.Create(&attendance.Attendance{StudentID: id, Date: day.AddDate(0, 0, 1), Status: attendance.StatusAbsent, MarkedBy: admin.ID})
+		db.DB.Create(&notifications.Notification{UserID: id, Title: "Hello", Message: "Welcome", Type: "system"})
+	}
+
+	get := func(user users.User, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		newTestRouter(user).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	check := func(rec *httptest.ResponseRecorder) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "attachment; filename=user-"+strconv.Itoa(int(student.ID))+"-export.json", rec.Header().Get("Content-Disposition"))
+		assert.NotContains(t, rec.Body.String(), "secret-hash")
+		assert.NotContains(t, rec.Body.String(), "password")
+
+		var export UserDataExport
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &export))
+		assert.Equal(t, student.ID, export.Profile.ID)
+		assert.Equal(t, student.Email, export.Profile.Email)
+		if assert.Len(t, export.LeaveRequests, 1) {
+			assert.Equal(t, student.ID, export.LeaveRequests[0].StudentID)
+		}
+		if assert.Len(t, export.Attendance, 2) {
+			assert.Equal(t, attendance.StatusPresent, export.Attendance[0].Status)
+			assert.Equal(t, attendance.StatusAbsent, export.Attendance[1].Status)
+		}
+		assert.Len(t, export.Notifications, 1)
+		assert.False(t, export.ExportedAt.IsZero())
+	}
+
+	// The student's own export and an admin's export of them are the same bundle
+	check(get(student, "/users/me/export"))
+	check(get(admin, "/users/"+strconv.Itoa(int(student.ID))+"/export"))
+
+	assert.Equal(t, http.StatusNotFound, get(admin, "/users/9999/export").Code)
+	assert.Equal(t, http.StatusBadRequest, get(admin, "/users/abc/export").Code)
+}
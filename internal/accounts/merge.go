@@ -0,0 +1,274 @@
+package accounts
+
+import (
+	"campus-backend/internal/attendance"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/internal/leaves"
+	"campus-backend/internal/notifications"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/validation"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Reasons two students are reported as likely duplicates
+const (
+	DuplicateNameDept     = "name_dept"     // Same name, ignoring case and spacing, in the same department
+	DuplicateSimilarEmail = "similar_email" // Same email mailbox once case, dots and +tags are ignored
+)
+
+// DuplicateCandidate is one account in a group of likely duplicates
+type DuplicateCandidate struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Dept      string    `json:"dept"`
+	StudentID *string   `json:"student_id,omitempty"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DuplicateGroup is a set of student accounts that appear to belong to the same person
+type DuplicateGroup struct {
+	Reason string               `json:"reason"`
+	Key    string               `json:"key"`
+	Users  []DuplicateCandidate `json:"users"` // Oldest first
+}
+
+type MergeUsersRequest struct {
+	SourceID uint `json:"source_id" binding:"required" validate:"required,min=1"` // Deactivated after the merge
+	TargetID uint `json:"target_id" binding:"required" validate:"required,min=1,nefield=SourceID"`
+}
+
+// FindDuplicateUsers godoc
+// @Summary Find likely duplicate students
+// @Description Admin lists groups of active student accounts that share a name and department, or whose emails differ only by case, dots or a +tag
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Duplicate groups"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/users/duplicates [get]
+func FindDuplicateUsers(c *gin.Context) {
+	var students []users.User
+	if err := db.DB.Where("role = ? AND is_active = ?", users.RoleStudent, true).Order("created_at ASC, id ASC").Find(&students).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get students"})
+		return
+	}
+
+	groups := append(groupDuplicates(students, DuplicateNameDept, nameDeptKey), groupDuplicates(students, DuplicateSimilarEmail, emailKey)...)
+	c.JSON(http.StatusOK, gin.H{
+		"groups": groups,
+		"total":  len(groups),
+	})
+}
+
+// groupDuplicates buckets the students by key and returns every bucket with more than one account
+func groupDuplicates(students []users.User, reason string, key func(*users.User) string) []DuplicateGroup {
+	buckets := make(map[string][]DuplicateCandidate)
+	for i := range students {
+		student := &students[i]
+		k := key(student)
+		buckets[k] = append(buckets[k], DuplicateCandidate{
+			ID:        student.ID,
+			Name:      student.Name,
+			Email:     student.Email,
+			Dept:      student.Dept,
+			StudentID: student.StudentID,
+			IsActive:  student.IsActive,
+			CreatedAt: student.CreatedAt,
+		})
+	}
+
+	groups := []DuplicateGroup{}
+	for k, candidates := range buckets {
+		if len(candidates) > 1 {
+			groups = append(groups, DuplicateGroup{Reason: reason, Key: k, Users: candidates})
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Key < groups[j].Key })
+	return groups
+}
+
+func nameDeptKey(user *users.User) string {
+	return strings.ToLower(strings.Join(strings.Fields(user.Name), " ")) + " / " + user.Dept
+}
+
+// emailKey reduces an email to its mailbox, so "Asha.Roy+hostel@x.edu" and "asharoy@y.com" match
+func emailKey(user *users.User) string {
+	local, _, _ := strings.Cut(strings.ToLower(user.Email), "@")
+	local, _, _ = strings.Cut(local, "+")
+	return strings.ReplaceAll(local, ".", "")
+}
+
+// MergeUsers godoc
+// @Summary Merge a duplicate student into another
+// @Description Admin moves the source student's leave requests, leave history, comments, attendance and notifications to the target student in one transaction, then deactivates the source. Where both students have attendance for the same day, subject and period, the most recently updated record is kept and the other deleted. The merge is recorded in the audit log.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MergeUsersRequest true "Account to merge away and account to keep"
+// @Success 200 {object} map[string]interface{} "Records moved"
+// @Failure 400 {object} map[string]interface{} "Validation failed or accounts cannot be merged"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/users/merge [post]
+func MergeUsers(c *gin.Context) {
+	var input MergeUsersRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+
+	if err := validation.ValidateStruct(input); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+
+	var source, target users.User
+	if err := db.DB.First(&source, input.SourceID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Source user not found"})
+		return
+	}
+	if err := db.DB.First(&target, input.TargetID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Target user not found"})
+		return
+	}
+
+	// Only student records can be moved between accounts meaningfully
+	if source.Role != users.RoleStudent || target.Role != users.RoleStudent {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only student accounts can be merged"})
+		return
+	}
+	if !target.IsActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot merge into a deactivated account"})
+		return
+	}
+
+	actorIDVal, _ := c.Get("userID")
+	actorID := actorIDVal.(uint)
+
+	var moved struct {
+		LeaveRequests       int64 `json:"leave_requests"`
+		LeaveSeries         int64 `json:"leave_series"`
+		LeaveEvents         int64 `json:"leave_events"`
+		LeaveComments       int64 `json:"leave_comments"`
+		Attendance          int64 `json:"attendance"`
+		AttendanceConflicts int64 `json:"attendance_conflicts"` // Records deleted because both students had the day
+		Notifications       int64 `json:"notifications"`
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&leaves.LeaveRequest{}).Where("student_id = ?", source.ID).Update("student_id", target.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved.LeaveRequests = result.RowsAffected
+
+		// The moved leaves keep their series, which the target must then be able to cancel
+		result = tx.Model(&leaves.LeaveSeries{}).Where("student_id = ?", source.ID).Update("student_id", target.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved.LeaveSeries = result.RowsAffected
+
+		result = tx.Model(&leaves.LeaveEvent{}).Where("actor_id = ?", source.ID).Update("actor_id", target.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved.LeaveEvents = result.RowsAffected
+
+		result = tx.Model(&leaves.LeaveComment{}).Where("author_id = ?", source.ID).Update("author_id", target.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved.LeaveComments = result.RowsAffected
+
+		dropped, err := dropAttendanceConflicts(tx, source.ID, target.ID)
+		if err != nil {
+			return err
+		}
+		moved.AttendanceConflicts = dropped
+
+		result = tx.Model(&attendance.Attendance{}).Where("student_id = ?", source.ID).Update("student_id", target.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved.Attendance = result.RowsAffected
+
+		result = tx.Model(&notifications.Notification{}).Where("user_id = ?", source.ID).Update("user_id", target.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved.Notifications = result.RowsAffected
+
+		if err := tx.Model(&source).Update("is_active", false).Error; err != nil {
+			return err
+		}
+
+		details := fmt.Sprintf("merged user %d (%s) into %d (%s): %d leave requests, %d leave series, %d leave events, %d comments, %d attendance records (%d conflicting dropped), %d notifications",
+			source.ID, source.Email, target.ID, target.Email, moved.LeaveRequests, moved.LeaveSeries, moved.LeaveEvents, moved.LeaveComments, moved.Attendance, moved.AttendanceConflicts, moved.Notifications)
+		return audit.Record(tx, actorID, "user_merge", "user", target.ID, details)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge users"})
+		return
+	}
+	if err := attendance.RefreshSummaries([]uint{source.ID, target.ID}); err != nil {
+		log.Printf("Failed to refresh attendance summaries after merging user %d into %d: %v", source.ID, target.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Users merged successfully",
+		"source_id": source.ID,
+		"target_id": target.ID,
+		"moved":     moved,
+	})
+}
+
+// dropAttendanceConflicts deletes, for every day, subject and period both students have attendance
+// for, the less recently updated of the two records, so moving the rest does not break the
+// one-record-per-day index. Returns how many records were deleted.
+func dropAttendanceConflicts(tx *gorm.DB, sourceID, targetID uint) (int64, error) {
+	var pairs []struct {
+		SourceRow       uint
+		SourceUpdatedAt time.Time
+		TargetRow       uint
+		TargetUpdatedAt time.Time
+	}
+	err := tx.Table("attendances AS s").
+		Select("s.id AS source_row, s.updated_at AS source_updated_at, t.id AS target_row, t.updated_at AS target_updated_at").
+		Joins("JOIN attendances AS t ON t.student_id = ? AND t.date = s.date AND t.deleted_at IS NULL "+
+			"AND COALESCE(t.subject, '') = COALESCE(s.subject, '') AND COALESCE(t.period, '') = COALESCE(s.period, '')", targetID).
+		Where("s.student_id = ? AND s.deleted_at IS NULL", sourceID).
+		Scan(&pairs).Error
+	if err != nil || len(pairs) == 0 {
+		return 0, err
+	}
+
+	older := make([]uint, 0, len(pairs))
+	for _, pair := range pairs {
+		if pair.SourceUpdatedAt.After(pair.TargetUpdatedAt) {
+			older = append(older, pair.TargetRow)
+		} else {
+			older = append(older, pair.SourceRow)
+		}
+	}
+	result := tx.Delete(&attendance.Attendance{}, older)
+	return result.RowsAffected, result.Error
+}
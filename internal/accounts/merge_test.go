@@ -0,0 +1,155 @@
+package accounts
+
+import (
+	"bytes"
+	"campus-backend/internal/attendance"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/leaves"
+	"campus-backend/internal/notifications"
+	"campus-backend/internal/settings"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupTestDB(t *testing.T) {
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&users.User{}, &leaves.LeaveRequest{}, &leaves.LeaveEvent{}, &leaves.LeaveComment{}, &leaves.LeaveSeries{}, &attendance.Attendance{}, &attendance.AttendanceSummary{}, &settings.Term{}, &notifications.Notification{}, &audit.AuditLog{})
+	db.DB = testDB
+}
+
+func newTestRouter(admin users.User) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", admin.ID)
+		c.Set("role", admin.Role)
+		c.Next()
+	})
+	r.GET("/admin/users/duplicates", FindDuplicateUsers)
+	r.POST("/admin/users/merge", MergeUsers)
+	r.PUT("/users/:id/transfer", TransferStudent)
+	r.DELETE("/users/:id", DeleteUser)
+	r.GET("/users/me/export", ExportMyData)
+	r.GET("/users/:id/export", ExportUserData)
+	return r
+}
+
+func TestFindAndMergeDuplicateUsers(t *testing.T) {
+	setupTestDB(t)
+	create := func(name, email, role, dept string) users.User {
+		user := users.User{Name: name, Email: email, Password: "hashed", Role: role, Dept: dept, IsActive: true}
+		if err := db.DB.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+	admin := create("Admin", "admin@example.com", users.RoleAdmin, "ADMIN")
+	original := create("Asha Roy", "asha.roy@campus.edu", users.RoleStudent, "CS")
+	duplicate := create("asha  roy", "asharoy+import@gmail.com", users.RoleStudent, "CS")
+	create("Asha Roy", "asha.r@campus.edu", users.RoleStudent, "EE") // Same name, other department
+	router := newTestRouter(admin)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/users/duplicates", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var found struct {
+		Groups []DuplicateGroup `json:"groups"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &found)
+	if assert.Len(t, found.Groups, 2) {
+		for _, group := range found.Groups {
+			assert.Contains(t, []string{DuplicateNameDept, DuplicateSimilarEmail}, group.Reason)
+			if assert.Len(t, group.Users, 2) {
+				assert.Equal(t, original.ID, group.Users[0].ID)
+				assert.Equal(t, duplicate.ID, group.Users[1].ID)
+			}
+		}
+	}
+
+	day := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	series := leaves.LeaveSeries{StudentID: duplicate.ID, LeaveType: "medical", Reason: "Weekly physiotherapy", Weekdays: []string{"tuesday"}, StartDate: day, Until: day, Dept: "CS"}
+	db.DB.Create(&series)
+	leave := leaves.LeaveRequest{StudentID: duplicate.ID, LeaveType: "medical", Reason: "Fever and cold", StartDate: day, EndDate: day, Status: "pending", Dept: "CS", Days: 1, SeriesID: &series.ID}
+	db.DB.Create(&leave)
+	db.DB.Create(&leaves.LeaveEvent{LeaveID: leave.ID, ActorID: &duplicate.ID, FromStatus: "needs_info", ToStatus: "pending"})
+	db.DB.Create(&attendance.Attendance{StudentID: duplicate.ID, Date: day, Status: "present", Present: true, MarkedBy: admin.ID})
+	assert.NoError(t, attendance.MigrateUniqueDay(db.DB))
+
+	// Both accounts have the next two days: the more recently updated record wins each
+	older := attendance.Attendance{StudentID: duplicate.ID, Date: day.AddDate(0, 0, 1), Status: "absent", MarkedBy: admin.ID}
+	newer := attendance.Attendance{StudentID: original.ID, Date: day.AddDate(0, 0, 1), Status: "present", Present: true, MarkedBy: admin.ID}
+	stale := attendance.Attendance{StudentID: original.ID, Date: day.AddDate(0, 0, 2), Status: "absent", MarkedBy: admin.ID}
+	fresh := attendance.Attendance{StudentID: duplicate.ID, Date: day.AddDate(0, 0, 2), Status: "late", Present: true, MarkedBy: admin.ID}
+	for _, record := range []*attendance.Attendance{&older, &newer, &stale, &fresh} {
+		db.DB.Create(record)
+	}
+	db.DB.Model(&older).UpdateColumn("updated_at", time.Now().Add(-time.Hour))
+	db.DB.Model(&stale).UpdateColumn("updated_at", time.Now().Add(-time.Hour))
+	db.DB.Create(&notifications.Notification{UserID: duplicate.ID, Title: "Hi", Message: "Hello", Type: "system"})
+
+	merge := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/users/merge", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusBadRequest, merge(`{"source_id":`+uintToString(duplicate.ID)+`,"target_id":`+uintToString(duplicate.ID)+`}`).Code)
+	assert.Equal(t, http.StatusBadRequest, merge(`{"source_id":`+uintToString(duplicate.ID)+`,"target_id":`+uintToString(admin.ID)+`}`).Code)
+
+	rec = merge(`{"source_id":` + uintToString(duplicate.ID) + `,"target_id":` + uintToString(original.ID) + `}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var count int64
+	db.DB.Model(&leaves.LeaveRequest{}).Where("student_id = ?", original.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+	var kept []uint
+	db.DB.Model(&attendance.Attendance{}).Where("student_id = ? AND date > ?", original.ID, day).Order("date ASC").Pluck("id", &kept)
+	assert.Equal(t, []uint{newer.ID, fresh.ID}, kept)
+	db.DB.Model(&attendance.Attendance{}).Where("student_id = ?", original.ID).Count(&count)
+	assert.Equal(t, int64(3), count)
+	db.DB.Model(&leaves.LeaveSeries{}).Where("student_id = ?", original.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+	db.DB.Model(&leaves.LeaveEvent{}).Where("actor_id = ?", original.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+	db.DB.Model(&notifications.Notification{}).Where("user_id = ?", original.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	var stored users.User
+	db.DB.First(&stored, duplicate.ID)
+	assert.False(t, stored.IsActive)
+
+	var entry audit.AuditLog
+	assert.NoError(t, db.DB.Where("action = ?", "user_merge").First(&entry).Error)
+	assert.Equal(t, original.ID, entry.EntityID)
+	assert.Contains(t, entry.Details, "1 leave requests, 1 leave series, 1 leave events, 0 comments, 2 attendance records (2 conflicting dropped), 1 notifications")
+
+	// The deactivated account no longer shows up as a duplicate
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/users/duplicates", nil))
+	json.Unmarshal(rec.Body.Bytes(), &found)
+	assert.Empty(t, found.Groups)
+}
+
+func uintToString(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,137 @@
+package accounts
+
+import (
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/internal/leaves"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/validation"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TransferStudentRequest struct {
+	Dept   *string `json:"dept" validate:"omitempty,min=1,max=100"`
+	Hostel *string `json:"hostel" validate:"omitempty,max=100"` // "" moves the student out of hostel accommodation
+}
+
+// TransferStudent godoc
+// @Summary Transfer a student to another department or hostel
+// @Description Admin changes a student's department and/or hostel. Pending and needs_info leaves move to the new scope, so the new department's faculty and hostel's warden decide them; their version is bumped so decisions already in progress under the old scope are refused. Decided leaves keep the department and hostel they were filed under, so past reports stay accurate. The transfer is audited.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Student ID"
+// @Param request body TransferStudentRequest true "New department and/or hostel"
+// @Success 200 {object} map[string]interface{} "Student transferred"
+// @Failure 400 {object} map[string]interface{} "Validation failed or nothing to change"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Student not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/{id}/transfer [put]
+func TransferStudent(c *gin.Context) {
+	var input TransferStudentRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+	if input.Dept != nil {
+		trimmed := strings.TrimSpace(*input.Dept)
+		input.Dept = &trimmed
+	}
+	if input.Hostel != nil {
+		trimmed := strings.TrimSpace(*input.Hostel)
+		input.Hostel = &trimmed
+	}
+
+	if err := validation.ValidateStruct(input); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+	if input.Dept != nil && *input.Dept == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dept must not be empty"})
+		return
+	}
+
+	var student users.User
+	if err := db.DB.Where("role = ?", users.RoleStudent).First(&student, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return
+	}
+
+	fromDept, fromHostel := student.Dept, optionalValue(student.Hostel)
+	toDept, toHostel := fromDept, fromHostel
+	if input.Dept != nil {
+		toDept = *input.Dept
+	}
+	if input.Hostel != nil {
+		toHostel = *input.Hostel
+	}
+	if toDept == fromDept && toHostel == fromHostel {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The student is already in that department and hostel"})
+		return
+	}
+
+	var hostel *string
+	if toHostel != "" {
+		hostel = &toHostel
+	}
+
+	actorIDVal, _ := c.Get("userID")
+	actorID := actorIDVal.(uint)
+
+	var rerouted int64
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&student).Updates(map[string]interface{}{"dept": toDept, "hostel": hostel}).Error; err != nil {
+			return err
+		}
+
+		// Leaves are routed on the scope stored with them, so only undecided ones follow the student
+		result := tx.Model(&leaves.LeaveRequest{}).
+			Where("student_id = ? AND status IN ?", student.ID, []string{"pending", "needs_info"}).
+			Updates(map[string]interface{}{"dept": toDept, "hostel": hostel, "version": gorm.Expr("version + 1")})
+		if result.Error != nil {
+			return result.Error
+		}
+		rerouted = result.RowsAffected
+
+		details := fmt.Sprintf("transferred from %s/%s to %s/%s; %d open leaves rerouted",
+			fromDept, hostelLabel(fromHostel), toDept, hostelLabel(toHostel), rerouted)
+		return audit.Record(tx, actorID, "student_transfer", "user", student.ID, details)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer student"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Student transferred successfully",
+		"student_id":      student.ID,
+		"dept":            toDept,
+		"hostel":          hostel,
+		"rerouted_leaves": rerouted,
+	})
+}
+
+func optionalValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// hostelLabel names a hostel for the audit log, where no hostel would otherwise be blank
+func hostelLabel(hostel string) string {
+	if hostel == "" {
+		return "no hostel"
+	}
+	return hostel
+}
@@ -0,0 +1,80 @@
+package accounts
+
+import (
+	"bytes"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/leaves"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferStudent(t *testing.T) {
+	setupTestDB(t)
+	h1 := "H1"
+	admin := users.User{Name: "Admin", Email: "admin@example.com", Password: "hashed", Role: users.RoleAdmin, Dept: "ADMIN", IsActive: true}
+	student := users.User{Name: "Asha Roy", Email: "asha@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", Hostel: &h1, IsActive: true}
+	db.DB.Create(&admin)
+	db.DB.Create(&student)
+
+	start := time.Now().AddDate(0, 0, 3)
+	leaveWith := func(status string) leaves.LeaveRequest {
+		leave := leaves.LeaveRequest{StudentID: student.ID, LeaveType: "personal", Reason: "Family function", StartDate: start, EndDate: start,
+			Status: status, Dept: "CS", Hostel: &h1, Days: 1}
+		db.DB.Create(&leave)
+		return leave
+	}
+	pending, needsInfo, approved := leaveWith("pending"), leaveWith("needs_info"), leaveWith("approved")
+
+	router := newTestRouter(admin)
+	transfer := func(id uint, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/users/"+strconv.Itoa(int(id))+"/transfer", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNotFound, transfer(admin.ID, `{"dept":"EE"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, transfer(student.ID, `{"dept":"CS","hostel":"H1"}`).Code)
+
+	rec := transfer(student.ID, `{"dept":"EE","hostel":"H2"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"rerouted_leaves":2`)
+
+	var stored users.User
+	db.DB.First(&stored, student.ID)
+	assert.Equal(t, "EE", stored.Dept)
+	assert.Equal(t, "H2", *stored.Hostel)
+
+	// Open leaves follow the student with a new version; decided ones keep their original scope
+	for _, id := range []uint{pending.ID, needsInfo.ID} {
+		var leave leaves.LeaveRequest
+		db.DB.First(&leave, id)
+		assert.Equal(t, "EE", leave.Dept)
+		assert.Equal(t, "H2", *leave.Hostel)
+		assert.Equal(t, 2, leave.Version)
+	}
+	var decided leaves.LeaveRequest
+	db.DB.First(&decided, approved.ID)
+	assert.Equal(t, "CS", decided.Dept)
+	assert.Equal(t, "H1", *decided.Hostel)
+
+	// Moving out of the hostel clears it
+	assert.Equal(t, http.StatusOK, transfer(student.ID, `{"hostel":""}`).Code)
+	db.DB.First(&stored, student.ID)
+	assert.Nil(t, stored.Hostel)
+
+	var entries []audit.AuditLog
+	db.DB.Where("action = ?", "student_transfer").Order("id ASC").Find(&entries)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "transferred from CS/H1 to EE/H2; 2 open leaves rerouted", entries[0].Details)
+		assert.Contains(t, entries[1].Details, "to EE/no hostel")
+	}
+}
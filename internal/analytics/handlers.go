@@ -1,19 +1,134 @@
 package analytics
 
 import (
+	"campus-backend/internal/auth"
+	"campus-backend/internal/core"
+	"campus-backend/internal/leaves"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/timeutil"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // DashboardStats struct - holds dashboard data
 type DashboardStats struct {
-	TotalStudents     int64   `json:"total_students"`
-	TotalLeaves       int64   `json:"total_leaves"`
-	PendingLeaves     int64   `json:"pending_leaves"`
+	TotalStudents     int64             `json:"total_students"`
+	TotalLeaves       int64             `json:"total_leaves"`
+	PendingLeaves     int64             `json:"pending_leaves"`
+	AverageAttendance float64           `json:"average_attendance"`
+	Errors            map[string]string `json:"errors,omitempty"` // Sections that failed to load, keyed by field
+}
+
+// AdminDashboard struct - holds the admin landing page data
+type AdminDashboard struct {
+	Summary          DashboardStats         `json:"summary"`
+	UsersByRole      map[string]int64       `json:"users_by_role"`
+	TodayAttendance  AttendanceCompleteness `json:"today_attendance"`
+	PendingApprovals PendingApprovals       `json:"pending_approvals"`
+	Errors           map[string]string      `json:"errors,omitempty"`
+}
+
+// AttendanceCompleteness struct - holds how many students were marked today
+type AttendanceCompleteness struct {
+	StudentsMarked      int64   `json:"students_marked"`
+	TotalStudents       int64   `json:"total_students"`
+	CompletenessPercent float64 `json:"completeness_percent"`
+}
+
+// PendingApprovals struct - holds pending leave counts per approval scope
+type PendingApprovals struct {
+	Total    int64            `json:"total"`
+	ByDept   map[string]int64 `json:"by_dept"`
+	ByHostel map[string]int64 `json:"by_hostel"`
+}
+
+// WardenDashboard struct - holds the warden landing page data for one hostel
+type WardenDashboard struct {
+	Hostel         string              `json:"hostel"`
+	Occupancy      int64               `json:"occupancy"`
+	PendingLeaves  int64               `json:"pending_leaves"`
+	OnLeaveToday   []OnLeaveRecord     `json:"on_leave_today"`
+	RecentActivity []leaves.LeaveEvent `json:"recent_activity"`
+	Errors         map[string]string   `json:"errors,omitempty"`
+}
+
+// OnLeaveRecord struct - holds a student currently away on approved leave
+type OnLeaveRecord struct {
+	LeaveID     uint      `json:"leave_id"`
+	StudentID   uint      `json:"student_id"`
+	StudentName string    `json:"student_name"`
+	LeaveType   string    `json:"leave_type"`
+	EndDate     time.Time `json:"end_date"`
+}
+
+// FacultyDashboard struct - holds the faculty landing page data for one department
+type FacultyDashboard struct {
+	Dept                  string                 `json:"dept"`
+	TotalStudents         int64                  `json:"total_students"`
+	PendingLeaves         int64                  `json:"pending_leaves"`
+	TodayMarking          []SubjectMarkingStatus `json:"today_marking"`
+	LowAttendanceStudents []LowAttendanceRecord  `json:"low_attendance_students"`
+	Errors                map[string]string      `json:"errors,omitempty"`
+}
+
+// StudentSummary struct - holds the student landing page data
+type StudentSummary struct {
+	AttendancePercentage float64              `json:"attendance_percentage"`
+	AttendanceThreshold  float64              `json:"attendance_threshold"`
+	BelowThreshold       bool                 `json:"below_threshold"` // False until attendance has been recorded
+	OpenLeaves           int64                `json:"open_leaves"`     // Pending or needs_info
+	NextLeave            *leaves.LeaveRequest `json:"next_leave,omitempty"`
+	Errors               map[string]string    `json:"errors,omitempty"`
+}
+
+// SubjectMarkingStatus struct - holds today's marking progress for one subject
+type SubjectMarkingStatus struct {
+	Subject        string `json:"subject"`
+	StudentsMarked int64  `json:"students_marked"`
+	Present        int64  `json:"present"` // Includes late
+	Late           int64  `json:"late"`
+	TotalStudents  int64  `json:"total_students"`
+}
+
+// DepartmentComparison struct - holds one department's attendance, ranked against the others
+type DepartmentComparison struct {
+	Rank              int     `json:"rank"` // 1 is the best; departments with equal averages share a rank
+	Dept              string  `json:"dept"`
+	StudentCount      int64   `json:"student_count"`
+	TotalRecords      int64   `json:"total_records"`
+	PresentRecords    int64   `json:"present_records"` // Includes late
 	AverageAttendance float64 `json:"average_attendance"`
 }
 
+// Factors that put a student at risk
+const (
+	RiskLowAttendance  = "low_attendance"
+	RiskFrequentLeaves = "frequent_leaves"
+)
+
+// AtRiskStudent struct - holds a student flagged for low attendance, frequent leaves or both
+type AtRiskStudent struct {
+	Rank              int      `json:"rank"`
+	StudentID         uint     `json:"student_id"`
+	StudentName       string   `json:"student_name"`
+	RiskScore         float64  `json:"risk_score"` // 0-100, weighted by AT_RISK_ATTENDANCE_WEIGHT and AT_RISK_LEAVE_WEIGHT
+	Factors           []string `json:"factors"`
+	AttendancePercent *float64 `json:"attendance_percent,omitempty"` // Set when below the threshold
+	Threshold         float64  `json:"threshold,omitempty"`          // The student's department threshold, set with AttendancePercent
+	LeaveCount        int      `json:"leave_count"`                  // Approved leaves filed in the range
+}
+
+// LowAttendanceRecord struct - holds a student below the attendance threshold
+type LowAttendanceRecord struct {
+	StudentID         uint    `json:"student_id"`
+	StudentName       string  `json:"student_name"`
+	Dept              string  `json:"dept,omitempty"`
+	AttendancePercent float64 `json:"attendance_percent"`
+}
+
 // GetSummary function - gets dashboard summary for admin
 func GetSummary(c *gin.Context) {
 	// Create service instance
@@ -22,7 +137,7 @@ func GetSummary(c *gin.Context) {
 	// Get dashboard stats
 	stats, err := service.GetDashboardSummary()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": core.PublicError(err, "Failed to get summary")})
 		return
 	}
 
@@ -30,15 +145,100 @@ func GetSummary(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetAdminDashboard function - gets the combined dashboard payload for admin
+func GetAdminDashboard(c *gin.Context) {
+	// Create service instance
+	service := NewService()
+
+	// Get dashboard data
+	dashboard, err := service.GetAdminDashboard()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": core.PublicError(err, "Failed to get admin dashboard")})
+		return
+	}
+
+	// Send dashboard as JSON
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// GetWardenDashboard function - gets hostel-scoped dashboard data for the logged-in warden
+func GetWardenDashboard(c *gin.Context) {
+	warden, err := auth.CurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
+		return
+	}
+	if warden.Hostel == nil || *warden.Hostel == "" {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No hostel assigned to this warden"})
+		return
+	}
+
+	// Create service instance
+	service := NewService()
+
+	// Get dashboard data
+	dashboard, err := service.GetWardenDashboard(*warden.Hostel)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": core.PublicError(err, "Failed to get warden dashboard")})
+		return
+	}
+
+	// Send dashboard as JSON
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// GetFacultyDashboard function - gets department-scoped dashboard data for the logged-in faculty
+func GetFacultyDashboard(c *gin.Context) {
+	faculty, err := auth.CurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Create service instance
+	service := NewService()
+
+	// Get dashboard data
+	dashboard, err := service.GetFacultyDashboard(faculty.Dept)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": core.PublicError(err, "Failed to get faculty dashboard")})
+		return
+	}
+
+	// Send dashboard as JSON
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// GetDemographics function - gets student headcounts by department and hostel for admin
+func GetDemographics(c *gin.Context) {
+	// Create service instance
+	service := NewService()
+
+	// Get headcounts
+	demographics, err := service.GetDemographics()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": core.PublicError(err, "Failed to get demographics")})
+		return
+	}
+
+	// Send headcounts as JSON
+	c.JSON(http.StatusOK, demographics)
+}
+
 // GetLeaveAnalytics function - gets leave analytics for admin
 func GetLeaveAnalytics(c *gin.Context) {
+	dr, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
 	// Create service instance
 	service := NewService()
 
 	// Get analytics data
-	analytics, err := service.GetLeaveAnalytics()
+	analytics, err := service.GetLeaveAnalytics(dr)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": core.PublicError(err, "Failed to get leave analytics")})
 		return
 	}
 
@@ -48,13 +248,18 @@ func GetLeaveAnalytics(c *gin.Context) {
 
 // GetAttendanceAnalytics function - gets attendance analytics for admin
 func GetAttendanceAnalytics(c *gin.Context) {
+	dr, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
 	// Create service instance
 	service := NewService()
 
 	// Get analytics data
-	analytics, err := service.GetAttendanceAnalytics()
+	analytics, err := service.GetAttendanceAnalytics(dr)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": core.PublicError(err, "Failed to get attendance analytics")})
 		return
 	}
 
@@ -62,9 +267,108 @@ func GetAttendanceAnalytics(c *gin.Context) {
 	c.JSON(http.StatusOK, analytics)
 }
 
+// CompareDepartments function - gets departments ranked by attendance for admin
+func CompareDepartments(c *gin.Context) {
+	dr, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	var depts []string
+	for _, dept := range strings.Split(c.Query("depts"), ",") {
+		if dept = strings.TrimSpace(dept); dept != "" {
+			depts = append(depts, dept)
+		}
+	}
+
+	// Create service instance
+	service := NewService()
+
+	// Get comparison data
+	comparison, err := service.CompareDepartments(dr, depts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": core.PublicError(err, "Failed to compare departments")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":        dr.From.In(timeutil.Location()).Format(timeutil.DateLayout),
+		"to":          dr.To.In(timeutil.Location()).Format(timeutil.DateLayout),
+		"departments": comparison,
+	})
+}
+
+// GetAtRiskStudents function - gets students at risk from low attendance or frequent leaves; faculty see their department only
+func GetAtRiskStudents(c *gin.Context) {
+	dr, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	dept := c.Query("dept")
+	if role, _ := auth.CurrentRole(c); role == users.RoleFaculty {
+		faculty, err := auth.CurrentUser(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
+			return
+		}
+		if faculty.Dept == "" || (dept != "" && dept != faculty.Dept) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only view students from your department"})
+			return
+		}
+		dept = faculty.Dept
+	}
+
+	// Create service instance
+	service := NewService()
+
+	// Get at-risk students
+	students, err := service.GetAtRiskStudents(dr, dept)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": core.PublicError(err, "Failed to get at-risk students")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":       dr.From.In(timeutil.Location()).Format(timeutil.DateLayout),
+		"to":         dr.To.In(timeutil.Location()).Format(timeutil.DateLayout),
+		"dept":       dept,
+		"threshold":  atRiskThreshold(dept),
+		"min_leaves": core.GetConfig().Attendance.AtRiskMinLeaves,
+		"students":   students,
+	})
+}
+
 // AbsenteeRecord struct - holds absentee data
 type AbsenteeRecord struct {
 	StudentID   uint   `json:"student_id"`
 	StudentName string `json:"student_name"`
 	LeaveCount  int    `json:"leave_count"`
 }
+
+// parseDateRange reads the from/to query parameters (YYYY-MM-DD), defaulting to the last
+// 12 months. Writes an error response and returns false on invalid input.
+func parseDateRange(c *gin.Context) (DateRange, bool) {
+	dr := DefaultDateRange()
+	if value := c.Query("from"); value != "" {
+		from, err := timeutil.ParseDate(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from format, expected YYYY-MM-DD"})
+			return dr, false
+		}
+		dr.From = from
+	}
+	if value := c.Query("to"); value != "" {
+		to, err := timeutil.ParseDate(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to format, expected YYYY-MM-DD"})
+			return dr, false
+		}
+		dr.To = to
+	}
+	if dr.To.Before(dr.From) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return dr, false
+	}
+	return dr, true
+}
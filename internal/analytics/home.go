@@ -0,0 +1,70 @@
+package analytics
+
+import (
+	"campus-backend/internal/auth"
+	"campus-backend/internal/core"
+	"campus-backend/internal/users"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Home tells the frontend where to send a user after login
+type Home struct {
+	Role      string      `json:"role"`
+	Dashboard string      `json:"dashboard"`       // admin, faculty, warden or student
+	Route     string      `json:"route,omitempty"` // From HOME_ROUTES; empty when the role has none
+	Summary   interface{} `json:"summary"`         // The role's dashboard data
+}
+
+// GetMyHome godoc
+// @Summary Get the current user's landing page
+// @Description Returns the dashboard for the caller's role, the frontend route configured for it in HOME_ROUTES and that dashboard's data: the admin, warden or faculty dashboard, or a student summary of attendance against the threshold, open leaves and the next approved leave.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Home "Landing page"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Role has no landing page"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/me/home [get]
+func GetMyHome(c *gin.Context) {
+	user, err := auth.CurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	role, _ := auth.CurrentRole(c)
+
+	service := NewService()
+	var summary interface{}
+	switch role {
+	case users.RoleAdmin:
+		summary, err = service.GetAdminDashboard()
+	case users.RoleWarden:
+		if user.Hostel == nil || *user.Hostel == "" {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "No hostel assigned to this warden"})
+			return
+		}
+		summary, err = service.GetWardenDashboard(*user.Hostel)
+	case users.RoleFaculty:
+		summary, err = service.GetFacultyDashboard(user.Dept)
+	case users.RoleStudent:
+		summary, err = service.GetStudentSummary(user)
+	default:
+		// Service accounts authenticate with API keys and have no dashboard
+		c.JSON(http.StatusForbidden, gin.H{"error": "No landing page for this role"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": core.PublicError(err, "Failed to load the dashboard")})
+		return
+	}
+
+	c.JSON(http.StatusOK, Home{
+		Role:      role,
+		Dashboard: role,
+		Route:     core.GetConfig().Server.HomeRoutes[role],
+		Summary:   summary,
+	})
+}
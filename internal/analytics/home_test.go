@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"campus-backend/internal/attendance"
+	"campus-backend/internal/core"
+	"campus-backend/internal/leaves"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestGetMyHome(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&users.User{}, &attendance.Attendance{}, &leaves.LeaveRequest{}, &leaves.LeaveEvent{})
+	db.DB = testDB
+	defer func() { core.AppConfig = nil }()
+	t.Setenv("HOME_ROUTES", "student=/me,faculty=/classes")
+	core.LoadConfig()
+
+	student := users.User{Name: "Student", Email: "student@example.com", Password: "x", Role: users.RoleStudent, Dept: "CS", IsActive: true}
+	faculty := users.User{Name: "Faculty", Email: "faculty@example.com", Password: "x", Role: users.RoleFaculty, Dept: "CS", IsActive: true}
+	warden := users.User{Name: "Warden", Email: "warden@example.com", Password: "x", Role: users.RoleWarden, Dept: "ADMIN", IsActive: true}
+	db.DB.Create(&student)
+	db.DB.Create(&faculty)
+	db.DB.Create(&warden)
+
+	today := timeutil.Today()
+	for i, present := range []bool{true, true, true, false} {
+		db.DB.Create(&attendance.Attendance{StudentID: student.ID, Date: today.AddDate(0, 0, -i-1), Present: present, Status: "present", MarkedBy: faculty.ID})
+	}
+	next := leaves.LeaveRequest{StudentID: student.ID, LeaveType: "personal", Reason: "Family function", StartDate: today.AddDate(0, 0, 3), EndDate: today.AddDate(0, 0, 4), Status: "approved", Dept: "CS", Days: 2}
+	db.DB.Create(&next)
+	db.DB.Create(&leaves.LeaveRequest{StudentID: student.ID, LeaveType: "medical", Reason: "Check-up", StartDate: today.AddDate(0, 0, 10), EndDate: today.AddDate(0, 0, 10), Status: "pending", Dept: "CS", Days: 1})
+	db.DB.Create(&leaves.LeaveRequest{StudentID: student.ID, LeaveType: "academic", Reason: "Conference", StartDate: today.AddDate(0, 0, 20), EndDate: today.AddDate(0, 0, 21), Status: "needs_info", Dept: "CS", Days: 2})
+
+	home := func(user users.User) (int, []byte) {
+		r := gin.New()
+		r.GET("/users/me/home", func(c *gin.Context) {
+			c.Set("userID", user.ID)
+			c.Set("role", user.Role)
+		}, GetMyHome)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/me/home", nil))
+		return rec.Code, rec.Body.Bytes()
+	}
+
+	code, body := home(student)
+	assert.Equal(t, http.StatusOK, code)
+	var studentHome struct {
+		Dashboard string         `json:"dashboard"`
+		Route     string         `json:"route"`
+		Summary   StudentSummary `json:"summary"`
+	}
+	json.Unmarshal(body, &studentHome)
+	assert.Equal(t, users.RoleStudent, studentHome.Dashboard)
+	assert.Equal(t, "/me", studentHome.Route)
+	assert.Equal(t, 75.0, studentHome.Summary.AttendancePercentage)
+	assert.False(t, studentHome.Summary.BelowThreshold)
+	assert.Equal(t, int64(2), studentHome.Summary.OpenLeaves)
+	if assert.NotNil(t, studentHome.Summary.NextLeave) {
+		assert.Equal(t, next.ID, studentHome.Summary.NextLeave.ID)
+	}
+
+	code, body = home(faculty)
+	assert.Equal(t, http.StatusOK, code)
+	var facultyHome struct {
+		Route   string           `json:"route"`
+		Summary FacultyDashboard `json:"summary"`
+	}
+	json.Unmarshal(body, &facultyHome)
+	assert.Equal(t, "/classes", facultyHome.Route)
+	assert.Equal(t, int64(1), facultyHome.Summary.TotalStudents)
+	assert.Equal(t, int64(2), facultyHome.Summary.PendingLeaves) // needs_info is still open
+
+	// Without a hostel the warden dashboard cannot be built
+	code, _ = home(warden)
+	assert.Equal(t, http.StatusInternalServerError, code)
+}
@@ -0,0 +1,32 @@
+package analytics
+
+import (
+	"campus-backend/pkg/timeutil"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DateRange bounds analytics queries to whole campus days, both ends inclusive
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// DefaultDateRange covers the current month and the 11 before it, up to today
+func DefaultDateRange() DateRange {
+	now := timeutil.Now()
+	from := time.Date(now.Year(), now.Month()-11, 1, 0, 0, 0, 0, timeutil.Location())
+	return DateRange{From: from.UTC(), To: timeutil.Today()}
+}
+
+// End returns the exclusive upper bound: the start of the day after To
+func (d DateRange) End() time.Time {
+	_, end := timeutil.DayBounds(d.To)
+	return end
+}
+
+// apply constrains the query so column falls within the range
+func (d DateRange) apply(query *gorm.DB, column string) *gorm.DB {
+	return query.Where(column+" >= ? AND "+column+" < ?", d.From, d.End())
+}
@@ -5,6 +5,7 @@ import (
 	"campus-backend/internal/leaves"
 	"campus-backend/internal/users"
 	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
 
 	"gorm.io/gorm"
 )
@@ -28,7 +29,7 @@ func (r *Repository) GetLeaveStats() (total int64, pending int64, err error) {
 	if err != nil {
 		return
 	}
-	err = r.db.Model(&leaves.LeaveRequest{}).Where("status = ?", "pending").Count(&pending).Error
+	err = r.db.Model(&leaves.LeaveRequest{}).Where("status IN ?", leaves.OpenStatuses).Count(&pending).Error
 	return
 }
 
@@ -42,17 +43,20 @@ func (r *Repository) GetAttendanceAverage() (float64, error) {
 	return result.Average * 100, err
 }
 
-func (r *Repository) GetMonthlyLeaveBreakdown() (map[string]int, error) {
+func (r *Repository) GetMonthlyLeaveBreakdown(dr DateRange) (map[string]int, error) {
 	var results []struct {
 		Month string
 		Count int
 	}
 
-	err := r.db.Model(&leaves.LeaveRequest{}).
-		Select("DATE_TRUNC('month', created_at) as month, COUNT(*) as count").
-		Group("DATE_TRUNC('month', created_at)").
+	month, err := db.DateBucket(r.db, db.PeriodMonth, "created_at")
+	if err != nil {
+		return nil, err
+	}
+	err = dr.apply(r.db.Model(&leaves.LeaveRequest{}), "created_at").
+		Select(month + " as month, COUNT(*) as count").
+		Group(month).
 		Order("month DESC").
-		Limit(12).
 		Scan(&results).Error
 
 	if err != nil {
@@ -61,20 +65,19 @@ func (r *Repository) GetMonthlyLeaveBreakdown() (map[string]int, error) {
 
 	breakdown := make(map[string]int)
 	for _, result := range results {
-		month := result.Month[:7] // Get YYYY-MM format
-		breakdown[month] = result.Count
+		breakdown[result.Month] = result.Count
 	}
 
 	return breakdown, nil
 }
 
-func (r *Repository) GetLeaveTypesDistribution() (map[string]int, error) {
+func (r *Repository) GetLeaveTypesDistribution(dr DateRange) (map[string]int, error) {
 	var results []struct {
 		LeaveType string
 		Count     int
 	}
 
-	err := r.db.Model(&leaves.LeaveRequest{}).
+	err := dr.apply(r.db.Model(&leaves.LeaveRequest{}), "created_at").
 		Select("leave_type, COUNT(*) as count").
 		Group("leave_type").
 		Scan(&results).Error
@@ -91,57 +94,81 @@ func (r *Repository) GetLeaveTypesDistribution() (map[string]int, error) {
 	return distribution, nil
 }
 
-func (r *Repository) GetTopAbsentees() ([]AbsenteeRecord, error) {
+// GetTopAbsentees returns active students by approved leaves filed in the range, most first,
+// optionally for one department only. A limit of 0 returns every student.
+func (r *Repository) GetTopAbsentees(dr DateRange, dept string, limit int) ([]AbsenteeRecord, error) {
 	var results []AbsenteeRecord
 
-	err := r.db.Table("users").
+	query := r.db.Table("users").
 		Select("users.id as student_id, users.name as student_name, COUNT(leave_requests.id) as leave_count").
-		Joins("LEFT JOIN leave_requests ON users.id = leave_requests.student_id AND leave_requests.status = 'approved'").
-		Where("users.role = ?", "student").
-		Group("users.id, users.name").
-		Order("leave_count DESC").
-		Limit(10).
-		Scan(&results).Error
+		Joins("LEFT JOIN leave_requests ON users.id = leave_requests.student_id AND leave_requests.status = 'approved' AND leave_requests.deleted_at IS NULL AND leave_requests.created_at >= ? AND leave_requests.created_at < ?", dr.From, dr.End()).
+		Where("users.role = ? AND users.deleted_at IS NULL AND users.is_active = ?", "student", true)
+	if dept != "" {
+		query = query.Where("users.dept = ?", dept)
+	}
+	query = query.Group("users.id, users.name").Order("leave_count DESC, users.id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Scan(&results).Error
 
 	return results, err
 }
 
-func (r *Repository) GetDepartmentWiseAttendance() (map[string]float64, error) {
-	var results []struct {
-		Dept          string
-		AvgAttendance float64
-	}
-
-	err := r.db.Table("users").
-		Select("users.dept, AVG(CASE WHEN attendance.present THEN 1 ELSE 0 END) * 100 as avg_attendance").
-		Joins("LEFT JOIN attendance ON users.id = attendance.student_id").
-		Where("users.role = ?", "student").
-		Group("users.dept").
-		Scan(&results).Error
-
+func (r *Repository) GetDepartmentWiseAttendance(dr DateRange) (map[string]float64, error) {
+	results, err := r.GetDepartmentAttendance(dr, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	deptWise := make(map[string]float64)
 	for _, result := range results {
-		deptWise[result.Dept] = result.AvgAttendance
+		deptWise[result.Dept] = result.AverageAttendance
 	}
 
 	return deptWise, nil
 }
 
-func (r *Repository) GetMonthlyAttendanceTrend() (map[string]float64, error) {
+// GetDepartmentAttendance returns attendance totals per department within the range, optionally
+// limited to the given departments. Students without records count towards StudentCount only.
+func (r *Repository) GetDepartmentAttendance(dr DateRange, depts []string) ([]DepartmentComparison, error) {
+	var results []DepartmentComparison
+
+	query := r.db.Table("users").
+		Select("users.dept, COUNT(DISTINCT users.id) as student_count, COUNT(attendances.id) as total_records, COUNT(CASE WHEN attendances.present THEN 1 END) as present_records").
+		Joins("LEFT JOIN attendances ON users.id = attendances.student_id AND attendances.deleted_at IS NULL AND attendances.date >= ? AND attendances.date < ?", dr.From, dr.End()).
+		Where("users.role = ? AND users.deleted_at IS NULL", "student")
+	if len(depts) > 0 {
+		query = query.Where("users.dept IN ?", depts)
+	}
+	err := query.Group("users.dept").Order("users.dept ASC").Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		if results[i].TotalRecords > 0 {
+			results[i].AverageAttendance = float64(results[i].PresentRecords) * 100 / float64(results[i].TotalRecords)
+		}
+	}
+
+	return results, nil
+}
+
+func (r *Repository) GetMonthlyAttendanceTrend(dr DateRange) (map[string]float64, error) {
 	var results []struct {
 		Month         string
 		AvgAttendance float64
 	}
 
-	err := r.db.Table("attendance").
-		Select("DATE_TRUNC('month', date) as month, AVG(CASE WHEN present THEN 1 ELSE 0 END) * 100 as avg_attendance").
-		Group("DATE_TRUNC('month', date)").
+	month, err := db.DateBucket(r.db, db.PeriodMonth, "date")
+	if err != nil {
+		return nil, err
+	}
+	err = dr.apply(r.db.Model(&attendance.Attendance{}), "date").
+		Select(month + " as month, AVG(CASE WHEN present THEN 1 ELSE 0 END) * 100 as avg_attendance").
+		Group(month).
 		Order("month DESC").
-		Limit(12).
 		Scan(&results).Error
 
 	if err != nil {
@@ -150,25 +177,305 @@ func (r *Repository) GetMonthlyAttendanceTrend() (map[string]float64, error) {
 
 	trend := make(map[string]float64)
 	for _, result := range results {
-		month := result.Month[:7] // Get YYYY-MM format
-		trend[month] = result.AvgAttendance
+		trend[result.Month] = result.AvgAttendance
 	}
 
 	return trend, nil
 }
 
-func (r *Repository) GetLowAttendanceStudents() ([]AbsenteeRecord, error) {
-	var results []AbsenteeRecord
+// GetLowAttendanceStudents returns active students whose attendance within the range is below
+// threshold, lowest first, optionally for one department only. A limit of 0 returns every such student.
+func (r *Repository) GetLowAttendanceStudents(dr DateRange, threshold float64, dept string, limit int) ([]LowAttendanceRecord, error) {
+	var results []LowAttendanceRecord
+
+	query := r.db.Table("users").
+		Select("users.id as student_id, users.name as student_name, users.dept as dept, (COUNT(CASE WHEN attendances.present THEN 1 END) * 100.0 / COUNT(attendances.id)) as attendance_percent").
+		Joins("JOIN attendances ON users.id = attendances.student_id AND attendances.deleted_at IS NULL AND attendances.date >= ? AND attendances.date < ?", dr.From, dr.End()).
+		Where("users.role = ? AND users.deleted_at IS NULL AND users.is_active = ?", "student", true)
+	if dept != "" {
+		query = query.Where("users.dept = ?", dept)
+	}
+	query = query.Group("users.id, users.name, users.dept").
+		Having("(COUNT(CASE WHEN attendances.present THEN 1 END) * 100.0 / COUNT(attendances.id)) < ?", threshold).
+		Order("attendance_percent ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Scan(&results).Error
+
+	return results, err
+}
+
+func (r *Repository) GetUserCountsByRole() (map[string]int64, error) {
+	var results []struct {
+		Role  string
+		Count int64
+	}
+
+	// API-key service accounts are not people
+	err := r.db.Model(&users.User{}).
+		Select("role, COUNT(*) as count").
+		Where("role <> ?", users.RoleService).
+		Group("role").
+		Scan(&results).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64)
+	for _, result := range results {
+		counts[result.Role] = result.Count
+	}
+
+	return counts, nil
+}
+
+func (r *Repository) GetTodayMarkedStudentCount() (int64, error) {
+	var count int64
+	start, end := timeutil.DayBounds(timeutil.Today())
+	err := r.db.Model(&attendance.Attendance{}).
+		Where("date >= ? AND date < ?", start, end).
+		Distinct("student_id").
+		Count(&count).Error
+	return count, err
+}
+
+func (r *Repository) GetPendingLeavesByDept() (map[string]int64, error) {
+	var results []struct {
+		Dept  string
+		Count int64
+	}
+
+	err := r.db.Model(&leaves.LeaveRequest{}).
+		Select("dept, COUNT(*) as count").
+		Where("status IN ?", leaves.OpenStatuses).
+		Group("dept").
+		Scan(&results).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	byDept := make(map[string]int64)
+	for _, result := range results {
+		byDept[result.Dept] = result.Count
+	}
+
+	return byDept, nil
+}
+
+func (r *Repository) GetPendingLeavesByHostel() (map[string]int64, error) {
+	var results []struct {
+		Hostel string
+		Count  int64
+	}
+
+	err := r.db.Model(&leaves.LeaveRequest{}).
+		Select("hostel, COUNT(*) as count").
+		Where("status IN ? AND hostel IS NOT NULL", leaves.OpenStatuses).
+		Group("hostel").
+		Scan(&results).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	byHostel := make(map[string]int64)
+	for _, result := range results {
+		byHostel[result.Hostel] = result.Count
+	}
+
+	return byHostel, nil
+}
+
+func (r *Repository) GetHostelStudentCount(hostel string) (int64, error) {
+	var count int64
+	err := r.db.Model(&users.User{}).Where("role = ? AND hostel = ?", "student", hostel).Count(&count).Error
+	return count, err
+}
+
+func (r *Repository) GetHostelPendingLeaveCount(hostel string) (int64, error) {
+	var count int64
+	err := r.db.Model(&leaves.LeaveRequest{}).Where("status IN ? AND hostel = ?", leaves.OpenStatuses, hostel).Count(&count).Error
+	return count, err
+}
+
+func (r *Repository) GetHostelStudentsOnLeaveToday(hostel string) ([]OnLeaveRecord, error) {
+	var results []OnLeaveRecord
+	start, end := timeutil.DayBounds(timeutil.Today())
+
+	err := r.db.Table("leave_requests").
+		Select("leave_requests.id as leave_id, users.id as student_id, users.name as student_name, leave_requests.leave_type, leave_requests.end_date").
+		Joins("JOIN users ON users.id = leave_requests.student_id").
+		Where("leave_requests.hostel = ? AND leave_requests.status = ?", hostel, "approved").
+		Where("leave_requests.start_date < ? AND leave_requests.end_date >= ?", end, start).
+		Where("leave_requests.deleted_at IS NULL").
+		Order("leave_requests.end_date ASC").
+		Scan(&results).Error
+
+	return results, err
+}
+
+func (r *Repository) GetHostelRecentLeaveActivity(hostel string, limit int) ([]leaves.LeaveEvent, error) {
+	var events []leaves.LeaveEvent
+
+	err := r.db.Joins("JOIN leave_requests ON leave_requests.id = leave_events.leave_id AND leave_requests.deleted_at IS NULL").
+		Where("leave_requests.hostel = ?", hostel).
+		Preload("Actor").
+		Order("leave_events.created_at DESC").
+		Limit(limit).
+		Find(&events).Error
+
+	return events, err
+}
+
+func (r *Repository) GetDeptStudentCount(dept string) (int64, error) {
+	var count int64
+	err := r.db.Model(&users.User{}).Where("role = ? AND dept = ?", "student", dept).Count(&count).Error
+	return count, err
+}
+
+func (r *Repository) GetDeptPendingLeaveCount(dept string) (int64, error) {
+	var count int64
+	err := r.db.Model(&leaves.LeaveRequest{}).Where("status IN ? AND dept = ?", leaves.OpenStatuses, dept).Count(&count).Error
+	return count, err
+}
+
+func (r *Repository) GetDeptTodayMarkingBySubject(dept string) ([]SubjectMarkingStatus, error) {
+	var results []SubjectMarkingStatus
+	start, end := timeutil.DayBounds(timeutil.Today())
+
+	err := r.db.Table("attendances").
+		Select("COALESCE(attendances.subject, 'unspecified') as subject, COUNT(DISTINCT attendances.student_id) as students_marked, COUNT(CASE WHEN attendances.present THEN 1 END) as present, COUNT(CASE WHEN attendances.status = ? THEN 1 END) as late", attendance.StatusLate).
+		Joins("JOIN users ON users.id = attendances.student_id").
+		Where("users.dept = ? AND attendances.date >= ? AND attendances.date < ?", dept, start, end).
+		Where("attendances.deleted_at IS NULL").
+		Group("COALESCE(attendances.subject, 'unspecified')").
+		Order("subject ASC").
+		Scan(&results).Error
+
+	return results, err
+}
+
+func (r *Repository) GetAttendanceStatusBreakdown(dr DateRange) (map[string]int64, error) {
+	var results []struct {
+		Status string
+		Count  int64
+	}
+
+	err := dr.apply(r.db.Model(&attendance.Attendance{}), "date").
+		Select("status, COUNT(*) as count").
+		Group("status").
+		Scan(&results).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := map[string]int64{
+		attendance.StatusPresent: 0,
+		attendance.StatusAbsent:  0,
+		attendance.StatusLate:    0,
+		attendance.StatusExcused: 0,
+	}
+	for _, result := range results {
+		breakdown[result.Status] = result.Count
+	}
+
+	return breakdown, nil
+}
+
+func (r *Repository) GetDeptLowAttendanceStudents(dept string, threshold float64) ([]LowAttendanceRecord, error) {
+	var results []LowAttendanceRecord
 
 	err := r.db.Table("users").
-		Select("users.id as student_id, users.name as student_name, (COUNT(CASE WHEN attendance.present THEN 1 END) * 100.0 / COUNT(attendance.id)) as leave_count").
-		Joins("LEFT JOIN attendance ON users.id = attendance.student_id").
-		Where("users.role = ?", "student").
+		Select("users.id as student_id, users.name as student_name, (COUNT(CASE WHEN attendances.present THEN 1 END) * 100.0 / COUNT(attendances.id)) as attendance_percent").
+		Joins("JOIN attendances ON users.id = attendances.student_id AND attendances.deleted_at IS NULL").
+		Where("users.role = ? AND users.dept = ?", "student", dept).
 		Group("users.id, users.name").
-		Having("(COUNT(CASE WHEN attendance.present THEN 1 END) * 100.0 / COUNT(attendance.id)) < 75").
-		Order("leave_count ASC").
+		Having("(COUNT(CASE WHEN attendances.present THEN 1 END) * 100.0 / COUNT(attendances.id)) < ?", threshold).
+		Order("attendance_percent ASC").
 		Limit(10).
 		Scan(&results).Error
 
 	return results, err
 }
+
+func (r *Repository) GetStudentCountsByDept() (map[string]int64, error) {
+	var results []struct {
+		Dept  string
+		Count int64
+	}
+
+	err := r.db.Model(&users.User{}).
+		Select("dept, COUNT(*) as count").
+		Where("role = ?", "student").
+		Group("dept").
+		Scan(&results).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	byDept := make(map[string]int64)
+	for _, result := range results {
+		byDept[result.Dept] = result.Count
+	}
+
+	return byDept, nil
+}
+
+func (r *Repository) GetStudentCountsByHostel() (map[string]int64, error) {
+	var results []struct {
+		Hostel string
+		Count  int64
+	}
+
+	// Day scholars without a hostel are grouped as "unassigned"
+	err := r.db.Model(&users.User{}).
+		Select("COALESCE(hostel, 'unassigned') as hostel, COUNT(*) as count").
+		Where("role = ?", "student").
+		Group("COALESCE(hostel, 'unassigned')").
+		Scan(&results).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	byHostel := make(map[string]int64)
+	for _, result := range results {
+		byHostel[result.Hostel] = result.Count
+	}
+
+	return byHostel, nil
+}
+
+func (r *Repository) GetStudentAttendanceCounts(studentID uint) (total int64, present int64, err error) {
+	err = r.db.Model(&attendance.Attendance{}).Where("student_id = ?", studentID).Count(&total).Error
+	if err != nil {
+		return 0, 0, err
+	}
+	err = r.db.Model(&attendance.Attendance{}).Where("student_id = ? AND present = ?", studentID, true).Count(&present).Error
+	return total, present, err
+}
+
+func (r *Repository) GetStudentOpenLeaveCount(studentID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&leaves.LeaveRequest{}).Where("student_id = ? AND status IN ?", studentID, leaves.OpenStatuses).Count(&count).Error
+	return count, err
+}
+
+// GetStudentNextLeave returns the approved leave in effect today or starting soonest, if any
+func (r *Repository) GetStudentNextLeave(studentID uint) (*leaves.LeaveRequest, error) {
+	var leave leaves.LeaveRequest
+	start, _ := timeutil.DayBounds(timeutil.Today())
+	err := r.db.Where("student_id = ? AND status = ? AND end_date >= ?", studentID, "approved", start).
+		Order("start_date ASC").
+		Limit(1).
+		Find(&leave).Error
+	if err != nil || leave.ID == 0 {
+		return nil, err
+	}
+	return &leave, nil
+}
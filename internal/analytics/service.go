@@ -1,5 +1,14 @@
 package analytics
 
+import (
+	"campus-backend/internal/core"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/timeutil"
+	"log"
+	"math"
+	"sort"
+)
+
 type Service struct {
 	repo *Repository
 }
@@ -8,78 +17,428 @@ func NewService() *Service {
 	return &Service{repo: NewRepository()}
 }
 
+// sections tracks the independent parts of one analytics response. A failing query blanks
+// only its own section and is reported under that section's name, so the rest still renders.
+type sections struct {
+	report    string
+	attempted int
+	errors    map[string]string
+	first     error
+}
+
+func newSections(report string) *sections {
+	return &sections{report: report, errors: map[string]string{}}
+}
+
+// ok records the outcome of loading a section and reports whether it succeeded
+func (s *sections) ok(section string, err error) bool {
+	s.attempted++
+	if err == nil {
+		return true
+	}
+	log.Printf("Analytics: %s section %s failed: %v", s.report, section, err)
+	s.errors[section] = core.PublicError(err, "Failed to load this section")
+	if s.first == nil {
+		s.first = err
+	}
+	return false
+}
+
+// merge folds in another report's section errors under a prefix
+func (s *sections) merge(prefix string, errors map[string]string, attempted int) {
+	s.attempted += attempted
+	for section, message := range errors {
+		s.errors[prefix+"."+section] = message
+	}
+}
+
+// failed returns an error only when every section failed, leaving nothing worth returning
+func (s *sections) failed() error {
+	if s.attempted > 0 && len(s.errors) == s.attempted {
+		return s.first
+	}
+	return nil
+}
+
+// result returns the section errors for the response, or nil if everything loaded
+func (s *sections) result() map[string]string {
+	if len(s.errors) == 0 {
+		return nil
+	}
+	return s.errors
+}
+
 func (s *Service) GetDashboardSummary() (*DashboardStats, error) {
-	students, err := s.repo.GetStudentCount()
-	if err != nil {
+	summary, parts := s.dashboardSummary()
+	if err := parts.failed(); err != nil {
 		return nil, err
 	}
+	return summary, nil
+}
+
+// dashboardSummary loads the summary sections, leaving failed ones at zero
+func (s *Service) dashboardSummary() (*DashboardStats, *sections) {
+	parts := newSections("summary")
+	summary := &DashboardStats{}
+
+	students, err := s.repo.GetStudentCount()
+	if parts.ok("total_students", err) {
+		summary.TotalStudents = students
+	}
 
 	total, pending, err := s.repo.GetLeaveStats()
-	if err != nil {
-		return nil, err
+	if parts.ok("leaves", err) {
+		summary.TotalLeaves = total
+		summary.PendingLeaves = pending
 	}
 
 	avg, err := s.repo.GetAttendanceAverage()
-	if err != nil {
+	if parts.ok("average_attendance", err) {
+		summary.AverageAttendance = avg
+	}
+
+	summary.Errors = parts.result()
+	return summary, parts
+}
+
+func (s *Service) GetAdminDashboard() (*AdminDashboard, error) {
+	parts := newSections("admin dashboard")
+	summary, summaryParts := s.dashboardSummary()
+	parts.merge("summary", summaryParts.errors, summaryParts.attempted)
+	summary.Errors = nil // Reported once, on the dashboard
+
+	dashboard := &AdminDashboard{
+		Summary: *summary,
+		TodayAttendance: AttendanceCompleteness{
+			TotalStudents: summary.TotalStudents,
+		},
+		PendingApprovals: PendingApprovals{
+			Total: summary.PendingLeaves,
+		},
+	}
+
+	usersByRole, err := s.repo.GetUserCountsByRole()
+	if parts.ok("users_by_role", err) {
+		dashboard.UsersByRole = usersByRole
+	}
+
+	// Marking completeness: share of students with attendance recorded today
+	marked, err := s.repo.GetTodayMarkedStudentCount()
+	if parts.ok("today_attendance", err) {
+		dashboard.TodayAttendance.StudentsMarked = marked
+		if summary.TotalStudents > 0 {
+			dashboard.TodayAttendance.CompletenessPercent = float64(marked) * 100 / float64(summary.TotalStudents)
+		}
+	}
+
+	byDept, err := s.repo.GetPendingLeavesByDept()
+	if parts.ok("pending_approvals.by_dept", err) {
+		dashboard.PendingApprovals.ByDept = byDept
+	}
+
+	byHostel, err := s.repo.GetPendingLeavesByHostel()
+	if parts.ok("pending_approvals.by_hostel", err) {
+		dashboard.PendingApprovals.ByHostel = byHostel
+	}
+
+	if err := parts.failed(); err != nil {
 		return nil, err
 	}
+	dashboard.Errors = parts.result()
+	return dashboard, nil
+}
+
+func (s *Service) GetWardenDashboard(hostel string) (*WardenDashboard, error) {
+	parts := newSections("warden dashboard")
+	dashboard := &WardenDashboard{Hostel: hostel}
+
+	occupancy, err := s.repo.GetHostelStudentCount(hostel)
+	if parts.ok("occupancy", err) {
+		dashboard.Occupancy = occupancy
+	}
+
+	pending, err := s.repo.GetHostelPendingLeaveCount(hostel)
+	if parts.ok("pending_leaves", err) {
+		dashboard.PendingLeaves = pending
+	}
+
+	onLeave, err := s.repo.GetHostelStudentsOnLeaveToday(hostel)
+	if parts.ok("on_leave_today", err) {
+		dashboard.OnLeaveToday = onLeave
+	}
 
-	return &DashboardStats{
-		TotalStudents:     students,
-		TotalLeaves:       total,
-		PendingLeaves:     pending,
-		AverageAttendance: avg,
-	}, nil
+	recent, err := s.repo.GetHostelRecentLeaveActivity(hostel, 10)
+	if parts.ok("recent_activity", err) {
+		dashboard.RecentActivity = recent
+	}
+
+	if err := parts.failed(); err != nil {
+		return nil, err
+	}
+	dashboard.Errors = parts.result()
+	return dashboard, nil
 }
 
-func (s *Service) GetLeaveAnalytics() (map[string]interface{}, error) {
-	// Monthly breakdown
-	monthlyBreakdown, err := s.repo.GetMonthlyLeaveBreakdown()
-	if err != nil {
+func (s *Service) GetFacultyDashboard(dept string) (*FacultyDashboard, error) {
+	parts := newSections("faculty dashboard")
+	dashboard := &FacultyDashboard{Dept: dept}
+
+	students, err := s.repo.GetDeptStudentCount(dept)
+	if parts.ok("total_students", err) {
+		dashboard.TotalStudents = students
+	}
+
+	pending, err := s.repo.GetDeptPendingLeaveCount(dept)
+	if parts.ok("pending_leaves", err) {
+		dashboard.PendingLeaves = pending
+	}
+
+	marking, err := s.repo.GetDeptTodayMarkingBySubject(dept)
+	if parts.ok("today_marking", err) {
+		for i := range marking {
+			marking[i].TotalStudents = students
+		}
+		dashboard.TodayMarking = marking
+	}
+
+	lowAttendance, err := s.repo.GetDeptLowAttendanceStudents(dept, core.GetConfig().Attendance.ThresholdFor(dept))
+	if parts.ok("low_attendance_students", err) {
+		dashboard.LowAttendanceStudents = lowAttendance
+	}
+
+	if err := parts.failed(); err != nil {
+		return nil, err
+	}
+	dashboard.Errors = parts.result()
+	return dashboard, nil
+}
+
+func (s *Service) GetStudentSummary(student *users.User) (*StudentSummary, error) {
+	parts := newSections("student summary")
+	summary := &StudentSummary{AttendanceThreshold: core.GetConfig().Attendance.ThresholdFor(student.Dept)}
+
+	total, present, err := s.repo.GetStudentAttendanceCounts(student.ID)
+	if parts.ok("attendance_percentage", err) && total > 0 {
+		summary.AttendancePercentage = float64(present) / float64(total) * 100
+		summary.BelowThreshold = summary.AttendancePercentage < summary.AttendanceThreshold
+	}
+
+	open, err := s.repo.GetStudentOpenLeaveCount(student.ID)
+	if parts.ok("open_leaves", err) {
+		summary.OpenLeaves = open
+	}
+
+	next, err := s.repo.GetStudentNextLeave(student.ID)
+	if parts.ok("next_leave", err) {
+		summary.NextLeave = next
+	}
+
+	if err := parts.failed(); err != nil {
 		return nil, err
 	}
+	summary.Errors = parts.result()
+	return summary, nil
+}
+
+func (s *Service) GetDemographics() (map[string]interface{}, error) {
+	parts := newSections("demographics")
+	result := map[string]interface{}{}
+
+	byDept, err := s.repo.GetStudentCountsByDept()
+	if parts.ok("students_by_dept", err) {
+		result["students_by_dept"] = byDept
+	}
+
+	byHostel, err := s.repo.GetStudentCountsByHostel()
+	if parts.ok("students_by_hostel", err) {
+		result["students_by_hostel"] = byHostel
+	}
+
+	byRole, err := s.repo.GetUserCountsByRole()
+	if parts.ok("users_by_role", err) {
+		result["users_by_role"] = byRole
+	}
+
+	return withSectionErrors(result, parts)
+}
+
+func (s *Service) GetLeaveAnalytics(dr DateRange) (map[string]interface{}, error) {
+	parts := newSections("leave analytics")
+	result := map[string]interface{}{
+		"from": dr.From.In(timeutil.Location()).Format(timeutil.DateLayout),
+		"to":   dr.To.In(timeutil.Location()).Format(timeutil.DateLayout),
+	}
+
+	// Monthly breakdown
+	monthlyBreakdown, err := s.repo.GetMonthlyLeaveBreakdown(dr)
+	if parts.ok("monthly_breakdown", err) {
+		result["monthly_breakdown"] = monthlyBreakdown
+	}
 
 	// Leave types distribution
-	leaveTypes, err := s.repo.GetLeaveTypesDistribution()
-	if err != nil {
-		return nil, err
+	leaveTypes, err := s.repo.GetLeaveTypesDistribution(dr)
+	if parts.ok("leave_types", err) {
+		result["leave_types"] = leaveTypes
 	}
 
 	// Top absentees
-	topAbsentees, err := s.repo.GetTopAbsentees()
-	if err != nil {
-		return nil, err
+	topAbsentees, err := s.repo.GetTopAbsentees(dr, "", 10)
+	if parts.ok("top_absentees", err) {
+		result["top_absentees"] = topAbsentees
 	}
 
-	return map[string]interface{}{
-		"monthly_breakdown": monthlyBreakdown,
-		"leave_types":       leaveTypes,
-		"top_absentees":     topAbsentees,
-	}, nil
+	return withSectionErrors(result, parts)
 }
 
-func (s *Service) GetAttendanceAnalytics() (map[string]interface{}, error) {
+func (s *Service) GetAttendanceAnalytics(dr DateRange) (map[string]interface{}, error) {
+	parts := newSections("attendance analytics")
+	result := map[string]interface{}{
+		"from": dr.From.In(timeutil.Location()).Format(timeutil.DateLayout),
+		"to":   dr.To.In(timeutil.Location()).Format(timeutil.DateLayout),
+	}
+
 	// Department-wise attendance
-	deptWise, err := s.repo.GetDepartmentWiseAttendance()
-	if err != nil {
-		return nil, err
+	deptWise, err := s.repo.GetDepartmentWiseAttendance(dr)
+	if parts.ok("department_wise", err) {
+		result["department_wise"] = deptWise
 	}
 
 	// Monthly trend
-	monthlyTrend, err := s.repo.GetMonthlyAttendanceTrend()
+	monthlyTrend, err := s.repo.GetMonthlyAttendanceTrend(dr)
+	if parts.ok("monthly_trend", err) {
+		result["monthly_trend"] = monthlyTrend
+	}
+
+	// Low attendance students
+	lowAttendance, err := s.repo.GetLowAttendanceStudents(dr, float64(core.GetConfig().Attendance.LowThreshold), "", 10)
+	if parts.ok("low_attendance_students", err) {
+		result["low_attendance_students"] = lowAttendance
+	}
+
+	// Records per status; late counts as present in the percentages above
+	statusBreakdown, err := s.repo.GetAttendanceStatusBreakdown(dr)
+	if parts.ok("status_breakdown", err) {
+		result["status_breakdown"] = statusBreakdown
+	}
+
+	return withSectionErrors(result, parts)
+}
+
+// CompareDepartments ranks departments by average attendance within the range, best first
+func (s *Service) CompareDepartments(dr DateRange, depts []string) ([]DepartmentComparison, error) {
+	comparison, err := s.repo.GetDepartmentAttendance(dr, depts)
 	if err != nil {
 		return nil, err
 	}
 
-	// Low attendance students
-	lowAttendance, err := s.repo.GetLowAttendanceStudents()
+	sort.SliceStable(comparison, func(i, j int) bool {
+		return comparison[i].AverageAttendance > comparison[j].AverageAttendance
+	})
+	for i := range comparison {
+		comparison[i].Rank = i + 1
+		if i > 0 && comparison[i].AverageAttendance == comparison[i-1].AverageAttendance {
+			comparison[i].Rank = comparison[i-1].Rank
+		}
+	}
+
+	return comparison, nil
+}
+
+// GetAtRiskStudents merges low-attendance students with those taking frequent leaves into one list,
+// highest risk first. With a department, that department's threshold applies; otherwise the campus one.
+func (s *Service) GetAtRiskStudents(dr DateRange, dept string) ([]AtRiskStudent, error) {
+	cfg := core.GetConfig().Attendance
+
+	// Campus-wide, fetch everyone below the highest threshold, then hold each student to their
+	// own department's
+	threshold := atRiskThreshold(dept)
+	if dept == "" {
+		for _, deptThreshold := range cfg.DeptThresholds {
+			threshold = math.Max(threshold, float64(deptThreshold))
+		}
+	}
+	lowAttendance, err := s.repo.GetLowAttendanceStudents(dr, threshold, dept, 0)
 	if err != nil {
 		return nil, err
 	}
+	leaveCounts, err := s.repo.GetTopAbsentees(dr, dept, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	byStudent := make(map[uint]*AtRiskStudent)
+	leaveCount := make(map[uint]int, len(leaveCounts))
+	for _, record := range leaveCounts {
+		leaveCount[record.StudentID] = record.LeaveCount
+		if record.LeaveCount >= cfg.AtRiskMinLeaves {
+			byStudent[record.StudentID] = &AtRiskStudent{StudentID: record.StudentID, StudentName: record.StudentName, LeaveCount: record.LeaveCount}
+		}
+	}
+	for _, record := range lowAttendance {
+		studentThreshold := cfg.ThresholdFor(record.Dept)
+		if record.AttendancePercent >= studentThreshold {
+			continue
+		}
+		student, ok := byStudent[record.StudentID]
+		if !ok {
+			student = &AtRiskStudent{StudentID: record.StudentID, StudentName: record.StudentName, LeaveCount: leaveCount[record.StudentID]}
+			byStudent[record.StudentID] = student
+		}
+		percent := record.AttendancePercent
+		student.AttendancePercent = &percent
+		student.Threshold = studentThreshold
+	}
+
+	weights := float64(cfg.AtRiskAttendanceWeight + cfg.AtRiskLeaveWeight)
+	atRisk := make([]AtRiskStudent, 0, len(byStudent))
+	for _, student := range byStudent {
+		var attendanceFactor, leaveFactor float64
+		student.Factors = []string{}
+		if student.AttendancePercent != nil {
+			student.Factors = append(student.Factors, RiskLowAttendance)
+			if student.Threshold > 0 {
+				attendanceFactor = (student.Threshold - *student.AttendancePercent) / student.Threshold
+			}
+		}
+		if student.LeaveCount >= cfg.AtRiskMinLeaves {
+			student.Factors = append(student.Factors, RiskFrequentLeaves)
+			// Half weight at the minimum, full weight at twice it
+			leaveFactor = math.Min(float64(student.LeaveCount)/float64(2*cfg.AtRiskMinLeaves), 1)
+		}
+		student.RiskScore = 100 * (float64(cfg.AtRiskAttendanceWeight)*attendanceFactor + float64(cfg.AtRiskLeaveWeight)*leaveFactor) / weights
+		atRisk = append(atRisk, *student)
+	}
+
+	sort.Slice(atRisk, func(i, j int) bool {
+		if atRisk[i].RiskScore != atRisk[j].RiskScore {
+			return atRisk[i].RiskScore > atRisk[j].RiskScore
+		}
+		return atRisk[i].StudentID < atRisk[j].StudentID
+	})
+	for i := range atRisk {
+		atRisk[i].Rank = i + 1
+	}
 
-	return map[string]interface{}{
-		"department_wise":         deptWise,
-		"monthly_trend":           monthlyTrend,
-		"low_attendance_students": lowAttendance,
-	}, nil
+	return atRisk, nil
+}
+
+// atRiskThreshold is the attendance percentage below which students count as at risk: the
+// department's own threshold when scoped to one, otherwise the campus-wide default
+func atRiskThreshold(dept string) float64 {
+	cfg := core.GetConfig().Attendance
+	if dept == "" {
+		return float64(cfg.LowThreshold)
+	}
+	return cfg.ThresholdFor(dept)
+}
+
+// withSectionErrors adds an "errors" entry naming the failed sections, or fails outright if none loaded
+func withSectionErrors(result map[string]interface{}, parts *sections) (map[string]interface{}, error) {
+	if err := parts.failed(); err != nil {
+		return nil, err
+	}
+	if errors := parts.result(); errors != nil {
+		result["errors"] = errors
+	}
+	return result, nil
 }
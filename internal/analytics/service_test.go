@@ -0,0 +1,474 @@
+package analytics
+
+import (
+	"campus-backend/internal/attendance"
+	"campus-backend/internal/core"
+	"campus-backend/internal/leaves"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestDashboardReturnsPartialResults(t *testing.T) {
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	// No leave tables, so every leave query fails while the rest still load
+	testDB.AutoMigrate(&users.User{}, &attendance.Attendance{})
+	db.DB = testDB
+	db.DB.Create(&users.User{Name: "Student", Email: "student@example.com", Password: "x", Role: users.RoleStudent, Dept: "CS"})
+
+	summary, err := NewService().GetDashboardSummary()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), summary.TotalStudents)
+	assert.Zero(t, summary.TotalLeaves)
+	assert.Contains(t, summary.Errors, "leaves")
+	assert.NotContains(t, summary.Errors, "total_students")
+
+	dashboard, err := NewService().GetAdminDashboard()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), dashboard.UsersByRole[users.RoleStudent])
+	assert.Contains(t, dashboard.Errors, "summary.leaves")
+	assert.Contains(t, dashboard.Errors, "pending_approvals.by_dept")
+	assert.Nil(t, dashboard.Summary.Errors)
+
+	demographics, err := NewService().GetDemographics()
+	assert.NoError(t, err)
+	assert.NotContains(t, demographics, "errors")
+
+	// With nothing to show the error is returned as before
+	db.DB.Migrator().DropTable(&users.User{}, &attendance.Attendance{})
+	_, err = NewService().GetDemographics()
+	assert.Error(t, err)
+}
+
+func TestCompareDepartments(t *testing.T) {
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&users.User{}, &attendance.Attendance{})
+	db.DB = testDB
+
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	mark := func(dept string, present ...bool) {
+		student := users.User{Name: dept + " student", Email: fmt.Sprintf("%s%d@example.com", dept, len(present)), Password: "x", Role: users.RoleStudent, Dept: dept}
+		db.DB.Create(&student)
+		for i, p := range present {
+			status := attendance.StatusAbsent
+			if p {
+				status = attendance.StatusPresent
+			}
+			db.DB.Create(&attendance.Attendance{StudentID: student.ID, Date: day.AddDate(0, 0, i), Status: status, Present: p, MarkedBy: 1})
+		}
+	}
+	mark("CS", true, true, true, false) // 75%
+	mark("CS")                          // No records: counted as a student only
+	mark("EE", true, false)             // 50%
+	mark("ME", true, true, false, false, true, false)
+	mark("CE", true, false, true, false) // Ties with EE and ME at 50%
+	// Outside the range
+	db.DB.Create(&attendance.Attendance{StudentID: 1, Date: day.AddDate(0, 1, 0), Status: attendance.StatusAbsent, MarkedBy: 1})
+
+	dr := DateRange{From: day, To: day.AddDate(0, 0, 9)}
+	comparison, err := NewService().CompareDepartments(dr, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, comparison, 4) {
+		assert.Equal(t, DepartmentComparison{Rank: 1, Dept: "CS", StudentCount: 2, TotalRecords: 4, PresentRecords: 3, AverageAttendance: 75}, comparison[0])
+		for _, tied := range comparison[1:] {
+			assert.Equal(t, 2, tied.Rank, tied.Dept)
+			assert.Equal(t, 50.0, tied.AverageAttendance, tied.Dept)
+		}
+	}
+
+	comparison, err = NewService().CompareDepartments(dr, []string{"EE", "ME"})
+	assert.NoError(t, err)
+	if assert.Len(t, comparison, 2) {
+		assert.Equal(t, "EE", comparison[0].Dept)
+		assert.Equal(t, "ME", comparison[1].Dept)
+		assert.Equal(t, int64(6), comparison[1].TotalRecords)
+	}
+
+	deptWise, err := NewService().repo.GetDepartmentWiseAttendance(dr)
+	assert.NoError(t, err)
+	assert.Equal(t, 75.0, deptWise["CS"])
+}
+
+func TestGetAtRiskStudents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&users.User{}, &attendance.Attendance{}, &leaves.LeaveRequest{})
+	db.DB = testDB
+	defer func() { core.AppConfig = nil }()
+	core.LoadConfig()
+
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	student := func(name, dept string, present, absent, approvedLeaves int) users.User {
+		user := users.User{Name: name, Email: name + "@example.com", Password: "x", Role: users.RoleStudent, Dept: dept}
+		db.DB.Create(&user)
+		for i := 0; i < present+absent; i++ {
+			status := attendance.StatusPresent
+			if i >= present {
+				status = attendance.StatusAbsent
+			}
+			db.DB.Create(&attendance.Attendance{StudentID: user.ID, Date: day.AddDate(0, 0, i), Status: status, Present: status == attendance.StatusPresent, MarkedBy: 1})
+		}
+		for i := 0; i < approvedLeaves; i++ {
+			start := day.AddDate(0, 0, 20+i)
+			db.DB.Create(&leaves.LeaveRequest{StudentID: user.ID, LeaveType: "personal", Reason: "Family function at home", StartDate: start, EndDate: start,
+				Status: "approved", Dept: dept, Days: 1, CreatedAt: day.AddDate(0, 0, i)})
+		}
+		return user
+	}
+	both := student("both", "CS", 1, 1, 6)         // 50% and twice the minimum leaves
+	lowOnly := student("low", "CS", 1, 3, 1)       // 25%
+	leavesOnly := student("leaves", "EE", 4, 0, 3) // 100% but at the minimum
+	student("fine", "CS", 4, 0, 2)
+	// Deleted and merged-away students are left out
+	deleted := student("deleted", "CS", 0, 4, 6)
+	db.DB.Delete(&deleted)
+	merged := student("merged", "CS", 0, 4, 6)
+	db.DB.Model(&merged).Update("is_active", false)
+	faculty := users.User{Name: "faculty", Email: "faculty@example.com", Password: "x", Role: users.RoleFaculty, Dept: "CS"}
+	db.DB.Create(&faculty)
+
+	dr := DateRange{From: day, To: day.AddDate(0, 0, 30)}
+	atRisk, err := NewService().GetAtRiskStudents(dr, "")
+	assert.NoError(t, err)
+	if assert.Len(t, atRisk, 3) {
+		// 0.7*(25/75) + 0.3*1
+		assert.Equal(t, both.ID, atRisk[0].StudentID)
+		assert.InDelta(t, 53.33, atRisk[0].RiskScore, 0.01)
+		assert.Equal(t, []string{RiskLowAttendance, RiskFrequentLeaves}, atRisk[0].Factors)
+		assert.Equal(t, 6, atRisk[0].LeaveCount)
+		// 0.7*(50/75)
+		assert.Equal(t, lowOnly.ID, atRisk[1].StudentID)
+		assert.InDelta(t, 46.67, atRisk[1].RiskScore, 0.01)
+		assert.Equal(t, 1, atRisk[1].LeaveCount)
+		// 0.3*0.5
+		assert.Equal(t, leavesOnly.ID, atRisk[2].StudentID)
+		assert.InDelta(t, 15, atRisk[2].RiskScore, 0.01)
+		assert.Nil(t, atRisk[2].AttendancePercent)
+		assert.Equal(t, 3, atRisk[2].Rank)
+	}
+
+	get := func(viewer users.User, query string) (int, []AtRiskStudent) {
+		router := gin.New()
+		router.GET("/analytics/at-risk", func(c *gin.Context) {
+			c.Set("userID", viewer.ID)
+			c.Set("role", viewer.Role)
+		}, GetAtRiskStudents)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/analytics/at-risk?from=2026-09-01&to=2026-10-01"+query, nil))
+		var resp struct {
+			Students []AtRiskStudent `json:"students"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Students
+	}
+
+	// Faculty only see their department
+	code, students := get(faculty, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, students, 2)
+	code, _ = get(faculty, "&dept=EE")
+	assert.Equal(t, http.StatusForbidden, code)
+	// Without a department there is nothing to scope to
+	noDept := users.User{Name: "nodept", Email: "nodept@example.com", Password: "x", Role: users.RoleFaculty}
+	db.DB.Create(&noDept)
+	code, _ = get(noDept, "")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	core.AppConfig.Attendance.DeptThresholds = map[string]int{"CS": 40}
+	code, students = get(users.User{Role: users.RoleAdmin}, "&dept=CS")
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, students, 2) {
+		assert.Equal(t, both.ID, students[0].StudentID) // Leaves only now: 50% is above 40%
+		assert.Equal(t, []string{RiskFrequentLeaves}, students[0].Factors)
+		assert.Equal(t, lowOnly.ID, students[1].StudentID)
+	}
+
+	// Campus-wide, each student is held to their own department's threshold
+	core.AppConfig.Attendance.DeptThresholds = map[string]int{"CS": 40, "EE": 90}
+	strictDept := student("strict", "EE", 4, 1, 0) // 80%: fine campus-wide, low for EE
+	code, students = get(users.User{Role: users.RoleAdmin}, "")
+	assert.Equal(t, http.StatusOK, code)
+	byID := make(map[uint]AtRiskStudent)
+	for _, s := range students {
+		byID[s.StudentID] = s
+	}
+	assert.Len(t, byID, 4)
+	assert.Equal(t, []string{RiskFrequentLeaves}, byID[both.ID].Factors)
+	assert.Equal(t, []string{RiskLowAttendance}, byID[lowOnly.ID].Factors)
+	assert.Equal(t, 40.0, byID[lowOnly.ID].Threshold)
+	if assert.Contains(t, byID, strictDept.ID) {
+		assert.Equal(t, 90.0, byID[strictDept.ID].Threshold)
+		assert.InDelta(t, 7.78, byID[strictDept.ID].RiskScore, 0.01) // 0.7*(10/90)
+	}
+}
+
+func TestGetAttendanceStatusBreakdown(t *testing.T) {
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&users.User{}, &attendance.Attendance{})
+	db.DB = testDB
+
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	for i, status := range []string{attendance.StatusPresent, attendance.StatusPresent, attendance.StatusLate, attendance.StatusAbsent} {
+		db.DB.Create(&attendance.Attendance{StudentID: uint(i + 1), Date: day, Status: status, Present: attendance.CountsAsPresent(status), MarkedBy: 99})
+	}
+	// Outside the range, and deleted
+	db.DB.Create(&attendance.Attendance{StudentID: 1, Date: day.AddDate(0, 1, 0), Status: attendance.StatusExcused, MarkedBy: 99})
+	deleted := attendance.Attendance{StudentID: 5, Date: day, Status: attendance.StatusLate, Present: true, MarkedBy: 99}
+	db.DB.Create(&deleted)
+	db.DB.Delete(&deleted)
+
+	breakdown, err := NewService().repo.GetAttendanceStatusBreakdown(DateRange{From: day, To: day.AddDate(0, 0, 7)})
+	assert.NoError(t, err)
+	// Every status is reported, even without records
+	assert.Equal(t, map[string]int64{
+		attendance.StatusPresent: 2,
+		attendance.StatusAbsent:  1,
+		attendance.StatusLate:    1,
+		attendance.StatusExcused: 0,
+	}, breakdown)
+}
+
+func TestGetHostelRecentLeaveActivity(t *testing.T) {
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&users.User{}, &leaves.LeaveRequest{}, &leaves.LeaveEvent{})
+	db.DB = testDB
+
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	leaveIn := func(hostel string) leaves.LeaveRequest {
+		leave := leaves.LeaveRequest{StudentID: 1, LeaveType: "personal", Reason: "Family function", StartDate: day, EndDate: day, Status: "pending", Dept: "CS", Hostel: &hostel, Days: 1}
+		db.DB.Create(&leave)
+		db.DB.Create(&leaves.LeaveEvent{LeaveID: leave.ID, ToStatus: "pending"})
+		return leave
+	}
+	kept := leaveIn("H1")
+	deleted := leaveIn("H1")
+	db.DB.Delete(&deleted)
+	leaveIn("H2")
+
+	// Events of deleted leaves and other hostels are left out
+	events, err := NewService().repo.GetHostelRecentLeaveActivity("H1", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, kept.ID, events[0].LeaveID)
+	}
+}
+
+func TestGetAdminDashboard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&users.User{}, &attendance.Attendance{}, &leaves.LeaveRequest{})
+	db.DB = testDB
+
+	north := "North"
+	create := func(name, role, dept string, hostel *string) users.User {
+		user := users.User{Name: name, Email: name + "@example.com", Password: "x", Role: role, Dept: dept, Hostel: hostel, IsActive: true}
+		db.DB.Create(&user)
+		return user
+	}
+	admin := create("admin", users.RoleAdmin, "ADMIN", nil)
+	faculty := create("faculty", users.RoleFaculty, "CS", nil)
+	create("service", users.RoleService, "Service", nil)
+	first := create("first", users.RoleStudent, "CS", &north)
+	second := create("second", users.RoleStudent, "CS", &north)
+	third := create("third", users.RoleStudent, "EE", nil)
+	create("fourth", users.RoleStudent, "EE", nil)
+
+	// Half the students are marked today, one of them twice
+	today := timeutil.Today()
+	subject := "Maths"
+	db.DB.Create(&attendance.Attendance{StudentID: first.ID, Date: today, Status: attendance.StatusPresent, Present: true, MarkedBy: faculty.ID})
+	db.DB.Create(&attendance.Attendance{StudentID: first.ID, Date: today, Subject: &subject, Status: attendance.StatusPresent, Present: true, MarkedBy: faculty.ID})
+	db.DB.Create(&attendance.Attendance{StudentID: second.ID, Date: today, Status: attendance.StatusAbsent, MarkedBy: faculty.ID})
+	db.DB.Create(&attendance.Attendance{StudentID: second.ID, Date: today.AddDate(0, 0, -1), Status: attendance.StatusPresent, Present: true, MarkedBy: faculty.ID})
+
+	leave := func(student users.User, status string) {
+		db.DB.Create(&leaves.LeaveRequest{StudentID: student.ID, LeaveType: "personal", Reason: "Family function", StartDate: today, EndDate: today, Status: status, Dept: student.Dept, Hostel: student.Hostel, Days: 1})
+	}
+	leave(first, "pending")
+	leave(second, "needs_info")
+	leave(second, "approved")
+	leave(third, "pending")
+
+	r := gin.New()
+	r.GET("/admin/dashboard", func(c *gin.Context) {
+		c.Set("userID", admin.ID)
+		c.Set("role", admin.Role)
+	}, GetAdminDashboard)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/dashboard", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var dashboard AdminDashboard
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dashboard))
+	assert.Empty(t, dashboard.Errors)
+	assert.Equal(t, int64(4), dashboard.Summary.TotalStudents)
+	assert.Equal(t, int64(4), dashboard.Summary.TotalLeaves)
+	assert.Equal(t, map[string]int64{users.RoleAdmin: 1, users.RoleFaculty: 1, users.RoleStudent: 4}, dashboard.UsersByRole)
+	assert.Equal(t, AttendanceCompleteness{StudentsMarked: 2, TotalStudents: 4, CompletenessPercent: 50}, dashboard.TodayAttendance)
+
+	// Open leaves across every scope; leaves without a hostel only count by department
+	assert.Equal(t, int64(3), dashboard.PendingApprovals.Total)
+	assert.Equal(t, map[string]int64{"CS": 2, "EE": 1}, dashboard.PendingApprovals.ByDept)
+	assert.Equal(t, map[string]int64{north: 2}, dashboard.PendingApprovals.ByHostel)
+}
+
+func TestGetFacultyDashboard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&users.User{}, &attendance.Attendance{}, &leaves.LeaveRequest{})
+	db.DB = testDB
+	defer func() { core.AppConfig = nil }()
+	core.LoadConfig()
+
+	create := func(name, role, dept string) users.User {
+		user := users.User{Name: name, Email: name + "@example.com", Password: "x", Role: role, Dept: dept, IsActive: true}
+		db.DB.Create(&user)
+		return user
+	}
+	faculty := create("faculty", users.RoleFaculty, "CS")
+	regular := create("regular", users.RoleStudent, "CS")
+	absentee := create("absentee", users.RoleStudent, "CS")
+	create("unmarked", users.RoleStudent, "CS")
+	other := create("other", users.RoleStudent, "EE")
+
+	today := timeutil.Today()
+	maths, physics := "Maths", "Physics"
+	mark := func(student users.User, day time.Time, subject *string, status string) {
+		db.DB.Create(&attendance.Attendance{StudentID: student.ID, Date: day, Subject: subject, Status: status, Present: attendance.CountsAsPresent(status), MarkedBy: faculty.ID})
+	}
+	mark(regular, today, &maths, attendance.StatusPresent)
+	mark(absentee, today, &maths, attendance.StatusAbsent)
+	mark(regular, today, &physics, attendance.StatusLate)
+	mark(absentee, today, nil, attendance.StatusAbsent)
+	mark(regular, today.AddDate(0, 0, -1), &maths, attendance.StatusPresent) // Not today
+	mark(other, today, &maths, attendance.StatusAbsent)                      // Another department
+
+	leave := func(student users.User, status string) {
+		db.DB.Create(&leaves.LeaveRequest{StudentID: student.ID, LeaveType: "personal", Reason: "Family function", StartDate: today, EndDate: today, Status: status, Dept: student.Dept, Days: 1})
+	}
+	leave(regular, "pending")
+	leave(absentee, "needs_info")
+	leave(absentee, "approved")
+	leave(other, "pending")
+
+	r := gin.New()
+	r.GET("/faculty/dashboard", func(c *gin.Context) {
+		c.Set("userID", faculty.ID)
+		c.Set("role", faculty.Role)
+	}, GetFacultyDashboard)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/faculty/dashboard", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var dashboard FacultyDashboard
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dashboard))
+	assert.Empty(t, dashboard.Errors)
+	assert.Equal(t, "CS", dashboard.Dept)
+	assert.Equal(t, int64(3), dashboard.TotalStudents)
+	assert.Equal(t, int64(2), dashboard.PendingLeaves)
+	assert.Equal(t, []SubjectMarkingStatus{
+		{Subject: maths, StudentsMarked: 2, Present: 1, TotalStudents: 3},
+		{Subject: physics, StudentsMarked: 1, Present: 1, Late: 1, TotalStudents: 3},
+		{Subject: "unspecified", StudentsMarked: 1, TotalStudents: 3},
+	}, dashboard.TodayMarking)
+
+	// Only the department's students below the threshold are listed
+	if assert.Len(t, dashboard.LowAttendanceStudents, 1) {
+		assert.Equal(t, absentee.ID, dashboard.LowAttendanceStudents[0].StudentID)
+		assert.Equal(t, float64(0), dashboard.LowAttendanceStudents[0].AttendancePercent)
+	}
+}
+
+func TestGetDemographics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&users.User{})
+	db.DB = testDB
+
+	north, south := "North", "South"
+	for i, u := range []struct {
+		role, dept string
+		hostel     *string
+	}{
+		{users.RoleStudent, "CS", &north},
+		{users.RoleStudent, "CS", &north},
+		{users.RoleStudent, "CS", nil},
+		{users.RoleStudent, "EE", &south},
+		{users.RoleFaculty, "CS", nil}, // Staff only count by role
+		{users.RoleWarden, "ADMIN", &north},
+		{users.RoleAdmin, "ADMIN", nil},
+		{users.RoleService, "Service", nil}, // API-key accounts are not counted
+	} {
+		db.DB.Create(&users.User{Name: "User", Email: fmt.Sprintf("user%d@example.com", i), Password: "x", Role: u.role, Dept: u.dept, Hostel: u.hostel, IsActive: true})
+	}
+
+	r := gin.New()
+	r.GET("/analytics/demographics", GetDemographics)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/analytics/demographics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		StudentsByDept   map[string]int64  `json:"students_by_dept"`
+		StudentsByHostel map[string]int64  `json:"students_by_hostel"`
+		UsersByRole      map[string]int64  `json:"users_by_role"`
+		Errors           map[string]string `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Empty(t, resp.Errors)
+	assert.Equal(t, map[string]int64{"CS": 3, "EE": 1}, resp.StudentsByDept)
+	assert.Equal(t, map[string]int64{north: 2, south: 1, "unassigned": 1}, resp.StudentsByHostel)
+	assert.Equal(t, map[string]int64{users.RoleStudent: 4, users.RoleFaculty: 1, users.RoleWarden: 1, users.RoleAdmin: 1}, resp.UsersByRole)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"campus-backend/internal/attendance"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/auth"
+	"campus-backend/internal/leaves"
+	"campus-backend/internal/notifications"
+	"campus-backend/internal/settings"
+	"campus-backend/internal/users"
+	"campus-backend/internal/webhooks"
+	"campus-backend/pkg/db"
+	"fmt"
+)
+
+// Models lists every table the application owns, in migration order
+var Models = []interface{}{
+	&users.User{},
+	&leaves.LeaveRequest{},
+	&leaves.LeaveEvent{},
+	&leaves.LeaveComment{},
+	&leaves.LeaveSeries{},
+	&attendance.Attendance{},
+	&attendance.Subject{},
+	&attendance.AttendanceSummary{},
+	&notifications.Notification{},
+	&audit.AuditLog{},
+	&auth.APIKey{},
+	&auth.ImpersonationSession{},
+	&webhooks.Webhook{},
+	&webhooks.WebhookDelivery{},
+	&settings.Setting{},
+	&settings.Term{},
+}
+
+// Migrate brings the schema up to date and backfills data for newly added columns
+func Migrate() error {
+	// Attendance marked before statuses existed only has the present flag; backfilled first
+	// so the schema migration can make the column NOT NULL
+	if err := attendance.MigrateStatus(db.DB); err != nil {
+		return fmt.Errorf("backfilling attendance statuses: %w", err)
+	}
+	// Summaries cached before they were kept per term are dropped and rebuilt on demand
+	if err := attendance.MigrateSummaryKey(db.DB); err != nil {
+		return fmt.Errorf("rekeying attendance summaries: %w", err)
+	}
+	if err := db.Migrate(Models...); err != nil {
+		return err
+	}
+
+	// Keeps the latest of any duplicate marks already stored, then adds the index
+	if err := attendance.MigrateUniqueDay(db.DB); err != nil {
+		return fmt.Errorf("indexing attendance by student and day: %w", err)
+	}
+	return nil
+}
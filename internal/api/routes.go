@@ -1,12 +1,17 @@
 package api
 
 import (
+	"campus-backend/internal/accounts"
 	"campus-backend/internal/analytics"
 	"campus-backend/internal/attendance"
 	"campus-backend/internal/auth"
+	"campus-backend/internal/calendar"
 	"campus-backend/internal/leaves"
 	"campus-backend/internal/notifications"
+	"campus-backend/internal/retention"
+	"campus-backend/internal/settings"
 	"campus-backend/internal/users"
+	"campus-backend/internal/webhooks"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,43 +21,133 @@ func SetupRoutes(r *gin.Engine) {
 	// API group for version 1
 	api := r.Group("/api/v1")
 
+	// Unauthenticated, so operators and bug reports can tell which build is running
+	api.GET("/version", GetVersion)
+
 	// AUTH routes
 	api.POST("/auth/register", auth.Register)
 	api.POST("/auth/login", auth.Login)
+	api.GET("/auth/introspect", auth.JWTAuthMiddleware(), auth.IntrospectToken)
+	api.POST("/auth/change-password", auth.JWTAuthMiddleware(), auth.DenyImpersonation(), auth.ChangePassword)
+
+	// META routes, public since the registration form needs them
+	api.GET("/meta/departments", users.ListDepartments)
+	api.GET("/meta/hostels", users.ListHostels)
 
 	// USER routes
 	api.GET("/users/me", auth.JWTAuthMiddleware(), users.MeHandler)
+	api.GET("/users/me/home", auth.JWTAuthMiddleware(), analytics.GetMyHome)
+	api.GET("/users/me/permissions", auth.JWTAuthMiddleware(), auth.GetMyPermissions)
+	api.PUT("/users/me/notification-preferences", auth.JWTAuthMiddleware(), auth.DenyImpersonation(), users.UpdateNotificationPreferences)
+	api.PUT("/users/me/emergency-contact", auth.JWTAuthMiddleware(), auth.DenyImpersonation(), users.UpdateEmergencyContact)
 	api.GET("/users/", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), users.ListUsers)
-	api.GET("/admin/dashboard", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), adminDashboardHandler)
-	api.GET("/warden/dashboard", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleWarden), wardenDashboardHandler)
-	api.GET("/faculty/dashboard", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleFaculty), facultyDashboardHandler)
+	api.POST("/users/import", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), auth.ImportUsers)
+	api.GET("/users/me/export", auth.JWTAuthMiddleware(), auth.DenyImpersonation(), accounts.ExportMyData)
+	api.GET("/users/:id/export", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), accounts.ExportUserData)
+	api.DELETE("/users/:id", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), accounts.DeleteUser)
+	api.PUT("/users/:id/transfer", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), accounts.TransferStudent)
+	api.GET("/admin/users/duplicates", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), accounts.FindDuplicateUsers)
+	api.POST("/admin/users/merge", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), accounts.MergeUsers)
+	api.GET("/admin/dashboard", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), analytics.GetAdminDashboard)
+	api.POST("/admin/leaves/:id/override", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), leaves.OverrideLeave)
+	api.POST("/admin/leaves/process-stale", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), leaves.ProcessStaleLeavesHandler)
+	api.GET("/admin/leaves/pipeline", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), leaves.GetLeavePipeline)
+	api.POST("/admin/attendance/recompute", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), attendance.RecomputeSummariesHandler)
+	api.GET("/admin/retention/preview", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), retention.PreviewPurge)
+
+	// IMPERSONATION routes; ending is called with the impersonation token itself
+	api.POST("/admin/impersonate/:id", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), auth.DenyImpersonation(), auth.StartImpersonation)
+	api.DELETE("/admin/impersonate", auth.JWTAuthMiddleware(), auth.EndImpersonation)
+
+	// API KEY routes (admin)
+	api.POST("/admin/api-keys", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), auth.CreateAPIKey)
+	api.GET("/admin/api-keys", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), auth.ListAPIKeys)
+	api.DELETE("/admin/api-keys/:id", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), auth.RevokeAPIKey)
+
+	// WEBHOOK routes (admin)
+	api.POST("/admin/webhooks", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), webhooks.CreateWebhook)
+	api.GET("/admin/webhooks", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), webhooks.ListWebhooks)
+	api.PUT("/admin/webhooks/:id", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), webhooks.UpdateWebhook)
+	api.DELETE("/admin/webhooks/:id", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), webhooks.DeleteWebhook)
+	api.GET("/admin/webhooks/:id/deliveries", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), webhooks.ListWebhookDeliveries)
+
+	// SETTINGS routes (admin)
+	api.GET("/admin/settings", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), settings.GetSettings)
+	api.PUT("/admin/settings", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), settings.UpdateSettings)
+	api.GET("/admin/terms", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), settings.ListTerms)
+	api.POST("/admin/terms", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), settings.CreateTerm)
+	api.POST("/admin/terms/:id/clone", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), settings.CloneTerm)
+	api.PUT("/admin/terms/:id/settings", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), settings.UpdateTermSettings)
+
+	api.GET("/warden/dashboard", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleWarden), analytics.GetWardenDashboard)
+	api.GET("/faculty/dashboard", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleFaculty), analytics.GetFacultyDashboard)
 
 	// LEAVES routes
 	leavesGroup := api.Group("/leaves")
 	{
-		leavesGroup.POST("/apply", auth.JWTAuthMiddleware(), leaves.ApplyLeave)
+		leavesGroup.POST("/apply", auth.JWTAuthMiddleware(), auth.DenyImpersonation(), leaves.ApplyLeave)
+		leavesGroup.POST("/apply-for/:studentId", auth.JWTAuthMiddleware(), auth.RequireAnyRole(auth.LeaveOnBehalfRoles...), auth.DenyImpersonation(), leaves.ApplyLeaveOnBehalf)
+		leavesGroup.POST("/recurring", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleStudent), auth.DenyImpersonation(), leaves.CreateRecurringLeave)
+		leavesGroup.PUT("/recurring/:id/approve", auth.JWTAuthMiddleware(), auth.RequireAnyRole(auth.LeaveApproverRoles...), auth.DenyImpersonation(), leaves.DecideLeaveSeries)
+		leavesGroup.DELETE("/recurring/:id", auth.JWTAuthMiddleware(), auth.DenyImpersonation(), leaves.CancelLeaveSeries)
 		leavesGroup.GET("/", auth.JWTAuthMiddleware(), leaves.ListLeaves)
 		leavesGroup.GET("/my", auth.JWTAuthMiddleware(), leaves.ListLeaves)
+		leavesGroup.GET("/active", auth.JWTAuthMiddleware(), auth.RequireAnyRole(auth.LeaveApproverRoles...), leaves.ListActiveLeaves)
+		leavesGroup.GET("/stats", auth.JWTAuthMiddleware(), leaves.GetLeaveStats)
 		leavesGroup.GET("/:id", auth.JWTAuthMiddleware(), leaves.GetLeaveDetails)
-		leavesGroup.PUT("/:id/approve", auth.JWTAuthMiddleware(), leaves.ApproveRejectLeave)
-		leavesGroup.PUT("/:id/reject", auth.JWTAuthMiddleware(), leaves.ApproveRejectLeave)
+		leavesGroup.GET("/:id/history", auth.JWTAuthMiddleware(), leaves.GetLeaveHistory)
+		leavesGroup.GET("/:id/decision", auth.JWTAuthMiddleware(), leaves.GetLeaveDecision)
+		leavesGroup.GET("/:id/comments", auth.JWTAuthMiddleware(), leaves.ListLeaveComments)
+		leavesGroup.POST("/:id/comments", auth.JWTAuthMiddleware(), auth.DenyImpersonation(), leaves.AddLeaveComment)
+		leavesGroup.GET("/:id/certificate", auth.JWTAuthMiddleware(), leaves.GetLeaveCertificate)
+		leavesGroup.PUT("/:id/approve", auth.JWTAuthMiddleware(), auth.DenyImpersonation(), leaves.ApproveRejectLeave)
+		leavesGroup.PUT("/:id/reject", auth.JWTAuthMiddleware(), auth.DenyImpersonation(), leaves.ApproveRejectLeave)
+		leavesGroup.POST("/:id/respond", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleStudent), auth.DenyImpersonation(), leaves.RespondToLeave)
+		leavesGroup.POST("/batch-approve", auth.JWTAuthMiddleware(), auth.RequireAnyRole(auth.LeaveApproverRoles...), auth.DenyImpersonation(), leaves.BatchApproveLeaves)
 	}
 
+	// STUDENT routes
+	api.GET("/students/:id/approval-chain", auth.JWTAuthMiddleware(), auth.RequireAnyRole(users.RoleStudent, users.RoleFaculty, users.RoleWarden, users.RoleAdmin), leaves.GetApprovalChain)
+
 	// ATTENDANCE routes
 	attendanceGroup := api.Group("/attendance")
 	{
-		attendanceGroup.POST("/mark", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleFaculty), attendance.MarkAttendance)
-		attendanceGroup.GET("/", auth.JWTAuthMiddleware(), attendance.ViewAttendance)
-		attendanceGroup.GET("/stats", auth.JWTAuthMiddleware(), attendance.GetStats)
+		// Marking and reading also accept an API key (e.g. attendance kiosks)
+		attendanceGroup.POST("/mark", auth.JWTOrAPIKeyMiddleware(auth.ScopeAttendanceMark), auth.RequireAnyRole(append([]string{users.RoleService}, auth.AttendanceMarkerRoles...)...), auth.DenyImpersonation(), attendance.MarkAttendance)
+		attendanceGroup.GET("/", auth.JWTOrAPIKeyMiddleware(auth.ScopeAttendanceRead), attendance.ViewAttendance)
+		attendanceGroup.DELETE("/", auth.JWTAuthMiddleware(), auth.RequireAnyRole(auth.AttendanceMarkerRoles...), auth.DenyImpersonation(), attendance.ResetAttendance)
+		attendanceGroup.PUT("/:id/marker", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), auth.DenyImpersonation(), attendance.ReassignMarker)
+		attendanceGroup.GET("/stats", auth.JWTOrAPIKeyMiddleware(auth.ScopeAttendanceRead), attendance.GetStats)
+		attendanceGroup.POST("/stats/batch", auth.JWTAuthMiddleware(), auth.RequireAnyRole(users.RoleFaculty, users.RoleWarden, users.RoleAdmin), attendance.GetBatchStats)
 		attendanceGroup.GET("/department", auth.JWTAuthMiddleware(), attendance.GetDepartmentStats)
+		attendanceGroup.GET("/calendar", auth.JWTAuthMiddleware(), attendance.GetAttendanceCalendar)
+		attendanceGroup.GET("/trend", auth.JWTAuthMiddleware(), attendance.GetAttendanceTrend)
+		attendanceGroup.GET("/series", auth.JWTAuthMiddleware(), attendance.GetAttendanceSeries)
+		attendanceGroup.GET("/eligibility", auth.JWTAuthMiddleware(), attendance.GetEligibility)
+		attendanceGroup.GET("/today", auth.JWTAuthMiddleware(), auth.RequireAnyRole(auth.AttendanceMarkerRoles...), attendance.GetTodayStatus)
+		attendanceGroup.GET("/gaps", auth.JWTAuthMiddleware(), auth.RequireAnyRole(auth.AttendanceMarkerRoles...), attendance.GetAttendanceGaps)
+		attendanceGroup.GET("/marker-activity", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), attendance.GetMarkerActivity)
+		attendanceGroup.POST("/leave-absences", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), attendance.GenerateLeaveAbsencesHandler)
+		attendanceGroup.GET("/subjects", auth.JWTAuthMiddleware(), attendance.ListSubjects)
+		attendanceGroup.POST("/subjects", auth.JWTAuthMiddleware(), auth.RequireAnyRole(auth.SubjectManagerRoles...), auth.DenyImpersonation(), attendance.CreateSubject)
+		attendanceGroup.POST("/subjects/normalize", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), attendance.NormalizeSubjects)
+		attendanceGroup.PUT("/subjects/:id", auth.JWTAuthMiddleware(), auth.RequireAnyRole(auth.SubjectManagerRoles...), auth.DenyImpersonation(), attendance.UpdateSubject)
+		attendanceGroup.DELETE("/subjects/:id", auth.JWTAuthMiddleware(), auth.RequireAnyRole(auth.SubjectManagerRoles...), auth.DenyImpersonation(), attendance.DeleteSubject)
 	}
 
+	// CALENDAR routes
+	api.GET("/calendar/day", auth.JWTAuthMiddleware(), calendar.GetCalendarDay)
+	api.GET("/calendar/working-days", auth.JWTAuthMiddleware(), calendar.GetWorkingDays)
+
 	// ANALYTICS routes
 	analyticsGroup := api.Group("/analytics")
 	{
 		analyticsGroup.GET("/summary", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), analytics.GetSummary)
 		analyticsGroup.GET("/leaves", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), analytics.GetLeaveAnalytics)
 		analyticsGroup.GET("/attendance", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), analytics.GetAttendanceAnalytics)
+		analyticsGroup.GET("/compare-departments", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), analytics.CompareDepartments)
+		analyticsGroup.GET("/at-risk", auth.JWTAuthMiddleware(), auth.RequireAnyRole(users.RoleAdmin, users.RoleFaculty), analytics.GetAtRiskStudents)
+		analyticsGroup.GET("/demographics", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), analytics.GetDemographics)
 	}
 
 	// NOTIFICATIONS routes
@@ -60,20 +155,16 @@ func SetupRoutes(r *gin.Engine) {
 	{
 		notificationsGroup.GET("/", auth.JWTAuthMiddleware(), notifications.GetNotifications)
 		notificationsGroup.GET("/unread-count", auth.JWTAuthMiddleware(), notifications.GetUnreadCount)
-		notificationsGroup.PUT("/:id/read", auth.JWTAuthMiddleware(), notifications.MarkNotificationAsRead)
-		notificationsGroup.PUT("/read-all", auth.JWTAuthMiddleware(), notifications.MarkAllNotificationsAsRead)
+		notificationsGroup.GET("/stats", auth.JWTAuthMiddleware(), notifications.GetNotificationStats)
+		notificationsGroup.GET("/:id", auth.JWTAuthMiddleware(), auth.DenyImpersonationWhen(markingRead), notifications.GetNotification)
+		notificationsGroup.PUT("/:id/read", auth.JWTAuthMiddleware(), auth.DenyImpersonation(), notifications.MarkNotificationAsRead)
+		notificationsGroup.PUT("/read-all", auth.JWTAuthMiddleware(), auth.DenyImpersonation(), notifications.MarkAllNotificationsAsRead)
 	}
+	api.GET("/admin/notifications/preview", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), notifications.PreviewEmail)
+	api.POST("/admin/notifications/broadcast", auth.JWTAuthMiddleware(), auth.RequireRole(users.RoleAdmin), notifications.Broadcast)
 }
 
-// Dashboard handlers
-func adminDashboardHandler(c *gin.Context) {
-	c.JSON(200, gin.H{"message": "Welcome, Admin!"})
-}
-
-func wardenDashboardHandler(c *gin.Context) {
-	c.JSON(200, gin.H{"message": "Welcome, Warden!"})
-}
-
-func facultyDashboardHandler(c *gin.Context) {
-	c.JSON(200, gin.H{"message": "Welcome, Faculty!"})
+// markingRead reports whether a notification read also marks it read
+func markingRead(c *gin.Context) bool {
+	return c.Query("mark_read") == "true"
 }
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"campus-backend/internal/auth"
+	"campus-backend/internal/notifications"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestWriteRoutesDenyImpersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&users.User{}, &auth.ImpersonationSession{}, &notifications.Notification{})
+	db.DB = testDB
+
+	admin := users.User{Name: "Admin", Email: "admin@example.com", Password: "hashed", Role: users.RoleAdmin, Dept: "ADMIN", IsActive: true}
+	faculty := users.User{Name: "Faculty", Email: "faculty@example.com", Password: "hashed", Role: users.RoleFaculty, Dept: "CS", IsActive: true}
+	db.DB.Create(&admin)
+	db.DB.Create(&faculty)
+	session := auth.ImpersonationSession{AdminID: admin.ID, TargetID: faculty.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	db.DB.Create(&session)
+	token, err := auth.GenerateImpersonationJWT(faculty.Email, faculty.Role, admin.ID, session.ID, session.ExpiresAt)
+	assert.NoError(t, err)
+
+	router := gin.New()
+	SetupRoutes(router)
+
+	// Routes that must stay reachable with an impersonation token, or that only read
+	allowed := map[string]bool{
+		"POST /api/v1/auth/register":          true,
+		"POST /api/v1/auth/login":             true,
+		"DELETE /api/v1/admin/impersonate":    true,
+		"POST /api/v1/attendance/stats/batch": true,
+	}
+	checked := 0
+	for _, route := range router.Routes() {
+		if route.Method == http.MethodGet || allowed[route.Method+" "+route.Path] {
+			continue
+		}
+		path := strings.NewReplacer(":id", "1", ":studentId", "1").Replace(route.Path)
+		req := httptest.NewRequest(route.Method, path, strings.NewReader("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code, "%s %s", route.Method, route.Path)
+		checked++
+	}
+	assert.Greater(t, checked, 30)
+
+	// Reading a notification is allowed, but not marking it read on the way
+	notification := notifications.Notification{UserID: faculty.ID, Title: "Hello", Message: "Welcome", Type: "system"}
+	db.DB.Create(&notification)
+	read := func(query string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/notifications/"+strconv.Itoa(int(notification.ID))+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusOK, read(""))
+	assert.Equal(t, http.StatusForbidden, read("?mark_read=true"))
+	db.DB.First(&notification, notification.ID)
+	assert.False(t, notification.IsRead)
+}
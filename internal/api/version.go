@@ -0,0 +1,19 @@
+package api
+
+import (
+	"campus-backend/pkg/buildinfo"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetVersion godoc
+// @Summary Get the server version
+// @Description Returns the version, git commit, build time and Go version of the running server. Version, commit and build time are set at build time with -ldflags; unset values are omitted, except the version, which is "dev".
+// @Tags System
+// @Produce json
+// @Success 200 {object} buildinfo.Info "Build information"
+// @Router /version [get]
+func GetVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, buildinfo.Get())
+}
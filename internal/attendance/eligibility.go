@@ -0,0 +1,96 @@
+package attendance
+
+import (
+	"campus-backend/internal/core"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Eligibility says whether a student's lifetime attendance meets their department's minimum
+type Eligibility struct {
+	StudentID            uint    `json:"student_id"`
+	StudentName          string  `json:"student_name"`
+	Dept                 string  `json:"dept"`
+	Eligible             bool    `json:"eligible"`
+	AttendancePercentage float64 `json:"attendance_percentage"`
+	Threshold            float64 `json:"threshold"`
+	TotalDays            int     `json:"total_days"`
+	PresentDays          int     `json:"present_days"`
+
+	// Consecutive days present needed to reach the threshold; nil when it cannot be reached
+	ClassesNeeded *int `json:"classes_needed"`
+}
+
+// classesNeeded is the smallest n with (present+n)/(total+n) >= threshold%, or nil when no number of
+// present days gets there (a 100% threshold after an absence)
+func classesNeeded(present, total int, threshold float64) *int {
+	needed := 0
+	if total == 0 {
+		if threshold > 0 {
+			needed = 1
+		}
+		return &needed
+	}
+	if float64(present)*100 >= threshold*float64(total) {
+		return &needed
+	}
+	if threshold >= 100 {
+		return nil
+	}
+	needed = int(math.Ceil((threshold*float64(total) - 100*float64(present)) / (100 - threshold)))
+	return &needed
+}
+
+// GetEligibility godoc
+// @Summary Check a student's attendance eligibility
+// @Description Whether the student's lifetime attendance meets the minimum for their department (ATTENDANCE_DEPT_THRESHOLDS, else ATTENDANCE_LOW_THRESHOLD), e.g. for exam eligibility, with how many days present they still need. Students get their own; faculty see their department's students, wardens their hostel's, admins anyone.
+// @Tags Attendance
+// @Produce json
+// @Security BearerAuth
+// @Param student_id query int false "Student ID (required for non-students)"
+// @Success 200 {object} Eligibility "Eligibility"
+// @Failure 400 {object} map[string]interface{} "Invalid parameters"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Student is outside your scope"
+// @Failure 404 {object} map[string]interface{} "Student not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/eligibility [get]
+func GetEligibility(c *gin.Context) {
+	studentID, ok := resolveStudentID(c)
+	if !ok {
+		return
+	}
+
+	var student users.User
+	if err := db.DB.Where("id = ? AND role = ?", studentID, users.RoleStudent).First(&student).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return
+	}
+	if !studentInScope(c, &student) {
+		return
+	}
+
+	stats, err := lifetimeStats(&student)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate attendance statistics"})
+		return
+	}
+
+	threshold := core.GetConfig().Attendance.ThresholdFor(student.Dept)
+	needed := classesNeeded(stats.PresentDays, stats.TotalDays, threshold)
+	c.JSON(http.StatusOK, Eligibility{
+		StudentID:            student.ID,
+		StudentName:          student.Name,
+		Dept:                 student.Dept,
+		Eligible:             needed != nil && *needed == 0,
+		AttendancePercentage: stats.AttendancePercentage,
+		Threshold:            threshold,
+		TotalDays:            stats.TotalDays,
+		PresentDays:          stats.PresentDays,
+		ClassesNeeded:        needed,
+	})
+}
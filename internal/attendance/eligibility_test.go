@@ -0,0 +1,80 @@
+package attendance
+
+import (
+	"campus-backend/internal/core"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassesNeeded(t *testing.T) {
+	for _, tc := range []struct {
+		present, total int
+		threshold      float64
+		want           *int
+	}{
+		{3, 5, 75, intPtr(3)}, // 6/8 is exactly 75%
+		{3, 4, 75, intPtr(0)},
+		{0, 0, 75, intPtr(1)},
+		{0, 0, 0, intPtr(0)},
+		{9, 10, 100, nil},
+		{10, 10, 100, intPtr(0)},
+	} {
+		assert.Equal(t, tc.want, classesNeeded(tc.present, tc.total, tc.threshold), "%d/%d at %.0f%%", tc.present, tc.total, tc.threshold)
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestGetEligibility(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	defer func() { core.AppConfig = nil }()
+	core.LoadConfig()
+	student := seedDepartment(t, "CS", 1, 5)[0] // 3 of 5 days present
+	eeFaculty := users.User{Name: "EE Faculty", Email: "faculty@ee.example.com", Password: "hashed", Role: users.RoleFaculty, Dept: "EE", IsActive: true}
+	db.DB.Create(&eeFaculty)
+
+	get := func(viewerID uint, role, query string) (int, Eligibility) {
+		router := gin.New()
+		router.GET("/attendance/eligibility", func(c *gin.Context) {
+			c.Set("userID", viewerID)
+			c.Set("role", role)
+		}, GetEligibility)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/attendance/eligibility"+query, nil))
+		var resp Eligibility
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	code, resp := get(student.ID, users.RoleStudent, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, resp.Eligible)
+	assert.InDelta(t, 60, resp.AttendancePercentage, 0.001)
+	assert.Equal(t, float64(75), resp.Threshold)
+	if assert.NotNil(t, resp.ClassesNeeded) {
+		assert.Equal(t, 3, *resp.ClassesNeeded)
+	}
+
+	// Department thresholds take precedence
+	core.AppConfig.Attendance.DeptThresholds = map[string]int{"CS": 60}
+	code, resp = get(1, users.RoleAdmin, "?student_id="+strconv.FormatUint(uint64(student.ID), 10))
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.Eligible)
+	assert.Equal(t, 0, *resp.ClassesNeeded)
+
+	code, _ = get(eeFaculty.ID, users.RoleFaculty, "?student_id="+strconv.FormatUint(uint64(student.ID), 10))
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = get(1, users.RoleAdmin, "")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,153 @@
+package attendance
+
+import (
+	"campus-backend/internal/auth"
+	"campus-backend/internal/calendar"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxGapRangeDays bounds the range one gaps report may cover
+const maxGapRangeDays = 92
+
+// AttendanceSlot is a subject and period that should be marked each working day. Both are
+// empty for departments without subjects, where any attendance that day counts.
+type AttendanceSlot struct {
+	Subject string `json:"subject,omitempty"`
+	Period  string `json:"period,omitempty"`
+}
+
+// AttendanceGapDay lists the slots with no attendance on one working day
+type AttendanceGapDay struct {
+	Date    string           `json:"date"`
+	Missing []AttendanceSlot `json:"missing"`
+}
+
+// GetAttendanceGaps godoc
+// @Summary List unmarked attendance for a department
+// @Description For each working day (not a weekend or campus holiday) in the range, the department's subjects and periods with no attendance recorded for any of its students. Departments without subjects report days with no attendance at all. Faculty always get their own department. Days after today are not reported.
+// @Tags Attendance
+// @Produce json
+// @Security BearerAuth
+// @Param dept query string false "Department (required for admins)"
+// @Param from query string false "Start date (YYYY-MM-DD); defaults to 6 days before to"
+// @Param to query string false "End date (YYYY-MM-DD); defaults to today"
+// @Success 200 {object} map[string]interface{} "Gaps per working day"
+// @Failure 400 {object} map[string]interface{} "Invalid parameters"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/gaps [get]
+func GetAttendanceGaps(c *gin.Context) {
+	role, _ := auth.CurrentRole(c)
+	dept, ok := resolveDepartmentParam(c, role, "dept")
+	if !ok {
+		return
+	}
+
+	from, to, ok := parseDateRangeParams(c, "from", "to")
+	if !ok {
+		return
+	}
+	today := timeutil.Today()
+	if to == nil || to.After(today) {
+		to = &today
+	}
+	if from == nil {
+		start := timeutil.StartOfDay(to.In(timeutil.Location()).AddDate(0, 0, -6))
+		from = &start
+	}
+	if from.After(*to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after today"})
+		return
+	}
+	if to.Sub(*from) > maxGapRangeDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("The range can cover at most %d days", maxGapRangeDays)})
+		return
+	}
+
+	var subjects []Subject
+	if err := db.DB.Where("dept = ?", dept).Order("name ASC").Find(&subjects).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subjects"})
+		return
+	}
+	slots := []AttendanceSlot{{}}
+	if len(subjects) > 0 {
+		slots = slots[:0]
+		for _, subject := range subjects {
+			if len(subject.Periods) == 0 {
+				slots = append(slots, AttendanceSlot{Subject: subject.Name})
+			}
+			for _, period := range subject.Periods {
+				slots = append(slots, AttendanceSlot{Subject: subject.Name, Period: period})
+			}
+		}
+	}
+
+	var records []Attendance
+	_, end := timeutil.DayBounds(*to)
+	err := db.DB.Select("date", "subject", "period").
+		Where("date >= ? AND date < ?", *from, end).
+		Where("student_id IN (?)", db.DB.Model(&users.User{}).Select("id").Where("role = ? AND dept = ?", users.RoleStudent, dept)).
+		Find(&records).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance"})
+		return
+	}
+
+	// Subjects and periods are matched case-insensitively, as when marking
+	marked := make(map[string]bool)
+	for _, record := range records {
+		day := record.Date.In(timeutil.Location()).Format(timeutil.DateLayout)
+		marked[day] = true
+		if record.Subject != nil {
+			subject := strings.ToLower(*record.Subject)
+			marked[day+"|"+subject] = true
+			if record.Period != nil {
+				marked[day+"|"+subject+"|"+strings.ToLower(*record.Period)] = true
+			}
+		}
+	}
+
+	days := []AttendanceGapDay{}
+	workingDays, missingSlots := 0, 0
+	for day := *from; !day.After(*to); day = timeutil.StartOfDay(day.In(timeutil.Location()).AddDate(0, 0, 1)) {
+		if !calendar.IsWorkingDay(day) {
+			continue
+		}
+		workingDays++
+
+		date := day.In(timeutil.Location()).Format(timeutil.DateLayout)
+		entry := AttendanceGapDay{Date: date, Missing: []AttendanceSlot{}}
+		for _, slot := range slots {
+			key := date
+			if slot.Subject != "" {
+				key += "|" + strings.ToLower(slot.Subject)
+			}
+			if slot.Period != "" {
+				key += "|" + strings.ToLower(slot.Period)
+			}
+			if !marked[key] {
+				entry.Missing = append(entry.Missing, slot)
+			}
+		}
+		missingSlots += len(entry.Missing)
+		days = append(days, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dept":          dept,
+		"from":          from.In(timeutil.Location()).Format(timeutil.DateLayout),
+		"to":            to.In(timeutil.Location()).Format(timeutil.DateLayout),
+		"working_days":  workingDays,
+		"missing_slots": missingSlots,
+		"days":          days,
+	})
+}
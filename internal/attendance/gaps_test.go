@@ -0,0 +1,77 @@
+package attendance
+
+import (
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAttendanceGaps(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	students := seedDepartment(t, "CS", 2, 0)
+	seedDepartment(t, "EE", 1, 0)
+	db.DB.Create(&Subject{Name: "Maths", Dept: "CS", Periods: []string{"1", "2"}})
+	db.DB.Create(&Subject{Name: "Physics", Dept: "CS"})
+
+	monday := time.Date(2026, 9, 7, 0, 0, 0, 0, time.UTC)
+	maths, physics, period1, period2 := "maths", "Physics", "1", "2"
+	for _, record := range []Attendance{
+		// Monday is fully marked across the two students; Tuesday misses Maths period 2
+		{StudentID: students[0].ID, Date: monday, Subject: &maths, Period: &period1},
+		{StudentID: students[1].ID, Date: monday, Subject: &maths, Period: &period2},
+		{StudentID: students[0].ID, Date: monday, Subject: &physics},
+		{StudentID: students[0].ID, Date: monday.AddDate(0, 0, 1), Subject: &maths, Period: &period1},
+		{StudentID: students[1].ID, Date: monday.AddDate(0, 0, 1), Subject: &physics},
+	} {
+		record.Status, record.MarkedBy = StatusPresent, 1
+		db.DB.Create(&record)
+	}
+
+	get := func(role, query string) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.GET("/attendance/gaps", func(c *gin.Context) {
+			c.Set("userID", uint(99))
+			c.Set("role", role)
+		}, GetAttendanceGaps)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/attendance/gaps"+query, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusBadRequest, get(users.RoleAdmin, "?from=2026-09-07").Code)
+	assert.Equal(t, http.StatusBadRequest, get(users.RoleAdmin, "?dept=CS&from=2026-01-01&to=2026-09-13").Code)
+	rec := get(users.RoleAdmin, "?dept=CS&from=2026-09-13&to=2026-09-07")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "from must not be after to")
+	rec = get(users.RoleAdmin, "?dept=CS&from="+time.Now().AddDate(0, 0, 5).Format("2006-01-02"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "from must not be after today")
+
+	rec = get(users.RoleAdmin, "?dept=CS&from=2026-09-07&to=2026-09-13")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		WorkingDays  int                `json:"working_days"`
+		MissingSlots int                `json:"missing_slots"`
+		Days         []AttendanceGapDay `json:"days"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 5, resp.WorkingDays) // The weekend is skipped
+	assert.Len(t, resp.Days, 5)
+	assert.Empty(t, resp.Days[0].Missing)
+	assert.Equal(t, []AttendanceSlot{{Subject: "Maths", Period: "2"}}, resp.Days[1].Missing)
+	assert.Len(t, resp.Days[2].Missing, 3)
+	assert.Equal(t, 1+3*3, resp.MissingSlots)
+
+	// Without subjects a day counts as marked once anyone has attendance
+	rec = get(users.RoleAdmin, "?dept=EE&from=2026-09-07&to=2026-09-08")
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, []AttendanceSlot{{}}, resp.Days[0].Missing)
+}
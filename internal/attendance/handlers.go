@@ -1,43 +1,60 @@
 package attendance
 
 import (
+	"campus-backend/internal/audit"
+	"campus-backend/internal/auth"
+	"campus-backend/internal/core"
+	"campus-backend/internal/settings"
 	"campus-backend/internal/users"
 	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
 	"campus-backend/pkg/validation"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type MarkAttendanceRequest struct {
 	StudentID uint      `json:"student_id" binding:"required" validate:"required"`
 	Date      time.Time `json:"date" binding:"required" validate:"required"`
-	Present   bool      `json:"present" binding:"required"`
-	Subject   *string   `json:"subject,omitempty" validate:"max=50"`
-	Period    *string   `json:"period,omitempty" validate:"max=20"`
+	Present   *bool     `json:"present" binding:"required_without=Status"`                               // Required when status is omitted
+	Status    *string   `json:"status,omitempty" validate:"omitempty,oneof=present absent late excused"` // Takes precedence over present
+	Subject   *string   `json:"subject,omitempty" validate:"omitnil,max=50"`
+	Period    *string   `json:"period,omitempty" validate:"omitnil,max=20"`
+
+	// Marks the student present despite an approved leave that day, e.g. after returning early
+	OverrideLeave bool    `json:"override_leave"`
+	Justification *string `json:"justification,omitempty" validate:"required_if=OverrideLeave true,omitempty,min=10,max=200"`
 }
 
 type AttendanceStats struct {
 	StudentID            uint       `json:"student_id"`
 	StudentName          string     `json:"student_name"`
 	TotalDays            int        `json:"total_days"`
-	PresentDays          int        `json:"present_days"`
-	AbsentDays           int        `json:"absent_days"`
+	PresentDays          int        `json:"present_days"` // Includes late days
+	AbsentDays           int        `json:"absent_days"`  // Includes excused days
+	LateDays             int        `json:"late_days"`
+	ExcusedDays          int        `json:"excused_days"`
 	AttendancePercentage float64    `json:"attendance_percentage"`
 	LastAttendance       *time.Time `json:"last_attendance,omitempty"`
 }
 
 // MarkAttendance godoc
 // @Summary Mark student attendance
-// @Description Faculty marks attendance for a student
+// @Description Faculty marks attendance for a student as present, absent, late or excused (late counts as present). Future dates are refused, as are dates older than ATTENDANCE_BACKDATE_DAYS unless the caller is an admin. Marking a student present during an approved leave needs override_leave with a justification, which is audited.
 // @Tags Attendance
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body MarkAttendanceRequest true "Attendance data"
 // @Success 201 {object} map[string]interface{} "Attendance marked successfully"
-// @Failure 400 {object} map[string]interface{} "Validation failed or attendance already marked"
+// @Failure 400 {object} map[string]interface{} "Validation failed, date out of range or attendance already marked"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 404 {object} map[string]interface{} "Student not found"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -45,13 +62,16 @@ type AttendanceStats struct {
 func MarkAttendance(c *gin.Context) {
 	var req MarkAttendanceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
 		return
 	}
 
+	// A blank justification counts as none
+	req.Justification = validation.TrimOptional(req.Justification)
+
 	// Validate the struct
 	if err := validation.ValidateStruct(req); err != nil {
-		errors := validation.FormatValidationErrors(err)
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
 		return
 	}
@@ -70,21 +90,73 @@ func MarkAttendance(c *gin.Context) {
 		return
 	}
 
-	// Check if attendance already exists for this date
+	// Once the department has subjects, the subject must be one of them and is stored under its canonical name
+	if req.Subject != nil {
+		subject, err := resolveSubject(student.Dept, *req.Subject)
+		if errors.Is(err, ErrUnknownSubject) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown subject for department " + student.Dept, "allowed_subjects": subjectNames(student.Dept)})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check subject"})
+			return
+		}
+		if subject != nil {
+			if req.Period != nil && !subject.AllowsPeriod(*req.Period) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Period is not scheduled for " + subject.Name, "allowed_periods": subject.Periods})
+				return
+			}
+			req.Subject = &subject.Name
+		}
+	}
+
+	// Attendance is recorded per campus day
+	date := timeutil.StartOfDay(req.Date)
+
+	today := timeutil.Today()
+	if date.After(today) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attendance cannot be marked for a future date"})
+		return
+	}
+	// Admins may correct older records; everyone else is held to the backdating window
+	role, _ := auth.CurrentRole(c)
+	if window := core.GetConfig().Attendance.BackdateDays; window > 0 && role != users.RoleAdmin {
+		earliest := timeutil.StartOfDay(today.In(timeutil.Location()).AddDate(0, 0, -window))
+		if date.Before(earliest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Attendance can only be marked up to %d day(s) back; ask an admin to record older dates", window)})
+			return
+		}
+	}
+
+	status := StatusAbsent
+	if req.Status != nil {
+		status = *req.Status
+	} else if *req.Present {
+		status = StatusPresent
+	}
+	present := CountsAsPresent(status)
+
+	// Check if attendance already exists for this date, subject and period (the unique-day index's key)
 	var existingAttendance Attendance
-	err := db.DB.Where("student_id = ? AND date = ?", req.StudentID, req.Date.Truncate(24*time.Hour)).First(&existingAttendance).Error
+	err := inSlot(db.DB, req.StudentID, date, req.Subject, req.Period).First(&existingAttendance).Error
 	if err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Attendance already marked for this date"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attendance already marked for this date, subject and period"})
 		return
 	}
 
 	// Check if student has approved leave for this date
 	var approvedLeave users.LeaveRequest
 	err = db.DB.Where("student_id = ? AND status = ? AND start_date <= ? AND end_date >= ?",
-		req.StudentID, "approved", req.Date.Truncate(24*time.Hour), req.Date.Truncate(24*time.Hour)).First(&approvedLeave).Error
+		req.StudentID, "approved", date, date).First(&approvedLeave).Error
+
+	onLeave := err == nil
+	if req.OverrideLeave && !(onLeave && present) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "override_leave only applies when marking a student present during an approved leave"})
+		return
+	}
 
 	// If student has approved leave and is marked present, warn the faculty
-	if err == nil && req.Present {
+	if onLeave && present && !req.OverrideLeave {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Student has approved leave for this date",
 			"leave_details": gin.H{
@@ -97,60 +169,232 @@ func MarkAttendance(c *gin.Context) {
 		return
 	}
 
+	// Only an absence can pull the percentage down
+	var warning *LowAttendanceWarning
+	if !present {
+		warning, err = lowAttendanceWarning(&student)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check attendance percentage"})
+			return
+		}
+	}
+
 	attendance := Attendance{
 		StudentID: req.StudentID,
-		Date:      req.Date.Truncate(24 * time.Hour),
-		Present:   req.Present,
+		Date:      date,
+		Status:    status,
+		Present:   present,
 		MarkedBy:  markerID,
 		Subject:   req.Subject,
 		Period:    req.Period,
 	}
+	if req.OverrideLeave {
+		attendance.OverriddenLeaveID = &approvedLeave.ID
+	}
 
-	if err := db.DB.Create(&attendance).Error; err != nil {
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attendance).Error; err != nil {
+			return err
+		}
+		if !req.OverrideLeave {
+			return nil
+		}
+		details := fmt.Sprintf("marked %s on %s during approved %s leave %d: %s", status,
+			date.In(timeutil.Location()).Format(timeutil.DateLayout), approvedLeave.LeaveType, approvedLeave.ID, *req.Justification)
+		return audit.Record(tx, markerID, "attendance_leave_override", "attendance", attendance.ID, details)
+	})
+	if db.IsUniqueViolation(err) {
+		// Another request marked the same slot between the check above and this insert
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attendance already marked for this date, subject and period"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark attendance"})
 		return
 	}
+	refreshSummary(attendance.StudentID)
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "Attendance marked successfully",
-		"attendance": gin.H{
-			"id":         attendance.ID,
-			"student_id": attendance.StudentID,
-			"date":       attendance.Date,
-			"present":    attendance.Present,
-			"subject":    attendance.Subject,
-			"period":     attendance.Period,
-			"marked_by":  attendance.MarkedBy,
-			"created_at": attendance.CreatedAt,
-		},
-	})
+	record := gin.H{
+		"id":         attendance.ID,
+		"student_id": attendance.StudentID,
+		"date":       attendance.Date,
+		"status":     attendance.Status,
+		"present":    attendance.Present,
+		"subject":    attendance.Subject,
+		"period":     attendance.Period,
+		"marked_by":  attendance.MarkedBy,
+		"created_at": attendance.CreatedAt,
+	}
+	if attendance.OverriddenLeaveID != nil {
+		record["overridden_leave_id"] = attendance.OverriddenLeaveID
+	}
+
+	response := gin.H{
+		"message":    "Attendance marked successfully",
+		"attendance": record,
+	}
+	if warning != nil {
+		response["warning"] = warning
+	}
+	c.JSON(http.StatusCreated, response)
 }
 
-func ViewAttendance(c *gin.Context) {
-	roleVal, _ := c.Get("role")
-	role := roleVal.(string)
+// ResetAttendance godoc
+// @Summary Remove a student's attendance for a date
+// @Description Deletes the attendance record for a student on a campus day, subject and period so it counts as unmarked and can be marked again. Omitting subject or period selects the record marked without one. Faculty can only remove records they marked; admins can remove any. The removal is audited.
+// @Tags Attendance
+// @Produce json
+// @Security BearerAuth
+// @Param student_id query int true "Student ID"
+// @Param date query string true "Day to reset (YYYY-MM-DD, campus time)"
+// @Param subject query string false "Subject of the record"
+// @Param period query string false "Period of the record"
+// @Success 200 {object} map[string]interface{} "Attendance removed"
+// @Failure 400 {object} map[string]interface{} "Missing or invalid parameters"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Record was marked by someone else"
+// @Failure 404 {object} map[string]interface{} "No attendance for that student, date, subject and period"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/ [delete]
+func ResetAttendance(c *gin.Context) {
+	studentID, err := strconv.ParseUint(c.Query("student_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student_id"})
+		return
+	}
+	date, err := timeutil.ParseDate(c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, expected YYYY-MM-DD"})
+		return
+	}
 
-	var studentID uint
-	var err error
+	userIDVal, _ := c.Get("userID")
+	userID := userIDVal.(uint)
+	role, _ := auth.CurrentRole(c)
 
-	// Determine which student's attendance to view
-	if role == users.RoleStudent {
-	studentIDVal, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+	var subject, period *string
+	if value := c.Query("subject"); value != "" {
+		subject = &value
+	}
+	if value := c.Query("period"); value != "" {
+		period = &value
+	}
+
+	var attendance Attendance
+	if err := inSlot(db.DB, uint(studentID), date, subject, period).First(&attendance).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No attendance marked for this student, date, subject and period"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get attendance"})
+		return
+	}
+	if role != users.RoleAdmin && attendance.MarkedBy != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only remove attendance you marked"})
+		return
+	}
+
+	// Hard delete: reports that read the attendances table directly would otherwise still count the
+	// row, and the day must be free for MarkAttendance again
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&attendance).Error; err != nil {
+			return err
+		}
+		details := fmt.Sprintf("removed %s attendance for student %d on %s (marked by %d)", attendance.Status,
+			attendance.StudentID, date.In(timeutil.Location()).Format(timeutil.DateLayout), attendance.MarkedBy)
+		return audit.Record(tx, userID, "attendance_reset", "attendance", attendance.ID, details)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove attendance"})
+		return
+	}
+	refreshSummary(attendance.StudentID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attendance removed successfully"})
+}
+
+type ReassignMarkerRequest struct {
+	MarkedBy uint `json:"marked_by" binding:"required" validate:"required"`
+}
+
+// ReassignMarker godoc
+// @Summary Correct who marked an attendance record
+// @Description Admin changes the marker of a record, e.g. one entered by an import or on behalf of a substitute, so the marker-activity report credits the right person. The new marker must be an active faculty member or admin. The change is audited.
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Attendance record ID"
+// @Param request body ReassignMarkerRequest true "New marker"
+// @Success 200 {object} map[string]interface{} "Marker updated"
+// @Failure 400 {object} map[string]interface{} "Validation failed or the user cannot mark attendance"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Attendance record not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/{id}/marker [put]
+func ReassignMarker(c *gin.Context) {
+	var req ReassignMarkerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+	if err := validation.ValidateStruct(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": validation.FormatValidationErrorsIn(core.Language(c), err)})
 		return
 	}
-		studentID = studentIDVal.(uint)
-	} else {
-		// Faculty, Warden, or Admin can view any student's attendance
-		studentIDParam := c.Query("student_id")
-		if studentIDParam == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "student_id parameter is required"})
+
+	var attendance Attendance
+	if err := db.DB.First(&attendance, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attendance record not found"})
 			return
 		}
-		// Parse studentID from string to uint
-		// This is a simplified version - in production you'd want proper validation
-		studentID = 1 // Placeholder - implement proper parsing
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get attendance"})
+		return
+	}
+
+	var marker users.User
+	if err := db.DB.Where("id = ? AND is_active = ?", req.MarkedBy, true).First(&marker).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Marker not found"})
+		return
+	}
+	if !slices.Contains(auth.AttendanceMarkerRoles, marker.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only faculty and admins can be attendance markers"})
+		return
+	}
+	if attendance.MarkedBy == marker.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attendance is already attributed to this marker"})
+		return
+	}
+
+	userIDVal, _ := c.Get("userID")
+	previous := attendance.MarkedBy
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&attendance).Update("marked_by", marker.ID).Error; err != nil {
+			return err
+		}
+		details := fmt.Sprintf("marked_by %d -> %d", previous, marker.ID)
+		return audit.Record(tx, userIDVal.(uint), "attendance_marker_change", "attendance", attendance.ID, details)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update marker"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Attendance marker updated",
+		"attendance": attendance,
+	})
+}
+
+func ViewAttendance(c *gin.Context) {
+	var err error
+
+	// Determine which student's attendance to view
+	studentID, ok := resolveStudentID(c)
+	if !ok {
+		return
 	}
 
 	// Get query parameters for filtering
@@ -162,12 +406,12 @@ func ViewAttendance(c *gin.Context) {
 	query := db.DB.Where("student_id = ?", studentID)
 
 	if startDate != "" {
-		if start, err := time.Parse("2006-01-02", startDate); err == nil {
+		if start, err := timeutil.ParseDate(startDate); err == nil {
 			query = query.Where("date >= ?", start)
 		}
 	}
 	if endDate != "" {
-		if end, err := time.Parse("2006-01-02", endDate); err == nil {
+		if end, err := timeutil.ParseDate(endDate); err == nil {
 			query = query.Where("date <= ?", end)
 		}
 	}
@@ -175,46 +419,87 @@ func ViewAttendance(c *gin.Context) {
 		query = query.Where("subject = ?", subject)
 	}
 
-	err = query.Preload("Student").Preload("Marker").Order("date DESC").Find(&records).Error
+	filters := gin.H{
+		"start_date": startDate,
+		"end_date":   endDate,
+		"subject":    subject,
+	}
+
+	// ?cursor= switches to keyset pagination on (date, id), which stays fast deep into long
+	// histories and skips the count; without it pages are numbered
+	after, cursorMode, err := core.CursorParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		return
+	}
+	page, limit := core.PaginationParams(c)
+
+	if cursorMode {
+		if after != nil {
+			query = query.Where("date < ? OR (date = ? AND id < ?)", after.Time, after.Time, after.ID)
+		}
+		err = query.Preload("Student").Preload("Marker").Order("date DESC, id DESC").Limit(limit + 1).Find(&records).Error
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance"})
+			return
+		}
+
+		pagination := core.CursorPagination{Limit: limit}
+		if len(records) > limit {
+			records = records[:limit]
+			last := records[limit-1]
+			pagination.HasNext = true
+			pagination.NextCursor = core.Cursor{Time: last.Date, ID: last.ID}.Encode()
+		}
+		core.Render(c, gin.H{"attendance": records, "pagination": pagination, "filters": filters}, "attendance")
+		return
+	}
+
+	var total int64
+	err = query.Model(&Attendance{}).Count(&total).Error
+	if err == nil {
+		err = query.Preload("Student").Preload("Marker").Order("date DESC").Offset((page - 1) * limit).Limit(limit).Find(&records).Error
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	core.Render(c, gin.H{
 		"attendance": records,
-		"filters": gin.H{
-			"start_date": startDate,
-			"end_date":   endDate,
-			"subject":    subject,
-		},
-	})
+		"pagination": core.CalculatePagination(page, limit, total),
+		"filters":    filters,
+	}, "attendance")
 }
 
 func GetStats(c *gin.Context) {
-	roleVal, _ := c.Get("role")
-	role := roleVal.(string)
-
-	var studentID uint
-	var err error
-
 	// Determine which student's stats to get
-	if role == users.RoleStudent {
-		studentIDVal, exists := c.Get("userID")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+	studentID, ok := resolveStudentID(c)
+	if !ok {
+		return
+	}
+
+	// Optional date range or term; lifetime stats when omitted
+	start, end, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+	var term *settings.Term
+	if termIDParam := c.Query("term_id"); termIDParam != "" {
+		termID, err := strconv.ParseUint(termIDParam, 10, 32)
+		if err != nil || termID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid term_id"})
 			return
 		}
-		studentID = studentIDVal.(uint)
-	} else {
-		// Faculty, Warden, or Admin can view any student's stats
-		studentIDParam := c.Query("student_id")
-		if studentIDParam == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "student_id parameter is required"})
+		if start != nil || end != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "term_id cannot be combined with start_date or end_date"})
+			return
+		}
+		term = &settings.Term{}
+		if err := db.DB.First(term, termID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Term not found"})
 			return
 		}
-		// Parse studentID from string to uint
-		studentID = 1 // Placeholder - implement proper parsing
 	}
 
 	// Get student details
@@ -224,121 +509,678 @@ func GetStats(c *gin.Context) {
 		return
 	}
 
-	// Calculate attendance statistics
-	var totalDays int64
-	var presentDays int64
-	var lastAttendance *time.Time
+	// Lifetime and term stats come from the summary cache; other ranges are always computed live
+	if start == nil && end == nil {
+		scope := summaryScope{}
+		if term != nil {
+			scope = termScope(term)
+		}
+		stats, err := cachedStats(&student, scope)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate attendance statistics"})
+			return
+		}
+		c.JSON(http.StatusOK, stats)
+		return
+	}
 
-	// Count total attendance records
-	err = db.DB.Model(&Attendance{}).Where("student_id = ?", studentID).Count(&totalDays).Error
+	stats, err := studentStats([]users.User{student}, start, end)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate total days"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate attendance statistics"})
 		return
 	}
 
-	// Count present days
-	err = db.DB.Model(&Attendance{}).Where("student_id = ? AND present = ?", studentID, true).Count(&presentDays).Error
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate present days"})
+	c.JSON(http.StatusOK, stats[0])
+}
+
+func GetDepartmentStats(c *gin.Context) {
+	role, _ := auth.CurrentRole(c)
+
+	if role != users.RoleFaculty && role != users.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 		return
 	}
 
-	// Get last attendance date
-	var lastRecord Attendance
-	err = db.DB.Where("student_id = ?", studentID).Order("date DESC").First(&lastRecord).Error
-	if err == nil {
-		lastAttendance = &lastRecord.Date
+	dept, ok := resolveDepartment(c, role)
+	if !ok {
+		return
+	}
+
+	start, end, ok := parseDateRange(c)
+	if !ok {
+		return
 	}
 
-	// Calculate percentage
-	var attendancePercentage float64
-	if totalDays > 0 {
-		attendancePercentage = float64(presentDays) / float64(totalDays) * 100
+	// Get all students in the department
+	students, err := departmentStudents(dept)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get department students"})
+		return
 	}
 
-	stats := AttendanceStats{
-		StudentID:            studentID,
-		StudentName:          student.Name,
-		TotalDays:            int(totalDays),
-		PresentDays:          int(presentDays),
-		AbsentDays:           int(totalDays - presentDays),
-		AttendancePercentage: attendancePercentage,
-		LastAttendance:       lastAttendance,
+	departmentStats, err := studentStats(students, start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate attendance statistics"})
+		return
 	}
 
-	c.JSON(http.StatusOK, stats)
+	c.JSON(http.StatusOK, gin.H{
+		"department":     dept,
+		"stats":          departmentStats,
+		"total_students": len(students),
+		"filters": gin.H{
+			"start_date": c.Query("start_date"),
+			"end_date":   c.Query("end_date"),
+		},
+	})
 }
 
-func GetDepartmentStats(c *gin.Context) {
-	roleVal, _ := c.Get("role")
-	role := roleVal.(string)
+// BatchStatsRequest lists the students to fetch attendance statistics for
+type BatchStatsRequest struct {
+	StudentIDs []uint `json:"student_ids" binding:"required" validate:"required,min=1,max=100"`
+}
 
-	if role != users.RoleFaculty && role != users.RoleAdmin {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+// GetBatchStats godoc
+// @Summary Get attendance statistics for several students
+// @Description Get attendance statistics for up to 100 students in one request, with an optional start_date/end_date range. IDs that are not students are listed under not_found. Faculty may only ask for their department's students and wardens for their hostel's; any other ID fails the request with 403 and is listed under out_of_scope.
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BatchStatsRequest true "Student IDs"
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{} "Attendance statistics"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Student is outside your scope"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/stats/batch [post]
+func GetBatchStats(c *gin.Context) {
+	var req BatchStatsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
 		return
 	}
 
-	var dept string
-	if role == users.RoleFaculty {
-		userIDVal, _ := c.Get("userID")
-		userID := userIDVal.(uint)
-		var faculty users.User
-		if err := db.DB.First(&faculty, userID).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Faculty not found"})
+	if err := validation.ValidateStruct(req); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+
+	start, end, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	var students []users.User
+	if err := db.DB.Where("id IN ? AND role = ?", req.StudentIDs, users.RoleStudent).Order("name ASC").Find(&students).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get students"})
+		return
+	}
+
+	role, _ := auth.CurrentRole(c)
+	if role == users.RoleFaculty || role == users.RoleWarden {
+		viewer, err := auth.CurrentUser(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
 			return
 		}
-		dept = faculty.Dept
-	} else {
-		dept = c.Query("department")
-		if dept == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "department parameter is required"})
+		outOfScope := []uint{}
+		var msg string
+		for i := range students {
+			if reason := scopeError(role, viewer, &students[i]); reason != "" {
+				outOfScope = append(outOfScope, students[i].ID)
+				msg = reason
+			}
+		}
+		if len(outOfScope) > 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": msg, "out_of_scope": outOfScope})
 			return
 		}
 	}
 
-	// Get all students in the department
+	found := make(map[uint]bool, len(students))
+	for _, student := range students {
+		found[student.ID] = true
+	}
+	notFound := []uint{}
+	for _, id := range req.StudentIDs {
+		if !found[id] {
+			notFound = append(notFound, id)
+			found[id] = true // Report duplicates once
+		}
+	}
+
+	stats, err := studentStats(students, start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate attendance statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"stats":     stats,
+		"not_found": notFound,
+		"filters": gin.H{
+			"start_date": c.Query("start_date"),
+			"end_date":   c.Query("end_date"),
+		},
+	})
+}
+
+// studentStats computes attendance statistics for the given students with two grouped
+// queries, returning one entry per student in the same order
+func studentStats(students []users.User, start, end *time.Time) ([]AttendanceStats, error) {
+	stats := make([]AttendanceStats, 0, len(students))
+	if len(students) == 0 {
+		return stats, nil
+	}
+
+	ids := make([]uint, 0, len(students))
+	for _, student := range students {
+		ids = append(ids, student.ID)
+	}
+
+	var counts []struct {
+		StudentID   uint
+		TotalDays   int64
+		PresentDays int64
+		LateDays    int64
+		ExcusedDays int64
+	}
+	err := withDateRange(db.DB.Model(&Attendance{}), start, end).
+		Select("student_id, COUNT(*) as total_days, "+
+			"COUNT(CASE WHEN present THEN 1 END) as present_days, "+
+			"COUNT(CASE WHEN status = ? THEN 1 END) as late_days, "+
+			"COUNT(CASE WHEN status = ? THEN 1 END) as excused_days", StatusLate, StatusExcused).
+		Where("student_id IN ?", ids).
+		Group("student_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Join back to the latest record per student so the date keeps its column type
+	latest := withDateRange(db.DB.Model(&Attendance{}), start, end).
+		Select("student_id, MAX(date) as last_date").
+		Where("student_id IN ?", ids).
+		Group("student_id")
+	var lastRecords []Attendance
+	err = db.DB.Model(&Attendance{}).
+		Select("attendances.student_id, attendances.date").
+		Joins("JOIN (?) latest ON latest.student_id = attendances.student_id AND latest.last_date = attendances.date", latest).
+		Scan(&lastRecords).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byStudent := make(map[uint]AttendanceStats, len(counts))
+	for _, count := range counts {
+		byStudent[count.StudentID] = AttendanceStats{
+			TotalDays:   int(count.TotalDays),
+			PresentDays: int(count.PresentDays),
+			AbsentDays:  int(count.TotalDays - count.PresentDays),
+			LateDays:    int(count.LateDays),
+			ExcusedDays: int(count.ExcusedDays),
+		}
+	}
+	lastByStudent := make(map[uint]time.Time, len(lastRecords))
+	for _, record := range lastRecords {
+		lastByStudent[record.StudentID] = record.Date
+	}
+
+	for _, student := range students {
+		entry := byStudent[student.ID]
+		entry.StudentID = student.ID
+		entry.StudentName = student.Name
+		if entry.TotalDays > 0 {
+			entry.AttendancePercentage = float64(entry.PresentDays) / float64(entry.TotalDays) * 100
+		}
+		if last, ok := lastByStudent[student.ID]; ok {
+			entry.LastAttendance = &last
+		}
+		stats = append(stats, entry)
+	}
+
+	return stats, nil
+}
+
+// LowAttendanceWarning tells the marker that an absence took the student below their department's threshold
+type LowAttendanceWarning struct {
+	Message              string  `json:"message"`
+	AttendancePercentage float64 `json:"attendance_percentage"`
+	Threshold            float64 `json:"threshold"`
+}
+
+// lowAttendanceWarning checks, before an absence is recorded, whether it will take the student's
+// lifetime attendance from at or above the threshold to below it. Returns nil when it will not.
+func lowAttendanceWarning(student *users.User) (*LowAttendanceWarning, error) {
+	var counts struct {
+		Total   int64
+		Present int64
+	}
+	err := db.DB.Model(&Attendance{}).
+		Select("COUNT(*) AS total, COALESCE(SUM(CASE WHEN present THEN 1 ELSE 0 END), 0) AS present").
+		Where("student_id = ?", student.ID).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := core.GetConfig().Attendance.ThresholdFor(student.Dept)
+	after := float64(counts.Present) / float64(counts.Total+1) * 100
+	if after >= threshold {
+		return nil, nil
+	}
+	if counts.Total > 0 && float64(counts.Present)/float64(counts.Total)*100 < threshold {
+		return nil, nil // Already below; only the crossing is worth flagging
+	}
+
+	return &LowAttendanceWarning{
+		Message:              fmt.Sprintf("%s's attendance is now %.1f%%, below the %s threshold of %.0f%%", student.Name, after, student.Dept, threshold),
+		AttendancePercentage: after,
+		Threshold:            threshold,
+	}, nil
+}
+
+// resolveDepartment returns the department a request is scoped to: faculty always get their own,
+// other roles must pass a department query parameter. Writes an error response and returns false on failure.
+func resolveDepartment(c *gin.Context, role string) (string, bool) {
+	return resolveDepartmentParam(c, role, "department")
+}
+
+// resolveDepartmentParam is resolveDepartment for a custom query parameter name
+func resolveDepartmentParam(c *gin.Context, role, key string) (string, bool) {
+	if role == users.RoleFaculty {
+		faculty, err := auth.CurrentUser(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Faculty not found"})
+			return "", false
+		}
+		return faculty.Dept, true
+	}
+
+	dept := c.Query(key)
+	if dept == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": key + " parameter is required"})
+		return "", false
+	}
+	return dept, true
+}
+
+// departmentStudents returns the students of a department, ordered by name
+func departmentStudents(dept string) ([]users.User, error) {
 	var students []users.User
-	err := db.DB.Where("role = ? AND dept = ?", users.RoleStudent, dept).Find(&students).Error
+	err := db.DB.Where("role = ? AND dept = ?", users.RoleStudent, dept).Order("name ASC").Find(&students).Error
+	return students, err
+}
+
+// resolveStudentID returns the student whose records are requested: students always get their own,
+// other roles must pass a student_id query parameter. Writes an error response and returns false on failure.
+func resolveStudentID(c *gin.Context) (uint, bool) {
+	role, _ := auth.CurrentRole(c)
+
+	if role == users.RoleStudent {
+		studentIDVal, exists := c.Get("userID")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			return 0, false
+		}
+		return studentIDVal.(uint), true
+	}
+
+	// Faculty, Warden, or Admin can view any student's attendance
+	studentIDParam := c.Query("student_id")
+	if studentIDParam == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "student_id parameter is required"})
+		return 0, false
+	}
+	studentID, err := strconv.ParseUint(studentIDParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student_id"})
+		return 0, false
+	}
+	return uint(studentID), true
+}
+
+// parseDateRange reads optional start_date/end_date (YYYY-MM-DD) query parameters,
+// writing a 400 response and returning false if either is malformed
+func parseDateRange(c *gin.Context) (start, end *time.Time, ok bool) {
+	return parseDateRangeParams(c, "start_date", "end_date")
+}
+
+// parseDateRangeParams is parseDateRange for custom query parameter names
+func parseDateRangeParams(c *gin.Context, startKey, endKey string) (start, end *time.Time, ok bool) {
+	if value := c.Query(startKey); value != "" {
+		parsed, err := timeutil.ParseDate(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + startKey + " format, expected YYYY-MM-DD"})
+			return nil, nil, false
+		}
+		start = &parsed
+	}
+	if value := c.Query(endKey); value != "" {
+		parsed, err := timeutil.ParseDate(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + endKey + " format, expected YYYY-MM-DD"})
+			return nil, nil, false
+		}
+		end = &parsed
+	}
+	if start != nil && end != nil && end.Before(*start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": startKey + " must not be after " + endKey})
+		return nil, nil, false
+	}
+	return start, end, true
+}
+
+// withDateRange constrains an attendance query to the given inclusive date range
+func withDateRange(query *gorm.DB, start, end *time.Time) *gorm.DB {
+	if start != nil {
+		query = query.Where("date >= ?", *start)
+	}
+	if end != nil {
+		query = query.Where("date <= ?", *end)
+	}
+	return query
+}
+
+// Calendar day statuses
+const (
+	DayPresent = "present"
+	DayLate    = "late"
+	DayAbsent  = "absent"
+	DayExcused = "excused" // Marked excused, or absent while on approved leave
+	DayLeave   = "leave"   // On approved leave with no attendance recorded
+	DayUnknown = "unknown" // Nothing recorded
+)
+
+// GetAttendanceCalendar godoc
+// @Summary Get monthly attendance calendar
+// @Description Get a day-by-day status map for a student's month, merging attendance records and approved leaves
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param student_id query int false "Student ID (required for non-students)"
+// @Param month query string false "Month in YYYY-MM format (defaults to current month)"
+// @Success 200 {object} map[string]interface{} "Calendar data"
+// @Failure 400 {object} map[string]interface{} "Invalid parameters"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/calendar [get]
+func GetAttendanceCalendar(c *gin.Context) {
+	studentID, ok := resolveStudentID(c)
+	if !ok {
+		return
+	}
+
+	loc := timeutil.Location()
+	month := c.DefaultQuery("month", timeutil.Now().Format("2006-01"))
+	monthStart, err := time.ParseInLocation("2006-01", month, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month format, expected YYYY-MM"})
+		return
+	}
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	var records []Attendance
+	err = db.DB.Where("student_id = ? AND date >= ? AND date < ?", studentID, monthStart.UTC(), monthEnd.UTC()).
+		Find(&records).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance"})
+		return
+	}
+
+	var approvedLeaves []users.LeaveRequest
+	err = db.DB.Where("student_id = ? AND status = ? AND start_date < ? AND end_date >= ?",
+		studentID, "approved", monthEnd.UTC(), monthStart.UTC()).Find(&approvedLeaves).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve leaves"})
+		return
+	}
+
+	// Index attendance by day of month (campus time), keeping the best status
+	// when a day has several marks: present, then late, then excused, then absent
+	rank := map[string]int{StatusAbsent: 1, StatusExcused: 2, StatusLate: 3, StatusPresent: 4}
+	statusByDay := make(map[int]string)
+	for _, record := range records {
+		day := record.Date.In(loc).Day()
+		if rank[record.Status] > rank[statusByDay[day]] {
+			statusByDay[day] = record.Status
+		}
+	}
+
+	days := make(map[int]string)
+	for day := monthStart; day.Before(monthEnd); day = day.AddDate(0, 0, 1) {
+		dayStart := day.UTC()
+		onLeave := false
+		for _, leave := range approvedLeaves {
+			if !timeutil.StartOfDay(leave.StartDate).After(dayStart) && !timeutil.StartOfDay(leave.EndDate).Before(dayStart) {
+				onLeave = true
+				break
+			}
+		}
+
+		status, marked := statusByDay[day.Day()]
+		switch {
+		case marked && status == StatusPresent:
+			days[day.Day()] = DayPresent
+		case marked && status == StatusLate:
+			days[day.Day()] = DayLate
+		case marked && (status == StatusExcused || onLeave):
+			days[day.Day()] = DayExcused
+		case marked:
+			days[day.Day()] = DayAbsent
+		case onLeave:
+			days[day.Day()] = DayLeave
+		default:
+			days[day.Day()] = DayUnknown
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"student_id": studentID,
+		"month":      monthStart.Format("2006-01"),
+		"days":       days,
+	})
+}
+
+// MarkUnmarked is reported in today's class view for students with no record yet
+const MarkUnmarked = "unmarked"
+
+// StudentMarkStatus is one student's marking state for today
+type StudentMarkStatus struct {
+	StudentID    uint    `json:"student_id"`
+	Name         string  `json:"name"`
+	RollNumber   *string `json:"roll_number,omitempty"`
+	Status       string  `json:"status"`
+	AttendanceID *uint   `json:"attendance_id,omitempty"`
+}
+
+// GetTodayStatus godoc
+// @Summary Get today's marking status for a class
+// @Description List the department's students with whether they are already marked (present, absent, late or excused) or unmarked for the current campus day
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param subject query string false "Only count records for this subject"
+// @Param period query string false "Only count records for this period"
+// @Param department query string false "Department (required for admins)"
+// @Success 200 {object} map[string]interface{} "Today's marking status"
+// @Failure 400 {object} map[string]interface{} "Invalid parameters"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/today [get]
+func GetTodayStatus(c *gin.Context) {
+	role, _ := auth.CurrentRole(c)
+
+	dept, ok := resolveDepartment(c, role)
+	if !ok {
+		return
+	}
+
+	students, err := departmentStudents(dept)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get department students"})
 		return
 	}
 
-	var departmentStats []AttendanceStats
+	today := timeutil.Today()
+	subject := c.Query("subject")
+	period := c.Query("period")
+
+	studentIDs := make([]uint, 0, len(students))
 	for _, student := range students {
-		var totalDays int64
-		var presentDays int64
-		var lastAttendance *time.Time
+		studentIDs = append(studentIDs, student.ID)
+	}
 
-		db.DB.Model(&Attendance{}).Where("student_id = ?", student.ID).Count(&totalDays)
-		db.DB.Model(&Attendance{}).Where("student_id = ? AND present = ?", student.ID, true).Count(&presentDays)
+	marked := make(map[uint]Attendance)
+	if len(studentIDs) > 0 {
+		start, end := timeutil.DayBounds(today)
+		query := db.DB.Where("student_id IN ? AND date >= ? AND date < ?", studentIDs, start, end)
+		if subject != "" {
+			query = query.Where("subject = ?", subject)
+		}
+		if period != "" {
+			query = query.Where("period = ?", period)
+		}
+		var records []Attendance
+		if err := query.Find(&records).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendance"})
+			return
+		}
+		for _, record := range records {
+			marked[record.StudentID] = record
+		}
+	}
 
-		var lastRecord Attendance
-		if err := db.DB.Where("student_id = ?", student.ID).Order("date DESC").First(&lastRecord).Error; err == nil {
-			lastAttendance = &lastRecord.Date
+	summary := map[string]int{StatusPresent: 0, StatusAbsent: 0, StatusLate: 0, StatusExcused: 0, MarkUnmarked: 0}
+	statuses := make([]StudentMarkStatus, 0, len(students))
+	for _, student := range students {
+		entry := StudentMarkStatus{StudentID: student.ID, Name: student.Name, RollNumber: student.StudentID, Status: MarkUnmarked}
+		if record, ok := marked[student.ID]; ok {
+			id := record.ID
+			entry.AttendanceID = &id
+			entry.Status = record.Status
 		}
+		summary[entry.Status]++
+		statuses = append(statuses, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"date":     today.In(timeutil.Location()).Format(timeutil.DateLayout),
+		"dept":     dept,
+		"subject":  subject,
+		"period":   period,
+		"students": statuses,
+		"summary":  summary,
+	})
+}
+
+// MarkerActivity summarizes the attendance records entered by one marker
+type MarkerActivity struct {
+	MarkerID      uint       `json:"marker_id"`
+	MarkerName    string     `json:"marker_name"`
+	Role          string     `json:"role"`
+	Dept          string     `json:"dept"`
+	RecordsMarked int64      `json:"records_marked"`
+	LastMarkedAt  *time.Time `json:"last_marked_at,omitempty"`
+}
+
+// GetMarkerActivity godoc
+// @Summary Get attendance marker activity
+// @Description Admin report of how many attendance records each marker entered and when they last marked. Faculty with no records in the range are listed with a zero count.
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{} "Marker activity"
+// @Failure 400 {object} map[string]interface{} "Invalid parameters"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/marker-activity [get]
+func GetMarkerActivity(c *gin.Context) {
+	start, end, ok := parseDateRangeParams(c, "from", "to")
+	if !ok {
+		return
+	}
 
-		var attendancePercentage float64
-		if totalDays > 0 {
-			attendancePercentage = float64(presentDays) / float64(totalDays) * 100
+	var rows []struct {
+		MarkedBy      uint
+		Name          string
+		Role          string
+		Dept          string
+		RecordsMarked int64
+		LastRecordID  uint
+	}
+	query := withDateRange(db.DB.Model(&Attendance{}), start, end).
+		Select("attendances.marked_by, users.name, users.role, users.dept, COUNT(*) as records_marked, MAX(attendances.id) as last_record_id").
+		Joins("JOIN users ON users.id = attendances.marked_by").
+		Group("attendances.marked_by, users.name, users.role, users.dept").
+		Order("records_marked DESC")
+	if err := query.Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get marker activity"})
+		return
+	}
+
+	// Look up the latest record per marker for its timestamp
+	lastIDs := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		lastIDs = append(lastIDs, row.LastRecordID)
+	}
+	lastMarked := make(map[uint]time.Time)
+	if len(lastIDs) > 0 {
+		var latest []Attendance
+		if err := db.DB.Select("id", "marked_by", "created_at").Where("id IN ?", lastIDs).Find(&latest).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get marker activity"})
+			return
 		}
+		for _, record := range latest {
+			lastMarked[record.MarkedBy] = record.CreatedAt
+		}
+	}
 
-		stats := AttendanceStats{
-			StudentID:            student.ID,
-			StudentName:          student.Name,
-			TotalDays:            int(totalDays),
-			PresentDays:          int(presentDays),
-			AbsentDays:           int(totalDays - presentDays),
-			AttendancePercentage: attendancePercentage,
-			LastAttendance:       lastAttendance,
+	markers := make([]MarkerActivity, 0, len(rows))
+	seen := make(map[uint]bool)
+	for _, row := range rows {
+		activity := MarkerActivity{
+			MarkerID:      row.MarkedBy,
+			MarkerName:    row.Name,
+			Role:          row.Role,
+			Dept:          row.Dept,
+			RecordsMarked: row.RecordsMarked,
+		}
+		if t, ok := lastMarked[row.MarkedBy]; ok {
+			activity.LastMarkedAt = &t
 		}
+		markers = append(markers, activity)
+		seen[row.MarkedBy] = true
+	}
 
-		departmentStats = append(departmentStats, stats)
+	// Include faculty who have not marked anything in the range
+	var faculty []users.User
+	if err := db.DB.Where("role = ?", users.RoleFaculty).Order("name ASC").Find(&faculty).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get faculty"})
+		return
+	}
+	for _, f := range faculty {
+		if !seen[f.ID] {
+			markers = append(markers, MarkerActivity{MarkerID: f.ID, MarkerName: f.Name, Role: f.Role, Dept: f.Dept})
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"department":     dept,
-		"stats":          departmentStats,
-		"total_students": len(students),
+		"markers": markers,
+		"filters": gin.H{
+			"from": c.Query("from"),
+			"to":   c.Query("to"),
+		},
 	})
 }
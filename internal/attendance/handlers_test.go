@@ -0,0 +1,677 @@
+package attendance
+
+import (
+	"bytes"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestViewAttendanceCursorPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	student := seedDepartment(t, "CS", 1, 12)[0]
+	// Several marks on one day must not be skipped or repeated across pages
+	sameDay := time.Date(2026, 9, 5, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		db.DB.Create(&Attendance{StudentID: student.ID, Date: sameDay, Status: StatusPresent, Present: true, MarkedBy: 1})
+	}
+
+	router := gin.New()
+	router.GET("/attendance", func(c *gin.Context) {
+		c.Set("userID", student.ID)
+		c.Set("role", users.RoleStudent)
+	}, ViewAttendance)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/attendance"+query, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	var seen []Attendance
+	cursor, pages := "", 0
+	for {
+		w := get("?limit=4&cursor=" + cursor)
+		if !assert.Equal(t, http.StatusOK, w.Code) {
+			return
+		}
+		var resp struct {
+			Attendance []Attendance          `json:"attendance"`
+			Pagination core.CursorPagination `json:"pagination"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		seen = append(seen, resp.Attendance...)
+		pages++
+		if !resp.Pagination.HasNext {
+			assert.Empty(t, resp.Pagination.NextCursor)
+			break
+		}
+		cursor = resp.Pagination.NextCursor
+	}
+
+	assert.Equal(t, 4, pages)
+	assert.Len(t, seen, 15)
+	ids := map[uint]bool{}
+	for i, record := range seen {
+		ids[record.ID] = true
+		if i > 0 {
+			prev := seen[i-1]
+			assert.True(t, record.Date.Before(prev.Date) || (record.Date.Equal(prev.Date) && record.ID < prev.ID), "records out of order")
+		}
+	}
+	assert.Len(t, ids, 15)
+
+	// Without a cursor the numbered pages are unchanged
+	w := get("?limit=4&page=2")
+	var offset struct {
+		Pagination core.Pagination `json:"pagination"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &offset)
+	assert.Equal(t, int64(15), offset.Pagination.Total)
+
+	assert.Equal(t, http.StatusBadRequest, get("?cursor=not-a-cursor").Code)
+	assert.NotEmpty(t, get("?limit=4&cursor=&format=csv").Header().Get("X-Next-Cursor"))
+}
+
+func TestMarkAttendanceOverrideLeave(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	db.DB.AutoMigrate(&users.LeaveRequest{}, &audit.AuditLog{})
+	student := seedDepartment(t, "CS", 1, 0)[0]
+	day := timeutil.Today().AddDate(0, 0, -3)
+	leave := users.LeaveRequest{StudentID: student.ID, LeaveType: "personal", Reason: "Family function", StartDate: day, EndDate: day.AddDate(0, 0, 2), Status: "approved", Dept: "CS", Days: 3}
+	db.DB.Create(&leave)
+
+	router := gin.New()
+	router.POST("/attendance/mark", func(c *gin.Context) {
+		c.Set("userID", uint(99))
+		c.Set("role", users.RoleFaculty)
+	}, MarkAttendance)
+	mark := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/attendance/mark", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	body := func(date time.Time, extra string) string {
+		return `{"student_id":` + strconv.FormatUint(uint64(student.ID), 10) + `,"date":"` + date.Format(time.RFC3339) + `","status":"present"` + extra + `}`
+	}
+
+	assert.Equal(t, http.StatusBadRequest, mark(body(day, "")).Code)
+	assert.Equal(t, http.StatusBadRequest, mark(body(day, `,"override_leave":true`)).Code, "justification is required")
+	assert.Equal(t, http.StatusBadRequest, mark(body(day, `,"override_leave":true,"justification":"            "`)).Code, "blank justification")
+	assert.Equal(t, http.StatusBadRequest, mark(body(day, `,"override_leave":true,"justification":"   Returned    "`)).Code, "too short once trimmed")
+	assert.Equal(t, http.StatusBadRequest, mark(body(timeutil.Today(), `,"override_leave":true,"justification":"Returned before the leave ended"`)).Code, "no leave to override")
+
+	w := mark(body(day.AddDate(0, 0, 1), `,"override_leave":true,"justification":"  Returned before the leave ended  "`))
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var stored Attendance
+	db.DB.Where("student_id = ?", student.ID).First(&stored)
+	assert.True(t, stored.Present)
+	if assert.NotNil(t, stored.OverriddenLeaveID) {
+		assert.Equal(t, leave.ID, *stored.OverriddenLeaveID)
+	}
+
+	var entry audit.AuditLog
+	assert.NoError(t, db.DB.Where("action = ?", "attendance_leave_override").First(&entry).Error)
+	assert.Equal(t, stored.ID, entry.EntityID)
+	assert.Contains(t, entry.Details, day.AddDate(0, 0, 1).Format(timeutil.DateLayout)+" during approved personal leave")
+	assert.True(t, strings.HasSuffix(entry.Details, ": Returned before the leave ended"), entry.Details)
+}
+
+func TestMarkAttendanceDateWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	defer func() { core.AppConfig = nil }()
+	students := seedDepartment(t, "CS", 4, 0)
+
+	mark := func(role string, student users.User, date time.Time) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.POST("/attendance/mark", func(c *gin.Context) {
+			c.Set("userID", uint(99))
+			c.Set("role", role)
+		}, MarkAttendance)
+		body := `{"student_id":` + strconv.FormatUint(uint64(student.ID), 10) + `,"date":"` + date.Format(time.RFC3339) + `","status":"present"}`
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/attendance/mark", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	today := timeutil.Today()
+	assert.Equal(t, http.StatusCreated, mark(users.RoleFaculty, students[0], today.Add(9*time.Hour)).Code)
+
+	w := mark(users.RoleFaculty, students[1], today.AddDate(0, 0, 1))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "future date")
+	assert.Equal(t, http.StatusBadRequest, mark(users.RoleAdmin, students[1], today.AddDate(0, 0, 1)).Code)
+
+	// The default window is a week, and only admins may go further back
+	assert.Equal(t, http.StatusCreated, mark(users.RoleFaculty, students[1], today.AddDate(0, 0, -7)).Code)
+	w = mark(users.RoleFaculty, students[2], today.AddDate(0, 0, -30))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "up to 7 day(s) back")
+	assert.Equal(t, http.StatusCreated, mark(users.RoleAdmin, students[2], today.AddDate(0, 0, -30)).Code)
+
+	t.Setenv("ATTENDANCE_BACKDATE_DAYS", "0")
+	core.LoadConfig()
+	assert.Equal(t, http.StatusCreated, mark(users.RoleFaculty, students[3], today.AddDate(0, -6, 0)).Code)
+}
+
+func TestMarkAttendanceMidnightBoundary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	assert.NoError(t, timeutil.SetLocation("Asia/Kolkata")) // UTC+05:30
+	defer timeutil.SetLocation("UTC")
+	student := seedDepartment(t, "CS", 1, 0)[0]
+
+	mark := func(at time.Time, fields string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.POST("/attendance/mark", func(c *gin.Context) {
+			c.Set("userID", uint(99))
+			c.Set("role", users.RoleFaculty)
+		}, MarkAttendance)
+		body := `{"student_id":` + strconv.FormatUint(uint64(student.ID), 10) + `,"date":"` + at.UTC().Format(time.RFC3339) + `"` + fields + `}`
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/attendance/mark", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// 23:59 and 00:01 campus time fall on different days even though both are the same UTC date
+	yesterday := timeutil.Today().AddDate(0, 0, -1)
+	lateEvening := yesterday.Add(23*time.Hour + 59*time.Minute)
+	earlyMorning := yesterday.AddDate(0, 0, 1).Add(time.Minute)
+	assert.Equal(t, http.StatusBadRequest, mark(lateEvening, "").Code, "present or status is required")
+	assert.Equal(t, http.StatusCreated, mark(lateEvening, `,"present":false`).Code)
+	assert.Equal(t, http.StatusCreated, mark(earlyMorning, `,"present":true`).Code)
+	w := mark(yesterday.Add(30*time.Minute), `,"status":"late"`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "already marked")
+
+	var records []Attendance
+	db.DB.Where("student_id = ?", student.ID).Order("date ASC").Find(&records)
+	if assert.Len(t, records, 2) {
+		assert.True(t, yesterday.Equal(records[0].Date))
+		assert.Equal(t, StatusAbsent, records[0].Status)
+		assert.True(t, yesterday.AddDate(0, 0, 1).Equal(records[1].Date))
+		assert.Equal(t, StatusPresent, records[1].Status)
+	}
+}
+
+func TestMarkAttendanceStatusPrecedence(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	students := seedDepartment(t, "CS", 3, 0)
+
+	mark := func(student users.User, fields string) Attendance {
+		router := gin.New()
+		router.POST("/attendance/mark", func(c *gin.Context) {
+			c.Set("userID", uint(99))
+			c.Set("role", users.RoleFaculty)
+		}, MarkAttendance)
+		body := `{"student_id":` + strconv.FormatUint(uint64(student.ID), 10) + `,"date":"` + timeutil.Today().Format(time.RFC3339) + `",` + fields + `}`
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/attendance/mark", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var record Attendance
+		db.DB.Where("student_id = ?", student.ID).First(&record)
+		return record
+	}
+
+	// The status wins over the present flag, which is then derived from it
+	record := mark(students[0], `"present":true,"status":"absent"`)
+	assert.Equal(t, StatusAbsent, record.Status)
+	assert.False(t, record.Present)
+	record = mark(students[1], `"present":false,"status":"late"`)
+	assert.Equal(t, StatusLate, record.Status)
+	assert.True(t, record.Present)
+	record = mark(students[2], `"present":true`)
+	assert.Equal(t, StatusPresent, record.Status)
+}
+
+func TestResetAttendance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	db.DB.AutoMigrate(&users.LeaveRequest{}, &audit.AuditLog{})
+	student := seedDepartment(t, "CS", 1, 0)[0]
+	day := timeutil.Today().AddDate(0, 0, -1)
+	db.DB.Create(&Attendance{StudentID: student.ID, Date: day, Status: StatusAbsent, MarkedBy: 99})
+	math, first := "Math", "1"
+	db.DB.Create(&Attendance{StudentID: student.ID, Date: day, Subject: &math, Period: &first, Status: StatusPresent, Present: true, MarkedBy: 99})
+
+	as := func(userID uint, role string) *gin.Engine {
+		router := gin.New()
+		setUser := func(c *gin.Context) {
+			c.Set("userID", userID)
+			c.Set("role", role)
+		}
+		router.DELETE("/attendance/", setUser, ResetAttendance)
+		router.POST("/attendance/mark", setUser, MarkAttendance)
+		return router
+	}
+	reset := func(router *gin.Engine, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/attendance/"+query, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	query := "?student_id=" + strconv.FormatUint(uint64(student.ID), 10) + "&date=" + day.In(timeutil.Location()).Format(timeutil.DateLayout)
+
+	assert.Equal(t, http.StatusBadRequest, reset(as(99, users.RoleFaculty), "?student_id=x&date=2026-01-01").Code)
+	assert.Equal(t, http.StatusForbidden, reset(as(98, users.RoleFaculty), query).Code)
+
+	// Each subject and period is its own record
+	marker := as(99, users.RoleFaculty)
+	assert.Equal(t, http.StatusNotFound, reset(marker, query+"&subject=Math&period=2").Code)
+	assert.Equal(t, http.StatusOK, reset(marker, query+"&subject=Math&period=1").Code)
+	var count int64
+	db.DB.Model(&Attendance{}).Where("student_id = ?", student.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, http.StatusOK, reset(marker, query).Code)
+	assert.Equal(t, http.StatusNotFound, reset(marker, query).Code)
+
+	db.DB.Unscoped().Model(&Attendance{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+	db.DB.Model(&audit.AuditLog{}).Where("action = ?", "attendance_reset").Count(&count)
+	assert.Equal(t, int64(2), count)
+
+	// The day can be marked again, and admins can remove anyone's record
+	w := httptest.NewRecorder()
+	body := `{"student_id":` + strconv.FormatUint(uint64(student.ID), 10) + `,"date":"` + day.Format(time.RFC3339) + `","status":"present"}`
+	req, _ := http.NewRequest("POST", "/attendance/mark", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	marker.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, http.StatusOK, reset(as(1, users.RoleAdmin), query).Code)
+}
+
+func TestReassignMarker(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	db.DB.AutoMigrate(&audit.AuditLog{})
+	student := seedDepartment(t, "CS", 1, 0)[0]
+	substitute := users.User{Name: "Substitute", Email: "substitute@example.com", Password: "hashed", Role: users.RoleFaculty, Dept: "CS", IsActive: true}
+	warden := users.User{Name: "Warden", Email: "warden@example.com", Password: "hashed", Role: users.RoleWarden, Dept: "CS", IsActive: true}
+	db.DB.Create(&substitute)
+	db.DB.Create(&warden)
+	record := Attendance{StudentID: student.ID, Date: timeutil.Today(), Status: StatusPresent, Present: true, MarkedBy: 99}
+	db.DB.Create(&record)
+
+	router := gin.New()
+	router.PUT("/attendance/:id/marker", func(c *gin.Context) { c.Set("userID", uint(1)) }, ReassignMarker)
+	reassign := func(id, markedBy uint) int {
+		w := httptest.NewRecorder()
+		body := `{"marked_by":` + strconv.FormatUint(uint64(markedBy), 10) + `}`
+		req, _ := http.NewRequest(http.MethodPut, "/attendance/"+strconv.FormatUint(uint64(id), 10)+"/marker", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNotFound, reassign(record.ID+1, substitute.ID))
+	assert.Equal(t, http.StatusBadRequest, reassign(record.ID, warden.ID))
+	assert.Equal(t, http.StatusBadRequest, reassign(record.ID, student.ID))
+	assert.Equal(t, http.StatusBadRequest, reassign(record.ID, 12345))
+	assert.Equal(t, http.StatusOK, reassign(record.ID, substitute.ID))
+	assert.Equal(t, http.StatusBadRequest, reassign(record.ID, substitute.ID))
+
+	db.DB.First(&record, record.ID)
+	assert.Equal(t, substitute.ID, record.MarkedBy)
+	var entry audit.AuditLog
+	db.DB.Where("action = ?", "attendance_marker_change").First(&entry)
+	assert.Equal(t, record.ID, entry.EntityID)
+	assert.Contains(t, entry.Details, "99 -> ")
+}
+
+func TestMarkAttendanceUniqueDay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	db.DB.AutoMigrate(&users.LeaveRequest{}, &audit.AuditLog{})
+	assert.NoError(t, MigrateUniqueDay(db.DB))
+	assert.NoError(t, MigrateUniqueDay(db.DB), "the migration can run again")
+	student := seedDepartment(t, "CS", 1, 0)[0]
+	day := timeutil.Today().AddDate(0, 0, -1)
+
+	// Records without a subject or period still collide, but soft-deleted ones do not
+	first := Attendance{StudentID: student.ID, Date: day, Status: StatusAbsent, MarkedBy: 99}
+	assert.NoError(t, db.DB.Create(&first).Error)
+	err := db.DB.Create(&Attendance{StudentID: student.ID, Date: day, Status: StatusPresent, MarkedBy: 99}).Error
+	assert.True(t, db.IsUniqueViolation(err))
+	db.DB.Delete(&first)
+
+	// A concurrent request inserts the same day after MarkAttendance's own check has passed
+	raced := false
+	db.DB.Callback().Create().Before("gorm:create").Register("test:race", func(tx *gorm.DB) {
+		if raced || tx.Statement.Table != "attendances" {
+			return
+		}
+		raced = true
+		tx.Session(&gorm.Session{NewDB: true}).Exec("INSERT INTO attendances (student_id, date, status, present, marked_by, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+			student.ID, day, StatusPresent, true, 98, time.Now())
+	})
+
+	router := gin.New()
+	router.POST("/attendance/mark", func(c *gin.Context) {
+		c.Set("userID", uint(99))
+		c.Set("role", users.RoleFaculty)
+	}, MarkAttendance)
+	w := httptest.NewRecorder()
+	body := `{"student_id":` + strconv.FormatUint(uint64(student.ID), 10) + `,"date":"` + day.Format(time.RFC3339) + `","status":"absent"}`
+	req, _ := http.NewRequest("POST", "/attendance/mark", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.True(t, raced)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "already marked")
+
+	// The check before inserting uses the index's key: another subject or period is free, the same one is not
+	mark := func(fields string) int {
+		w := httptest.NewRecorder()
+		body := `{"student_id":` + strconv.FormatUint(uint64(student.ID), 10) + `,"date":"` + day.Format(time.RFC3339) + `","status":"present"` + fields + `}`
+		req, _ := http.NewRequest("POST", "/attendance/mark", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusCreated, mark(`,"subject":"Math","period":"1"`))
+	assert.Equal(t, http.StatusCreated, mark(`,"subject":"Math","period":"2"`))
+	assert.Equal(t, http.StatusBadRequest, mark(`,"subject":"Math","period":"1"`))
+	assert.Equal(t, http.StatusCreated, mark("")) // The raced insert was rolled back with the failed mark
+	assert.Equal(t, http.StatusBadRequest, mark(""))
+}
+
+func TestMigrateUniqueDayRemovesDuplicates(t *testing.T) {
+	setupTestDB(t)
+	students := seedDepartment(t, "CS", 2, 0)
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	math := "Math"
+
+	create := func(studentID uint, subject *string, status string, updated time.Time) Attendance {
+		record := Attendance{StudentID: studentID, Date: day, Subject: subject, Status: status, Present: CountsAsPresent(status), MarkedBy: 1}
+		assert.NoError(t, db.DB.Create(&record).Error)
+		db.DB.Model(&record).UpdateColumn("updated_at", updated)
+		return record
+	}
+	earlier := time.Now().Add(-time.Hour)
+	older := create(students[0].ID, nil, StatusAbsent, earlier)
+	latest := create(students[0].ID, nil, StatusPresent, time.Now())
+	tieLow := create(students[0].ID, &math, StatusAbsent, earlier)
+	tieHigh := create(students[0].ID, &math, StatusLate, earlier) // Same update time: the higher ID wins
+	other := create(students[1].ID, nil, StatusAbsent, earlier)   // No duplicate
+	assert.NoError(t, RefreshSummaries([]uint{students[0].ID}))
+
+	assert.NoError(t, MigrateUniqueDay(db.DB))
+
+	var live []uint
+	db.DB.Model(&Attendance{}).Order("id").Pluck("id", &live)
+	assert.Equal(t, []uint{latest.ID, tieHigh.ID, other.ID}, live)
+	var deleted int64
+	db.DB.Unscoped().Model(&Attendance{}).Where("id IN ? AND deleted_at IS NOT NULL", []uint{older.ID, tieLow.ID}).Count(&deleted)
+	assert.Equal(t, int64(2), deleted)
+
+	var summary AttendanceSummary
+	assert.NoError(t, db.DB.Where("student_id = ?", students[0].ID).First(&summary).Error)
+	assert.Equal(t, 2, summary.TotalDays)
+
+	// Once the index is in place the duplicates cannot come back
+	err := db.DB.Create(&Attendance{StudentID: students[1].ID, Date: day, Status: StatusPresent, Present: true, MarkedBy: 1}).Error
+	assert.True(t, db.IsUniqueViolation(err))
+	assert.NoError(t, MigrateUniqueDay(db.DB))
+}
+
+func TestGetAttendanceCalendar(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	db.DB.AutoMigrate(&users.LeaveRequest{})
+	pair := seedDepartment(t, "CS", 2, 0)
+	student, other := pair[0], pair[1]
+
+	day := func(d int) time.Time { return time.Date(2026, 9, d, 0, 0, 0, 0, time.UTC) }
+	mark := func(studentID uint, d int, status string) {
+		db.DB.Create(&Attendance{StudentID: studentID, Date: day(d), Status: status, Present: CountsAsPresent(status), MarkedBy: 1})
+	}
+	mark(student.ID, 1, StatusPresent)
+	mark(student.ID, 2, StatusLate)
+	mark(student.ID, 3, StatusAbsent) // On leave, so excused
+	mark(student.ID, 5, StatusExcused)
+	mark(student.ID, 6, StatusAbsent) // The better of two marks wins
+	mark(student.ID, 6, StatusPresent)
+	mark(student.ID, 7, StatusAbsent)
+	mark(other.ID, 8, StatusPresent)
+	db.DB.Create(&users.LeaveRequest{StudentID: student.ID, LeaveType: "personal", Reason: "Family function", StartDate: day(3), EndDate: day(4), Status: "approved", Dept: "CS", Days: 2})
+	db.DB.Create(&users.LeaveRequest{StudentID: student.ID, LeaveType: "personal", Reason: "Family function", StartDate: day(9), EndDate: day(9), Status: "pending", Dept: "CS", Days: 1})
+
+	calendar := func(user users.User, query string) (int, map[int]string) {
+		router := gin.New()
+		router.GET("/attendance/calendar", func(c *gin.Context) {
+			c.Set("userID", user.ID)
+			c.Set("role", user.Role)
+		}, GetAttendanceCalendar)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/attendance/calendar"+query, nil)
+		router.ServeHTTP(w, req)
+		var resp struct {
+			StudentID uint           `json:"student_id"`
+			Days      map[int]string `json:"days"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if w.Code == http.StatusOK {
+			assert.Equal(t, student.ID, resp.StudentID)
+		}
+		return w.Code, resp.Days
+	}
+
+	code, days := calendar(student, "?month=2026-09")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, days, 30)
+	assert.Equal(t, DayPresent, days[1])
+	assert.Equal(t, DayLate, days[2])
+	assert.Equal(t, DayExcused, days[3])
+	assert.Equal(t, DayLeave, days[4])
+	assert.Equal(t, DayExcused, days[5])
+	assert.Equal(t, DayPresent, days[6])
+	assert.Equal(t, DayAbsent, days[7])
+	assert.Equal(t, DayUnknown, days[8], "another student's record")
+	assert.Equal(t, DayUnknown, days[9], "pending leaves do not count")
+	assert.Equal(t, DayUnknown, days[30])
+
+	// A month with nothing recorded is all unknown
+	code, days = calendar(student, "?month=2026-10")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, days, 31)
+	for _, status := range days {
+		assert.Equal(t, DayUnknown, status)
+	}
+
+	// Students always get their own calendar; staff name the student
+	_, days = calendar(student, "?month=2026-09&student_id="+strconv.Itoa(int(other.ID)))
+	assert.Equal(t, DayPresent, days[1])
+	faculty := users.User{Model: gorm.Model{ID: 999}, Role: users.RoleFaculty}
+	code, days = calendar(faculty, "?month=2026-09&student_id="+strconv.Itoa(int(student.ID)))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, DayLate, days[2])
+
+	code, _ = calendar(faculty, "?month=2026-09")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = calendar(faculty, "?month=2026-09&student_id=abc")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = calendar(student, "?month=09-2026")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestGetMarkerActivity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	student := seedDepartment(t, "CS", 1, 0)[0]
+	create := func(name, role string) users.User {
+		user := users.User{Name: name, Email: name + "@example.com", Password: "hashed", Role: role, Dept: "CS", IsActive: true}
+		if err := db.DB.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+	busy := create("Busy Faculty", users.RoleFaculty)
+	occasional := create("Occasional Faculty", users.RoleFaculty)
+	idle := create("Idle Faculty", users.RoleFaculty)
+	admin := create("Admin", users.RoleAdmin)
+
+	day := func(d int) time.Time { return time.Date(2026, 9, d, 0, 0, 0, 0, time.UTC) }
+	mark := func(marker users.User, d int) Attendance {
+		record := Attendance{StudentID: student.ID, Date: day(d), Status: StatusPresent, Present: true, MarkedBy: marker.ID}
+		db.DB.Create(&record)
+		return record
+	}
+	for d := 1; d <= 3; d++ {
+		mark(busy, d)
+	}
+	latest := mark(busy, 10)
+	mark(occasional, 2)
+	mark(admin, 20)
+
+	activity := func(query string) (int, []MarkerActivity) {
+		router := gin.New()
+		router.GET("/attendance/marker-activity", func(c *gin.Context) {
+			c.Set("userID", admin.ID)
+			c.Set("role", admin.Role)
+		}, GetMarkerActivity)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/attendance/marker-activity"+query, nil)
+		router.ServeHTTP(w, req)
+		var resp struct {
+			Markers []MarkerActivity `json:"markers"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Markers
+	}
+	counts := func(markers []MarkerActivity) map[string]int64 {
+		byName := map[string]int64{}
+		for _, marker := range markers {
+			byName[marker.MarkerName] = marker.RecordsMarked
+		}
+		return byName
+	}
+
+	code, markers := activity("")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]int64{busy.Name: 4, occasional.Name: 1, admin.Name: 1, idle.Name: 0}, counts(markers))
+	if assert.Len(t, markers, 4) {
+		assert.Equal(t, busy.ID, markers[0].MarkerID, "busiest first")
+		assert.Equal(t, users.RoleFaculty, markers[0].Role)
+		if assert.NotNil(t, markers[0].LastMarkedAt) {
+			assert.WithinDuration(t, latest.CreatedAt, *markers[0].LastMarkedAt, time.Second)
+		}
+		assert.Equal(t, idle.ID, markers[3].MarkerID, "faculty who marked nothing last")
+		assert.Nil(t, markers[3].LastMarkedAt)
+	}
+
+	// The range filters by attendance date; faculty outside it drop to zero
+	code, markers = activity("?from=2026-09-02&to=2026-09-03")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]int64{busy.Name: 2, occasional.Name: 1, idle.Name: 0}, counts(markers))
+
+	code, _ = activity("?from=2026-09-03&to=2026-09-01")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = activity("?from=yesterday")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestGetTodayStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	students := seedDepartment(t, "CS", 3, 0)
+	eeStudent := seedDepartment(t, "EE", 1, 0)[0]
+	faculty := users.User{Name: "CS Faculty", Email: "faculty@cs.example.com", Password: "hashed", Role: users.RoleFaculty, Dept: "CS", IsActive: true}
+	db.DB.Create(&faculty)
+
+	today := timeutil.Today()
+	maths, physics, first := "Maths", "Physics", "1"
+	mark := func(student users.User, day time.Time, subject *string, status string) Attendance {
+		record := Attendance{StudentID: student.ID, Date: day, Subject: subject, Period: &first, Status: status, Present: CountsAsPresent(status), MarkedBy: faculty.ID}
+		db.DB.Create(&record)
+		return record
+	}
+	present := mark(students[0], today, &maths, StatusPresent)
+	mark(students[1], today, &maths, StatusLate)
+	mark(students[2], today, &physics, StatusAbsent)
+	mark(students[2], today.AddDate(0, 0, -1), &maths, StatusPresent) // Yesterday's mark does not count
+	mark(eeStudent, today, &maths, StatusPresent)
+
+	type status struct {
+		Students []StudentMarkStatus `json:"students"`
+		Summary  map[string]int      `json:"summary"`
+		Dept     string              `json:"dept"`
+		Date     string              `json:"date"`
+	}
+	get := func(viewer users.User, query string) (int, status) {
+		router := gin.New()
+		router.GET("/attendance/today", func(c *gin.Context) {
+			c.Set("userID", viewer.ID)
+			c.Set("role", viewer.Role)
+		}, GetTodayStatus)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/attendance/today"+query, nil)
+		router.ServeHTTP(w, req)
+		var resp status
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+	statuses := func(resp status) []string {
+		var got []string
+		for _, student := range resp.Students {
+			got = append(got, student.Status)
+		}
+		return got
+	}
+
+	// Faculty see their own department, in name order
+	code, resp := get(faculty, "?subject=Maths&period=1")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "CS", resp.Dept)
+	assert.Equal(t, today.In(timeutil.Location()).Format(timeutil.DateLayout), resp.Date)
+	assert.Equal(t, []string{StatusPresent, StatusLate, MarkUnmarked}, statuses(resp))
+	assert.Equal(t, map[string]int{StatusPresent: 1, StatusLate: 1, StatusAbsent: 0, StatusExcused: 0, MarkUnmarked: 1}, resp.Summary)
+	if assert.NotNil(t, resp.Students[0].AttendanceID) {
+		assert.Equal(t, present.ID, *resp.Students[0].AttendanceID)
+	}
+	assert.Nil(t, resp.Students[2].AttendanceID)
+
+	_, resp = get(faculty, "?subject=Physics")
+	assert.Equal(t, []string{MarkUnmarked, MarkUnmarked, StatusAbsent}, statuses(resp))
+	_, resp = get(faculty, "?subject=Maths&period=2")
+	assert.Equal(t, []string{MarkUnmarked, MarkUnmarked, MarkUnmarked}, statuses(resp))
+	_, resp = get(faculty, "")
+	assert.Equal(t, 0, resp.Summary[MarkUnmarked])
+
+	// Admins name the department
+	admin := users.User{Model: gorm.Model{ID: 999}, Role: users.RoleAdmin}
+	code, _ = get(admin, "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, resp = get(admin, "?department=EE&subject=Maths")
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, resp.Students, 1) {
+		assert.Equal(t, eeStudent.ID, resp.Students[0].StudentID)
+		assert.Equal(t, StatusPresent, resp.Students[0].Status)
+	}
+}
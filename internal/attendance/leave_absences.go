@@ -0,0 +1,122 @@
+package attendance
+
+import (
+	"campus-backend/internal/audit"
+	"campus-backend/internal/calendar"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LeaveAbsenceResult summarizes one run of GenerateLeaveAbsences
+type LeaveAbsenceResult struct {
+	Date       time.Time `json:"date"`
+	WorkingDay bool      `json:"working_day"` // False for weekends and holidays, when nothing is recorded
+	OnLeave    int       `json:"on_leave"`    // Students with an approved leave covering the date
+	Created    []uint    `json:"created"`     // Students given an excused record
+}
+
+// GenerateLeaveAbsences records an excused absence on the given campus day for every student
+// with an approved leave covering it who has no attendance that day yet. Weekends and holidays
+// are skipped, since excused days count against attendance. Running it again for the same day
+// creates nothing new. markerID is audit.SystemActorID when run by the scheduler.
+func GenerateLeaveAbsences(date time.Time, markerID uint) (*LeaveAbsenceResult, error) {
+	day := timeutil.StartOfDay(date)
+	result := &LeaveAbsenceResult{Date: day, WorkingDay: calendar.IsWorkingDay(day), Created: []uint{}}
+	if !result.WorkingDay {
+		return result, nil
+	}
+
+	var onLeave []uint
+	err := db.DB.Model(&users.LeaveRequest{}).
+		Where("status = ? AND start_date <= ? AND end_date >= ?", "approved", day, day).
+		Distinct().Pluck("student_id", &onLeave).Error
+	if err != nil {
+		return nil, err
+	}
+	result.OnLeave = len(onLeave)
+	if len(onLeave) == 0 {
+		return result, nil
+	}
+
+	var marked []uint
+	if err := db.DB.Model(&Attendance{}).Where("date = ? AND student_id IN ?", day, onLeave).Distinct().Pluck("student_id", &marked).Error; err != nil {
+		return nil, err
+	}
+	already := make(map[uint]bool, len(marked))
+	for _, id := range marked {
+		already[id] = true
+	}
+
+	records := []Attendance{}
+	for _, studentID := range onLeave {
+		if already[studentID] {
+			continue
+		}
+		records = append(records, Attendance{
+			StudentID: studentID,
+			Date:      day,
+			Status:    StatusExcused,
+			Present:   CountsAsPresent(StatusExcused),
+			MarkedBy:  markerID,
+		})
+		result.Created = append(result.Created, studentID)
+	}
+	if len(records) > 0 {
+		if err := db.DB.Create(&records).Error; err != nil {
+			return nil, err
+		}
+		if err := RefreshSummaries(result.Created); err != nil {
+			log.Printf("Failed to refresh attendance summaries after leave absences: %v", err)
+		}
+	}
+
+	log.Printf("Attendance: recorded %d excused absences for approved leaves on %s",
+		len(result.Created), day.In(timeutil.Location()).Format(timeutil.DateLayout))
+	return result, nil
+}
+
+// GenerateYesterdaysLeaveAbsences is the scheduled form of GenerateLeaveAbsences, run once the day is over
+func GenerateYesterdaysLeaveAbsences() error {
+	_, err := GenerateLeaveAbsences(timeutil.Today().AddDate(0, 0, -1), audit.SystemActorID)
+	return err
+}
+
+// GenerateLeaveAbsencesHandler godoc
+// @Summary Record excused attendance for students on leave
+// @Description Admin creates excused attendance for a day for every student on approved leave who has no record that day. Weekends and holidays are skipped. Safe to run repeatedly; the scheduler does the same for each previous day.
+// @Tags Attendance
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Day to process (YYYY-MM-DD, campus time); defaults to today"
+// @Success 200 {object} LeaveAbsenceResult "Excused records created"
+// @Failure 400 {object} map[string]interface{} "Invalid date"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/leave-absences [post]
+func GenerateLeaveAbsencesHandler(c *gin.Context) {
+	date := timeutil.Today()
+	if value := c.Query("date"); value != "" {
+		parsed, err := timeutil.ParseDate(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, expected YYYY-MM-DD"})
+			return
+		}
+		date = parsed
+	}
+
+	adminIDVal, _ := c.Get("userID")
+	result, err := GenerateLeaveAbsences(date, adminIDVal.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record leave absences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
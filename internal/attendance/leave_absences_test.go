@@ -0,0 +1,70 @@
+package attendance
+
+import (
+	"campus-backend/internal/core"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateLeaveAbsences(t *testing.T) {
+	setupTestDB(t)
+	db.DB.AutoMigrate(&users.LeaveRequest{})
+	defer func() { core.AppConfig = nil }()
+	t.Setenv("CAMPUS_HOLIDAYS", "2026-10-22=Founders Day")
+	core.LoadConfig()
+	students := seedDepartment(t, "CS", 3, 0)
+	day := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC) // Tuesday
+
+	onLeave, marked, pending := students[0], students[1], students[2]
+	for _, leave := range []users.LeaveRequest{
+		{StudentID: onLeave.ID, Status: "approved", StartDate: day.AddDate(0, 0, -1), EndDate: day.AddDate(0, 0, 1)},
+		{StudentID: marked.ID, Status: "approved", StartDate: day, EndDate: day},
+		{StudentID: pending.ID, Status: "pending", StartDate: day, EndDate: day},
+	} {
+		leave.LeaveType, leave.Reason, leave.Dept, leave.Days = "personal", "Family function", "CS", 1
+		db.DB.Create(&leave)
+	}
+	// Already marked students keep their record, even if it disagrees with the leave
+	db.DB.Create(&Attendance{StudentID: marked.ID, Date: day, Status: StatusPresent, Present: true, MarkedBy: 1})
+
+	result, err := GenerateLeaveAbsences(day.Add(15*time.Hour), 7)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.OnLeave)
+	assert.Equal(t, []uint{onLeave.ID}, result.Created)
+
+	var record Attendance
+	assert.NoError(t, db.DB.Where("student_id = ? AND date = ?", onLeave.ID, day).First(&record).Error)
+	assert.Equal(t, StatusExcused, record.Status)
+	assert.False(t, record.Present)
+	assert.Equal(t, uint(7), record.MarkedBy)
+
+	// A second run for the same day creates nothing
+	result, err = GenerateLeaveAbsences(day, 7)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Created)
+
+	var count int64
+	db.DB.Model(&Attendance{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+
+	// Weekends and holidays inside the leave get no record, so they cannot lower attendance
+	longLeave := users.LeaveRequest{StudentID: pending.ID, Status: "approved", StartDate: day, EndDate: day.AddDate(0, 0, 5), LeaveType: "medical", Reason: "Surgery", Dept: "CS", Days: 6}
+	db.DB.Create(&longLeave)
+	for _, nonWorking := range []time.Time{day.AddDate(0, 0, 2), day.AddDate(0, 0, 4)} { // Founders Day, Saturday
+		result, err = GenerateLeaveAbsences(nonWorking, 7)
+		assert.NoError(t, err)
+		assert.False(t, result.WorkingDay)
+		assert.Empty(t, result.Created)
+	}
+	result, err = GenerateLeaveAbsences(day.AddDate(0, 0, 3), 7) // Friday
+	assert.NoError(t, err)
+	assert.True(t, result.WorkingDay)
+	assert.Equal(t, []uint{pending.ID}, result.Created)
+
+	db.DB.Model(&Attendance{}).Count(&count)
+	assert.Equal(t, int64(3), count)
+}
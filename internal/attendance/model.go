@@ -1,6 +1,8 @@
 package attendance
 
 import (
+	"log"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,14 +12,142 @@ import (
 type Attendance struct {
 	gorm.Model
 	StudentID uint      `json:"student_id" gorm:"not null;index"`
-	Student   User      `json:"student,omitempty" gorm:"foreignKey:StudentID"`
+	Student   User      `json:"student,omitempty" gorm:"foreignKey:StudentID;belongsTo"` // Otherwise gorm takes User.StudentID as a has-one key
 	Date      time.Time `json:"date" gorm:"not null;index"`
-	Present   bool      `json:"present" gorm:"not null"`
+	Status    string    `json:"status" gorm:"size:20;not null;default:absent;index"` // present, absent, late or excused
+	Present   bool      `json:"present" gorm:"not null"`                             // Derived from Status: true for present and late
 	MarkedBy  uint      `json:"marked_by" gorm:"not null"`
 	Marker    User      `json:"marker,omitempty" gorm:"foreignKey:MarkedBy"`
 	Subject   *string   `json:"subject,omitempty"`
 	Period    *string   `json:"period,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
+
+	// Set when the student was marked present during this approved leave, i.e. attended the leave day
+	OverriddenLeaveID *uint `json:"overridden_leave_id,omitempty" gorm:"index"`
+}
+
+// Subject is a class taught in a department; attendance subjects are checked against it
+type Subject struct {
+	gorm.Model
+	Name      string   `json:"name" gorm:"not null;uniqueIndex:idx_subject_dept_name"`
+	Dept      string   `json:"dept" gorm:"not null;uniqueIndex:idx_subject_dept_name"`
+	TeacherID *uint    `json:"teacher_id,omitempty" gorm:"index"`
+	Periods   []string `json:"periods" gorm:"serializer:json"` // Allowed periods; empty allows any
+}
+
+// AllowsPeriod reports whether the period may be recorded for this subject
+func (s *Subject) AllowsPeriod(period string) bool {
+	if len(s.Periods) == 0 {
+		return true
+	}
+	for _, allowed := range s.Periods {
+		if strings.EqualFold(allowed, period) {
+			return true
+		}
+	}
+	return false
+}
+
+// Attendance statuses
+const (
+	StatusPresent = "present"
+	StatusAbsent  = "absent"
+	StatusLate    = "late"    // Counts as present for percentages
+	StatusExcused = "excused" // Counts as absent for percentages
+)
+
+// CountsAsPresent reports whether a status counts towards attendance percentage
+func CountsAsPresent(status string) bool {
+	return status == StatusPresent || status == StatusLate
+}
+
+// MigrateStatus fills Status for records created before it existed, from the Present flag. It runs before
+// the schema migration: adding the column there would give every old record the "absent" default, and
+// making it NOT NULL fails while records without a status remain.
+func MigrateStatus(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+	if !migrator.HasTable(&Attendance{}) {
+		return nil
+	}
+	if !migrator.HasColumn(&Attendance{}, "status") {
+		if err := tx.Exec("ALTER TABLE attendances ADD COLUMN status VARCHAR(20)").Error; err != nil {
+			return err
+		}
+	}
+	// Soft-deleted records need a status too before the column can be NOT NULL
+	return tx.Unscoped().Model(&Attendance{}).
+		Where("status IS NULL OR status = ?", "").
+		Update("status", gorm.Expr("CASE WHEN present THEN ? ELSE ? END", StatusPresent, StatusAbsent)).Error
+}
+
+// MigrateUniqueDay adds the unique index that keeps two concurrent marks from both inserting a record for
+// the same student, day, subject and period. Subject and period are coalesced since NULLs never collide in a
+// unique index, and soft-deleted rows are left out so they do not block marking the day again.
+// Duplicates already in the table would make the index fail, so the first run keeps the most recently
+// updated record of each set, soft-deletes the rest and logs their IDs.
+func MigrateUniqueDay(tx *gorm.DB) error {
+	if tx.Migrator().HasIndex(&Attendance{}, "idx_attendance_student_day") {
+		return nil
+	}
+
+	var removed []uint
+	var affected []uint
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		var duplicates []struct {
+			ID        uint
+			StudentID uint
+		}
+		// A record is superseded when another live record for its slot was updated later, or at the same time with a higher ID
+		err := tx.Table("attendances AS a").Select("a.id, a.student_id").
+			Where("a.deleted_at IS NULL").
+			Where("EXISTS (SELECT 1 FROM attendances AS b WHERE b.deleted_at IS NULL AND b.student_id = a.student_id AND b.date = a.date " +
+				"AND COALESCE(b.subject, '') = COALESCE(a.subject, '') AND COALESCE(b.period, '') = COALESCE(a.period, '') " +
+				"AND (b.updated_at > a.updated_at OR (b.updated_at = a.updated_at AND b.id > a.id)))").
+			Order("a.id").Scan(&duplicates).Error
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[uint]bool)
+		for _, duplicate := range duplicates {
+			removed = append(removed, duplicate.ID)
+			if !seen[duplicate.StudentID] {
+				seen[duplicate.StudentID] = true
+				affected = append(affected, duplicate.StudentID)
+			}
+		}
+		if len(removed) > 0 {
+			if err := tx.Delete(&Attendance{}, removed).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_attendance_student_day ON attendances " +
+			"(student_id, date, COALESCE(subject, ''), COALESCE(period, '')) WHERE deleted_at IS NULL").Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(removed) > 0 {
+		log.Printf("Attendance: removed %d duplicate records before adding the one-per-day index: %v", len(removed), removed)
+		if err := RefreshSummaries(affected); err != nil {
+			log.Printf("Failed to refresh attendance summaries after removing duplicates: %v", err)
+		}
+	}
+	return nil
+}
+
+// inSlot constrains an attendance query to one key of the unique-day index: the student's record for the
+// day, subject and period, with a missing subject or period matching an empty one as the index does
+func inSlot(query *gorm.DB, studentID uint, date time.Time, subject, period *string) *gorm.DB {
+	orEmpty := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+	return query.Where("student_id = ? AND date = ? AND COALESCE(subject, '') = ? AND COALESCE(period, '') = ?",
+		studentID, date, orEmpty(subject), orEmpty(period))
 }
 
 // User represents a user (imported from users package)
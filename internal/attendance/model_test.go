@@ -0,0 +1,75 @@
+package attendance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// legacyAttendance is the attendances table as it was before statuses existed
+type legacyAttendance struct {
+	gorm.Model
+	StudentID uint      `gorm:"not null;index"`
+	Date      time.Time `gorm:"not null;index"`
+	Present   bool      `gorm:"not null"`
+	MarkedBy  uint      `gorm:"not null"`
+}
+
+func (legacyAttendance) TableName() string {
+	return "attendances"
+}
+
+func TestCountsAsPresent(t *testing.T) {
+	assert.True(t, CountsAsPresent(StatusPresent))
+	assert.True(t, CountsAsPresent(StatusLate))
+	assert.False(t, CountsAsPresent(StatusAbsent))
+	assert.False(t, CountsAsPresent(StatusExcused))
+	assert.False(t, CountsAsPresent(""))
+}
+
+func TestMigrateStatus(t *testing.T) {
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+
+	// Nothing to do before the table exists
+	assert.NoError(t, MigrateStatus(testDB))
+
+	testDB.AutoMigrate(&legacyAttendance{})
+	day := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	present := legacyAttendance{StudentID: 1, Date: day, Present: true, MarkedBy: 2}
+	absent := legacyAttendance{StudentID: 1, Date: day.AddDate(0, 0, 1), Present: false, MarkedBy: 2}
+	deleted := legacyAttendance{StudentID: 1, Date: day.AddDate(0, 0, 2), Present: true, MarkedBy: 2}
+	for _, record := range []*legacyAttendance{&present, &absent, &deleted} {
+		testDB.Create(record)
+	}
+	testDB.Delete(&deleted)
+
+	assert.NoError(t, MigrateStatus(testDB))
+	assert.NoError(t, testDB.AutoMigrate(&Attendance{}))
+	assert.NoError(t, MigrateStatus(testDB), "the backfill can run again")
+
+	status := func(id uint) string {
+		var record Attendance
+		testDB.Unscoped().First(&record, id)
+		return record.Status
+	}
+	assert.Equal(t, StatusPresent, status(present.ID))
+	assert.Equal(t, StatusAbsent, status(absent.ID))
+	assert.Equal(t, StatusPresent, status(deleted.ID))
+
+	// The column is now NOT NULL and defaults to absent
+	err = testDB.Exec("INSERT INTO attendances (student_id, date, present, marked_by, status) VALUES (1, ?, true, 2, NULL)", day.AddDate(0, 0, 3)).Error
+	assert.Error(t, err)
+	assert.NoError(t, testDB.Exec("INSERT INTO attendances (student_id, date, present, marked_by) VALUES (1, ?, false, 2)", day.AddDate(0, 0, 4)).Error)
+	var defaulted Attendance
+	testDB.Where("date = ?", day.AddDate(0, 0, 4)).First(&defaulted)
+	assert.Equal(t, StatusAbsent, defaulted.Status)
+}
@@ -0,0 +1,88 @@
+package attendance
+
+import (
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SeriesPoint holds a student's raw attendance counts within one day, week or month
+type SeriesPoint struct {
+	Bucket  string `json:"bucket"`  // YYYY-MM-DD of the day or the week's Monday, or YYYY-MM
+	Present int    `json:"present"` // Includes late
+	Absent  int    `json:"absent"`  // Excludes excused
+	Excused int    `json:"excused"`
+}
+
+// GetAttendanceSeries godoc
+// @Summary Get raw attendance counts for charting
+// @Description Present, absent and excused counts per day, week or month, oldest first, for clients drawing their own charts. Late counts as present. Students get their own; faculty see their department's students, wardens their hostel's, admins anyone. Buckets with no records are omitted.
+// @Tags Attendance
+// @Produce json
+// @Security BearerAuth
+// @Param student_id query int false "Student ID (required for non-students)"
+// @Param granularity query string false "day, week or month" default(day)
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{} "Attendance series"
+// @Failure 400 {object} map[string]interface{} "Invalid parameters"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Student is outside your scope"
+// @Failure 404 {object} map[string]interface{} "Student not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/series [get]
+func GetAttendanceSeries(c *gin.Context) {
+	granularity := c.DefaultQuery("granularity", db.PeriodDay)
+	if granularity != db.PeriodDay && granularity != db.PeriodWeek && granularity != db.PeriodMonth {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "granularity must be day, week or month"})
+		return
+	}
+
+	studentID, ok := resolveStudentID(c)
+	if !ok {
+		return
+	}
+	start, end, ok := parseDateRangeParams(c, "from", "to")
+	if !ok {
+		return
+	}
+
+	var student users.User
+	if err := db.DB.Where("id = ? AND role = ?", studentID, users.RoleStudent).First(&student).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return
+	}
+	if !studentInScope(c, &student) {
+		return
+	}
+
+	bucket, err := db.DateBucket(db.DB, granularity, "date")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate attendance series"})
+		return
+	}
+
+	series := make([]SeriesPoint, 0)
+	err = withDateRange(db.DB.Model(&Attendance{}), start, end).
+		Select(bucket+" AS bucket, "+
+			"COUNT(CASE WHEN present THEN 1 END) AS present, "+
+			"COUNT(CASE WHEN NOT present AND status <> ? THEN 1 END) AS absent, "+
+			"COUNT(CASE WHEN status = ? THEN 1 END) AS excused", StatusExcused, StatusExcused).
+		Where("student_id = ?", student.ID).
+		Group(bucket).
+		Order("bucket ASC").
+		Scan(&series).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate attendance series"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"student_id":   student.ID,
+		"student_name": student.Name,
+		"granularity":  granularity,
+		"series":       series,
+	})
+}
@@ -0,0 +1,62 @@
+package attendance
+
+import (
+	"campus-backend/internal/users"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAttendanceSeries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	student := seedDepartment(t, "CS", 1, 5)[0] // present, present, late, absent, excused from 2026-09-01
+
+	get := func(viewerID uint, role, query string) (int, []SeriesPoint) {
+		router := gin.New()
+		router.GET("/attendance/series", func(c *gin.Context) {
+			c.Set("userID", viewerID)
+			c.Set("role", role)
+		}, GetAttendanceSeries)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/attendance/series"+query, nil))
+
+		var resp struct {
+			Series []SeriesPoint `json:"series"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Series
+	}
+
+	code, series := get(student.ID, users.RoleStudent, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []SeriesPoint{
+		{Bucket: "2026-09-01", Present: 1},
+		{Bucket: "2026-09-02", Present: 1},
+		{Bucket: "2026-09-03", Present: 1},
+		{Bucket: "2026-09-04", Absent: 1},
+		{Bucket: "2026-09-05", Excused: 1},
+	}, series)
+
+	code, series = get(1, users.RoleAdmin, fmt.Sprintf("?student_id=%d&granularity=week", student.ID))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []SeriesPoint{{Bucket: "2026-08-31", Present: 3, Absent: 1, Excused: 1}}, series)
+
+	code, series = get(student.ID, users.RoleStudent, "?granularity=month&from=2026-09-03&to=2026-09-04")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []SeriesPoint{{Bucket: "2026-09", Present: 1, Absent: 1}}, series)
+
+	code, series = get(student.ID, users.RoleStudent, "?from=2026-10-01")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, series)
+
+	code, _ = get(student.ID, users.RoleStudent, "?granularity=year")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get(student.ID, users.RoleStudent, "?from=09/01/2026")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,323 @@
+package attendance
+
+import (
+	"bytes"
+	"campus-backend/internal/core"
+	"campus-backend/internal/settings"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupTestDB(tb testing.TB) {
+	testDB, err := gorm.Open(sqlite.Open(tb.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		tb.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&users.User{}, &Attendance{}, &Subject{}, &AttendanceSummary{}, &settings.Term{})
+	db.DB = testDB
+}
+
+// seedDepartment creates students in one department, each with a mix of statuses over the given number of days
+func seedDepartment(tb testing.TB, dept string, students, days int) []users.User {
+	statuses := []string{StatusPresent, StatusPresent, StatusLate, StatusAbsent, StatusExcused}
+	base := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	created := make([]users.User, 0, students)
+	for i := 0; i < students; i++ {
+		student := users.User{
+			Name:     fmt.Sprintf("Student %03d", i),
+			Email:    fmt.Sprintf("student%03d@%s.example.com", i, dept),
+			Password: "hashed",
+			Role:     users.RoleStudent,
+			Dept:     dept,
+			IsActive: true,
+		}
+		if err := db.DB.Create(&student).Error; err != nil {
+			tb.Fatal(err)
+		}
+		created = append(created, student)
+
+		records := make([]Attendance, 0, days)
+		for d := 0; d < days-i%3; d++ { // Vary totals so last dates differ
+			status := statuses[(i+d)%len(statuses)]
+			records = append(records, Attendance{
+				StudentID: student.ID,
+				Date:      base.AddDate(0, 0, d),
+				Status:    status,
+				Present:   CountsAsPresent(status),
+				MarkedBy:  1,
+			})
+		}
+		if len(records) > 0 {
+			if err := db.DB.Create(&records).Error; err != nil {
+				tb.Fatal(err)
+			}
+		}
+	}
+	return created
+}
+
+// perStudentStats is the previous per-student implementation, kept to check and benchmark studentStats against
+func perStudentStats(students []users.User, start, end *time.Time) []AttendanceStats {
+	stats := make([]AttendanceStats, 0, len(students))
+	for _, student := range students {
+		var totalDays, presentDays, lateDays, excusedDays int64
+		withDateRange(db.DB.Model(&Attendance{}), start, end).Where("student_id = ?", student.ID).Count(&totalDays)
+		withDateRange(db.DB.Model(&Attendance{}), start, end).Where("student_id = ? AND present = ?", student.ID, true).Count(&presentDays)
+		withDateRange(db.DB.Model(&Attendance{}), start, end).Where("student_id = ? AND status = ?", student.ID, StatusLate).Count(&lateDays)
+		withDateRange(db.DB.Model(&Attendance{}), start, end).Where("student_id = ? AND status = ?", student.ID, StatusExcused).Count(&excusedDays)
+
+		entry := AttendanceStats{
+			StudentID:   student.ID,
+			StudentName: student.Name,
+			TotalDays:   int(totalDays),
+			PresentDays: int(presentDays),
+			AbsentDays:  int(totalDays - presentDays),
+			LateDays:    int(lateDays),
+			ExcusedDays: int(excusedDays),
+		}
+		if totalDays > 0 {
+			entry.AttendancePercentage = float64(presentDays) / float64(totalDays) * 100
+		}
+
+		var lastRecord Attendance
+		if err := withDateRange(db.DB, start, end).Where("student_id = ?", student.ID).Order("date DESC").First(&lastRecord).Error; err == nil {
+			entry.LastAttendance = &lastRecord.Date
+		}
+		stats = append(stats, entry)
+	}
+	return stats
+}
+
+func TestStudentStatsMatchesPerStudentQueries(t *testing.T) {
+	setupTestDB(t)
+	students := seedDepartment(t, "CS", 6, 10)
+
+	// A student with no records still gets a zeroed entry
+	empty := users.User{Name: "No Records", Email: "empty@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", IsActive: true}
+	db.DB.Create(&empty)
+	students = append(students, empty)
+
+	start := time.Date(2026, 9, 3, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 9, 8, 0, 0, 0, 0, time.UTC)
+	ranges := [][2]*time.Time{{nil, nil}, {&start, &end}}
+
+	for _, r := range ranges {
+		got, err := studentStats(students, r[0], r[1])
+		assert.NoError(t, err)
+
+		want := perStudentStats(students, r[0], r[1])
+		assert.Equal(t, len(want), len(got))
+		for i := range want {
+			assert.Equal(t, want[i].StudentID, got[i].StudentID)
+			assert.Equal(t, want[i].TotalDays, got[i].TotalDays)
+			assert.Equal(t, want[i].PresentDays, got[i].PresentDays)
+			assert.Equal(t, want[i].AbsentDays, got[i].AbsentDays)
+			assert.Equal(t, want[i].LateDays, got[i].LateDays)
+			assert.Equal(t, want[i].ExcusedDays, got[i].ExcusedDays)
+			assert.InDelta(t, want[i].AttendancePercentage, got[i].AttendancePercentage, 0.0001)
+			if want[i].LastAttendance == nil {
+				assert.Nil(t, got[i].LastAttendance)
+			} else if assert.NotNil(t, got[i].LastAttendance) {
+				assert.True(t, want[i].LastAttendance.Equal(*got[i].LastAttendance))
+			}
+		}
+	}
+}
+
+func BenchmarkDepartmentStats(b *testing.B) {
+	setupTestDB(b)
+	students := seedDepartment(b, "CS", 200, 30)
+
+	b.Run("grouped", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := studentStats(students, nil, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("per_student", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			perStudentStats(students, nil, nil)
+		}
+	})
+}
+
+func TestLowAttendanceWarning(t *testing.T) {
+	setupTestDB(t)
+	defer func() { core.AppConfig = nil }()
+	core.LoadConfig()
+	core.AppConfig.Attendance = core.AttendanceConfig{LowThreshold: 75, DeptThresholds: map[string]int{"EE": 50}}
+
+	student := users.User{Name: "Asha", Email: "asha@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", IsActive: true}
+	db.DB.Create(&student)
+	base := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	mark := func(day int, status string) {
+		db.DB.Create(&Attendance{StudentID: student.ID, Date: base.AddDate(0, 0, day), Status: status, Present: CountsAsPresent(status), MarkedBy: 1})
+	}
+
+	// First record absent: crosses from no attendance to 0%
+	warning, err := lowAttendanceWarning(&student)
+	assert.NoError(t, err)
+	assert.NotNil(t, warning)
+
+	for day, status := range []string{StatusPresent, StatusPresent, StatusLate, StatusPresent, StatusAbsent} {
+		mark(day, status)
+	}
+
+	// 4/5 = 80%; another absence makes 4/6 = 66.7%
+	warning, err = lowAttendanceWarning(&student)
+	assert.NoError(t, err)
+	if assert.NotNil(t, warning) {
+		assert.Equal(t, float64(75), warning.Threshold)
+		assert.InDelta(t, 66.67, warning.AttendancePercentage, 0.01)
+	}
+
+	// Already below the threshold: no repeat warning
+	mark(5, StatusAbsent)
+	warning, err = lowAttendanceWarning(&student)
+	assert.NoError(t, err)
+	assert.Nil(t, warning)
+
+	// Department override: another absence makes 4/7 = 57%, still above EE's 50%
+	student.Dept = "EE"
+	warning, err = lowAttendanceWarning(&student)
+	assert.NoError(t, err)
+	assert.Nil(t, warning)
+}
+
+func TestGetBatchStatsScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	csStudent := seedDepartment(t, "CS", 1, 5)[0]
+	eeStudent := seedDepartment(t, "EE", 1, 5)[0]
+	hostel := "North"
+	db.DB.Model(&csStudent).Update("hostel", hostel)
+
+	csFaculty := users.User{Name: "CS Faculty", Email: "faculty@cs.example.com", Password: "hashed", Role: users.RoleFaculty, Dept: "CS", IsActive: true}
+	warden := users.User{Name: "Warden", Email: "warden@example.com", Password: "hashed", Role: users.RoleWarden, Hostel: &hostel, IsActive: true}
+	db.DB.Create(&csFaculty)
+	db.DB.Create(&warden)
+
+	post := func(viewer users.User, ids ...uint) (int, map[string]interface{}) {
+		router := gin.New()
+		router.POST("/attendance/stats/batch", func(c *gin.Context) {
+			c.Set("userID", viewer.ID)
+			c.Set("role", viewer.Role)
+		}, GetBatchStats)
+		body, _ := json.Marshal(BatchStatsRequest{StudentIDs: ids})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/attendance/stats/batch", bytes.NewReader(body)))
+
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	code, resp := post(csFaculty, csStudent.ID)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, resp["stats"], 1)
+
+	// An EE student fails the whole request, alone or mixed with in-scope IDs
+	code, resp = post(csFaculty, eeStudent.ID)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, []interface{}{float64(eeStudent.ID)}, resp["out_of_scope"])
+	code, _ = post(csFaculty, csStudent.ID, eeStudent.ID)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, _ = post(warden, csStudent.ID)
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = post(warden, eeStudent.ID)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, resp = post(users.User{Model: gorm.Model{ID: 1}, Role: users.RoleAdmin}, csStudent.ID, eeStudent.ID)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, resp["stats"], 2)
+}
+
+func TestStatsDateRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	defer func() { core.AppConfig = nil }()
+	core.LoadConfig()
+	// The first cycles present, present, late, absent, excused from 1 September
+	students := seedDepartment(t, "CS", 2, 10)
+	faculty := users.User{Name: "CS Faculty", Email: "faculty@cs.example.com", Password: "hashed", Role: users.RoleFaculty, Dept: "CS", IsActive: true}
+	db.DB.Create(&faculty)
+
+	get := func(viewer users.User, path string, handler gin.HandlerFunc) (int, []byte) {
+		router := gin.New()
+		router.GET("/stats", func(c *gin.Context) {
+			c.Set("userID", viewer.ID)
+			c.Set("role", viewer.Role)
+		}, handler)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code, w.Body.Bytes()
+	}
+	stats := func(query string) AttendanceStats {
+		code, body := get(students[0], "/stats"+query, GetStats)
+		assert.Equal(t, http.StatusOK, code)
+		var resp AttendanceStats
+		json.Unmarshal(body, &resp)
+		return resp
+	}
+
+	assert.Equal(t, 10, stats("").TotalDays)
+	firstWeek := stats("?start_date=2026-09-01&end_date=2026-09-05")
+	assert.Equal(t, 5, firstWeek.TotalDays)
+	assert.Equal(t, 3, firstWeek.PresentDays)
+	assert.Equal(t, 1, firstWeek.LateDays)
+	assert.Equal(t, 2, firstWeek.AbsentDays)
+	assert.Equal(t, 1, firstWeek.ExcusedDays)
+	assert.Equal(t, float64(60), firstWeek.AttendancePercentage)
+	assert.Equal(t, time.Date(2026, 9, 5, 0, 0, 0, 0, time.UTC), firstWeek.LastAttendance.UTC())
+
+	// Either end may be left open
+	assert.Equal(t, 2, stats("?start_date=2026-09-09").TotalDays)
+	assert.Equal(t, 3, stats("?end_date=2026-09-03").TotalDays)
+	assert.Equal(t, 0, stats("?start_date=2026-10-01").TotalDays)
+
+	department := func(query string) (int, []AttendanceStats) {
+		code, body := get(faculty, "/stats"+query, GetDepartmentStats)
+		var resp struct {
+			Stats []AttendanceStats `json:"stats"`
+		}
+		json.Unmarshal(body, &resp)
+		return code, resp.Stats
+	}
+	code, deptStats := department("?start_date=2026-09-06&end_date=2026-09-07")
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, deptStats, 2) {
+		for _, entry := range deptStats {
+			assert.Equal(t, 2, entry.TotalDays)
+			assert.Equal(t, 2, entry.PresentDays)
+		}
+	}
+	_, deptStats = department("")
+	if assert.Len(t, deptStats, 2) {
+		assert.Equal(t, 10, deptStats[0].TotalDays)
+	}
+
+	for _, query := range []string{"?start_date=2026/09/01", "?end_date=September", "?start_date=2026-09-05&end_date=2026-09-01"} {
+		code, _ := get(students[0], "/stats"+query, GetStats)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+		code, _ = department(query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+}
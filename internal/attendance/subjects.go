@@ -0,0 +1,383 @@
+package attendance
+
+import (
+	"campus-backend/internal/auth"
+	"campus-backend/internal/core"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/validation"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ErrUnknownSubject is returned when a department has subjects defined and the name matches none of them
+var ErrUnknownSubject = errors.New("unknown subject")
+
+type SubjectRequest struct {
+	Name      string   `json:"name" binding:"required" validate:"required,min=2,max=50"`
+	Dept      string   `json:"dept,omitempty" validate:"omitempty,max=50"` // Admin only; faculty always use their own
+	TeacherID *uint    `json:"teacher_id,omitempty"`
+	Periods   []string `json:"periods,omitempty" validate:"omitempty,max=20,dive,required,max=20"`
+}
+
+type NormalizeSubjectsRequest struct {
+	Aliases map[string]string `json:"aliases,omitempty"` // Free-text value -> subject name, e.g. "Maths" -> "Mathematics"
+}
+
+// resolveSubject finds the department's subject matching name case-insensitively. It returns
+// nil without error when the department has no subjects yet, so free text is still accepted there.
+func resolveSubject(dept, name string) (*Subject, error) {
+	var subjects []Subject
+	if err := db.DB.Where("dept = ?", dept).Find(&subjects).Error; err != nil {
+		return nil, err
+	}
+	if len(subjects) == 0 {
+		return nil, nil
+	}
+
+	name = strings.TrimSpace(name)
+	for i := range subjects {
+		if strings.EqualFold(subjects[i].Name, name) {
+			return &subjects[i], nil
+		}
+	}
+	return nil, ErrUnknownSubject
+}
+
+// subjectNames lists the department's subject names, for error messages
+func subjectNames(dept string) []string {
+	var names []string
+	db.DB.Model(&Subject{}).Where("dept = ?", dept).Order("name ASC").Pluck("name", &names)
+	return names
+}
+
+// isFacultyMember reports whether the user exists and has the faculty role, so can be a subject's teacher
+func isFacultyMember(userID uint) bool {
+	var count int64
+	db.DB.Model(&users.User{}).Where("id = ? AND role = ?", userID, users.RoleFaculty).Count(&count)
+	return count > 0
+}
+
+// loadManagedSubject loads the subject in the path, checking faculty only manage their own department's.
+// Writes an error response and returns nil on failure.
+func loadManagedSubject(c *gin.Context) *Subject {
+	var subject Subject
+	if err := db.DB.First(&subject, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Subject not found"})
+		return nil
+	}
+
+	if role, _ := auth.CurrentRole(c); role == users.RoleFaculty {
+		faculty, err := auth.CurrentUser(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Faculty not found"})
+			return nil
+		}
+		if faculty.Dept != subject.Dept {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage subjects in your department"})
+			return nil
+		}
+	}
+	return &subject
+}
+
+// CreateSubject godoc
+// @Summary Add a subject
+// @Description Faculty add a subject to their department (admins pass dept). teacher_id, if given, must be a faculty member; faculty default to themselves. Once a department has subjects, attendance must use one of them.
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body SubjectRequest true "Subject"
+// @Success 201 {object} map[string]interface{} "Subject created"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 409 {object} map[string]interface{} "Subject already exists in the department"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/subjects [post]
+func CreateSubject(c *gin.Context) {
+	var req SubjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if err := validation.ValidateStruct(req); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+
+	user, err := auth.CurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
+		return
+	}
+
+	if req.TeacherID != nil && !isFacultyMember(*req.TeacherID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "teacher_id must be an existing faculty member"})
+		return
+	}
+
+	subject := Subject{Name: req.Name, Dept: strings.TrimSpace(req.Dept), TeacherID: req.TeacherID, Periods: req.Periods}
+	if user.Role == users.RoleFaculty {
+		subject.Dept = user.Dept
+		if subject.TeacherID == nil {
+			subject.TeacherID = &user.ID
+		}
+	} else if subject.Dept == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dept is required"})
+		return
+	}
+
+	var existing int64
+	db.DB.Model(&Subject{}).Where("dept = ? AND LOWER(name) = LOWER(?)", subject.Dept, subject.Name).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Subject already exists in this department"})
+		return
+	}
+
+	if err := db.DB.Create(&subject).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create subject"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Subject created successfully",
+		"subject": subject,
+	})
+}
+
+// ListSubjects godoc
+// @Summary List subjects
+// @Description List a department's subjects. Faculty and students default to their own department.
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param dept query string false "Department (required for admins)"
+// @Param mine query bool false "Faculty only: subjects they teach"
+// @Success 200 {object} map[string]interface{} "Subjects"
+// @Failure 400 {object} map[string]interface{} "Invalid parameters"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/subjects [get]
+func ListSubjects(c *gin.Context) {
+	user, err := auth.CurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
+		return
+	}
+
+	dept := c.Query("dept")
+	if dept == "" && (user.Role == users.RoleFaculty || user.Role == users.RoleStudent) {
+		dept = user.Dept
+	}
+	if dept == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dept parameter is required"})
+		return
+	}
+
+	query := db.DB.Where("dept = ?", dept)
+	if c.Query("mine") == "true" && user.Role == users.RoleFaculty {
+		query = query.Where("teacher_id = ?", user.ID)
+	}
+
+	subjects := []Subject{}
+	if err := query.Order("name ASC").Find(&subjects).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subjects"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dept":     dept,
+		"subjects": subjects,
+	})
+}
+
+// UpdateSubject godoc
+// @Summary Update a subject
+// @Description Rename a subject or change its teacher (a faculty member) and periods. Renaming also updates the department's existing attendance records.
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Subject ID"
+// @Param request body SubjectRequest true "Subject"
+// @Success 200 {object} map[string]interface{} "Subject updated"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Subject not found"
+// @Failure 409 {object} map[string]interface{} "Subject already exists in the department"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/subjects/{id} [put]
+func UpdateSubject(c *gin.Context) {
+	var req SubjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if err := validation.ValidateStruct(req); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+
+	subject := loadManagedSubject(c)
+	if subject == nil {
+		return
+	}
+
+	if req.TeacherID != nil && !isFacultyMember(*req.TeacherID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "teacher_id must be an existing faculty member"})
+		return
+	}
+
+	var existing int64
+	db.DB.Model(&Subject{}).Where("dept = ? AND LOWER(name) = LOWER(?) AND id <> ?", subject.Dept, req.Name, subject.ID).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Subject already exists in this department"})
+		return
+	}
+
+	oldName := subject.Name
+	subject.Name = req.Name
+	subject.Periods = req.Periods
+	if req.TeacherID != nil {
+		subject.TeacherID = req.TeacherID
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(subject).Error; err != nil {
+			return err
+		}
+		if oldName == subject.Name {
+			return nil
+		}
+		return tx.Model(&Attendance{}).
+			Where("subject = ? AND student_id IN (?)", oldName, departmentStudentIDs(tx, subject.Dept)).
+			Update("subject", subject.Name).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update subject"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Subject updated successfully",
+		"subject": subject,
+	})
+}
+
+// DeleteSubject godoc
+// @Summary Delete a subject
+// @Description Remove a subject from the department's list. Existing attendance records keep their subject text.
+// @Tags Attendance
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Subject ID"
+// @Success 200 {object} map[string]interface{} "Subject deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Subject not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/subjects/{id} [delete]
+func DeleteSubject(c *gin.Context) {
+	subject := loadManagedSubject(c)
+	if subject == nil {
+		return
+	}
+
+	if err := db.DB.Delete(subject).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete subject"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Subject deleted successfully"})
+}
+
+// NormalizeSubjects godoc
+// @Summary Normalize attendance subjects
+// @Description Admin rewrites free-text attendance subjects to the department's subject names, matching case and surrounding spaces loosely and applying any aliases given
+// @Tags Attendance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body NormalizeSubjectsRequest false "Aliases"
+// @Success 200 {object} map[string]interface{} "Records updated per subject"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/subjects/normalize [post]
+func NormalizeSubjects(c *gin.Context) {
+	var req NormalizeSubjectsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+			return
+		}
+	}
+
+	// Group aliases by the subject name they map to
+	aliasesByName := make(map[string][]string)
+	for alias, name := range req.Aliases {
+		key := strings.ToLower(strings.TrimSpace(name))
+		aliasesByName[key] = append(aliasesByName[key], strings.ToLower(strings.TrimSpace(alias)))
+	}
+
+	var subjects []Subject
+	if err := db.DB.Order("dept ASC, name ASC").Find(&subjects).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get subjects"})
+		return
+	}
+
+	type normalized struct {
+		SubjectID uint   `json:"subject_id"`
+		Dept      string `json:"dept"`
+		Name      string `json:"name"`
+		Updated   int64  `json:"updated"`
+	}
+	results := make([]normalized, 0, len(subjects))
+	var total int64
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		for _, subject := range subjects {
+			variants := append([]string{strings.ToLower(subject.Name)}, aliasesByName[strings.ToLower(subject.Name)]...)
+			result := tx.Model(&Attendance{}).
+				Where("LOWER(TRIM(subject)) IN ? AND subject <> ?", variants, subject.Name).
+				Where("student_id IN (?)", departmentStudentIDs(tx, subject.Dept)).
+				Update("subject", subject.Name)
+			if result.Error != nil {
+				return result.Error
+			}
+			results = append(results, normalized{SubjectID: subject.ID, Dept: subject.Dept, Name: subject.Name, Updated: result.RowsAffected})
+			total += result.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to normalize subjects"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Attendance subjects normalized",
+		"total_updated": total,
+		"subjects":      results,
+	})
+}
+
+// departmentStudentIDs is a subquery selecting the IDs of the department's students
+func departmentStudentIDs(tx *gorm.DB, dept string) *gorm.DB {
+	return tx.Model(&users.User{}).Select("id").Where("role = ? AND dept = ?", users.RoleStudent, dept)
+}
@@ -0,0 +1,140 @@
+package attendance
+
+import (
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSubject(t *testing.T) {
+	setupTestDB(t)
+
+	// No subjects yet: free text is accepted
+	subject, err := resolveSubject("CS", "anything")
+	assert.NoError(t, err)
+	assert.Nil(t, subject)
+
+	db.DB.Create(&Subject{Name: "Data Structures", Dept: "CS", Periods: []string{"P1", "P2"}})
+
+	subject, err = resolveSubject("CS", "  data structures ")
+	assert.NoError(t, err)
+	if assert.NotNil(t, subject) {
+		assert.Equal(t, "Data Structures", subject.Name)
+		assert.True(t, subject.AllowsPeriod("p1"))
+		assert.False(t, subject.AllowsPeriod("P3"))
+	}
+
+	_, err = resolveSubject("CS", "Algorithms")
+	assert.ErrorIs(t, err, ErrUnknownSubject)
+
+	// Other departments are unaffected
+	subject, err = resolveSubject("EE", "Algorithms")
+	assert.NoError(t, err)
+	assert.Nil(t, subject)
+}
+
+func TestNormalizeSubjects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	cs := seedDepartment(t, "CS", 1, 0)[0]
+	ee := seedDepartment(t, "EE", 1, 0)[0]
+	db.DB.Create(&Subject{Name: "Mathematics", Dept: "CS"})
+
+	base := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	for i, record := range []struct {
+		student users.User
+		subject string
+	}{
+		{cs, "mathematics "},
+		{cs, "Maths"},
+		{cs, "Physics"},
+		{ee, "maths"}, // EE has no Mathematics subject
+	} {
+		subject := record.subject
+		db.DB.Create(&Attendance{StudentID: record.student.ID, Date: base.AddDate(0, 0, i), Status: StatusPresent, Present: true, MarkedBy: 1, Subject: &subject})
+	}
+
+	router := gin.New()
+	router.POST("/subjects/normalize", NormalizeSubjects)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/subjects/normalize", strings.NewReader(`{"aliases": {"Maths": "Mathematics"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, float64(2), response["total_updated"])
+
+	var subjects []string
+	db.DB.Model(&Attendance{}).Order("date ASC").Pluck("subject", &subjects)
+	assert.Equal(t, []string{"Mathematics", "Mathematics", "Physics", "maths"}, subjects)
+}
+
+func TestSubjectTeacherMustBeFaculty(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	student := seedDepartment(t, "CS", 1, 0)[0]
+	create := func(name, role string) users.User {
+		user := users.User{Name: name, Email: name + "@example.com", Password: "hashed", Role: role, Dept: "CS", IsActive: true}
+		if err := db.DB.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+	faculty := create("faculty", users.RoleFaculty)
+	colleague := create("colleague", users.RoleFaculty)
+	admin := create("admin", users.RoleAdmin)
+	former := create("former", users.RoleFaculty)
+	db.DB.Delete(&former)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", faculty.ID)
+		c.Set("role", faculty.Role)
+	})
+	router.POST("/attendance/subjects", CreateSubject)
+	router.PUT("/attendance/subjects/:id", UpdateSubject)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+	teacher := func(id uint) string { return strconv.Itoa(int(id)) }
+
+	for _, id := range []uint{student.ID, admin.ID, former.ID, 9999} {
+		w := send(http.MethodPost, "/attendance/subjects", `{"name":"Algorithms","teacher_id":`+teacher(id)+`}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "teacher_id must be an existing faculty member")
+	}
+
+	// Faculty teach what they add unless they name a colleague
+	w := send(http.MethodPost, "/attendance/subjects", `{"name":"Algorithms"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	w = send(http.MethodPost, "/attendance/subjects", `{"name":"Databases","teacher_id":`+teacher(colleague.ID)+`}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var subjects []Subject
+	db.DB.Order("name ASC").Find(&subjects)
+	if assert.Len(t, subjects, 2) {
+		assert.Equal(t, faculty.ID, *subjects[0].TeacherID)
+		assert.Equal(t, colleague.ID, *subjects[1].TeacherID)
+	}
+
+	path := "/attendance/subjects/" + strconv.Itoa(int(subjects[0].ID))
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPut, path, `{"name":"Algorithms","teacher_id":`+teacher(student.ID)+`}`).Code)
+	assert.Equal(t, http.StatusOK, send(http.MethodPut, path, `{"name":"Algorithms","teacher_id":`+teacher(colleague.ID)+`}`).Code)
+	var updated Subject
+	db.DB.First(&updated, subjects[0].ID)
+	assert.Equal(t, colleague.ID, *updated.TeacherID)
+}
@@ -0,0 +1,217 @@
+package attendance
+
+import (
+	"campus-backend/internal/core"
+	"campus-backend/internal/settings"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// summaryBatchSize is how many students one recompute query covers
+const summaryBatchSize = 500
+
+// AttendanceSummary caches a student's attendance counts, lifetime and for each academic term, so GetStats
+// does not scan their records. It is refreshed whenever the student's attendance changes and rebuilt for
+// everyone by the scheduler.
+type AttendanceSummary struct {
+	StudentID      uint       `json:"student_id" gorm:"primaryKey;autoIncrement:false"`
+	TermID         uint       `json:"term_id" gorm:"primaryKey;autoIncrement:false"` // 0 for lifetime counts
+	TotalDays      int        `json:"total_days"`
+	PresentDays    int        `json:"present_days"`
+	LateDays       int        `json:"late_days"`
+	ExcusedDays    int        `json:"excused_days"`
+	LastAttendance *time.Time `json:"last_attendance,omitempty"`
+	ComputedAt     time.Time  `json:"computed_at" gorm:"not null"`
+}
+
+// summaryScope is the span of attendance one summary covers: a term's dates, or everything for term 0
+type summaryScope struct {
+	termID     uint
+	start, end *time.Time
+}
+
+// termScope is the summary scope of an academic term
+func termScope(term *settings.Term) summaryScope {
+	start, end := term.StartDate, term.EndDate
+	return summaryScope{termID: term.ID, start: &start, end: &end}
+}
+
+// summaryScopes lists the summaries kept for every student: lifetime, then each term
+func summaryScopes() ([]summaryScope, error) {
+	var terms []settings.Term
+	if err := db.DB.Select("id", "start_date", "end_date").Order("start_date ASC").Find(&terms).Error; err != nil {
+		return nil, err
+	}
+	scopes := []summaryScope{{}}
+	for i := range terms {
+		scopes = append(scopes, termScope(&terms[i]))
+	}
+	return scopes, nil
+}
+
+// MigrateSummaryKey drops a summary table from before summaries were kept per term. Its primary key
+// cannot be altered in place, and the cache is rebuilt from the attendance records as it is read.
+func MigrateSummaryKey(tx *gorm.DB) error {
+	migrator := tx.Migrator()
+	if !migrator.HasTable(&AttendanceSummary{}) || migrator.HasColumn(&AttendanceSummary{}, "TermID") {
+		return nil
+	}
+	return migrator.DropTable(&AttendanceSummary{})
+}
+
+// fresh reports whether the summary is recent enough to serve
+func (s *AttendanceSummary) fresh() bool {
+	maxAge := time.Duration(core.GetConfig().Attendance.SummaryMaxAgeMinutes) * time.Minute
+	return maxAge > 0 && time.Since(s.ComputedAt) <= maxAge
+}
+
+// stats expands the cached counts into the GetStats response
+func (s *AttendanceSummary) stats(student *users.User) AttendanceStats {
+	entry := AttendanceStats{
+		StudentID:      student.ID,
+		StudentName:    student.Name,
+		TotalDays:      s.TotalDays,
+		PresentDays:    s.PresentDays,
+		AbsentDays:     s.TotalDays - s.PresentDays,
+		LateDays:       s.LateDays,
+		ExcusedDays:    s.ExcusedDays,
+		LastAttendance: s.LastAttendance,
+	}
+	if entry.TotalDays > 0 {
+		entry.AttendancePercentage = float64(entry.PresentDays) / float64(entry.TotalDays) * 100
+	}
+	return entry
+}
+
+// saveSummaries stores freshly computed stats for the scope's term, replacing any cached ones
+func saveSummaries(termID uint, stats []AttendanceStats) error {
+	if len(stats) == 0 {
+		return nil
+	}
+	now := time.Now()
+	summaries := make([]AttendanceSummary, 0, len(stats))
+	for _, entry := range stats {
+		summaries = append(summaries, AttendanceSummary{
+			StudentID:      entry.StudentID,
+			TermID:         termID,
+			TotalDays:      entry.TotalDays,
+			PresentDays:    entry.PresentDays,
+			LateDays:       entry.LateDays,
+			ExcusedDays:    entry.ExcusedDays,
+			LastAttendance: entry.LastAttendance,
+			ComputedAt:     now,
+		})
+	}
+	return db.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&summaries).Error
+}
+
+// RefreshSummaries recomputes the cached lifetime and per-term stats of the given students
+func RefreshSummaries(studentIDs []uint) error {
+	scopes, err := summaryScopes()
+	if err != nil {
+		return err
+	}
+	students := make([]users.User, 0, len(studentIDs))
+	for _, id := range studentIDs {
+		students = append(students, users.User{Model: gorm.Model{ID: id}})
+	}
+	for start := 0; start < len(students); start += summaryBatchSize {
+		batch := students[start:min(start+summaryBatchSize, len(students))]
+		for _, scope := range scopes {
+			stats, err := studentStats(batch, scope.start, scope.end)
+			if err != nil {
+				return err
+			}
+			if err := saveSummaries(scope.termID, stats); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// refreshSummary is RefreshSummaries for one student after a change to their attendance. A failure only
+// leaves the old summary in place until it goes stale, so it is logged rather than failing the request.
+func refreshSummary(studentID uint) {
+	if err := RefreshSummaries([]uint{studentID}); err != nil {
+		log.Printf("Failed to refresh attendance summary for student %d: %v", studentID, err)
+	}
+}
+
+// RecomputeSummaries rebuilds the cached stats of every student and returns how many were written
+func RecomputeSummaries() (int, error) {
+	var ids []uint
+	if err := db.DB.Model(&users.User{}).Where("role = ?", users.RoleStudent).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if err := RefreshSummaries(ids); err != nil {
+		return 0, err
+	}
+	log.Printf("Attendance: recomputed summaries for %d students", len(ids))
+	return len(ids), nil
+}
+
+// RecomputeAllSummaries is the scheduled form of RecomputeSummaries
+func RecomputeAllSummaries() error {
+	_, err := RecomputeSummaries()
+	return err
+}
+
+// lifetimeStats serves a student's lifetime stats from the cache
+func lifetimeStats(student *users.User) (AttendanceStats, error) {
+	return cachedStats(student, summaryScope{})
+}
+
+// cachedStats serves a student's stats for the scope from the cache, computing and caching them live when
+// the summary is missing or stale
+func cachedStats(student *users.User, scope summaryScope) (AttendanceStats, error) {
+	var summary AttendanceSummary
+	if err := db.DB.Where("student_id = ? AND term_id = ?", student.ID, scope.termID).Limit(1).Find(&summary).Error; err != nil {
+		return AttendanceStats{}, err
+	}
+	if summary.StudentID != 0 && summary.fresh() {
+		return summary.stats(student), nil
+	}
+
+	stats, err := studentStats([]users.User{*student}, scope.start, scope.end)
+	if err != nil {
+		return AttendanceStats{}, err
+	}
+	if err := saveSummaries(scope.termID, stats); err != nil {
+		log.Printf("Failed to cache attendance summary for student %d: %v", student.ID, err)
+	}
+	return stats[0], nil
+}
+
+// RecomputeSummariesHandler godoc
+// @Summary Rebuild the attendance stats cache
+// @Description Admin recomputes the cached lifetime and per-term attendance stats of every student from their records, e.g. after a bulk correction. The scheduler does the same every ATTENDANCE_SUMMARY_RECOMPUTE_INTERVAL_MINUTES.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Number of students recomputed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/attendance/recompute [post]
+func RecomputeSummariesHandler(c *gin.Context) {
+	started := time.Now()
+	students, err := RecomputeSummaries()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recompute attendance summaries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Attendance summaries recomputed",
+		"students":    students,
+		"duration_ms": time.Since(started).Milliseconds(),
+	})
+}
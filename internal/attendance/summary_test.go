@@ -0,0 +1,216 @@
+package attendance
+
+import (
+	"bytes"
+	"campus-backend/internal/core"
+	"campus-backend/internal/settings"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSummariesMatchLiveStats(t *testing.T) {
+	setupTestDB(t)
+	defer func() { core.AppConfig = nil }()
+	core.LoadConfig()
+	students := seedDepartment(t, "CS", 6, 12)
+
+	recomputed, err := RecomputeSummaries()
+	assert.NoError(t, err)
+	assert.Equal(t, len(students), recomputed)
+
+	live, err := studentStats(students, nil, nil)
+	assert.NoError(t, err)
+	for i, student := range students {
+		var summary AttendanceSummary
+		assert.NoError(t, db.DB.First(&summary, "student_id = ?", student.ID).Error)
+		cached := summary.stats(&student)
+		assert.Equal(t, live[i].TotalDays, cached.TotalDays)
+		assert.Equal(t, live[i].PresentDays, cached.PresentDays)
+		assert.Equal(t, live[i].AbsentDays, cached.AbsentDays)
+		assert.Equal(t, live[i].LateDays, cached.LateDays)
+		assert.Equal(t, live[i].ExcusedDays, cached.ExcusedDays)
+		assert.InDelta(t, live[i].AttendancePercentage, cached.AttendancePercentage, 0.001)
+		if assert.NotNil(t, cached.LastAttendance) {
+			assert.True(t, live[i].LastAttendance.Equal(*cached.LastAttendance))
+		}
+	}
+}
+
+func TestTermSummaries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	defer func() { core.AppConfig = nil }()
+	core.LoadConfig()
+	students := seedDepartment(t, "CS", 3, 12)
+	start, end := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 9, 5, 0, 0, 0, 0, time.UTC)
+	term := settings.Term{Name: "Autumn", StartDate: start, EndDate: end}
+	db.DB.Create(&term)
+
+	// Every student gets a lifetime and a term summary
+	_, err := RecomputeSummaries()
+	assert.NoError(t, err)
+	var count int64
+	db.DB.Model(&AttendanceSummary{}).Count(&count)
+	assert.Equal(t, int64(2*len(students)), count)
+
+	live, err := studentStats(students, &start, &end)
+	assert.NoError(t, err)
+	for i, student := range students {
+		var summary AttendanceSummary
+		assert.NoError(t, db.DB.First(&summary, "student_id = ? AND term_id = ?", student.ID, term.ID).Error)
+		assert.Equal(t, 5, summary.TotalDays)
+		assert.Equal(t, live[i].PresentDays, summary.PresentDays)
+		assert.Equal(t, live[i].LateDays, summary.LateDays)
+	}
+
+	router := gin.New()
+	router.GET("/attendance/stats", func(c *gin.Context) {
+		c.Set("userID", uint(99))
+		c.Set("role", users.RoleAdmin)
+	}, GetStats)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/attendance/stats?student_id="+strconv.FormatUint(uint64(students[0].ID), 10)+query, nil))
+		return w
+	}
+
+	// The term summary is served like the lifetime one
+	db.DB.Model(&AttendanceSummary{}).Where("student_id = ? AND term_id = ?", students[0].ID, term.ID).Update("total_days", 4)
+	w := get("&term_id=" + strconv.FormatUint(uint64(term.ID), 10))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var stats AttendanceStats
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	assert.Equal(t, 4, stats.TotalDays)
+
+	// Without a summary the term is computed live and cached
+	db.DB.Where("term_id = ?", term.ID).Delete(&AttendanceSummary{})
+	w = get("&term_id=" + strconv.FormatUint(uint64(term.ID), 10))
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	assert.Equal(t, 5, stats.TotalDays)
+	db.DB.Model(&AttendanceSummary{}).Where("term_id = ?", term.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	// Lifetime stats are unaffected
+	json.Unmarshal(get("").Body.Bytes(), &stats)
+	assert.Equal(t, 12, stats.TotalDays)
+
+	assert.Equal(t, http.StatusBadRequest, get("&term_id=abc").Code)
+	assert.Equal(t, http.StatusBadRequest, get("&term_id=0").Code)
+	assert.Equal(t, http.StatusBadRequest, get("&term_id=1&start_date=2026-09-01").Code)
+	assert.Equal(t, http.StatusNotFound, get("&term_id=999").Code)
+}
+
+func TestMigrateSummaryKey(t *testing.T) {
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+
+	// Nothing to do before the table exists
+	assert.NoError(t, MigrateSummaryKey(testDB))
+
+	// A summary table keyed by student alone is dropped
+	assert.NoError(t, testDB.Exec("CREATE TABLE attendance_summaries (student_id integer PRIMARY KEY, total_days integer)").Error)
+	assert.NoError(t, testDB.Exec("INSERT INTO attendance_summaries (student_id, total_days) VALUES (1, 3)").Error)
+	assert.NoError(t, MigrateSummaryKey(testDB))
+	assert.False(t, testDB.Migrator().HasTable(&AttendanceSummary{}))
+
+	// The current table is kept
+	assert.NoError(t, testDB.AutoMigrate(&AttendanceSummary{}))
+	testDB.Create(&AttendanceSummary{StudentID: 1, TotalDays: 3, ComputedAt: time.Now()})
+	testDB.Create(&AttendanceSummary{StudentID: 1, TermID: 2, TotalDays: 1, ComputedAt: time.Now()})
+	assert.NoError(t, MigrateSummaryKey(testDB))
+	var count int64
+	testDB.Model(&AttendanceSummary{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestGetStatsUsesSummary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	defer func() { core.AppConfig = nil }()
+	core.LoadConfig()
+	student := seedDepartment(t, "CS", 1, 5)[0]
+	studentID := strconv.FormatUint(uint64(student.ID), 10)
+
+	router := gin.New()
+	setUser := func(c *gin.Context) {
+		c.Set("userID", uint(99))
+		c.Set("role", users.RoleAdmin)
+	}
+	router.GET("/attendance/stats", setUser, GetStats)
+	router.POST("/attendance/mark", setUser, MarkAttendance)
+	stats := func(query string) AttendanceStats {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/attendance/stats?student_id="+studentID+query, nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp AttendanceStats
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	// The first read caches the live result
+	assert.Equal(t, 5, stats("").TotalDays)
+	var summary AttendanceSummary
+	assert.NoError(t, db.DB.First(&summary, "student_id = ?", student.ID).Error)
+	assert.Equal(t, 5, summary.TotalDays)
+
+	// A fresh summary is served even when records change behind its back...
+	db.DB.Create(&Attendance{StudentID: student.ID, Date: time.Date(2026, 9, 20, 0, 0, 0, 0, time.UTC), Status: StatusAbsent, MarkedBy: 1})
+	assert.Equal(t, 5, stats("").TotalDays)
+	// ...while date ranges always read the records
+	assert.Equal(t, 6, stats("&start_date=2026-09-01&end_date=2026-09-30").TotalDays)
+
+	// Marking refreshes it
+	w := httptest.NewRecorder()
+	body := `{"student_id":` + studentID + `,"date":"` + timeutil.Today().Format(time.RFC3339) + `","status":"present"}`
+	req, _ := http.NewRequest(http.MethodPost, "/attendance/mark", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 7, stats("").TotalDays)
+
+	// A stale summary falls back to the records
+	db.DB.Model(&AttendanceSummary{}).Where("student_id = ?", student.ID).Updates(map[string]interface{}{"total_days": 1, "computed_at": time.Now().Add(-48 * time.Hour)})
+	assert.Equal(t, 7, stats("").TotalDays)
+
+	// With no max age every read is live
+	db.DB.Model(&AttendanceSummary{}).Where("student_id = ?", student.ID).Update("total_days", 1)
+	core.AppConfig.Attendance.SummaryMaxAgeMinutes = 0
+	assert.Equal(t, 7, stats("").TotalDays)
+}
+
+func TestRecomputeSummariesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	students := seedDepartment(t, "CS", 3, 4)
+	db.DB.Create(&AttendanceSummary{StudentID: students[0].ID, TotalDays: 99, ComputedAt: time.Now()})
+
+	router := gin.New()
+	router.POST("/admin/attendance/recompute", RecomputeSummariesHandler)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/attendance/recompute", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, float64(3), resp["students"])
+
+	var summary AttendanceSummary
+	db.DB.First(&summary, "student_id = ?", students[0].ID)
+	assert.Equal(t, 4, summary.TotalDays)
+}
@@ -0,0 +1,130 @@
+package attendance
+
+import (
+	"campus-backend/internal/auth"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrendPoint is a student's attendance within one week or month
+type TrendPoint struct {
+	Period               string  `json:"period"` // YYYY-MM-DD of the week's Monday, or YYYY-MM
+	TotalDays            int     `json:"total_days"`
+	PresentDays          int     `json:"present_days"`
+	AttendancePercentage float64 `json:"attendance_percentage"`
+}
+
+// GetAttendanceTrend godoc
+// @Summary Get a student's attendance trend
+// @Description Attendance percentage per week or month, oldest first, for charting. Students get their own; faculty see their department's students, wardens their hostel's, admins anyone. Periods with no records are omitted.
+// @Tags Attendance
+// @Produce json
+// @Security BearerAuth
+// @Param student_id query int false "Student ID (required for non-students)"
+// @Param granularity query string false "week or month" default(month)
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{} "Attendance trend"
+// @Failure 400 {object} map[string]interface{} "Invalid parameters"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Student is outside your scope"
+// @Failure 404 {object} map[string]interface{} "Student not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /attendance/trend [get]
+func GetAttendanceTrend(c *gin.Context) {
+	granularity := c.DefaultQuery("granularity", db.PeriodMonth)
+	if granularity != db.PeriodWeek && granularity != db.PeriodMonth {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "granularity must be week or month"})
+		return
+	}
+
+	studentID, ok := resolveStudentID(c)
+	if !ok {
+		return
+	}
+	start, end, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	var student users.User
+	if err := db.DB.Where("id = ? AND role = ?", studentID, users.RoleStudent).First(&student).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return
+	}
+	if !studentInScope(c, &student) {
+		return
+	}
+
+	bucket, err := db.DateBucket(db.DB, granularity, "date")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate attendance trend"})
+		return
+	}
+
+	var rows []struct {
+		Period      string
+		TotalDays   int
+		PresentDays int
+	}
+	err = withDateRange(db.DB.Model(&Attendance{}), start, end).
+		Select(bucket+" AS period, COUNT(*) AS total_days, COUNT(CASE WHEN present THEN 1 END) AS present_days").
+		Where("student_id = ?", student.ID).
+		Group(bucket).
+		Order("period ASC").
+		Scan(&rows).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate attendance trend"})
+		return
+	}
+
+	trend := make([]TrendPoint, 0, len(rows))
+	for _, row := range rows {
+		point := TrendPoint{Period: row.Period, TotalDays: row.TotalDays, PresentDays: row.PresentDays}
+		if row.TotalDays > 0 {
+			point.AttendancePercentage = float64(row.PresentDays) / float64(row.TotalDays) * 100
+		}
+		trend = append(trend, point)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"student_id":   student.ID,
+		"student_name": student.Name,
+		"granularity":  granularity,
+		"trend":        trend,
+	})
+}
+
+// studentInScope limits faculty to their department's students and wardens to their hostel's.
+// Writes an error response and returns false when the student is out of reach.
+func studentInScope(c *gin.Context, student *users.User) bool {
+	role, _ := auth.CurrentRole(c)
+	if role != users.RoleFaculty && role != users.RoleWarden {
+		return true
+	}
+
+	viewer, err := auth.CurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
+		return false
+	}
+	if msg := scopeError(role, viewer, student); msg != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": msg})
+		return false
+	}
+	return true
+}
+
+// scopeError returns why the viewer may not see the student, or "" when they may
+func scopeError(role string, viewer, student *users.User) string {
+	if role == users.RoleFaculty && viewer.Dept != student.Dept {
+		return "You can only view students from your department"
+	}
+	if role == users.RoleWarden && (viewer.Hostel == nil || student.Hostel == nil || *viewer.Hostel != *student.Hostel) {
+		return "You can only view students from your hostel"
+	}
+	return ""
+}
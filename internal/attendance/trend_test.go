@@ -0,0 +1,86 @@
+package attendance
+
+import (
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAttendanceTrend(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	// Midnight in Kolkata is the previous evening in UTC, so buckets must use campus time
+	assert.NoError(t, timeutil.SetLocation("Asia/Kolkata"))
+	defer timeutil.SetLocation("UTC")
+
+	student := seedDepartment(t, "CS", 1, 0)[0]
+	for _, record := range []struct {
+		date    string
+		present bool
+	}{
+		{"2026-08-31", true},  // Monday
+		{"2026-09-06", false}, // Sunday of the same week
+		{"2026-09-07", true},
+		{"2026-10-01", true}, // Thursday, week of 2026-09-28
+	} {
+		date, _ := timeutil.ParseDate(record.date)
+		status := StatusAbsent
+		if record.present {
+			status = StatusPresent
+		}
+		db.DB.Create(&Attendance{StudentID: student.ID, Date: date, Status: status, Present: record.present, MarkedBy: 1})
+	}
+
+	csFaculty := users.User{Name: "CS Faculty", Email: "faculty@cs.example.com", Password: "hashed", Role: users.RoleFaculty, Dept: "CS", IsActive: true}
+	eeFaculty := users.User{Name: "EE Faculty", Email: "faculty@ee.example.com", Password: "hashed", Role: users.RoleFaculty, Dept: "EE", IsActive: true}
+	db.DB.Create(&csFaculty)
+	db.DB.Create(&eeFaculty)
+
+	get := func(viewer users.User, query string) (int, []TrendPoint) {
+		router := gin.New()
+		router.GET("/attendance/trend", func(c *gin.Context) {
+			c.Set("userID", viewer.ID)
+			c.Set("role", viewer.Role)
+			c.Next()
+		}, GetAttendanceTrend)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/attendance/trend"+query, nil)
+		router.ServeHTTP(w, req)
+
+		var resp struct {
+			Trend []TrendPoint `json:"trend"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Trend
+	}
+
+	code, trend := get(student, "?granularity=week")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []TrendPoint{
+		{Period: "2026-08-31", TotalDays: 2, PresentDays: 1, AttendancePercentage: 50},
+		{Period: "2026-09-07", TotalDays: 1, PresentDays: 1, AttendancePercentage: 100},
+		{Period: "2026-09-28", TotalDays: 1, PresentDays: 1, AttendancePercentage: 100},
+	}, trend)
+
+	code, trend = get(csFaculty, fmt.Sprintf("?student_id=%d", student.ID))
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []TrendPoint{
+		{Period: "2026-08", TotalDays: 1, PresentDays: 1, AttendancePercentage: 100},
+		{Period: "2026-09", TotalDays: 2, PresentDays: 1, AttendancePercentage: 50},
+		{Period: "2026-10", TotalDays: 1, PresentDays: 1, AttendancePercentage: 100},
+	}, trend)
+
+	code, _ = get(eeFaculty, fmt.Sprintf("?student_id=%d", student.ID))
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, _ = get(student, "?granularity=day")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,20 @@
+package audit
+
+import (
+	"gorm.io/gorm"
+)
+
+// SystemActorID is recorded as the actor for actions taken by background jobs
+const SystemActorID uint = 0
+
+// Record writes an audit log entry using the given database handle (which may be a transaction)
+func Record(tx *gorm.DB, actorID uint, action, entityType string, entityID uint, details string) error {
+	entry := AuditLog{
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Details:    details,
+	}
+	return tx.Create(&entry).Error
+}
@@ -0,0 +1,18 @@
+package audit
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditLog records a privileged or sensitive action taken by a user
+type AuditLog struct {
+	gorm.Model
+	ActorID    uint      `json:"actor_id" gorm:"not null;index"`
+	Action     string    `json:"action" gorm:"not null;index"` // e.g. leave_override, user_delete
+	EntityType string    `json:"entity_type" gorm:"not null"`  // e.g. leave_request, user
+	EntityID   uint      `json:"entity_id" gorm:"index"`
+	Details    string    `json:"details"`
+	CreatedAt  time.Time `json:"created_at"`
+}
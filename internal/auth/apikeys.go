@@ -0,0 +1,169 @@
+package auth
+
+import (
+	"campus-backend/internal/core"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/validation"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required" validate:"required,min=2,max=100"`
+	Scopes []string `json:"scopes" binding:"required" validate:"required,min=1,dive,oneof=attendance:mark attendance:read"`
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Admin creates a scoped API key for a service caller. The key is only returned once. Each key acts as its own service account, so records it writes (such as marked_by) name the key rather than the admin.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateAPIKeyRequest true "API key name and scopes"
+// @Success 201 {object} map[string]interface{} "API key created"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/api-keys [post]
+func CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+
+	if err := validation.ValidateStruct(req); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+
+	adminIDVal, _ := c.Get("userID")
+	adminID := adminIDVal.(uint)
+
+	rawKey, err := GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate API key"})
+		return
+	}
+
+	key := APIKey{
+		Name:      req.Name,
+		Prefix:    rawKey[:10],
+		KeyHash:   HashAPIKey(rawKey),
+		Scopes:    strings.Join(req.Scopes, ","),
+		CreatedBy: adminID,
+	}
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&key).Error; err != nil {
+			return err
+		}
+		return createServiceAccount(tx, &key)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created. Store it now, it will not be shown again.",
+		"key":     rawKey,
+		"api_key": key,
+	})
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description Admin lists all API keys (without the secret values)
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "List of API keys"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/api-keys [get]
+func ListAPIKeys(c *gin.Context) {
+	var keys []APIKey
+	if err := db.DB.Order("created_at DESC").Find(&keys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Admin revokes an API key so it can no longer authenticate, and deactivates its service account
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API key ID"
+// @Success 200 {object} map[string]interface{} "API key revoked"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "API key not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/api-keys/{id} [delete]
+func RevokeAPIKey(c *gin.Context) {
+	var key APIKey
+	if err := db.DB.First(&key, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	if key.RevokedAt == nil {
+		now := time.Now()
+		key.RevokedAt = &now
+		err := db.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Save(&key).Error; err != nil {
+				return err
+			}
+			if key.UserID == 0 {
+				return nil
+			}
+			return tx.Model(&users.User{}).Where("id = ?", key.UserID).Update("is_active", false).Error
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked", "api_key": key})
+}
+
+// createServiceAccount adds the user an API key acts as and links it to the key. Its password
+// is random and never shown, so the account can only be used through the key.
+func createServiceAccount(tx *gorm.DB, key *APIKey) error {
+	password, err := GenerateRandomPassword(32)
+	if err != nil {
+		return err
+	}
+	hashed, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	account := users.User{
+		Name:     "API key: " + key.Name,
+		Email:    fmt.Sprintf("api-key-%d@service.invalid", key.ID),
+		Password: hashed,
+		Role:     users.RoleService,
+		Dept:     "Service",
+		IsActive: true,
+	}
+	if err := tx.Create(&account).Error; err != nil {
+		return err
+	}
+	key.UserID = account.ID
+	return tx.Model(key).UpdateColumn("user_id", account.ID).Error
+}
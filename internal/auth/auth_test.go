@@ -1,18 +1,32 @@
 package auth
 
 import (
+	"bytes"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/internal/notifications"
 	"campus-backend/internal/users"
 	"campus-backend/pkg/db"
+	"campus-backend/pkg/validation"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/go-playground/validator/v10"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-var validate = validator.New()
-
 func setupTestDB() *gorm.DB {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
@@ -34,6 +48,23 @@ func TestHashPassword(t *testing.T) {
 	assert.Len(t, hashed, 60) // bcrypt hash length
 }
 
+func TestHashPasswordUsesConfiguredCost(t *testing.T) {
+	t.Setenv("BCRYPT_COST", "5")
+	core.LoadConfig()
+	defer func() { core.AppConfig = nil }()
+
+	hashed, err := HashPassword("testpassword123")
+	assert.NoError(t, err)
+
+	cost, err := bcrypt.Cost([]byte(hashed))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, cost)
+
+	// Out-of-range costs fall back to the default
+	t.Setenv("BCRYPT_COST", "99")
+	assert.Equal(t, 12, core.LoadConfig().Auth.BcryptCost)
+}
+
 func TestCheckPasswordHash(t *testing.T) {
 	password := "testpassword123"
 	hashed, _ := HashPassword(password)
@@ -53,20 +84,55 @@ func TestGenerateJWT(t *testing.T) {
 	
 	assert.NoError(t, err)
 	assert.NotEmpty(t, token)
-	assert.Len(t, token, 150) // Approximate JWT length
+	assert.Len(t, strings.Split(token, "."), 3) // header.payload.signature
+
+	// Token should carry the email and role claims
+	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+	assert.NoError(t, err)
+	claims := parsed.Claims.(jwt.MapClaims)
+	assert.Equal(t, email, claims["email"])
+	assert.Equal(t, role, claims["role"])
+}
+
+func TestGenerateJWTPerRoleExpiry(t *testing.T) {
+	core.LoadConfig()
+	defer func() { core.AppConfig = nil }()
+
+	cases := map[string]time.Duration{
+		users.RoleAdmin:   8 * time.Hour,
+		users.RoleStudent: 72 * time.Hour,
+		users.RoleFaculty: 24 * time.Hour, // no override, uses JWT_EXPIRY_HOURS
+	}
+
+	for role, want := range cases {
+		token, err := GenerateJWT(role+"@example.com", role)
+		assert.NoError(t, err)
+
+		parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+			return []byte(os.Getenv("JWT_SECRET")), nil
+		})
+		assert.NoError(t, err)
+		exp, err := parsed.Claims.GetExpirationTime()
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(want), exp.Time, time.Minute, role)
+	}
 }
 
 func TestValidateStruct(t *testing.T) {
 	// Test valid struct
+	studentID := "CS2024001"
 	validReq := RegisterRequest{
-		Name:     "John Doe",
-		Email:    "john@example.com",
-		Password: "password123",
-		Role:     "student",
-		Dept:     "Computer Science",
+		Name:      "John Doe",
+		Email:     "john@example.com",
+		Password:  "Password123",
+		Role:      "student",
+		Dept:      "Computer Science",
+		StudentID: &studentID,
 	}
 	
-	err := validate.ValidateStruct(validReq)
+	err := validation.ValidateStruct(validReq)
 	assert.NoError(t, err)
 	
 	// Test invalid struct
@@ -78,10 +144,213 @@ func TestValidateStruct(t *testing.T) {
 		Dept:     "", // Required field missing
 	}
 	
-	err = validate.ValidateStruct(invalidReq)
+	err = validation.ValidateStruct(invalidReq)
 	assert.Error(t, err)
 }
 
+func TestRoleSpecificFieldsRequired(t *testing.T) {
+	// Wardens need a hostel
+	warden := RegisterRequest{
+		Name:     "Hostel Warden",
+		Email:    "warden@example.com",
+		Password: "Password123",
+		Role:     "warden",
+		Dept:     "Administration",
+	}
+	errors := validation.FormatValidationErrors(validation.ValidateStruct(warden))
+	assert.Equal(t, "Hostel is required when Role is warden", errors["Hostel"])
+
+	// Blank values are normalized to nil before validation
+	blank := "   "
+	warden.Hostel = validation.TrimOptional(&blank)
+	errors = validation.FormatValidationErrors(validation.ValidateStruct(warden))
+	assert.Contains(t, errors, "Hostel")
+
+	hostel := "Block A"
+	warden.Hostel = &hostel
+	assert.NoError(t, validation.ValidateStruct(warden))
+
+	// Students need a student ID
+	student := RegisterRequest{
+		Name:     "New Student",
+		Email:    "student@example.com",
+		Password: "Password123",
+		Role:     "student",
+		Dept:     "Computer Science",
+	}
+	errors = validation.FormatValidationErrors(validation.ValidateStruct(student))
+	assert.Equal(t, "StudentID is required when Role is student", errors["StudentID"])
+
+	// Other roles need neither
+	student.Role = "faculty"
+	assert.NoError(t, validation.ValidateStruct(student))
+}
+
+func TestPhoneValidation(t *testing.T) {
+	defer validation.SetPhonePattern("")
+	phone := func(value string) map[string]string {
+		req := RegisterRequest{Name: "Student", Email: "student@example.com", Password: "Password123", Role: "faculty", Dept: "CS", Phone: &value}
+		return validation.FormatValidationErrors(validation.ValidateStruct(req))
+	}
+
+	assert.Empty(t, phone("+919876543210"))
+	assert.Contains(t, phone("98765 43210")["Phone"], "international format")
+	assert.NotEmpty(t, phone("+0123456789"))
+	assert.NotEmpty(t, phone("not a phone"))
+
+	// Optional: omitted numbers are fine
+	assert.NoError(t, validation.ValidateStruct(RegisterRequest{Name: "Faculty", Email: "f@example.com", Password: "Password123", Role: "faculty", Dept: "CS"}))
+
+	assert.NoError(t, validation.SetPhonePattern(`^[6-9][0-9]{9}$`))
+	assert.Empty(t, phone("9876543210"))
+	assert.Equal(t, "Phone must be a valid phone number", phone("+919876543210")["Phone"])
+	assert.Error(t, validation.SetPhonePattern("("))
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	defer func() { core.AppConfig = nil }()
+	defer validation.SetPasswordPolicySource(nil)
+	t.Setenv("PASSWORD_REQUIRE_SYMBOL", "true")
+	core.LoadConfig()
+	validation.SetPasswordPolicySource(PasswordPolicy)
+
+	password := func(value string) string {
+		req := RegisterRequest{Name: "Faculty", Email: "f@example.com", Password: value, Role: "faculty", Dept: "CS"}
+		return validation.FormatValidationErrors(validation.ValidateStruct(req))["Password"]
+	}
+
+	assert.Empty(t, password("Passw0rd!"))
+	assert.Equal(t, "Password must contain at least 8 characters", password("Pa5s!"))
+	assert.Equal(t, "Password must contain an uppercase letter", password("passw0rd!"))
+	assert.Equal(t, "Password must contain a lowercase letter", password("PASSW0RD!"))
+	assert.Equal(t, "Password must contain a digit", password("Password!"))
+	assert.Equal(t, "Password must contain a symbol", password("Passw0rd"))
+	assert.Equal(t, "Password must contain at least 8 characters, an uppercase letter, a digit, a symbol", password("pass"))
+
+	// Settings overrides replace the configuration, and the next check uses them
+	cfg := *core.GetConfig()
+	cfg.Auth.PasswordMinLength = 12
+	core.SetConfig(&cfg)
+	assert.Equal(t, "Password must contain at least 12 characters", password("Passw0rd!"))
+
+	generated, err := GenerateRandomPassword(12)
+	assert.NoError(t, err)
+	assert.Empty(t, password(generated))
+}
+
+func TestGenerateRandomPasswordIsUniform(t *testing.T) {
+	const charset = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789!#%+=?@"
+	counts := map[rune]int{}
+	total := 0
+	for i := 0; i < 2000; i++ {
+		generated, err := GenerateRandomPassword(64)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, generated, 64)
+		for _, char := range generated {
+			counts[char]++
+			total++
+		}
+	}
+
+	// A byte taken modulo the charset size would draw the first characters about a quarter more often
+	expected := float64(total) / float64(len(charset))
+	assert.Len(t, counts, len(charset))
+	for char, n := range counts {
+		assert.Contains(t, charset, string(char))
+		assert.InDelta(t, expected, float64(n), expected*0.12, "character %q", char)
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db.DB = setupTestDB()
+	db.DB.AutoMigrate(&audit.AuditLog{})
+	defer func() { core.AppConfig = nil }()
+	t.Setenv("BCRYPT_COST", strconv.Itoa(bcrypt.MinCost))
+	core.LoadConfig()
+
+	hashed, _ := HashPassword("OldPassw0rd")
+	user := users.User{Name: "Student", Email: "student@example.com", Password: hashed, Role: users.RoleStudent, Dept: "CS", IsActive: true}
+	db.DB.Create(&user)
+
+	r := gin.New()
+	r.POST("/auth/change-password", func(c *gin.Context) { c.Set("userID", user.ID) }, ChangePassword)
+	change := func(current, next string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"current_password": %q, "new_password": %q}`, current, next)
+		req := httptest.NewRequest(http.MethodPost, "/auth/change-password", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	weak := change("OldPassw0rd", "weakpass")
+	assert.Equal(t, http.StatusBadRequest, weak.Code)
+	assert.Contains(t, weak.Body.String(), "an uppercase letter")
+	assert.Equal(t, http.StatusBadRequest, change("OldPassw0rd", "OldPassw0rd").Code)
+	assert.Equal(t, http.StatusUnauthorized, change("WrongPassw0rd", "NewPassw0rd").Code)
+	assert.Equal(t, http.StatusOK, change("OldPassw0rd", "NewPassw0rd").Code)
+
+	var stored users.User
+	db.DB.First(&stored, user.ID)
+	assert.True(t, CheckPasswordHash("NewPassw0rd", stored.Password))
+	var count int64
+	db.DB.Model(&audit.AuditLog{}).Where("action = ? AND entity_id = ?", "password_change", user.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestLoginLite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db.DB = setupTestDB()
+	defer func() { core.AppConfig = nil }()
+	t.Setenv("BCRYPT_COST", strconv.Itoa(bcrypt.MinCost))
+	core.LoadConfig()
+
+	hashed, _ := HashPassword("Passw0rd")
+	user := users.User{Name: "Student", Email: "student@example.com", Password: hashed, Role: users.RoleStudent, Dept: "CS", IsActive: true}
+	db.DB.Create(&user)
+
+	r := gin.New()
+	r.POST("/auth/login", Login)
+	login := func(query string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login"+query, strings.NewReader(`{"email": "student@example.com", "password": "Passw0rd"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.NotContains(t, w.Body.String(), "password")
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	// The full response stays the default
+	code, resp := login("")
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotEmpty(t, resp["token"])
+	assert.Contains(t, resp, "user")
+
+	code, resp = login("?lite=true")
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotEmpty(t, resp["token"])
+	assert.NotContains(t, resp, "user")
+	assert.Equal(t, float64(user.ID), resp["user_id"])
+	assert.Equal(t, "student@example.com", resp["email"])
+	assert.Equal(t, users.RoleStudent, resp["role"])
+	assert.NotEmpty(t, resp["expires_at"])
+
+	code, _ = login("?lite=maybe")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// LOGIN_LITE flips the default, and lite=false still gets the user object
+	core.AppConfig.Auth.LoginLite = true
+	_, resp = login("")
+	assert.NotContains(t, resp, "user")
+	_, resp = login("?lite=false")
+	assert.Contains(t, resp, "user")
+}
+
 func TestFormatValidationErrors(t *testing.T) {
 	invalidReq := RegisterRequest{
 		Name:     "J",
@@ -91,8 +360,8 @@ func TestFormatValidationErrors(t *testing.T) {
 		Dept:     "",
 	}
 	
-	err := validate.ValidateStruct(invalidReq)
-	errors := validate.FormatValidationErrors(err)
+	err := validation.ValidateStruct(invalidReq)
+	errors := validation.FormatValidationErrors(err)
 	
 	assert.NotEmpty(t, errors)
 	assert.Contains(t, errors, "Name")
@@ -109,16 +378,18 @@ func TestUserRegistration(t *testing.T) {
 	db.DB = testDB
 	
 	// Test data
+	studentID := "CS2024002"
 	req := RegisterRequest{
-		Name:     "Test User",
-		Email:    "test@example.com",
-		Password: "password123",
-		Role:     "student",
-		Dept:     "Computer Science",
+		Name:      "Test User",
+		Email:     "test@example.com",
+		Password:  "Password123",
+		Role:      "student",
+		Dept:      "Computer Science",
+		StudentID: &studentID,
 	}
 	
 	// Validate request
-	err := validate.ValidateStruct(req)
+	err := validation.ValidateStruct(req)
 	assert.NoError(t, err)
 	
 	// Check if email already exists (should not exist)
@@ -154,3 +425,475 @@ func TestUserRegistration(t *testing.T) {
 	assert.Equal(t, req.Dept, createdUser.Dept)
 	assert.True(t, createdUser.IsActive)
 }
+
+func TestRegisterRollsBackOnFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db.DB = setupTestDB()
+	db.DB.AutoMigrate(&notifications.Notification{})
+	defer func() { core.AppConfig = nil }()
+	t.Setenv("BCRYPT_COST", strconv.Itoa(bcrypt.MinCost))
+	core.LoadConfig()
+
+	r := gin.New()
+	r.POST("/auth/register", Register)
+	register := func(email, studentID string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"name": "New Student", "email": %q, "password": "Passw0rd1", "role": "student", "dept": "CS", "student_id": %q}`, email, studentID)
+		req := httptest.NewRequest(http.MethodPost, "/auth/register", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// The welcome notification is written with the account
+	assert.Equal(t, http.StatusCreated, register("first@example.com", "CS2026001").Code)
+	assert.Equal(t, http.StatusConflict, register("other@example.com", "CS2026001").Code)
+	var welcomed int64
+	db.DB.Model(&notifications.Notification{}).Count(&welcomed)
+	assert.Equal(t, int64(1), welcomed)
+
+	// A failing notification takes the new account with it
+	db.DB.Callback().Create().Before("gorm:create").Register("test:fail_notification", func(tx *gorm.DB) {
+		if tx.Statement.Table == "notifications" {
+			tx.AddError(fmt.Errorf("notification store unavailable"))
+		}
+	})
+	w := register("second@example.com", "CS2026002")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "Failed to register user")
+
+	var count int64
+	db.DB.Unscoped().Model(&users.User{}).Where("email = ?", "second@example.com").Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestCurrentUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db.DB = setupTestDB()
+	user := users.User{Name: "Warden", Email: "warden@example.com", Password: "hashed", Role: users.RoleWarden, Dept: "ADMIN", IsActive: true}
+	db.DB.Create(&user)
+	token, _ := GenerateJWT(user.Email, user.Role)
+
+	// The middleware's user is reused, so a later lookup needs no query
+	var fromMiddleware *users.User
+	r := gin.New()
+	r.GET("/me", JWTAuthMiddleware(), func(c *gin.Context) {
+		db.DB.Delete(&users.User{}, user.ID)
+		fromMiddleware, _ = CurrentUser(c)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if assert.NotNil(t, fromMiddleware) {
+		assert.Equal(t, user.Email, fromMiddleware.Email)
+	}
+
+	// Without the middleware the user is loaded by userID
+	db.DB.Unscoped().Model(&users.User{}).Where("id = ?", user.ID).Update("deleted_at", nil)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("userID", user.ID)
+	loaded, err := CurrentUser(c)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, loaded.ID)
+
+	c, _ = gin.CreateTestContext(httptest.NewRecorder())
+	_, err = CurrentUser(c)
+	assert.Error(t, err)
+}
+
+func TestJWTAuthMiddlewareRejectsInactiveUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db.DB = setupTestDB()
+	user := users.User{Name: "Student", Email: "student@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", IsActive: true}
+	db.DB.Create(&user)
+	token, _ := GenerateJWT(user.Email, user.Role)
+
+	r := gin.New()
+	r.GET("/me", JWTAuthMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	get := func() int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get())
+	// A token issued before the account was deactivated stops working
+	db.DB.Model(&user).Update("is_active", false)
+	assert.Equal(t, http.StatusUnauthorized, get())
+}
+
+func TestJWTAuthMiddlewareRequiresRoleClaim(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db.DB = setupTestDB()
+	user := users.User{Name: "Student", Email: "student@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", IsActive: true}
+	db.DB.Create(&user)
+
+	r := gin.New()
+	r.GET("/me", JWTAuthMiddleware(), func(c *gin.Context) {
+		role, ok := CurrentRole(c)
+		assert.True(t, ok)
+		c.String(http.StatusOK, role)
+	})
+
+	sign := func(claims jwt.MapClaims) string {
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(os.Getenv("JWT_SECRET")))
+		return token
+	}
+
+	cases := []struct {
+		name       string
+		claims     jwt.MapClaims
+		wantStatus int
+	}{
+		{"valid", jwt.MapClaims{"email": user.Email, "role": user.Role}, http.StatusOK},
+		{"missing role", jwt.MapClaims{"email": user.Email}, http.StatusUnauthorized},
+		{"non-string role", jwt.MapClaims{"email": user.Email, "role": 7}, http.StatusUnauthorized},
+		{"missing email", jwt.MapClaims{"role": user.Role}, http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+sign(tc.claims))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		assert.Equal(t, tc.wantStatus, rec.Code, tc.name)
+		if tc.wantStatus == http.StatusOK {
+			assert.Equal(t, user.Role, rec.Body.String())
+		}
+	}
+}
+
+func TestImpersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db.DB = setupTestDB()
+	sqlDB, _ := db.DB.DB()
+	sqlDB.SetMaxOpenConns(1) // Keep the in-memory database on one connection
+	db.DB.AutoMigrate(&ImpersonationSession{}, &audit.AuditLog{})
+	defer func() { core.AppConfig = nil }()
+
+	admin := users.User{Name: "Admin", Email: "admin@example.com", Password: "hashed", Role: users.RoleAdmin, Dept: "Administration", IsActive: true}
+	student := users.User{Name: "Student", Email: "student@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", IsActive: true}
+	db.DB.Create(&admin)
+	db.DB.Create(&student)
+
+	r := gin.New()
+	r.POST("/admin/impersonate/:id", JWTAuthMiddleware(), RequireRole(users.RoleAdmin), DenyImpersonation(), StartImpersonation)
+	r.DELETE("/admin/impersonate", JWTAuthMiddleware(), EndImpersonation)
+	r.GET("/me", JWTAuthMiddleware(), func(c *gin.Context) {
+		adminID, _ := ImpersonatedBy(c)
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetUint("userID"), "impersonated_by": adminID})
+	})
+	r.POST("/sensitive", JWTAuthMiddleware(), DenyImpersonation(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	call := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	adminToken, _ := GenerateJWT(admin.Email, admin.Role)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/admin/impersonate/"+strconv.Itoa(int(admin.ID)), adminToken).Code)
+
+	rec := call(http.MethodPost, "/admin/impersonate/"+strconv.Itoa(int(student.ID)), adminToken)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var started struct {
+		Token string `json:"token"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &started))
+
+	rec = call(http.MethodGet, "/me", started.Token)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"user_id": %d, "impersonated_by": %d}`, student.ID, admin.ID), rec.Body.String())
+
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, "/sensitive", started.Token).Code)
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, "/sensitive", adminToken).Code)
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodDelete, "/admin/impersonate", adminToken).Code)
+
+	// Ending the session invalidates the token
+	assert.Equal(t, http.StatusOK, call(http.MethodDelete, "/admin/impersonate", started.Token).Code)
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/me", started.Token).Code)
+
+	var actions []string
+	db.DB.Model(&audit.AuditLog{}).Where("actor_id = ?", admin.ID).Order("id ASC").Pluck("action", &actions)
+	assert.Equal(t, []string{"impersonation_start", "impersonation_end"}, actions)
+}
+
+func TestIntrospectToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db.DB = setupTestDB()
+	db.DB.AutoMigrate(&ImpersonationSession{})
+	defer func() { core.AppConfig = nil }()
+
+	admin := users.User{Name: "Admin", Email: "admin@example.com", Password: "hashed", Role: users.RoleAdmin, Dept: "Administration", IsActive: true}
+	student := users.User{Name: "Student", Email: "student@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", IsActive: true}
+	db.DB.Create(&admin)
+	db.DB.Create(&student)
+
+	r := gin.New()
+	r.GET("/auth/introspect", JWTAuthMiddleware(), IntrospectToken)
+	introspect := func(token string) (int, TokenIntrospection) {
+		req := httptest.NewRequest(http.MethodGet, "/auth/introspect", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		var resp TokenIntrospection
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+
+	token, _ := GenerateJWT(student.Email, student.Role)
+	code, resp := introspect(token)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.Active)
+	assert.Equal(t, student.ID, resp.UserID)
+	assert.Equal(t, student.Email, resp.Email)
+	assert.Equal(t, users.RoleStudent, resp.Role)
+	if assert.NotNil(t, resp.ExpiresAt) {
+		assert.WithinDuration(t, time.Now().Add(core.GetConfig().JWT.ExpiryFor(users.RoleStudent)), *resp.ExpiresAt, time.Minute)
+	}
+	assert.Positive(t, resp.ExpiresInSeconds)
+	assert.Nil(t, resp.Impersonation)
+
+	expiresAt := time.Now().Add(10 * time.Minute)
+	session := ImpersonationSession{AdminID: admin.ID, TargetID: student.ID, ExpiresAt: expiresAt}
+	db.DB.Create(&session)
+	token, _ = GenerateImpersonationJWT(student.Email, student.Role, admin.ID, session.ID, expiresAt)
+	code, resp = introspect(token)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, &ImpersonationInfo{AdminID: admin.ID, SessionID: session.ID}, resp.Impersonation)
+
+	code, _ = introspect("not-a-token")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestValidationErrorsFollowLanguage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db.DB = setupTestDB()
+
+	r := gin.New()
+	r.POST("/register", Register)
+	body := `{"name": "Asha", "email": "not-an-email", "password": "secret1", "role": "student", "dept": "CS", "student_id": "CS2026001"}`
+	req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "hi-IN,hi;q=0.9,en;q=0.8")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var resp struct {
+		Details map[string]string `json:"details"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "Email एक मान्य ईमेल पता होना चाहिए", resp.Details["Email"])
+
+	// A saved preference wins over the header
+	user := users.User{Name: "Asha", Email: "asha@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", Language: "hi", IsActive: true}
+	db.DB.Create(&user)
+	token, _ := GenerateJWT(user.Email, user.Role)
+	var lang string
+	r.GET("/lang", JWTAuthMiddleware(), func(c *gin.Context) { lang = core.Language(c) })
+	req = httptest.NewRequest(http.MethodGet, "/lang", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept-Language", "en")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "hi", lang)
+}
+
+func TestGetMyPermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db.DB = setupTestDB()
+	hostel := "H1"
+	warden := users.User{Name: "Warden", Email: "warden@example.com", Password: "hashed", Role: users.RoleWarden, Dept: "ADMIN", Hostel: &hostel, IsActive: true}
+	db.DB.Create(&warden)
+
+	r := gin.New()
+	r.GET("/users/me/permissions", func(c *gin.Context) {
+		c.Set("userID", warden.ID)
+		c.Set("role", warden.Role)
+	}, GetMyPermissions)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/me/permissions", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var permissions Permissions
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &permissions))
+	assert.Equal(t, "hostel", permissions.Scope)
+	assert.Equal(t, "H1", *permissions.ScopeValue)
+	assert.True(t, permissions.Capabilities[CanApproveLeaves])
+	assert.True(t, permissions.Capabilities[CanApplyForOthers])
+	assert.False(t, permissions.Capabilities[CanMarkAttendance])
+	assert.False(t, permissions.Capabilities[CanViewAnalytics])
+
+	admin := PermissionsFor(&users.User{Role: users.RoleAdmin}, false)
+	assert.Equal(t, "all", admin.Scope)
+	assert.True(t, admin.Capabilities[CanMarkAttendance])
+
+	// Impersonating admins lose the actions DenyImpersonation refuses
+	student := PermissionsFor(&users.User{Role: users.RoleStudent}, true)
+	assert.Equal(t, "self", student.Scope)
+	assert.False(t, student.Capabilities[CanApplyLeave])
+	assert.True(t, PermissionsFor(&users.User{Role: users.RoleStudent}, false).Capabilities[CanApplyLeave])
+}
+
+func TestAPIKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db.DB = setupTestDB()
+	sqlDB, _ := db.DB.DB()
+	sqlDB.SetMaxOpenConns(1) // Keep the in-memory database on one connection
+	db.DB.AutoMigrate(&APIKey{})
+	t.Setenv("BCRYPT_COST", "4")
+	core.LoadConfig()
+	defer func() { core.AppConfig = nil }()
+
+	admin := users.User{Name: "Admin", Email: "admin@example.com", Password: "hashed", Role: users.RoleAdmin, Dept: "Administration", IsActive: true}
+	db.DB.Create(&admin)
+	adminToken, _ := GenerateJWT(admin.Email, admin.Role)
+
+	r := gin.New()
+	r.POST("/admin/api-keys", JWTAuthMiddleware(), RequireRole(users.RoleAdmin), CreateAPIKey)
+	r.GET("/admin/api-keys", JWTAuthMiddleware(), RequireRole(users.RoleAdmin), ListAPIKeys)
+	r.DELETE("/admin/api-keys/:id", JWTAuthMiddleware(), RequireRole(users.RoleAdmin), RevokeAPIKey)
+	whoami := func(c *gin.Context) {
+		user, _ := CurrentUser(c)
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetUint("userID"), "role": c.GetString("role"), "name": user.Name})
+	}
+	r.POST("/mark", JWTOrAPIKeyMiddleware(ScopeAttendanceMark), whoami)
+	r.GET("/read", APIKeyMiddleware(ScopeAttendanceRead), whoami)
+
+	call := func(method, path, body string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	create := func(body string) (string, APIKey) {
+		rec := call(http.MethodPost, "/admin/api-keys", body, "Authorization", "Bearer "+adminToken)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var resp struct {
+			Key    string `json:"key"`
+			APIKey APIKey `json:"api_key"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp.Key, resp.APIKey
+	}
+
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/admin/api-keys", `{"name": "Kiosk", "scopes": ["attendance:delete"]}`, "Authorization", "Bearer "+adminToken).Code)
+
+	kioskKey, kiosk := create(`{"name": "Kiosk", "scopes": ["attendance:mark"]}`)
+	lmsKey, _ := create(`{"name": "LMS", "scopes": ["attendance:read"]}`)
+	assert.NotZero(t, kiosk.UserID)
+	assert.NotEqual(t, admin.ID, kiosk.UserID)
+
+	// Only the hash is stored, and listing never returns the secret
+	var stored APIKey
+	db.DB.First(&stored, kiosk.ID)
+	assert.Equal(t, HashAPIKey(kioskKey), stored.KeyHash)
+	rec := call(http.MethodGet, "/admin/api-keys", "", "Authorization", "Bearer "+adminToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), kioskKey)
+
+	// The key acts as its own service account, not as the admin who made it
+	rec = call(http.MethodPost, "/mark", "", "X-API-Key", kioskKey)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"user_id": %d, "role": "service", "name": "API key: Kiosk"}`, kiosk.UserID), rec.Body.String())
+	db.DB.First(&stored, kiosk.ID)
+	assert.NotNil(t, stored.LastUsedAt)
+
+	// JWTs still work where either is accepted
+	rec = call(http.MethodPost, "/mark", "", "Authorization", "Bearer "+adminToken)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"role":"admin"`)
+
+	// Scopes are enforced per route
+	assert.Equal(t, http.StatusForbidden, call(http.MethodPost, "/mark", "", "X-API-Key", lmsKey).Code)
+	assert.Equal(t, http.StatusForbidden, call(http.MethodGet, "/read", "", "X-API-Key", kioskKey).Code)
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/read", "", "X-API-Key", lmsKey).Code)
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/read", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/read", "", "X-API-Key", "ck_unknown").Code)
+
+	// Revoking stops the key and deactivates its account
+	assert.Equal(t, http.StatusOK, call(http.MethodDelete, fmt.Sprintf("/admin/api-keys/%d", kiosk.ID), "", "Authorization", "Bearer "+adminToken).Code)
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, "/mark", "", "X-API-Key", kioskKey).Code)
+	var account users.User
+	db.DB.First(&account, kiosk.UserID)
+	assert.False(t, account.IsActive)
+	assert.Equal(t, http.StatusNotFound, call(http.MethodDelete, "/admin/api-keys/999", "", "Authorization", "Bearer "+adminToken).Code)
+
+	// Keys created before service accounts get one on first use
+	legacyKey, _ := GenerateAPIKey()
+	legacy := APIKey{Name: "Legacy", Prefix: legacyKey[:10], KeyHash: HashAPIKey(legacyKey), Scopes: ScopeAttendanceRead, CreatedBy: admin.ID}
+	db.DB.Create(&legacy)
+	rec = call(http.MethodGet, "/read", "", "X-API-Key", legacyKey)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	db.DB.First(&legacy, legacy.ID)
+	assert.NotZero(t, legacy.UserID)
+	assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"user_id":%d`, legacy.UserID))
+}
+
+func TestImportUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db.DB = setupTestDB()
+	t.Setenv("BCRYPT_COST", "4")
+	core.LoadConfig()
+	defer func() { core.AppConfig = nil }()
+	db.DB.Create(&users.User{Name: "Existing", Email: "existing@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", IsActive: true})
+
+	r := gin.New()
+	r.POST("/users/import", ImportUsers)
+	upload := func(csv string) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile("file", "users.csv")
+		part.Write([]byte(csv))
+		form.Close()
+		req := httptest.NewRequest(http.MethodPost, "/users/import", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := upload("name,email,role,dept,hostel,phone,student_id\n" +
+		"Asha Roy,asha@example.com,student,CS,,,CS2026001\n" +
+		"No ID,noid@example.com,student,CS\n" +
+		"Bad Email,not-an-email,faculty,EE\n" +
+		"Asha Again,asha@example.com,student,CS,,,CS2026002\n" +
+		"Existing,existing@example.com,student,CS,,,CS2026003\n")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Summary map[string]int    `json:"summary"`
+		Results []ImportRowResult `json:"results"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, map[string]int{ImportStatusCreated: 1, ImportStatusDuplicate: 2, ImportStatusInvalid: 2}, resp.Summary)
+	if assert.Len(t, resp.Results, 5) {
+		assert.Equal(t, 2, resp.Results[0].Row) // Rows count the header
+		assert.Equal(t, ImportStatusCreated, resp.Results[0].Status)
+		// Row errors name the CSV column, not the Go field
+		assert.Contains(t, resp.Results[1].Errors, "student_id")
+		assert.NotContains(t, resp.Results[1].Errors, "StudentID")
+		assert.Contains(t, resp.Results[2].Errors, "email")
+		assert.Equal(t, ImportStatusDuplicate, resp.Results[3].Status)
+		assert.Equal(t, ImportStatusDuplicate, resp.Results[4].Status)
+	}
+
+	var created users.User
+	if assert.NoError(t, db.DB.Where("email = ?", "asha@example.com").First(&created).Error) {
+		assert.Equal(t, "CS2026001", *created.StudentID)
+		assert.True(t, created.IsActive)
+		assert.Nil(t, created.Hostel)
+		assert.Len(t, created.Password, 60) // Stored hashed
+	}
+
+	w = upload("name,email\n\"unterminated,x\n")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Malformed CSV")
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys set by the auth middleware
+const (
+	currentUserKey          = "currentUser"    // The authenticated *users.User
+	impersonatedByKey       = "impersonatedBy" // ID of the admin behind an impersonation token
+	impersonationSessionKey = "impersonationSessionID"
+	tokenExpiresAtKey       = "tokenExpiresAt" // time.Time from the token's exp claim
+)
+
+// CurrentUser returns the authenticated user. JWTAuthMiddleware stores the user it loaded; other callers
+// (API keys, tests that only set userID) are looked up by userID once and cached on the context.
+func CurrentUser(c *gin.Context) (*users.User, error) {
+	if val, ok := c.Get(currentUserKey); ok {
+		if user, ok := val.(*users.User); ok {
+			return user, nil
+		}
+	}
+
+	userIDVal, ok := c.Get("userID")
+	if !ok {
+		return nil, errors.New("no authenticated user")
+	}
+	userID, ok := userIDVal.(uint)
+	if !ok {
+		return nil, errors.New("no authenticated user")
+	}
+
+	var user users.User
+	if err := db.DB.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	c.Set(currentUserKey, &user)
+	return &user, nil
+}
+
+// CurrentRole returns the caller's role as set by the auth middleware
+func CurrentRole(c *gin.Context) (string, bool) {
+	val, ok := c.Get("role")
+	if !ok {
+		return "", false
+	}
+	role, ok := val.(string)
+	return role, ok && role != ""
+}
+
+// ImpersonatedBy returns the ID of the admin impersonating the caller, if the request uses an impersonation token
+func ImpersonatedBy(c *gin.Context) (uint, bool) {
+	val, ok := c.Get(impersonatedByKey)
+	if !ok {
+		return 0, false
+	}
+	adminID, ok := val.(uint)
+	return adminID, ok
+}
@@ -1,25 +1,36 @@
 package auth
 
 import (
+	"campus-backend/internal/core"
+	"campus-backend/internal/notifications"
 	"campus-backend/internal/users"
 	"campus-backend/pkg/db"
 	"campus-backend/pkg/validation"
+	"encoding/csv"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // Request structs for API
 type RegisterRequest struct {
 	Name      string  `json:"name" binding:"required" validate:"required,min=2,max=100"`
 	Email     string  `json:"email" binding:"required" validate:"required,email"`
-	Password  string  `json:"password" binding:"required" validate:"required,min=6"`
+	Password  string  `json:"password" binding:"required" validate:"required,password"`
 	Role      string  `json:"role" binding:"required" validate:"required,oneof=admin student faculty warden"`
 	Dept      string  `json:"dept" binding:"required" validate:"required"`
-	Hostel    *string `json:"hostel,omitempty"`
-	Phone     *string `json:"phone,omitempty"`
-	StudentID *string `json:"student_id,omitempty"`
+	Hostel    *string `json:"hostel,omitempty" validate:"required_if=Role warden"`
+	Phone     *string `json:"phone,omitempty" validate:"omitempty,phone"`
+	StudentID *string `json:"student_id,omitempty" validate:"required_if=Role student"`
+
+	EmergencyContactName  *string `json:"emergency_contact_name,omitempty" validate:"omitempty,min=2,max=100"`
+	EmergencyContactPhone *string `json:"emergency_contact_phone,omitempty" validate:"required_with=EmergencyContactName,omitempty,phone"`
 }
 
 type LoginRequest struct {
@@ -36,7 +47,7 @@ type LoginRequest struct {
 // @Param request body RegisterRequest true "User registration data"
 // @Success 201 {object} map[string]interface{} "User registered successfully"
 // @Failure 400 {object} map[string]interface{} "Validation failed"
-// @Failure 409 {object} map[string]interface{} "Email already registered"
+// @Failure 409 {object} map[string]interface{} "Email or student ID already registered"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /auth/register [post]
 func Register(c *gin.Context) {
@@ -44,13 +55,20 @@ func Register(c *gin.Context) {
 
 	// Get JSON data from request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
 		return
 	}
 
+	// Treat blank optional fields as missing so role-specific requirements apply
+	req.Hostel = validation.TrimOptional(req.Hostel)
+	req.Phone = validation.TrimOptional(req.Phone)
+	req.StudentID = validation.TrimOptional(req.StudentID)
+	req.EmergencyContactName = validation.TrimOptional(req.EmergencyContactName)
+	req.EmergencyContactPhone = validation.TrimOptional(req.EmergencyContactPhone)
+
 	// Validate the data
 	if err := validation.ValidateStruct(req); err != nil {
-		errors := validation.FormatValidationErrors(err)
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
 		return
 	}
@@ -80,11 +98,26 @@ func Register(c *gin.Context) {
 		Phone:     req.Phone,
 		StudentID: req.StudentID,
 		IsActive:  true,
+
+		EmergencyContactName:  req.EmergencyContactName,
+		EmergencyContactPhone: req.EmergencyContactPhone,
 	}
 
-	// Save to database
-	if err := db.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+	// The account and its welcome notification are saved together or not at all
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return notifications.NotifyWelcome(tx, &user)
+	})
+	if db.IsUniqueViolation(err) {
+		// Lost a race with another registration, or the student ID is taken
+		c.JSON(http.StatusConflict, gin.H{"error": "Email or student ID already registered"})
+		return
+	}
+	if err != nil {
+		log.Printf("Registration of %s failed: %v", req.Email, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
 		return
 	}
 
@@ -100,11 +133,12 @@ func Register(c *gin.Context) {
 
 // Login godoc
 // @Summary User login
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return JWT token. With lite=true only the token and its claims are returned; clients fetch the profile from /users/me. LOGIN_LITE makes that the default.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param request body LoginRequest true "Login credentials"
+// @Param lite query bool false "Leave the user object out of the response"
 // @Success 200 {object} map[string]interface{} "Login successful"
 // @Failure 400 {object} map[string]interface{} "Validation failed"
 // @Failure 401 {object} map[string]interface{} "Invalid credentials"
@@ -113,15 +147,25 @@ func Register(c *gin.Context) {
 func Login(c *gin.Context) {
 	var req LoginRequest
 
+	lite := core.GetConfig().Auth.LoginLite
+	if value, ok := c.GetQuery("lite"); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "lite must be true or false"})
+			return
+		}
+		lite = parsed
+	}
+
 	// Get JSON data from request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
 		return
 	}
 
 	// Validate the data
 	if err := validation.ValidateStruct(req); err != nil {
-		errors := validation.FormatValidationErrors(err)
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
 		return
 	}
@@ -140,6 +184,7 @@ func Login(c *gin.Context) {
 	}
 
 	// Generate JWT token
+	expiresAt := time.Now().Add(core.GetConfig().JWT.ExpiryFor(user.Role))
 	token, err := GenerateJWT(user.Email, user.Role)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
@@ -154,6 +199,18 @@ func Login(c *gin.Context) {
 	// Don't send password back
 	user.Password = ""
 
+	if lite {
+		c.JSON(http.StatusOK, gin.H{
+			"message":    "Login successful",
+			"token":      token,
+			"user_id":    user.ID,
+			"email":      user.Email,
+			"role":       user.Role,
+			"expires_at": expiresAt,
+		})
+		return
+	}
+
 	// Send success response with token
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Login successful",
@@ -176,3 +233,209 @@ func ListUsersByRole(c *gin.Context) {
 	// Send users list
 	c.JSON(http.StatusOK, users)
 }
+
+// ImportRowResult describes the outcome of importing a single CSV row
+type ImportRowResult struct {
+	Row    int               `json:"row"`
+	Email  string            `json:"email,omitempty"`
+	Status string            `json:"status"` // created, skipped_duplicate, invalid
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Import row statuses
+const (
+	ImportStatusCreated   = "created"
+	ImportStatusDuplicate = "skipped_duplicate"
+	ImportStatusInvalid   = "invalid"
+)
+
+// importColumns is the expected column order of the import CSV
+var importColumns = []string{"name", "email", "role", "dept", "hostel", "phone", "student_id"}
+
+// ImportUsers godoc
+// @Summary Bulk import users from CSV
+// @Description Admin uploads a CSV with columns name,email,role,dept,hostel,phone,student_id. Each user gets a random initial password sent by email. Errors of invalid rows are keyed by column name.
+// @Tags Users
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV file"
+// @Success 200 {object} map[string]interface{} "Per-row import report"
+// @Failure 400 {object} map[string]interface{} "Missing or malformed CSV"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/import [post]
+func ImportUsers(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is required in the 'file' field"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // Optional trailing columns may be omitted
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Malformed CSV", "details": core.PublicError(err, "The file could not be read as CSV")})
+		return
+	}
+
+	// Skip the header row if present
+	startRow := 0
+	if len(records) > 0 && len(records[0]) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), importColumns[0]) {
+		startRow = 1
+	}
+
+	type pendingUser struct {
+		user     users.User
+		password string
+	}
+
+	results := make([]ImportRowResult, 0, len(records)-startRow)
+	var toCreate []pendingUser
+	seenEmails := make(map[string]bool)
+	seenStudentIDs := make(map[string]bool)
+
+	for i := startRow; i < len(records); i++ {
+		// Pad missing optional columns
+		record := records[i]
+		for len(record) < len(importColumns) {
+			record = append(record, "")
+		}
+		for j := range record {
+			record[j] = strings.TrimSpace(record[j])
+		}
+
+		result := ImportRowResult{Row: i + 1, Email: record[1]}
+
+		password, err := GenerateRandomPassword(max(12, validation.CurrentPasswordPolicy().MinLength))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate password"})
+			return
+		}
+
+		req := RegisterRequest{
+			Name:      record[0],
+			Email:     record[1],
+			Password:  password,
+			Role:      record[2],
+			Dept:      record[3],
+			Hostel:    optionalString(record[4]),
+			Phone:     optionalString(record[5]),
+			StudentID: optionalString(record[6]),
+		}
+
+		// Validate with the same rules as registration
+		if err := validation.ValidateStruct(req); err != nil {
+			result.Status = ImportStatusInvalid
+			result.Errors = validation.FormatValidationErrorPathsIn(core.Language(c), err) // Keyed by CSV column, e.g. student_id
+			results = append(results, result)
+			continue
+		}
+
+		// Skip duplicates within the file and against existing users
+		if seenEmails[req.Email] || (req.StudentID != nil && seenStudentIDs[*req.StudentID]) {
+			result.Status = ImportStatusDuplicate
+			results = append(results, result)
+			continue
+		}
+		var count int64
+		query := db.DB.Model(&users.User{}).Where("email = ?", req.Email)
+		if req.StudentID != nil {
+			query = query.Or("student_id = ?", *req.StudentID)
+		}
+		if err := query.Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing users"})
+			return
+		}
+		if count > 0 {
+			result.Status = ImportStatusDuplicate
+			results = append(results, result)
+			continue
+		}
+
+		hashedPassword, err := HashPassword(password)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			return
+		}
+
+		seenEmails[req.Email] = true
+		if req.StudentID != nil {
+			seenStudentIDs[*req.StudentID] = true
+		}
+
+		result.Status = ImportStatusCreated
+		results = append(results, result)
+		toCreate = append(toCreate, pendingUser{
+			user: users.User{
+				Name:      req.Name,
+				Email:     req.Email,
+				Password:  hashedPassword,
+				Role:      req.Role,
+				Dept:      req.Dept,
+				Hostel:    req.Hostel,
+				Phone:     req.Phone,
+				StudentID: req.StudentID,
+				IsActive:  true,
+			},
+			password: password,
+		})
+	}
+
+	// Insert all valid rows atomically
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range toCreate {
+			if err := tx.Create(&toCreate[i].user).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import users", "details": core.PublicError(err, "No users were imported")})
+		return
+	}
+
+	// Email initial passwords once the users are committed
+	emailService := notifications.NewEmailService()
+	for _, p := range toCreate {
+		body := fmt.Sprintf("Dear %s,\n\nAn account has been created for you on the Campus Management System.\n\nEmail: %s\nTemporary password: %s\n\nPlease change your password after logging in.\n\nBest regards,\nCampus Management System\n",
+			p.user.Name, p.user.Email, p.password)
+		if err := emailService.SendEmail(p.user.Email, "Your Campus Management System account", body); err != nil {
+			log.Printf("Failed to send welcome email to %s: %v", p.user.Email, err)
+		}
+	}
+
+	summary := gin.H{
+		ImportStatusCreated:   0,
+		ImportStatusDuplicate: 0,
+		ImportStatusInvalid:   0,
+	}
+	for _, r := range results {
+		summary[r.Status] = summary[r.Status].(int) + 1
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Import completed",
+		"summary": summary,
+		"results": results,
+	})
+}
+
+// optionalString returns nil for empty strings so optional columns stay NULL
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+// StartImpersonation godoc
+// @Summary Impersonate a user
+// @Description Admin gets a short-lived token that acts as the user, for support. The token carries an impersonated_by claim and the session is recorded in the audit log.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 201 {object} map[string]interface{} "Impersonation token"
+// @Failure 400 {object} map[string]interface{} "User cannot be impersonated"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/impersonate/{id} [post]
+func StartImpersonation(c *gin.Context) {
+	adminIDVal, _ := c.Get("userID")
+	adminID := adminIDVal.(uint)
+
+	var target users.User
+	if err := db.DB.First(&target, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if target.ID == adminID || target.Role == users.RoleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Admins cannot be impersonated"})
+		return
+	}
+	if !target.IsActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Inactive users cannot be impersonated"})
+		return
+	}
+
+	session := ImpersonationSession{
+		AdminID:   adminID,
+		TargetID:  target.ID,
+		ExpiresAt: time.Now().Add(time.Duration(core.GetConfig().JWT.ImpersonationMinutes) * time.Minute),
+	}
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		details := fmt.Sprintf("impersonating %s (%s) until %s", target.Email, target.Role, session.ExpiresAt.UTC().Format(time.RFC3339))
+		return audit.Record(tx, adminID, "impersonation_start", "user", target.ID, details)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start impersonation"})
+		return
+	}
+
+	token, err := GenerateImpersonationJWT(target.Email, target.Role, adminID, session.ID, session.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	target.Password = ""
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Impersonation started",
+		"token":      token,
+		"expires_at": session.ExpiresAt,
+		"session_id": session.ID,
+		"user":       target,
+	})
+}
+
+// EndImpersonation godoc
+// @Summary End impersonation
+// @Description Called with an impersonation token; invalidates it and records the end in the audit log
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Impersonation ended"
+// @Failure 400 {object} map[string]interface{} "Not an impersonation token"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/impersonate [delete]
+func EndImpersonation(c *gin.Context) {
+	adminID, ok := ImpersonatedBy(c)
+	sessionIDVal, _ := c.Get(impersonationSessionKey)
+	sessionID, _ := sessionIDVal.(uint)
+	if !ok || sessionID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not an impersonation token"})
+		return
+	}
+
+	userIDVal, _ := c.Get("userID")
+	targetID := userIDVal.(uint)
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&ImpersonationSession{}).Where("id = ? AND ended_at IS NULL", sessionID).Update("ended_at", now).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, adminID, "impersonation_end", "user", targetID, fmt.Sprintf("session %d ended", sessionID))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end impersonation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Impersonation ended"})
+}
+
+// DenyImpersonation blocks an action when the caller is an admin impersonating someone
+func DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if adminID, ok := ImpersonatedBy(c); ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This action is not allowed while impersonating", "impersonated_by": adminID})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// DenyImpersonationWhen is DenyImpersonation for a read route that writes only on some requests,
+// such as a GET with a flag that also marks what it returns as seen
+func DenyImpersonationWhen(writes func(c *gin.Context) bool) gin.HandlerFunc {
+	deny := DenyImpersonation()
+	return func(c *gin.Context) {
+		if !writes(c) {
+			c.Next()
+			return
+		}
+		deny(c)
+	}
+}
+
+// activeImpersonation loads the session named in an impersonation token's claims,
+// reporting false if it is unknown, ended, expired or does not match the claimed admin
+func activeImpersonation(claims jwt.MapClaims) (*ImpersonationSession, bool) {
+	adminID, ok := claims["impersonated_by"].(float64)
+	if !ok {
+		return nil, false
+	}
+	sessionID, ok := claims["impersonation_session"].(float64)
+	if !ok {
+		return nil, false
+	}
+
+	var session ImpersonationSession
+	if err := db.DB.First(&session, uint(sessionID)).Error; err != nil {
+		return nil, false
+	}
+	if session.AdminID != uint(adminID) || session.EndedAt != nil || time.Now().After(session.ExpiresAt) {
+		return nil, false
+	}
+	return &session, true
+}
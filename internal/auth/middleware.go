@@ -4,12 +4,15 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
+	"campus-backend/internal/core"
 	"campus-backend/internal/users"
 	"campus-backend/pkg/db"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
 func JWTAuthMiddleware() gin.HandlerFunc {
@@ -36,17 +39,109 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
-		c.Set("email", claims["email"])
+		// Both claims must be non-empty strings; handlers rely on them
+		email, _ := claims["email"].(string)
+		role, _ := claims["role"].(string)
+		if email == "" || role == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			c.Abort()
+			return
+		}
+		c.Set("email", email)
+		// Deactivated accounts (including ones merged away) lose access before their tokens expire
 		var user users.User
-		email := claims["email"].(string)
-		if err := db.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		if err := db.DB.Where("email = ? AND is_active = ?", email, true).First(&user).Error; err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 			c.Abort()
 			return
 		}
+		if _, ok := claims["impersonated_by"]; ok {
+			session, ok := activeImpersonation(claims)
+			if !ok {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Impersonation session has ended"})
+				c.Abort()
+				return
+			}
+			c.Set(impersonatedByKey, session.AdminID)
+			c.Set(impersonationSessionKey, session.ID)
+		}
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			c.Set(tokenExpiresAtKey, exp.Time)
+		}
 		c.Set("userID", user.ID)
-		c.Set("role", claims["role"])
-		c.Set("role", claims["role"])
+		c.Set("role", role)
+		c.Set(currentUserKey, &user) // Handlers read it with CurrentUser instead of re-querying
+		if user.Language != "" {
+			c.Set(core.LanguageKey, user.Language)
+		}
 		c.Next()
 	}
 }
+
+// APIKeyMiddleware authenticates service-to-service callers via the X-API-Key header
+func APIKeyMiddleware(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authenticateAPIKey(c, scope) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// JWTOrAPIKeyMiddleware accepts either an X-API-Key header with the given scope or a Bearer JWT
+func JWTOrAPIKeyMiddleware(scope string) gin.HandlerFunc {
+	jwtMiddleware := JWTAuthMiddleware()
+	apiKeyMiddleware := APIKeyMiddleware(scope)
+	return func(c *gin.Context) {
+		if c.GetHeader("X-API-Key") == "" {
+			jwtMiddleware(c)
+			return
+		}
+		apiKeyMiddleware(c)
+	}
+}
+
+// authenticateAPIKey validates the X-API-Key header and sets a synthetic service identity,
+// writing an error response and returning false on failure
+func authenticateAPIKey(c *gin.Context, scope string) bool {
+	rawKey := c.GetHeader("X-API-Key")
+	if rawKey == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "X-API-Key header missing"})
+		return false
+	}
+
+	var key APIKey
+	if err := db.DB.Where("key_hash = ? AND revoked_at IS NULL", HashAPIKey(rawKey)).First(&key).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked API key"})
+		return false
+	}
+	if !key.HasScope(scope) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API key does not have the required scope: " + scope})
+		return false
+	}
+
+	// Keys made before service accounts existed get one on first use
+	if key.UserID == 0 {
+		if err := db.DB.Transaction(func(tx *gorm.DB) error { return createServiceAccount(tx, &key) }); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set up API key account"})
+			return false
+		}
+	}
+	var account users.User
+	if err := db.DB.First(&account, key.UserID).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked API key"})
+		return false
+	}
+
+	// Record usage without touching updated_at
+	now := time.Now()
+	db.DB.Model(&key).UpdateColumn("last_used_at", now)
+
+	// Actions performed with the key are attributed to its own service account
+	c.Set("userID", account.ID)
+	c.Set("role", users.RoleService)
+	c.Set("apiKeyID", key.ID)
+	c.Set(currentUserKey, &account)
+	return true
+}
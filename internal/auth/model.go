@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// API key scopes
+const (
+	ScopeAttendanceMark = "attendance:mark"
+	ScopeAttendanceRead = "attendance:read"
+)
+
+// APIKey represents a credential for service-to-service callers
+type APIKey struct {
+	gorm.Model
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"not null"`        // First characters of the key, for identification
+	KeyHash    string     `json:"-" gorm:"uniqueIndex;not null"` // SHA-256 of the key, never the key itself
+	Scopes     string     `json:"scopes" gorm:"not null"`        // Comma-separated list of scopes
+	CreatedBy  uint       `json:"created_by" gorm:"not null"`    // Admin who created the key
+	UserID     uint       `json:"user_id" gorm:"index"`          // Service account the key acts as
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// HasScope reports whether the key grants the given scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range strings.Split(k.Scopes, ",") {
+		if strings.TrimSpace(s) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ImpersonationSession tracks an admin acting as another user for support. Tokens minted for it
+// carry its ID, so ending the session invalidates them before they expire.
+type ImpersonationSession struct {
+	gorm.Model
+	AdminID   uint       `json:"admin_id" gorm:"not null;index"`
+	TargetID  uint       `json:"target_id" gorm:"not null;index"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+}
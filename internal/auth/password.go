@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/validation"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PasswordPolicy builds the strength policy from the current configuration, so settings
+// overrides apply to the next password checked. main installs it as the "password" tag's source.
+func PasswordPolicy() validation.PasswordPolicy {
+	cfg := core.GetConfig().Auth
+	return validation.PasswordPolicy{
+		MinLength:     cfg.PasswordMinLength,
+		RequireUpper:  cfg.PasswordRequireUpper,
+		RequireLower:  cfg.PasswordRequireLower,
+		RequireDigit:  cfg.PasswordRequireDigit,
+		RequireSymbol: cfg.PasswordRequireSymbol,
+	}
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required" validate:"required"`
+	NewPassword     string `json:"new_password" binding:"required" validate:"required,password,nefield=CurrentPassword"`
+}
+
+// ChangePassword godoc
+// @Summary Change the current user's password
+// @Description Replaces the caller's password after checking the current one. The new password must meet the configured strength policy and differ from the current one. Refused while impersonating.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} map[string]interface{} "Password changed"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized or current password incorrect"
+// @Failure 403 {object} map[string]interface{} "Forbidden while impersonating"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /auth/change-password [post]
+func ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+	if err := validation.ValidateStruct(req); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+
+	user, err := CurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	if !CheckPasswordHash(req.CurrentPassword, user.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	hashedPassword, err := HashPassword(req.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("password", hashedPassword).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, user.ID, "password_change", "user", user.ID, "")
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
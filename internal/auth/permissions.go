@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"campus-backend/internal/core"
+	"campus-backend/internal/users"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Role groups shared by the route checks and the capabilities reported to clients
+var (
+	LeaveApproverRoles    = []string{users.RoleFaculty, users.RoleWarden, users.RoleAdmin}
+	AttendanceMarkerRoles = []string{users.RoleFaculty, users.RoleAdmin}
+	SubjectManagerRoles   = []string{users.RoleFaculty, users.RoleAdmin}
+	LeaveOnBehalfRoles    = []string{users.RoleWarden, users.RoleAdmin}
+)
+
+// Capability names reported by GET /users/me/permissions
+const (
+	CanApplyLeave     = "can_apply_leave"
+	CanApplyForOthers = "can_apply_for_others"
+	CanApproveLeaves  = "can_approve_leaves"
+	CanMarkAttendance = "can_mark_attendance"
+	CanManageSubjects = "can_manage_subjects"
+	CanViewAnalytics  = "can_view_analytics"
+	CanManageUsers    = "can_manage_users"
+	CanManageSettings = "can_manage_settings"
+	CanImpersonate    = "can_impersonate"
+)
+
+// capabilityRoles maps each capability to the roles granted it
+var capabilityRoles = map[string][]string{
+	CanApplyLeave:     {users.RoleStudent},
+	CanApplyForOthers: LeaveOnBehalfRoles,
+	CanApproveLeaves:  LeaveApproverRoles,
+	CanMarkAttendance: AttendanceMarkerRoles,
+	CanManageSubjects: SubjectManagerRoles,
+	CanViewAnalytics:  {users.RoleAdmin},
+	CanManageUsers:    {users.RoleAdmin},
+	CanManageSettings: {users.RoleAdmin},
+	CanImpersonate:    {users.RoleAdmin},
+}
+
+// deniedWhileImpersonating lists the capabilities whose routes use DenyImpersonation
+var deniedWhileImpersonating = []string{CanApplyLeave, CanApplyForOthers, CanApproveLeaves, CanMarkAttendance, CanImpersonate}
+
+// Permissions describes what a user can do, for clients deciding which UI to show
+type Permissions struct {
+	Role         string          `json:"role"`
+	Scope        string          `json:"scope"`                 // self, dept, hostel or all
+	ScopeValue   *string         `json:"scope_value,omitempty"` // The department or hostel for dept and hostel scopes
+	Impersonated bool            `json:"impersonated"`
+	Capabilities map[string]bool `json:"capabilities"`
+}
+
+// PermissionsFor derives a user's permissions from their role. Impersonation turns off the
+// capabilities an impersonating admin is refused.
+func PermissionsFor(user *users.User, impersonated bool) Permissions {
+	permissions := Permissions{
+		Role:         user.Role,
+		Impersonated: impersonated,
+		Capabilities: make(map[string]bool, len(capabilityRoles)),
+	}
+	for capability, roles := range capabilityRoles {
+		permissions.Capabilities[capability] = slices.Contains(roles, user.Role)
+	}
+	if !core.GetConfig().Leave.ApplyOnBehalf {
+		permissions.Capabilities[CanApplyForOthers] = false
+	}
+	if impersonated {
+		for _, capability := range deniedWhileImpersonating {
+			permissions.Capabilities[capability] = false
+		}
+	}
+
+	switch user.Role {
+	case users.RoleAdmin:
+		permissions.Scope = "all"
+	case users.RoleFaculty:
+		permissions.Scope = "dept"
+		permissions.ScopeValue = &user.Dept
+	case users.RoleWarden:
+		permissions.Scope = "hostel"
+		permissions.ScopeValue = user.Hostel
+	default:
+		permissions.Scope = "self"
+	}
+	return permissions
+}
+
+// GetMyPermissions godoc
+// @Summary Get the current user's permissions
+// @Description Capabilities derived from the caller's role, with the department or hostel they are scoped to. While impersonating, actions refused to the admin are reported as false.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Permissions "Permissions"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /users/me/permissions [get]
+func GetMyPermissions(c *gin.Context) {
+	user, err := CurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	_, impersonated := ImpersonatedBy(c)
+	c.JSON(http.StatusOK, PermissionsFor(user, impersonated))
+}
@@ -8,8 +8,8 @@ import (
 
 func RequireRole(role string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		r, exists := c.Get("role")
-		if !exists || r != role {
+		r, ok := CurrentRole(c)
+		if !ok || r != role {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden - insufficient permissions"})
 			c.Abort()
 			return
@@ -17,3 +17,20 @@ func RequireRole(role string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireAnyRole allows the request if the caller has any of the given roles
+func RequireAnyRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		r, ok := CurrentRole(c)
+		if ok {
+			for _, role := range roles {
+				if r == role {
+					c.Next()
+					return
+				}
+			}
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden - insufficient permissions"})
+		c.Abort()
+	}
+}
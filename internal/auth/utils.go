@@ -1,15 +1,22 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
 	"os"
 	"time"
 
+	"campus-backend/internal/core"
+	"campus-backend/pkg/validation"
+
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), core.GetConfig().Auth.BcryptCost)
 	return string(bytes), err
 }
 func CheckPasswordHash(password, hash string) bool {
@@ -21,7 +28,58 @@ func GenerateJWT(email, role string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"email": email,
 		"role":  role,
-		"exp":   time.Now().Add(24 * time.Hour).Unix(),
+		"exp":   time.Now().Add(core.GetConfig().JWT.ExpiryFor(role)).Unix(),
+	})
+	return token.SignedString(secret)
+}
+
+// GenerateImpersonationJWT mints a short-lived token for the target user that records the admin behind it
+func GenerateImpersonationJWT(email, role string, adminID, sessionID uint, expiresAt time.Time) (string, error) {
+	secret := []byte(os.Getenv("JWT_SECRET"))
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"email":                 email,
+		"role":                  role,
+		"exp":                   expiresAt.Unix(),
+		"impersonated_by":       adminID,
+		"impersonation_session": sessionID,
 	})
 	return token.SignedString(secret)
 }
+
+// GenerateRandomPassword creates a random password of the given length (at least 4) holding an
+// uppercase letter, a lowercase letter, a digit and a symbol, so it meets any strength policy
+func GenerateRandomPassword(length int) (string, error) {
+	const charset = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789!#%+=?@"
+	// rand.Int draws uniformly, where a random byte modulo the charset size would favour its first characters
+	size := big.NewInt(int64(len(charset)))
+	buf := make([]byte, length)
+	for {
+		for i := range buf {
+			n, err := rand.Int(rand.Reader, size)
+			if err != nil {
+				return "", err
+			}
+			buf[i] = charset[n.Int64()]
+		}
+		// Redraw the rare password missing a character class
+		all := validation.PasswordPolicy{RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true}
+		if len(all.Violations(string(buf))) == 0 {
+			return string(buf), nil
+		}
+	}
+}
+
+// GenerateAPIKey creates a new random API key
+func GenerateAPIKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "ck_" + hex.EncodeToString(buf), nil
+}
+
+// HashAPIKey returns the SHA-256 digest stored in place of the key
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,131 @@
+package calendar
+
+import (
+	"campus-backend/internal/core"
+	"campus-backend/pkg/timeutil"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRangeDays bounds the range one working-days count may cover
+const maxRangeDays = 366
+
+// Day types reported by GET /calendar/day
+const (
+	DayWorking = "working_day"
+	DayWeekend = "weekend"
+	DayHoliday = "holiday"
+)
+
+// Holiday returns the name of the holiday on the given campus day, if any
+func Holiday(day time.Time) (string, bool) {
+	name, ok := core.GetConfig().Campus.Holidays[day.In(timeutil.Location()).Format(timeutil.DateLayout)]
+	return name, ok
+}
+
+// IsWorkingDay reports whether day is neither a weekend nor a holiday
+func IsWorkingDay(day time.Time) bool {
+	if timeutil.IsWeekend(day) {
+		return false
+	}
+	_, holiday := Holiday(day)
+	return !holiday
+}
+
+// CalendarDay describes one date. Holidays falling on a weekend are reported as holidays.
+type CalendarDay struct {
+	Date       string `json:"date"`
+	Weekday    string `json:"weekday"`
+	Type       string `json:"type"` // working_day, weekend or holiday
+	Holiday    string `json:"holiday,omitempty"`
+	WorkingDay bool   `json:"working_day"`
+}
+
+// Describe classifies a campus day
+func Describe(day time.Time) CalendarDay {
+	local := day.In(timeutil.Location())
+	entry := CalendarDay{Date: local.Format(timeutil.DateLayout), Weekday: local.Weekday().String(), Type: DayWorking, WorkingDay: true}
+	if name, ok := Holiday(day); ok {
+		entry.Type, entry.Holiday, entry.WorkingDay = DayHoliday, name, false
+	} else if timeutil.IsWeekend(day) {
+		entry.Type, entry.WorkingDay = DayWeekend, false
+	}
+	return entry
+}
+
+// GetCalendarDay godoc
+// @Summary Check whether a date is a working day
+// @Description Reports whether the date is a working day, a weekend or a holiday (with its name). Holidays come from the campus.holidays setting.
+// @Tags Calendar
+// @Produce json
+// @Security BearerAuth
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Success 200 {object} CalendarDay "Day"
+// @Failure 400 {object} map[string]interface{} "Invalid date"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /calendar/day [get]
+func GetCalendarDay(c *gin.Context) {
+	day, err := timeutil.ParseDate(c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must be in YYYY-MM-DD format"})
+		return
+	}
+	c.JSON(http.StatusOK, Describe(day))
+}
+
+// GetWorkingDays godoc
+// @Summary Count working days in a range
+// @Description Counts the days from from to to, inclusive, that are neither weekends nor holidays, and lists the holidays in the range.
+// @Tags Calendar
+// @Produce json
+// @Security BearerAuth
+// @Param from query string true "Start date (YYYY-MM-DD)"
+// @Param to query string true "End date (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{} "Working day count"
+// @Failure 400 {object} map[string]interface{} "Invalid range"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /calendar/working-days [get]
+func GetWorkingDays(c *gin.Context) {
+	from, err := timeutil.ParseDate(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be in YYYY-MM-DD format"})
+		return
+	}
+	to, err := timeutil.ParseDate(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must be in YYYY-MM-DD format"})
+		return
+	}
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+	if to.Sub(from) > maxRangeDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("The range can cover at most %d days", maxRangeDays)})
+		return
+	}
+
+	workingDays, totalDays := 0, 0
+	holidays := []CalendarDay{}
+	for day := from; !day.After(to); day = timeutil.StartOfDay(day.In(timeutil.Location()).AddDate(0, 0, 1)) {
+		totalDays++
+		entry := Describe(day)
+		if entry.WorkingDay {
+			workingDays++
+		}
+		if entry.Type == DayHoliday {
+			holidays = append(holidays, entry)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":         from.In(timeutil.Location()).Format(timeutil.DateLayout),
+		"to":           to.In(timeutil.Location()).Format(timeutil.DateLayout),
+		"total_days":   totalDays,
+		"working_days": workingDays,
+		"holidays":     holidays,
+	})
+}
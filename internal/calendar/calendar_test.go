@@ -0,0 +1,76 @@
+package calendar
+
+import (
+	"campus-backend/internal/core"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/calendar/day", GetCalendarDay)
+	r.GET("/calendar/working-days", GetWorkingDays)
+	return r
+}
+
+func get(r *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestGetCalendarDay(t *testing.T) {
+	defer func() { core.AppConfig = nil }()
+	t.Setenv("CAMPUS_HOLIDAYS", "2026-10-02=Gandhi Jayanti,2026-10-04=Sunday Holiday,not-a-date=Ignored")
+	core.LoadConfig()
+	r := newTestRouter()
+
+	day := func(date string) CalendarDay {
+		w := get(r, "/calendar/day?date="+date)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp CalendarDay
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	assert.Equal(t, CalendarDay{Date: "2026-10-01", Weekday: "Thursday", Type: DayWorking, WorkingDay: true}, day("2026-10-01"))
+	assert.Equal(t, CalendarDay{Date: "2026-10-02", Weekday: "Friday", Type: DayHoliday, Holiday: "Gandhi Jayanti"}, day("2026-10-02"))
+	assert.Equal(t, CalendarDay{Date: "2026-10-03", Weekday: "Saturday", Type: DayWeekend}, day("2026-10-03"))
+	assert.Equal(t, DayHoliday, day("2026-10-04").Type)
+	assert.NotContains(t, core.GetConfig().Campus.Holidays, "not-a-date")
+
+	assert.Equal(t, http.StatusBadRequest, get(r, "/calendar/day").Code)
+	assert.Equal(t, http.StatusBadRequest, get(r, "/calendar/day?date=02-10-2026").Code)
+}
+
+func TestGetWorkingDays(t *testing.T) {
+	defer func() { core.AppConfig = nil }()
+	t.Setenv("CAMPUS_HOLIDAYS", "2026-10-02=Gandhi Jayanti")
+	core.LoadConfig()
+	r := newTestRouter()
+
+	// Thursday 1st to Wednesday 7th: a holiday, a weekend and four working days
+	w := get(r, "/calendar/working-days?from=2026-10-01&to=2026-10-07")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		TotalDays   int           `json:"total_days"`
+		WorkingDays int           `json:"working_days"`
+		Holidays    []CalendarDay `json:"holidays"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, 7, resp.TotalDays)
+	assert.Equal(t, 4, resp.WorkingDays)
+	if assert.Len(t, resp.Holidays, 1) {
+		assert.Equal(t, "Gandhi Jayanti", resp.Holidays[0].Holiday)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, get(r, "/calendar/working-days?from=2026-10-07&to=2026-10-01").Code)
+	assert.Equal(t, http.StatusBadRequest, get(r, "/calendar/working-days?from=2025-01-01&to=2026-10-01").Code)
+	assert.Equal(t, http.StatusBadRequest, get(r, "/calendar/working-days?from=2026-10-01").Code)
+}
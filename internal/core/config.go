@@ -1,19 +1,38 @@
 package core
 
 import (
+	"campus-backend/pkg/timeutil"
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Config holds application configuration
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	JWT      JWTConfig
-	Email    EmailConfig
+	Database   DatabaseConfig
+	Server     ServerConfig
+	JWT        JWTConfig
+	Auth       AuthConfig
+	Campus     CampusConfig
+	Leave      LeaveConfig
+	Attendance AttendanceConfig
+	Pagination PaginationConfig
+	Email      EmailConfig
+	SMS        SMSConfig
+	Retention  RetentionConfig
 }
 
+// AppConfig holds the configuration loaded at startup, with any runtime settings applied.
+// Replace it with SetConfig once the server is running.
+var AppConfig *Config
+
+var configMu sync.RWMutex
+
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
 	Type     string
@@ -26,13 +45,136 @@ type DatabaseConfig struct {
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port    string
-	GinMode string
+	Port           string
+	GinMode        string
+	MetricsEnabled bool
+
+	// IPs or CIDRs of load balancers whose X-Forwarded-For is believed; empty trusts none
+	TrustedProxies []string
+
+	HomeRoutes map[string]string // Role -> frontend route returned by GET /users/me/home
 }
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	Secret string
+	Secret               string
+	ExpiryHours          int            // Token lifetime for roles without an override
+	RoleExpiryHours      map[string]int // Role -> token lifetime in hours
+	ImpersonationMinutes int            // Lifetime of tokens minted for admin impersonation
+}
+
+// ExpiryFor returns the token lifetime for the given role
+func (j JWTConfig) ExpiryFor(role string) time.Duration {
+	if hours, ok := j.RoleExpiryHours[role]; ok && hours > 0 {
+		return time.Duration(hours) * time.Hour
+	}
+	return time.Duration(j.ExpiryHours) * time.Hour
+}
+
+// AuthConfig holds password hashing and strength configuration
+type AuthConfig struct {
+	BcryptCost int
+
+	PasswordMinLength     int
+	PasswordRequireUpper  bool
+	PasswordRequireLower  bool
+	PasswordRequireDigit  bool
+	PasswordRequireSymbol bool
+
+	LoginLite bool // Login returns only the token and its claims unless ?lite=false is passed
+}
+
+// CampusConfig holds campus-wide settings
+type CampusConfig struct {
+	Timezone     string // IANA name used for day boundaries, e.g. "Asia/Kolkata"
+	PhonePattern string // Regular expression for phone numbers; empty means E.164
+
+	Holidays map[string]string // YYYY-MM-DD -> holiday name; weekends are never working days
+}
+
+// LeaveConfig holds leave policy settings
+type LeaveConfig struct {
+	AllowedTypes  []string       // Leave types students may apply for
+	MinNoticeDays map[string]int // Leave type -> days of advance notice required
+
+	// Leave type -> types of existing leave it may overlap, e.g. emergency over personal.
+	// Any pair not listed blocks the application.
+	OverlapAllowed map[string][]string
+
+	// Pending leaves whose start date passed more than StaleGraceDays ago are
+	// rejected or flagged (StaleAction) every StaleCheckIntervalMinutes (0 disables the job)
+	StaleAction               string
+	StaleGraceDays            int
+	StaleCheckIntervalMinutes int
+
+	// Pending leaves not decided within ApprovalDeadlineHours of being filed (0 disables it) are
+	// escalated to the next approver in the chain, checked every EscalationCheckIntervalMinutes
+	ApprovalDeadlineHours          int
+	EscalationCheckIntervalMinutes int
+
+	ApplyOnBehalf     bool // Wardens and admins may file leaves for students
+	AllowSelfApproval bool // Approvers may decide leaves they are the subject of or filed themselves
+}
+
+// ApprovalDeadline is how long approvers have to decide a pending leave; zero means no deadline
+func (l LeaveConfig) ApprovalDeadline() time.Duration {
+	return time.Duration(l.ApprovalDeadlineHours) * time.Hour
+}
+
+// IsAllowedType reports whether the leave type is in the configured list
+func (l LeaveConfig) IsAllowedType(leaveType string) bool {
+	for _, allowed := range l.AllowedTypes {
+		if allowed == leaveType {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsOverlap reports whether a new leave of leaveType may overlap an existing leave of existingType
+func (l LeaveConfig) AllowsOverlap(leaveType, existingType string) bool {
+	for _, allowed := range l.OverlapAllowed[leaveType] {
+		if allowed == existingType {
+			return true
+		}
+	}
+	return false
+}
+
+// AttendanceConfig holds attendance policy settings
+type AttendanceConfig struct {
+	LowThreshold   int            // Attendance percentage below which a student is at risk
+	DeptThresholds map[string]int // Department -> threshold overriding LowThreshold
+
+	// How often yesterday's approved leaves are turned into excused attendance (0 disables the job)
+	LeaveAbsenceIntervalMinutes int
+
+	BackdateDays int // How many days back non-admins may mark attendance; 0 allows any past date
+
+	// Cached per-student stats older than SummaryMaxAgeMinutes are recomputed on read (0 always reads live),
+	// and every cached summary is rebuilt every SummaryRecomputeIntervalMinutes (0 disables the job)
+	SummaryMaxAgeMinutes            int
+	SummaryRecomputeIntervalMinutes int
+
+	// Students are at risk below their threshold or with at least AtRiskMinLeaves approved leaves in
+	// the range; their risk score weighs the two factors by AtRiskAttendanceWeight : AtRiskLeaveWeight
+	AtRiskMinLeaves        int
+	AtRiskAttendanceWeight int
+	AtRiskLeaveWeight      int
+}
+
+// ThresholdFor returns the low-attendance threshold percentage for a department
+func (a AttendanceConfig) ThresholdFor(dept string) float64 {
+	if threshold, ok := a.DeptThresholds[dept]; ok {
+		return float64(threshold)
+	}
+	return float64(a.LowThreshold)
+}
+
+// PaginationConfig holds list endpoint page size limits
+type PaginationConfig struct {
+	DefaultPageSize int
+	MaxPageSize     int
 }
 
 // EmailConfig holds email configuration
@@ -42,11 +184,29 @@ type EmailConfig struct {
 	SMTPUsername string
 	SMTPPassword string
 	FromEmail    string
+	TemplateDir  string // Overrides for the built-in email templates, one <name>.tmpl per notification type
+}
+
+// SMSConfig holds SMS configuration
+type SMSConfig struct {
+	Provider         string // "log" (default) or "twilio"
+	TwilioAccountSID string
+	TwilioAuthToken  string
+	FromNumber       string // Sending number in E.164 format
+}
+
+// RetentionConfig holds the data retention policy
+type RetentionConfig struct {
+	// Attendance and read notifications older than Months are hard-deleted every
+	// CheckIntervalMinutes; 0 months keeps everything
+	Months               int
+	ActiveTermStart      string // YYYY-MM-DD; nothing from this date on is purged, whatever its age
+	CheckIntervalMinutes int
 }
 
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
-	return &Config{
+	config := &Config{
 		Database: DatabaseConfig{
 			Type:     getEnv("DB_TYPE", "sqlite"),
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -56,11 +216,69 @@ func LoadConfig() *Config {
 			Port:     getEnv("DB_PORT", "5432"),
 		},
 		Server: ServerConfig{
-			Port:    getEnv("PORT", "8080"),
-			GinMode: getEnv("GIN_MODE", "debug"),
+			Port:           getEnv("PORT", "8080"),
+			GinMode:        getEnv("GIN_MODE", "release"),
+			MetricsEnabled: getEnvAsBool("METRICS_ENABLED", true),
+			TrustedProxies: getEnvAsSlice("TRUSTED_PROXIES", nil),
+			HomeRoutes: getEnvAsStringMap("HOME_ROUTES", map[string]string{
+				"admin": "/admin/dashboard", "faculty": "/faculty/dashboard", "warden": "/warden/dashboard", "student": "/student/home",
+			}),
 		},
 		JWT: JWTConfig{
-			Secret: getEnv("JWT_SECRET", "your-super-secret-jwt-key"),
+			Secret:      getEnv("JWT_SECRET", "your-super-secret-jwt-key"),
+			ExpiryHours: getEnvAsInt("JWT_EXPIRY_HOURS", 24),
+			// Admins get shorter sessions, students longer ones
+			RoleExpiryHours:      getEnvAsIntMap("JWT_ROLE_EXPIRY_HOURS", map[string]int{"admin": 8, "student": 72}),
+			ImpersonationMinutes: getEnvAsInt("JWT_IMPERSONATION_MINUTES", 30),
+		},
+		Auth: AuthConfig{
+			BcryptCost: getEnvAsInt("BCRYPT_COST", 12),
+
+			PasswordMinLength:     getEnvAsInt("PASSWORD_MIN_LENGTH", 8),
+			PasswordRequireUpper:  getEnvAsBool("PASSWORD_REQUIRE_UPPER", true),
+			PasswordRequireLower:  getEnvAsBool("PASSWORD_REQUIRE_LOWER", true),
+			PasswordRequireDigit:  getEnvAsBool("PASSWORD_REQUIRE_DIGIT", true),
+			PasswordRequireSymbol: getEnvAsBool("PASSWORD_REQUIRE_SYMBOL", false),
+
+			LoginLite: getEnvAsBool("LOGIN_LITE", false),
+		},
+		Campus: CampusConfig{
+			Timezone:     getEnv("CAMPUS_TIMEZONE", "UTC"),
+			PhonePattern: getEnv("PHONE_PATTERN", ""),
+			Holidays:     getEnvAsStringMap("CAMPUS_HOLIDAYS", map[string]string{}),
+		},
+		Leave: LeaveConfig{
+			AllowedTypes:   getEnvAsSlice("LEAVE_TYPES", []string{"medical", "personal", "emergency", "academic"}),
+			MinNoticeDays:  getEnvAsIntMap("LEAVE_MIN_NOTICE_DAYS", map[string]int{}),
+			OverlapAllowed: getEnvAsListMap("LEAVE_OVERLAP_ALLOWED", map[string][]string{}),
+
+			StaleAction:               getEnv("LEAVE_STALE_ACTION", "reject"),
+			StaleGraceDays:            getEnvAsInt("LEAVE_STALE_GRACE_DAYS", 0),
+			StaleCheckIntervalMinutes: getEnvAsInt("LEAVE_STALE_CHECK_INTERVAL_MINUTES", 60),
+
+			ApprovalDeadlineHours:          getEnvAsInt("LEAVE_APPROVAL_DEADLINE_HOURS", 48),
+			EscalationCheckIntervalMinutes: getEnvAsInt("LEAVE_ESCALATION_CHECK_INTERVAL_MINUTES", 60),
+
+			ApplyOnBehalf:     getEnvAsBool("LEAVE_APPLY_ON_BEHALF", true),
+			AllowSelfApproval: getEnvAsBool("LEAVE_ALLOW_SELF_APPROVAL", false),
+		},
+		Attendance: AttendanceConfig{
+			LowThreshold:   getEnvAsInt("ATTENDANCE_LOW_THRESHOLD", 75),
+			DeptThresholds: getEnvAsIntMap("ATTENDANCE_DEPT_THRESHOLDS", map[string]int{}),
+
+			LeaveAbsenceIntervalMinutes: getEnvAsInt("ATTENDANCE_LEAVE_ABSENCE_INTERVAL_MINUTES", 60),
+			BackdateDays:                getEnvAsInt("ATTENDANCE_BACKDATE_DAYS", 7),
+
+			SummaryMaxAgeMinutes:            getEnvAsInt("ATTENDANCE_SUMMARY_MAX_AGE_MINUTES", 1440),
+			SummaryRecomputeIntervalMinutes: getEnvAsInt("ATTENDANCE_SUMMARY_RECOMPUTE_INTERVAL_MINUTES", 1440),
+
+			AtRiskMinLeaves:        getEnvAsInt("AT_RISK_MIN_LEAVES", 3),
+			AtRiskAttendanceWeight: getEnvAsInt("AT_RISK_ATTENDANCE_WEIGHT", 70),
+			AtRiskLeaveWeight:      getEnvAsInt("AT_RISK_LEAVE_WEIGHT", 30),
+		},
+		Pagination: PaginationConfig{
+			DefaultPageSize: getEnvAsInt("DEFAULT_PAGE_SIZE", 10),
+			MaxPageSize:     getEnvAsInt("MAX_PAGE_SIZE", 100),
 		},
 		Email: EmailConfig{
 			SMTPHost:     getEnv("SMTP_HOST", "smtp.gmail.com"),
@@ -68,8 +286,115 @@ func LoadConfig() *Config {
 			SMTPUsername: getEnv("SMTP_USERNAME", ""),
 			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
 			FromEmail:    getEnv("FROM_EMAIL", "noreply@campus.edu"),
+			TemplateDir:  getEnv("EMAIL_TEMPLATE_DIR", ""),
+		},
+		SMS: SMSConfig{
+			Provider:         getEnv("SMS_PROVIDER", "log"),
+			TwilioAccountSID: getEnv("TWILIO_ACCOUNT_SID", ""),
+			TwilioAuthToken:  getEnv("TWILIO_AUTH_TOKEN", ""),
+			FromNumber:       getEnv("SMS_FROM_NUMBER", ""),
 		},
+		Retention: RetentionConfig{
+			Months:               getEnvAsInt("RETENTION_MONTHS", 0),
+			ActiveTermStart:      getEnv("RETENTION_ACTIVE_TERM_START", ""),
+			CheckIntervalMinutes: getEnvAsInt("RETENTION_CHECK_INTERVAL_MINUTES", 1440),
+		},
+	}
+
+	// bcrypt rejects costs outside its legal range
+	if config.Auth.BcryptCost < bcrypt.MinCost || config.Auth.BcryptCost > bcrypt.MaxCost {
+		log.Printf("Invalid BCRYPT_COST %d (must be %d-%d), using default: 12", config.Auth.BcryptCost, bcrypt.MinCost, bcrypt.MaxCost)
+		config.Auth.BcryptCost = 12
 	}
+	// bcrypt only reads the first 72 bytes, so longer minimums could never be met meaningfully
+	if config.Auth.PasswordMinLength < 6 || config.Auth.PasswordMinLength > 72 {
+		log.Printf("Invalid PASSWORD_MIN_LENGTH %d (must be 6-72), using default: 8", config.Auth.PasswordMinLength)
+		config.Auth.PasswordMinLength = 8
+	}
+
+	for date := range config.Campus.Holidays {
+		if _, err := time.Parse(timeutil.DateLayout, date); err != nil {
+			log.Printf("Invalid CAMPUS_HOLIDAYS date %q (expected YYYY-MM-DD), ignoring it", date)
+			delete(config.Campus.Holidays, date)
+		}
+	}
+
+	if config.Leave.StaleAction != "reject" && config.Leave.StaleAction != "flag" {
+		log.Printf("Invalid LEAVE_STALE_ACTION %q (must be reject or flag), using default: reject", config.Leave.StaleAction)
+		config.Leave.StaleAction = "reject"
+	}
+	if config.Leave.StaleGraceDays < 0 {
+		config.Leave.StaleGraceDays = 0
+	}
+	if config.Leave.ApprovalDeadlineHours < 0 {
+		config.Leave.ApprovalDeadlineHours = 0
+	}
+
+	if config.Retention.Months < 0 {
+		config.Retention.Months = 0
+	}
+
+	if config.SMS.Provider != "log" && config.SMS.Provider != "twilio" {
+		log.Printf("Invalid SMS_PROVIDER %q (must be log or twilio), using default: log", config.SMS.Provider)
+		config.SMS.Provider = "log"
+	}
+
+	if config.Attendance.LowThreshold < 0 || config.Attendance.LowThreshold > 100 {
+		log.Printf("Invalid ATTENDANCE_LOW_THRESHOLD %d (must be 0-100), using default: 75", config.Attendance.LowThreshold)
+		config.Attendance.LowThreshold = 75
+	}
+	if config.Attendance.BackdateDays < 0 {
+		config.Attendance.BackdateDays = 0
+	}
+	if config.Attendance.AtRiskMinLeaves < 1 {
+		config.Attendance.AtRiskMinLeaves = 1
+	}
+	if config.Attendance.AtRiskAttendanceWeight < 0 || config.Attendance.AtRiskLeaveWeight < 0 ||
+		config.Attendance.AtRiskAttendanceWeight+config.Attendance.AtRiskLeaveWeight == 0 {
+		log.Printf("Invalid AT_RISK_ATTENDANCE_WEIGHT/AT_RISK_LEAVE_WEIGHT %d/%d (must be non-negative, not both 0), using defaults: 70/30",
+			config.Attendance.AtRiskAttendanceWeight, config.Attendance.AtRiskLeaveWeight)
+		config.Attendance.AtRiskAttendanceWeight, config.Attendance.AtRiskLeaveWeight = 70, 30
+	}
+
+	if config.JWT.ExpiryHours < 1 {
+		config.JWT.ExpiryHours = 24
+	}
+	if config.JWT.ImpersonationMinutes < 1 {
+		config.JWT.ImpersonationMinutes = 30
+	}
+
+	// Keep page sizes usable even if misconfigured
+	if config.Pagination.MaxPageSize < 1 {
+		config.Pagination.MaxPageSize = 100
+	}
+	if config.Pagination.DefaultPageSize < 1 {
+		config.Pagination.DefaultPageSize = 10
+	}
+	if config.Pagination.DefaultPageSize > config.Pagination.MaxPageSize {
+		config.Pagination.DefaultPageSize = config.Pagination.MaxPageSize
+	}
+
+	SetConfig(config)
+	return config
+}
+
+// GetConfig returns the loaded configuration, loading it from the environment on first use
+func GetConfig() *Config {
+	configMu.RLock()
+	config := AppConfig
+	configMu.RUnlock()
+	if config == nil {
+		return LoadConfig()
+	}
+	return config
+}
+
+// SetConfig swaps in a new configuration. Callers must not modify a Config after passing it here,
+// since requests may be reading it; copy it instead.
+func SetConfig(config *Config) {
+	configMu.Lock()
+	AppConfig = config
+	configMu.Unlock()
 }
 
 // getEnv gets environment variable with default value
@@ -90,3 +415,100 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvAsBool gets environment variable as boolean with default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+		log.Printf("Invalid boolean value for %s: %s, using default: %t", key, value, defaultValue)
+	}
+	return defaultValue
+}
+
+// getEnvAsSlice parses a comma-separated list with default value
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	if len(result) == 0 {
+		return defaultValue
+	}
+	return result
+}
+
+// getEnvAsIntMap parses "key=value,key=value" into a map of integers with default value
+func getEnvAsIntMap(key string, defaultValue map[string]int) map[string]int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			log.Printf("Invalid entry %q for %s, expected key=value", pair, key)
+			continue
+		}
+		intValue, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			log.Printf("Invalid integer value %q for %s, skipping", parts[1], key)
+			continue
+		}
+		result[strings.TrimSpace(parts[0])] = intValue
+	}
+	return result
+}
+
+// getEnvAsStringMap parses "key=value,key=value" into a map with default value
+func getEnvAsStringMap(key string, defaultValue map[string]string) map[string]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+			log.Printf("Invalid entry %q for %s, expected key=value", pair, key)
+			continue
+		}
+		result[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return result
+}
+
+// getEnvAsListMap parses "key=a|b,key=c" into a map of string lists with default value
+func getEnvAsListMap(key string, defaultValue map[string][]string) map[string][]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	result := make(map[string][]string)
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			log.Printf("Invalid entry %q for %s, expected key=a|b", pair, key)
+			continue
+		}
+		name := strings.TrimSpace(parts[0])
+		for _, item := range strings.Split(parts[1], "|") {
+			if item = strings.TrimSpace(item); item != "" {
+				result[name] = append(result[name], item)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CursorPagination describes a page fetched by keyset: pass NextCursor back as ?cursor= for the next one
+type CursorPagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasNext    bool   `json:"has_next"`
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a position in a list ordered by a timestamp then ID, both descending
+type Cursor struct {
+	Time time.Time
+	ID   uint
+}
+
+// Encode returns an opaque token for the cursor
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.Time.UnixNano(), 10) + ":" + strconv.FormatUint(uint64(c.ID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a token produced by Cursor.Encode
+func DecodeCursor(token string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, errInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return Cursor{}, errInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return Cursor{}, errInvalidCursor
+	}
+	i, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return Cursor{}, errInvalidCursor
+	}
+	return Cursor{Time: time.Unix(0, n).UTC(), ID: uint(i)}, nil
+}
+
+// CursorParam reports whether the request asked for keyset pagination with ?cursor= (empty for
+// the first page) and decodes the position to continue after
+func CursorParam(c *gin.Context) (after *Cursor, requested bool, err error) {
+	value, requested := c.GetQuery("cursor")
+	if !requested || value == "" {
+		return nil, requested, nil
+	}
+	cursor, err := DecodeCursor(value)
+	if err != nil {
+		return nil, true, err
+	}
+	return &cursor, true, nil
+}
@@ -0,0 +1,26 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValidateGinMode rejects anything gin.SetMode would not recognize. gin panics on an
+// unknown mode, so this lets startup fail with a readable message instead.
+func ValidateGinMode(mode string) error {
+	switch mode {
+	case gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+		return nil
+	}
+	return fmt.Errorf("must be %s, %s or %s, got %q", gin.DebugMode, gin.ReleaseMode, gin.TestMode, mode)
+}
+
+// PublicError returns the message to put in an error response. Raw binding and database
+// errors name internal types, columns and queries, so release mode only shows fallback.
+func PublicError(err error, fallback string) string {
+	if gin.Mode() == gin.ReleaseMode {
+		return fallback
+	}
+	return err.Error()
+}
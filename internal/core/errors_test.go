@@ -0,0 +1,36 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateGinMode(t *testing.T) {
+	for _, mode := range []string{"debug", "release", "test"} {
+		assert.NoError(t, ValidateGinMode(mode), mode)
+	}
+	for _, mode := range []string{"", "production", "Release"} {
+		assert.Error(t, ValidateGinMode(mode), mode)
+	}
+}
+
+func TestPublicErrorHidesDetailsInReleaseMode(t *testing.T) {
+	defer gin.SetMode(gin.Mode())
+	err := errors.New("Key: 'ApplyLeaveRequest.Reason' Error:Field validation for 'Reason' failed on the 'required' tag")
+
+	gin.SetMode(gin.DebugMode)
+	assert.Equal(t, err.Error(), PublicError(err, "Invalid request body"))
+
+	gin.SetMode(gin.ReleaseMode)
+	assert.Equal(t, "Invalid request body", PublicError(err, "Invalid request body"))
+}
+
+func TestDefaultGinModeIsRelease(t *testing.T) {
+	t.Setenv("GIN_MODE", "")
+	defer func() { AppConfig = nil }()
+
+	assert.Equal(t, gin.ReleaseMode, LoadConfig().Server.GinMode)
+}
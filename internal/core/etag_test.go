@@ -0,0 +1,56 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONWithETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	body := gin.H{"id": 1, "name": "Asha"}
+
+	serve := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		if ifNoneMatch != "" {
+			c.Request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		JSONWithETag(c, body)
+		c.Writer.WriteHeaderNow()
+		return w
+	}
+
+	first := serve("")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.JSONEq(t, `{"id":1,"name":"Asha"}`, first.Body.String())
+	etag := first.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	cases := []struct {
+		ifNoneMatch string
+		wantStatus  int
+	}{
+		{etag, http.StatusNotModified},
+		{"W/" + etag, http.StatusNotModified},
+		{`"other", ` + etag, http.StatusNotModified},
+		{"*", http.StatusNotModified},
+		{`"other"`, http.StatusOK},
+	}
+	for _, tc := range cases {
+		w := serve(tc.ifNoneMatch)
+		assert.Equal(t, tc.wantStatus, w.Code, tc.ifNoneMatch)
+		assert.Equal(t, etag, w.Header().Get("ETag"), tc.ifNoneMatch)
+		if tc.wantStatus == http.StatusNotModified {
+			assert.Empty(t, w.Body.String(), tc.ifNoneMatch)
+		}
+	}
+
+	// Any change to the body changes the ETag
+	body["name"] = "Asha Rao"
+	assert.NotEqual(t, etag, serve("").Header().Get("ETag"))
+}
@@ -0,0 +1,19 @@
+package core
+
+import (
+	"campus-backend/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LanguageKey is the context key for the authenticated user's saved language, set by the auth middleware
+const LanguageKey = "language"
+
+// Language returns the language to respond in: the user's saved preference if any,
+// otherwise the best supported match for the Accept-Language header
+func Language(c *gin.Context) string {
+	if lang := c.GetString(LanguageKey); i18n.Supported(lang) {
+		return lang
+	}
+	return i18n.Match(c.GetHeader("Accept-Language"))
+}
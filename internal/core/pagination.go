@@ -20,18 +20,20 @@ type Pagination struct {
 
 // PaginationParams extracts pagination parameters from request
 func PaginationParams(c *gin.Context) (page, limit int) {
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "10")
+	cfg := GetConfig().Pagination
 
-	page, _ = strconv.Atoi(pageStr)
-	limit, _ = strconv.Atoi(limitStr)
+	page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, err := strconv.Atoi(c.Query("limit"))
 
-	// Set defaults and limits
+	// Set defaults and clamp oversized limits to the maximum
 	if page < 1 {
 		page = 1
 	}
-	if limit < 1 || limit > 100 {
-		limit = 10
+	if err != nil || limit < 1 {
+		limit = cfg.DefaultPageSize
+	}
+	if limit > cfg.MaxPageSize {
+		limit = cfg.MaxPageSize
 	}
 
 	return page, limit
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginationParamsUsesConfiguredLimits(t *testing.T) {
+	t.Setenv("DEFAULT_PAGE_SIZE", "15")
+	t.Setenv("MAX_PAGE_SIZE", "40")
+	LoadConfig()
+	defer func() { AppConfig = nil }()
+
+	cases := []struct {
+		query     string
+		wantPage  int
+		wantLimit int
+	}{
+		{"", 1, 15},
+		{"?page=2&limit=25", 2, 25},
+		{"?limit=500", 1, 40}, // clamped, not rejected
+		{"?page=0&limit=0", 1, 15},
+		{"?limit=abc", 1, 15},
+	}
+
+	for _, tc := range cases {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/"+tc.query, nil)
+
+		page, limit := PaginationParams(c)
+		assert.Equal(t, tc.wantPage, page, tc.query)
+		assert.Equal(t, tc.wantLimit, limit, tc.query)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{Time: time.Date(2026, 9, 1, 18, 30, 0, 0, time.UTC), ID: 42}
+	decoded, err := DecodeCursor(cursor.Encode())
+	assert.NoError(t, err)
+	assert.True(t, cursor.Time.Equal(decoded.Time))
+	assert.Equal(t, uint(42), decoded.ID)
+
+	for _, token := range []string{"", "!!", "bm9jb2xvbg", "YWJjOjE"} {
+		_, err := DecodeCursor(token)
+		assert.Error(t, err, token)
+	}
+}
@@ -0,0 +1,12 @@
+package core
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// ApplyTrustedProxies sets which proxies gin takes X-Forwarded-For and X-Real-IP from when
+// working out ClientIP. With none, the header is ignored and the connection's peer address is
+// used, since a client could otherwise claim any IP it likes.
+func ApplyTrustedProxies(r *gin.Engine, proxies []string) error {
+	return r.SetTrustedProxies(proxies) // gin.Default trusts every proxy until this is called
+}
@@ -0,0 +1,34 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	clientIP := func(proxies []string, remoteAddr string) string {
+		r := gin.New()
+		assert.NoError(t, ApplyTrustedProxies(r, proxies))
+		r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	// Nothing is trusted by default, so the header cannot spoof the address
+	assert.Equal(t, "10.0.0.5", clientIP(nil, "10.0.0.5:4000"))
+
+	assert.Equal(t, "203.0.113.7", clientIP([]string{"10.0.0.0/8"}, "10.0.0.5:4000"))
+	assert.Equal(t, "198.51.100.9", clientIP([]string{"10.0.0.0/8"}, "198.51.100.9:4000"))
+
+	assert.Error(t, ApplyTrustedProxies(gin.New(), []string{"not-an-ip"}))
+}
@@ -0,0 +1,190 @@
+package core
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+const mimeCSV = "text/csv"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Render writes a 200 response as JSON, or as CSV when the client sends ?format=csv or
+// prefers text/csv in its Accept header. The CSV has one row per element of data[rowsKey],
+// a slice of structs whose scalar fields become columns named after their JSON keys.
+func Render(c *gin.Context, data gin.H, rowsKey string) {
+	if !wantsCSV(c) {
+		c.JSON(http.StatusOK, data)
+		return
+	}
+
+	header, records, err := csvRecords(data[rowsKey])
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode CSV"})
+		return
+	}
+
+	// CSV has nowhere to carry pagination, so it goes in headers
+	switch pagination := data["pagination"].(type) {
+	case Pagination:
+		c.Header("X-Total-Count", strconv.FormatInt(pagination.Total, 10))
+		c.Header("X-Total-Pages", strconv.Itoa(pagination.TotalPages))
+	case CursorPagination:
+		if pagination.NextCursor != "" {
+			c.Header("X-Next-Cursor", pagination.NextCursor)
+		}
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+rowsKey+`.csv"`)
+	c.Header("Content-Type", mimeCSV+"; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write(header)
+	w.WriteAll(records)
+}
+
+// wantsCSV reports whether the request asks for CSV; ?format= overrides the Accept header
+func wantsCSV(c *gin.Context) bool {
+	switch strings.ToLower(c.Query("format")) {
+	case "csv":
+		return true
+	case "json":
+		return false
+	}
+	return c.NegotiateFormat(gin.MIMEJSON, mimeCSV) == mimeCSV
+}
+
+// csvRecords flattens a slice of structs (or struct pointers) into a header and rows.
+// Nested structs, slices and maps are left out; times are written as RFC 3339 and text that
+// would start a spreadsheet formula is quoted.
+func csvRecords(rows interface{}) ([]string, [][]string, error) {
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return nil, nil, fmt.Errorf("csv rows must be a slice, got %T", rows)
+	}
+
+	elem := v.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("csv rows must be structs, got %s", elem)
+	}
+
+	type column struct {
+		name  string
+		index []int
+	}
+	var columns []column
+	for _, field := range reflect.VisibleFields(elem) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		// Skip fields shadowed by an outer field of the same name
+		if outer, _ := elem.FieldByName(field.Name); !slices.Equal(outer.Index, field.Index) {
+			continue
+		}
+		if !isScalar(field.Type) {
+			continue
+		}
+
+		name := field.Tag.Get("json")
+		name, _, _ = strings.Cut(name, ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = snakeCase(field.Name)
+		}
+		columns = append(columns, column{name: name, index: field.Index})
+	}
+
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.name
+	}
+
+	records := make([][]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		row := reflect.Indirect(v.Index(i))
+		record := make([]string, len(columns))
+		if row.IsValid() {
+			for j, col := range columns {
+				field, err := row.FieldByIndexErr(col.index)
+				if err == nil {
+					record[j] = formatCell(field)
+				}
+			}
+		}
+		records = append(records, record)
+	}
+	return header, records, nil
+}
+
+// isScalar reports whether a field type fits in a single CSV cell
+func isScalar(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return t == timeType
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+		return false
+	}
+	return true
+}
+
+func formatCell(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	if v.Kind() == reflect.String {
+		return escapeFormula(v.String())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// escapeFormula prefixes text that a spreadsheet would run as a formula with a quote, so a name
+// such as =HYPERLINK(...) is shown as typed. Numbers are written as they are.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// snakeCase converts a Go field name such as CreatedAt or ID to created_at or id
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			startsWord := i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])))
+			if startsWord {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type renderBase struct {
+	ID        uint
+	CreatedAt time.Time
+}
+
+type renderRow struct {
+	renderBase
+	Name      string     `json:"name"`
+	Password  string     `json:"-"`
+	Hostel    *string    `json:"hostel,omitempty"`
+	Tags      []string   `json:"tags"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"` // Shadows renderBase.CreatedAt
+	Parent    *renderRow `json:"parent,omitempty"`
+}
+
+func TestRender(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hostel := "Hostel A"
+	created := time.Date(2026, 9, 1, 10, 30, 0, 0, time.UTC)
+	rows := []renderRow{
+		{renderBase: renderBase{ID: 1}, Name: "Asha, R", Password: "secret", Hostel: &hostel, Tags: []string{"x"}, Active: true, CreatedAt: created},
+		{renderBase: renderBase{ID: 2}, Name: "Vikram"},
+	}
+	data := gin.H{"users": rows, "pagination": CalculatePagination(1, 10, 2)}
+
+	serve := func(target, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", target, nil)
+		if accept != "" {
+			c.Request.Header.Set("Accept", accept)
+		}
+		Render(c, data, "users")
+		return w
+	}
+
+	for _, accept := range []string{"", "*/*", "application/json"} {
+		w := serve("/", accept)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	}
+	assert.Contains(t, serve("/?format=json", "text/csv").Header().Get("Content-Type"), "application/json")
+
+	want := "id,name,hostel,active,created_at\n" +
+		"1,\"Asha, R\",Hostel A,true,2026-09-01T10:30:00Z\n" +
+		"2,Vikram,,false,\n"
+	for _, w := range []*httptest.ResponseRecorder{serve("/?format=csv", ""), serve("/", "text/csv"), serve("/", "text/csv, application/json;q=0.5")} {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/csv")
+		assert.Equal(t, "2", w.Header().Get("X-Total-Count"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "users.csv")
+		assert.Equal(t, want, w.Body.String())
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, "id", snakeCase("ID"))
+	assert.Equal(t, "created_at", snakeCase("CreatedAt"))
+	assert.Equal(t, "student_id", snakeCase("StudentID"))
+	assert.Equal(t, "http_status", snakeCase("HTTPStatus"))
+}
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	type row struct {
+		Name  string `json:"name"`
+		Delta int    `json:"delta"`
+	}
+	_, records, err := csvRecords([]row{{"=HYPERLINK(\"http://x\")", -5}, {"+91 98765", 0}, {"-1", 0}, {"@SUM(A1)", 0}, {"\tTab", 0}, {"Asha = Roy", 0}})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"'=HYPERLINK(\"http://x\")", "-5"}, // Numbers are not quoted
+		{"'+91 98765", "0"},
+		{"'-1", "0"},
+		{"'@SUM(A1)", "0"},
+		{"'\tTab", "0"},
+		{"Asha = Roy", "0"},
+	}, records)
+}
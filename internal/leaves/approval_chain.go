@@ -0,0 +1,102 @@
+package leaves
+
+import (
+	"campus-backend/internal/auth"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApprovalStep is one group of people who can decide on a student's leaves
+type ApprovalStep struct {
+	Step      int                `json:"step"`
+	Role      string             `json:"role"`
+	Scope     string             `json:"scope"` // The hostel or department the role covers, or "all" for admins
+	Approvers []PipelineApprover `json:"approvers"`
+}
+
+// GetApprovalChain godoc
+// @Summary Get who approves a student's leaves
+// @Description Ordered approvers for a student's leave under the current routing rules: the warden of their hostel, then the faculty of their department, with admins as the fallback who can decide on any leave. Any one approver can decide. Students can only look up themselves.
+// @Tags Leaves
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Student ID"
+// @Success 200 {object} map[string]interface{} "Approval chain"
+// @Failure 400 {object} map[string]interface{} "Invalid student ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Student not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /students/{id}/approval-chain [get]
+func GetApprovalChain(c *gin.Context) {
+	studentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	role, _ := auth.CurrentRole(c)
+	if role == users.RoleStudent {
+		userIDVal, _ := c.Get("userID")
+		if userIDVal.(uint) != uint(studentID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own approval chain"})
+			return
+		}
+	}
+
+	var student User
+	if err := db.DB.Where("role = ?", users.RoleStudent).First(&student, studentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return
+	}
+
+	// Leaves are routed on the department and hostel they are filed with, which come from the student
+	byDept, byHostel, err := pipelineApprovers([]LeaveRequest{{Dept: student.Dept, Hostel: student.Hostel}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get approvers"})
+		return
+	}
+
+	var admins []User
+	if err := db.DB.Where("role = ? AND is_active = ?", users.RoleAdmin, true).Order("name ASC").Find(&admins).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get approvers"})
+		return
+	}
+
+	chain := []ApprovalStep{}
+	addStep := func(role, scope string, approvers []PipelineApprover) {
+		if approvers == nil {
+			approvers = []PipelineApprover{}
+		}
+		chain = append(chain, ApprovalStep{Step: len(chain) + 1, Role: role, Scope: scope, Approvers: approvers})
+	}
+	if student.Hostel != nil && *student.Hostel != "" {
+		addStep(users.RoleWarden, *student.Hostel, byHostel[*student.Hostel])
+	}
+	addStep(users.RoleFaculty, student.Dept, byDept[student.Dept])
+
+	adminApprovers := make([]PipelineApprover, 0, len(admins))
+	for _, admin := range admins {
+		adminApprovers = append(adminApprovers, PipelineApprover{ID: admin.ID, Name: admin.Name, Email: admin.Email, Role: admin.Role})
+	}
+	addStep(users.RoleAdmin, "all", adminApprovers)
+
+	// Without a warden or faculty member in scope only an admin can act, as in the pipeline's unroutable stage
+	routable := len(byDept[student.Dept]) > 0
+	if student.Hostel != nil {
+		routable = routable || len(byHostel[*student.Hostel]) > 0
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"student_id":   student.ID,
+		"student_name": student.Name,
+		"dept":         student.Dept,
+		"hostel":       student.Hostel,
+		"routable":     routable,
+		"chain":        chain,
+	})
+}
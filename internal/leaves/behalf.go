@@ -0,0 +1,73 @@
+package leaves
+
+import (
+	"campus-backend/internal/auth"
+	"campus-backend/internal/core"
+	"campus-backend/internal/notifications"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApplyLeaveOnBehalf godoc
+// @Summary Apply for leave on a student's behalf
+// @Description A warden files a leave for a student of their hostel, or an admin for any student, e.g. one without device access. The application gets the same validation and overlap checks as the student's own, records the staff member in applied_by, and the student is notified. Disabled with LEAVE_APPLY_ON_BEHALF=false.
+// @Tags Leaves
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param studentId path int true "Student ID"
+// @Param request body ApplyLeaveRequest true "Leave application data"
+// @Success 201 {object} map[string]interface{} "Leave request submitted successfully"
+// @Failure 400 {object} map[string]interface{} "Validation failed or overlapping leave"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Disabled, or the student is outside your hostel"
+// @Failure 404 {object} map[string]interface{} "Student not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /leaves/apply-for/{studentId} [post]
+func ApplyLeaveOnBehalf(c *gin.Context) {
+	if !core.GetConfig().Leave.ApplyOnBehalf {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Applying for leave on a student's behalf is disabled"})
+		return
+	}
+
+	staff, err := auth.CurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
+		return
+	}
+
+	var student users.User
+	if err := db.DB.Where("role = ? AND is_active = ?", users.RoleStudent, true).First(&student, c.Param("studentId")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return
+	}
+	if staff.Role == users.RoleWarden {
+		if staff.Hostel == nil || student.Hostel == nil || *staff.Hostel != *student.Hostel {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only apply for students of your hostel"})
+			return
+		}
+	}
+
+	input, ok := bindApplyLeave(c)
+	if !ok {
+		return
+	}
+	leave, ok := fileLeave(c, &student, &staff.ID, input)
+	if !ok {
+		return
+	}
+
+	userLeaveRequest := notificationLeave(leave)
+	if err := notifications.NotifyLeaveFiledForStudent(&userLeaveRequest, staff.Name); err != nil {
+		log.Printf("Failed to notify student %d about leave %d filed for them: %v", student.ID, leave.ID, err)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":       "Leave request submitted on the student's behalf",
+		"leave_request": appliedLeave(leave),
+	})
+}
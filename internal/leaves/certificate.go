@@ -0,0 +1,134 @@
+package leaves
+
+import (
+	"bytes"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-pdf/fpdf"
+)
+
+// GetLeaveCertificate godoc
+// @Summary Download leave approval certificate
+// @Description Download a printable PDF confirming an approved leave. Available to the student and to anyone who can view the leave.
+// @Tags Leaves
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Leave request ID"
+// @Success 200 {file} file "Leave certificate PDF"
+// @Failure 400 {object} map[string]interface{} "Leave is not approved"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Leave request not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /leaves/{id}/certificate [get]
+func GetLeaveCertificate(c *gin.Context) {
+	leaveID := c.Param("id")
+
+	var leave LeaveRequest
+	if err := db.DB.Preload("Student").Preload("Approver").First(&leave, leaveID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Leave request not found"})
+		return
+	}
+
+	if !checkLeaveAccess(c, &leave) {
+		return
+	}
+
+	if leave.Status != "approved" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Certificates are only available for approved leaves"})
+		return
+	}
+
+	// Use the latest approval event; fall back to the last update for leaves approved before history existed
+	approvedAt := leave.UpdatedAt
+	var event LeaveEvent
+	if err := db.DB.Where("leave_id = ? AND to_status = ?", leave.ID, "approved").Order("created_at DESC, id DESC").First(&event).Error; err == nil {
+		approvedAt = event.CreatedAt
+	}
+
+	pdf, err := renderLeaveCertificate(&leave, approvedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate certificate"})
+		return
+	}
+
+	filename := fmt.Sprintf("leave-%d-certificate.pdf", leave.ID)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Content-Length", strconv.Itoa(len(pdf)))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// renderLeaveCertificate lays out a one-page A4 certificate for an approved leave
+func renderLeaveCertificate(leave *LeaveRequest, approvedAt time.Time) ([]byte, error) {
+	loc := timeutil.Location()
+	date := func(t time.Time) string { return t.In(loc).Format("02 Jan 2006") }
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("") // Core fonts are cp1252
+	pdf.SetTitle(fmt.Sprintf("Leave Certificate #%d", leave.ID), true)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 12, "Campus Management System", "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 14)
+	pdf.CellFormat(0, 10, "Leave Approval Certificate", "", 1, "C", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.MultiCell(0, 6, tr("This is to certify that the leave request below has been approved."), "", "L", false)
+	pdf.Ln(4)
+
+	approver := "-"
+	if leave.Approver != nil {
+		approver = fmt.Sprintf("%s (%s)", leave.Approver.Name, leave.Approver.Role)
+	}
+	studentNumber := "-"
+	if leave.Student.StudentID != nil {
+		studentNumber = *leave.Student.StudentID
+	}
+	hostel := "-"
+	if leave.Hostel != nil {
+		hostel = *leave.Hostel
+	}
+
+	rows := [][2]string{
+		{"Certificate No.", fmt.Sprintf("LV-%06d", leave.ID)},
+		{"Student", leave.Student.Name},
+		{"Student ID", studentNumber},
+		{"Department", leave.Dept},
+		{"Hostel", hostel},
+		{"Leave Type", leave.LeaveType},
+		{"From", date(leave.StartDate)},
+		{"To", date(leave.EndDate)},
+		{"Days", strconv.Itoa(leave.Days)},
+		{"Reason", leave.Reason},
+		{"Approved By", approver},
+		{"Approved On", date(approvedAt)},
+	}
+	if leave.Remarks != nil && *leave.Remarks != "" {
+		rows = append(rows, [2]string{"Remarks", *leave.Remarks})
+	}
+
+	for _, row := range rows {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(45, 8, row[0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.MultiCell(0, 8, tr(row[1]), "", "L", false)
+	}
+
+	pdf.Ln(10)
+	pdf.SetFont("Helvetica", "I", 9)
+	pdf.MultiCell(0, 5, "Generated on "+date(timeutil.Now())+". Verify with the campus office using the certificate number.", "", "L", false)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
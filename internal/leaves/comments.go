@@ -0,0 +1,172 @@
+package leaves
+
+import (
+	"campus-backend/internal/auth"
+	"campus-backend/internal/core"
+	"campus-backend/internal/notifications"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/validation"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AddLeaveCommentRequest struct {
+	Body string `json:"body" binding:"required" validate:"required,min=1,max=1000"`
+}
+
+// ListLeaveComments godoc
+// @Summary List comments on a leave request
+// @Description Get the discussion thread of a leave request, oldest first. Anyone who can view the leave can read it.
+// @Tags Leaves
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Leave request ID"
+// @Success 200 {object} map[string]interface{} "Comments"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Leave request not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /leaves/{id}/comments [get]
+func ListLeaveComments(c *gin.Context) {
+	var leave LeaveRequest
+	if err := db.DB.First(&leave, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Leave request not found"})
+		return
+	}
+
+	if !checkLeaveAccess(c, &leave) {
+		return
+	}
+
+	var comments []LeaveComment
+	if err := db.DB.Preload("Author").Where("leave_id = ?", leave.ID).Order("created_at ASC, id ASC").Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"leave_id": leave.ID,
+		"comments": comments,
+	})
+}
+
+// AddLeaveComment godoc
+// @Summary Comment on a leave request
+// @Description Add a comment to a leave's thread. Anyone who can view the leave can comment. A staff comment notifies the student; a student comment notifies the staff already involved, or the leave's approvers if no one is yet.
+// @Tags Leaves
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Leave request ID"
+// @Param request body AddLeaveCommentRequest true "Comment text (up to 1000 characters)"
+// @Success 201 {object} LeaveComment "Comment added"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Leave request not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /leaves/{id}/comments [post]
+func AddLeaveComment(c *gin.Context) {
+	var input AddLeaveCommentRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+
+	// A comment of only whitespace is treated as empty
+	input.Body = strings.TrimSpace(input.Body)
+	if err := validation.ValidateStruct(input); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+
+	var leave LeaveRequest
+	if err := db.DB.First(&leave, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Leave request not found"})
+		return
+	}
+
+	if !checkLeaveAccess(c, &leave) {
+		return
+	}
+
+	author, err := auth.CurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
+		return
+	}
+
+	comment := LeaveComment{LeaveID: leave.ID, AuthorID: author.ID, Body: input.Body}
+	if err := db.DB.Create(&comment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add comment"})
+		return
+	}
+	db.DB.Preload("Author").First(&comment, comment.ID)
+
+	recipients, err := commentRecipients(&leave, author.ID)
+	if err == nil {
+		err = notifications.NotifyLeaveComment(recipients, leave.ID, author.Name, comment.Body)
+	}
+	if err != nil {
+		// Log error but don't fail the request
+		log.Printf("Failed to notify about comment on leave %d: %v", leave.ID, err)
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// commentRecipients returns who should hear about a new comment on the leave. Staff comments go
+// to the student. Student comments go to staff who have commented on or acted on the leave, falling
+// back to the faculty and wardens who can approve it.
+func commentRecipients(leave *LeaveRequest, authorID uint) ([]uint, error) {
+	if authorID != leave.StudentID {
+		return []uint{leave.StudentID}, nil
+	}
+
+	var involved []uint
+	err := db.DB.Model(&LeaveComment{}).Where("leave_id = ? AND author_id <> ?", leave.ID, authorID).
+		Distinct().Pluck("author_id", &involved).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var actors []uint
+	err = db.DB.Model(&LeaveEvent{}).Where("leave_id = ? AND actor_id IS NOT NULL AND actor_id <> ?", leave.ID, authorID).
+		Distinct().Pluck("actor_id", &actors).Error
+	if err != nil {
+		return nil, err
+	}
+	involved = append(involved, actors...)
+	if leave.ApprovedBy != nil {
+		involved = append(involved, *leave.ApprovedBy)
+	}
+
+	if len(involved) == 0 {
+		byDept, byHostel, err := pipelineApprovers([]LeaveRequest{*leave})
+		if err != nil {
+			return nil, err
+		}
+		approvers := byDept[leave.Dept]
+		if leave.Hostel != nil {
+			approvers = append(approvers, byHostel[*leave.Hostel]...)
+		}
+		for _, approver := range approvers {
+			involved = append(involved, approver.ID)
+		}
+	}
+
+	// The same person may have both commented and acted
+	seen := make(map[uint]bool, len(involved))
+	recipients := make([]uint, 0, len(involved))
+	for _, id := range involved {
+		if !seen[id] {
+			seen[id] = true
+			recipients = append(recipients, id)
+		}
+	}
+	return recipients, nil
+}
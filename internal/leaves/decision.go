@@ -0,0 +1,81 @@
+package leaves
+
+import (
+	"campus-backend/pkg/db"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LeaveDecision is the outcome of a leave: who decided it, when, and what they said
+type LeaveDecision struct {
+	LeaveID      uint       `json:"leave_id"`
+	Status       string     `json:"status"`
+	Decided      bool       `json:"decided"` // False while the leave is pending; needs_info counts as decided
+	DecidedBy    *uint      `json:"decided_by,omitempty"`
+	ApproverName string     `json:"approver_name,omitempty"`
+	Automatic    bool       `json:"automatic,omitempty"` // Decided by a background job, e.g. the stale leave check
+	DecidedAt    *time.Time `json:"decided_at,omitempty"`
+	Remarks      *string    `json:"remarks,omitempty"` // The reason for a rejection, or the approver's question
+}
+
+// GetLeaveDecision godoc
+// @Summary Get the decision on a leave
+// @Description Returns the leave's status with who decided it, when, and their remarks, such as the reason for a rejection. Visible to the student who applied and to the approvers who can view the leave.
+// @Tags Leaves
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Leave request ID"
+// @Success 200 {object} LeaveDecision "Decision"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Leave request not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /leaves/{id}/decision [get]
+func GetLeaveDecision(c *gin.Context) {
+	var leave LeaveRequest
+	if err := db.DB.Preload("Approver").First(&leave, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Leave request not found"})
+		return
+	}
+
+	if !checkLeaveAccess(c, &leave) {
+		return
+	}
+
+	decision := LeaveDecision{LeaveID: leave.ID, Status: leave.Status}
+	if leave.Status == "pending" {
+		c.JSON(http.StatusOK, decision)
+		return
+	}
+	decision.Decided = true
+	decision.Remarks = leave.Remarks
+
+	// The latest transition into the current status says who decided and when
+	var events []LeaveEvent
+	err := db.DB.Preload("Actor").Where("leave_id = ? AND to_status = ?", leave.ID, leave.Status).
+		Order("created_at DESC, id DESC").Limit(1).Find(&events).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get leave decision"})
+		return
+	}
+	if len(events) > 0 {
+		event := events[0]
+		decision.DecidedAt = &event.CreatedAt
+		decision.DecidedBy = event.ActorID
+		decision.Automatic = event.ActorID == nil
+		if event.Actor != nil {
+			decision.ApproverName = event.Actor.Name
+		}
+	} else {
+		// Leaves decided before history was recorded
+		decision.DecidedAt = &leave.UpdatedAt
+		decision.DecidedBy = leave.ApprovedBy
+		if leave.Approver != nil {
+			decision.ApproverName = leave.Approver.Name
+		}
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
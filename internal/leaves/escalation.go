@@ -0,0 +1,130 @@
+package leaves
+
+import (
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/internal/notifications"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EscalationResult summarizes one run of the approval deadline check
+type EscalationResult struct {
+	Cutoff    time.Time `json:"cutoff"` // Pending leaves filed before this were overdue
+	Escalated []uint    `json:"escalated"`
+	Failed    []uint    `json:"failed"`
+}
+
+// setDeadline fills in DeadlineAt and IsOverdue for a pending leave when an approval deadline is configured
+func (l *LeaveRequest) setDeadline() {
+	deadline := core.GetConfig().Leave.ApprovalDeadline()
+	if deadline <= 0 || l.Status != "pending" {
+		return
+	}
+	at := l.CreatedAt.Add(deadline)
+	l.DeadlineAt = &at
+	l.IsOverdue = time.Now().After(at)
+}
+
+// EscalateOverdueLeaves flags every leave still pending past the approval deadline and notifies the
+// next step of its approval chain: department faculty when the hostel warden sat on it, otherwise
+// admins. Each leave is escalated once. actorID is audit.SystemActorID when run by the scheduler.
+func EscalateOverdueLeaves(actorID uint) (*EscalationResult, error) {
+	deadline := core.GetConfig().Leave.ApprovalDeadline()
+	result := &EscalationResult{Cutoff: time.Now().Add(-deadline), Escalated: []uint{}, Failed: []uint{}}
+	if deadline <= 0 {
+		return result, nil
+	}
+
+	var overdue []LeaveRequest
+	err := db.DB.Preload("Student").Where("status = ? AND escalated_at IS NULL AND created_at < ?", "pending", result.Cutoff).
+		Order("created_at ASC").Find(&overdue).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(overdue) == 0 {
+		return result, nil
+	}
+
+	byDept, byHostel, err := pipelineApprovers(overdue)
+	if err != nil {
+		return nil, err
+	}
+	var adminIDs []uint
+	if err := db.DB.Model(&User{}).Where("role = ? AND is_active = ?", users.RoleAdmin, true).Pluck("id", &adminIDs).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range overdue {
+		leave := &overdue[i]
+
+		recipients := adminIDs
+		if leave.Hostel != nil && len(byHostel[*leave.Hostel]) > 0 && len(byDept[leave.Dept]) > 0 {
+			recipients = approverIDs(byDept[leave.Dept])
+		}
+
+		err := escalateLeave(leave, actorID, recipients)
+		if errors.Is(err, ErrLeaveAlreadyProcessed) {
+			continue // Decided or escalated since we loaded it
+		}
+		if err != nil {
+			log.Printf("Failed to escalate overdue leave %d: %v", leave.ID, err)
+			result.Failed = append(result.Failed, leave.ID)
+			continue
+		}
+		result.Escalated = append(result.Escalated, leave.ID)
+	}
+
+	return result, nil
+}
+
+// EscalateOverdueLeavesJob is the scheduled form of EscalateOverdueLeaves
+func EscalateOverdueLeavesJob() error {
+	_, err := EscalateOverdueLeaves(audit.SystemActorID)
+	return err
+}
+
+// escalateLeave marks a pending leave as escalated and notifies the recipients
+func escalateLeave(leave *LeaveRequest, actorID uint, recipients []uint) error {
+	now := time.Now()
+	leave.EscalatedAt = &now
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		rows, err := updateIfVersion(tx.Where("status = ? AND escalated_at IS NULL", "pending"), leave, "escalated_at")
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrLeaveAlreadyProcessed
+		}
+		details := fmt.Sprintf("pending leave filed %s escalated to %d approver(s)",
+			leave.CreatedAt.In(timeutil.Location()).Format(timeutil.DateLayout), len(recipients))
+		return audit.Record(tx, actorID, "leave_escalate", "leave_request", leave.ID, details)
+	})
+	if err != nil {
+		return err
+	}
+
+	userLeaveRequest := notificationLeave(leave)
+	hours := core.GetConfig().Leave.ApprovalDeadlineHours
+	if err := notifications.NotifyLeaveEscalated(recipients, &userLeaveRequest, leave.Student.Name, hours); err != nil {
+		log.Printf("Failed to notify approvers about overdue leave %d: %v", leave.ID, err)
+	}
+	return nil
+}
+
+// approverIDs lists the IDs of the approvers
+func approverIDs(approvers []PipelineApprover) []uint {
+	ids := make([]uint, 0, len(approvers))
+	for _, approver := range approvers {
+		ids = append(ids, approver.ID)
+	}
+	return ids
+}
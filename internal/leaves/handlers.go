@@ -1,31 +1,70 @@
 package leaves
 
 import (
+	"campus-backend/internal/audit"
+	"campus-backend/internal/auth"
+	"campus-backend/internal/core"
 	"campus-backend/internal/notifications"
 	"campus-backend/internal/users"
+	"campus-backend/internal/webhooks"
 	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
 	"campus-backend/pkg/validation"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type ApplyLeaveRequest struct {
-	LeaveType string    `json:"leave_type" binding:"required" validate:"required,oneof=medical personal emergency academic"`
+	LeaveType string    `json:"leave_type" binding:"required" validate:"required"` // Checked against the configured leave types
 	Reason    string    `json:"reason" binding:"required" validate:"required,min=10,max=500"`
 	StartDate time.Time `json:"start_date" binding:"required" validate:"required,future_date"`
 	EndDate   time.Time `json:"end_date" binding:"required" validate:"required,date_range,leave_duration"`
 }
 
 type ApproveRejectRequest struct {
-	Action  string  `json:"action" binding:"required" validate:"required,oneof=approve reject"`
-	Remarks *string `json:"remarks" validate:"max=200"`
+	Action  string  `json:"action" binding:"required" validate:"required,oneof=approve reject info_requested"`
+	Remarks *string `json:"remarks" validate:"omitempty,max=200"` // Required for reject (the reason) and info_requested (the question)
+	Version int     `json:"version" binding:"required" validate:"required,min=1"`
+}
+
+type BatchApproveRequest struct {
+	LeaveIDs []uint  `json:"leave_ids" binding:"required" validate:"required,min=1,max=100,dive,min=1"`
+	Action   string  `json:"action" binding:"required" validate:"required,oneof=approve reject"`
+	Remarks  *string `json:"remarks" validate:"omitempty,max=200"`
+}
+
+// BatchLeaveResult reports the outcome for one leave in a batch decision
+type BatchLeaveResult struct {
+	LeaveID uint   `json:"leave_id"`
+	Result  string `json:"result"` // approved, rejected or skipped
+	Reason  string `json:"reason,omitempty"`
+}
+
+// RespondLeaveRequest is a student's answer to an approver's request for more information
+type RespondLeaveRequest struct {
+	Response string  `json:"response" binding:"required" validate:"required,min=2,max=500"`
+	Reason   *string `json:"reason" validate:"omitempty,min=10,max=500"` // Replaces the leave reason when set
+	Version  int     `json:"version" binding:"required" validate:"required,min=1"`
+}
+
+type OverrideLeaveRequest struct {
+	Status        string `json:"status" binding:"required" validate:"required,oneof=approved rejected"`
+	Justification string `json:"justification" binding:"required" validate:"required,min=10,max=200"`
+	Version       int    `json:"version" binding:"required" validate:"required,min=1"`
 }
 
 // ApplyLeave godoc
 // @Summary Apply for leave
-// @Description Student applies for leave with validation
+// @Description Student applies for leave with validation. Overlapping an open or approved leave is refused, naming the conflicting leave, unless LEAVE_OVERLAP_ALLOWED lets the two types coexist.
 // @Tags Leaves
 // @Accept json
 // @Produce json
@@ -37,18 +76,8 @@ type ApproveRejectRequest struct {
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /leaves/apply [post]
 func ApplyLeave(c *gin.Context) {
-	var input ApplyLeaveRequest
-
-	// Get JSON data from request
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Validate the data
-	if err := validation.ValidateStruct(input); err != nil {
-		errors := validation.FormatValidationErrors(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+	input, ok := bindApplyLeave(c)
+	if !ok {
 		return
 	}
 
@@ -67,20 +96,73 @@ func ApplyLeave(c *gin.Context) {
 		return
 	}
 
-	// Check if student already has leave for same period
-	var existingLeaves []LeaveRequest
-	err := db.DB.Where("student_id = ? AND status IN (?) AND ((start_date <= ? AND end_date >= ?) OR (start_date <= ? AND end_date >= ?))",
-		studentID, []string{"pending", "approved"}, input.StartDate, input.StartDate, input.EndDate, input.EndDate).Find(&existingLeaves).Error
+	leave, ok := fileLeave(c, &student, nil, input)
+	if !ok {
+		return
+	}
+
+	// Send success response
+	c.JSON(http.StatusCreated, gin.H{
+		"message":       "Leave request submitted successfully",
+		"leave_request": appliedLeave(leave),
+	})
+}
+
+// bindApplyLeave reads and validates a leave application, writing a 400 response and returning false
+// when it is invalid, of a type that is not allowed, or too short notice
+func bindApplyLeave(c *gin.Context) (ApplyLeaveRequest, bool) {
+	var input ApplyLeaveRequest
+
+	// Get JSON data from request
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return input, false
+	}
+
+	// Validate the data
+	if err := validation.ValidateStruct(input); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return input, false
+	}
+
+	leaveConfig := core.GetConfig().Leave
+	if !leaveConfig.IsAllowedType(input.LeaveType) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": gin.H{"LeaveType": "LeaveType must be one of: " + strings.Join(leaveConfig.AllowedTypes, " ")},
+		})
+		return input, false
+	}
+
+	// Enforce the minimum notice period for this leave type
+	if msg := checkMinimumNotice(input.LeaveType, input.StartDate); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return input, false
+	}
+	return input, true
+}
 
+// fileLeave creates a pending leave for the student after the overlap check. appliedBy is the staff
+// member filing on the student's behalf, nil when the student applies. Writes an error response and
+// returns false on failure.
+func fileLeave(c *gin.Context, student *users.User, appliedBy *uint, input ApplyLeaveRequest) (*LeaveRequest, bool) {
+	// Check if student already has leave for same period
+	existing, err := conflictingLeave(student.ID, input.LeaveType, input.StartDate, input.EndDate)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing leaves"})
-		return
+		return nil, false
 	}
-
-	// If overlapping leave exists, reject
-	if len(existingLeaves) > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "You already have a leave request for this period"})
-		return
+	if existing != nil {
+		message := "You already have a leave request for this period"
+		if appliedBy != nil {
+			message = "The student already has a leave request for this period"
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":             message,
+			"conflicting_leave": conflictSummary(existing),
+		})
+		return nil, false
 	}
 
 	// Calculate number of days
@@ -88,7 +170,7 @@ func ApplyLeave(c *gin.Context) {
 
 	// Create leave request
 	leave := LeaveRequest{
-		StudentID: studentID,
+		StudentID: student.ID,
 		LeaveType: input.LeaveType,
 		Reason:    input.Reason,
 		StartDate: input.StartDate,
@@ -97,124 +179,176 @@ func ApplyLeave(c *gin.Context) {
 		Dept:      student.Dept,
 		Hostel:    student.Hostel,
 		Days:      days,
+		AppliedBy: appliedBy,
 	}
+	origin := originOf(c)
+	leave.ClientIP, leave.UserAgent = origin.ClientIP, origin.UserAgent
 
-	// Save to database
-	if err := db.DB.Create(&leave).Error; err != nil {
+	// Save the request and its initial history event together; the history names whoever filed it
+	actorID := student.ID
+	if appliedBy != nil {
+		actorID = *appliedBy
+	}
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&leave).Error; err != nil {
+			return err
+		}
+		if err := recordLeaveEvent(tx, leave.ID, actorID, "", leave.Status, nil, origin); err != nil {
+			return err
+		}
+		if appliedBy == nil {
+			return nil
+		}
+		details := fmt.Sprintf("%s leave %s to %s filed for student %d", leave.LeaveType,
+			leave.StartDate.In(timeutil.Location()).Format(timeutil.DateLayout), leave.EndDate.In(timeutil.Location()).Format(timeutil.DateLayout), student.ID)
+		return audit.Record(tx, actorID, "leave_apply_on_behalf", "leave_request", leave.ID, details)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create leave request"})
-		return
+		return nil, false
 	}
+	return &leave, true
+}
 
-	// Send success response
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "Leave request submitted successfully",
-		"leave_request": gin.H{
-			"id":         leave.ID,
-			"leave_type": leave.LeaveType,
-			"reason":     leave.Reason,
-			"start_date": leave.StartDate,
-			"end_date":   leave.EndDate,
-			"days":       leave.Days,
-			"status":     leave.Status,
-			"created_at": leave.CreatedAt,
-		},
-	})
+// appliedLeave is the leave_request part of an application response
+func appliedLeave(leave *LeaveRequest) gin.H {
+	applied := gin.H{
+		"id":         leave.ID,
+		"leave_type": leave.LeaveType,
+		"reason":     leave.Reason,
+		"start_date": leave.StartDate,
+		"end_date":   leave.EndDate,
+		"days":       leave.Days,
+		"status":     leave.Status,
+		"created_at": leave.CreatedAt,
+	}
+	if leave.AppliedBy != nil {
+		applied["applied_by"] = *leave.AppliedBy
+	}
+	return applied
 }
 
 // ListLeaves godoc
 // @Summary List leave requests
-// @Description Get list of leave requests based on user role
+// @Description Get list of leave requests based on user role. Approvers also see deadline_at and is_overdue on pending leaves when LEAVE_APPROVAL_DEADLINE_HOURS is set.
 // @Tags Leaves
 // @Accept json
-// @Produce json
+// @Produce json,text/csv
 // @Security BearerAuth
-// @Param status query string false "Filter by status (pending, approved, rejected)"
+// @Param status query string false "Filter by status (pending, needs_info, approved, rejected), comma-separated for several; approvers default to pending and needs_info"
 // @Param leave_type query string false "Filter by leave type"
+// @Param include_deleted query bool false "Include soft-deleted leaves, flagged with deleted: true (admins only)"
+// @Param client_ip query string false "Only leaves applied for from this IP, e.g. to investigate bulk applications (admins only)"
 // @Param page query int false "Page number" default(1)
-// @Param limit query int false "Items per page" default(10)
+// @Param limit query int false "Items per page (clamped to the configured maximum)" default(10)
+// @Param format query string false "Response format (json or csv); Accept: text/csv also works"
 // @Success 200 {object} map[string]interface{} "List of leave requests"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /leaves/ [get]
 func ListLeaves(c *gin.Context) {
-	roleVal, _ := c.Get("role")
-	role := roleVal.(string)
+	role, _ := auth.CurrentRole(c)
 
 	var leaves []LeaveRequest
+	var total int64
 	var err error
 
 	// Get query parameters for filtering
-	status := c.Query("status")
+	statuses, ok := parseStatuses(c.Query("status"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be a comma-separated list of pending, needs_info, approved or rejected"})
+		return
+	}
 	leaveType := c.Query("leave_type")
-	page := c.DefaultQuery("page", "1")
-	limit := c.DefaultQuery("limit", "10")
+	includeDeleted := c.Query("include_deleted") == "true"
+	page, limit := core.PaginationParams(c)
+
+	if includeDeleted && role != users.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can include deleted leaves"})
+		return
+	}
+	clientIP := c.Query("client_ip")
+	if clientIP != "" && role != users.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can filter by client IP"})
+		return
+	}
 
 	if role == users.RoleStudent {
 		userIDVal, _ := c.Get("userID")
 		userID := userIDVal.(uint)
 
 		query := db.DB.Where("student_id = ?", userID)
-		if status != "" {
-			query = query.Where("status = ?", status)
+		if len(statuses) > 0 {
+			query = query.Where("status IN ?", statuses)
 		}
 		if leaveType != "" {
 			query = query.Where("leave_type = ?", leaveType)
 		}
 
-		err = query.Preload("Approver").Order("created_at DESC").Find(&leaves).Error
+		err = findLeavesPage(query, false, page, limit, &leaves, &total)
 	} else if role == users.RoleWarden || role == users.RoleFaculty || role == users.RoleAdmin {
 		// Filter leaves according to approval scope for warden and faculty
 		if role == users.RoleWarden {
-			userIDVal, _ := c.Get("userID")
-			userID := userIDVal.(uint)
-			var approver users.User
-			if err := db.DB.First(&approver, userID).Error; err != nil {
+			var approver *users.User
+			approver, err = auth.CurrentUser(c) // Assign, not shadow: err is checked after the branches
+			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
 				return
 			}
 
+			if approver.Hostel == nil || *approver.Hostel == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Your account has no hostel assigned; contact admin"})
+				return
+			}
+
 			query := db.DB.Where("hostel = ?", *approver.Hostel)
-			if status != "" {
-				query = query.Where("status = ?", status)
+			if len(statuses) > 0 {
+				query = query.Where("status IN ?", statuses)
 			} else {
-				query = query.Where("status = ?", "pending") // Default to pending for wardens
+				query = query.Where("status IN ?", OpenStatuses) // Default to open leaves for wardens
 			}
 			if leaveType != "" {
 				query = query.Where("leave_type = ?", leaveType)
 			}
 
-			err = query.Preload("Student").Preload("Approver").Order("created_at DESC").Find(&leaves).Error
+			err = findLeavesPage(query, true, page, limit, &leaves, &total)
 		} else if role == users.RoleFaculty {
-			userIDVal, _ := c.Get("userID")
-			userID := userIDVal.(uint)
-			var approver users.User
-			if err := db.DB.First(&approver, userID).Error; err != nil {
+			var approver *users.User
+			approver, err = auth.CurrentUser(c)
+			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
 				return
 			}
 
 			query := db.DB.Where("dept = ?", approver.Dept)
-			if status != "" {
-				query = query.Where("status = ?", status)
+			if len(statuses) > 0 {
+				query = query.Where("status IN ?", statuses)
 			} else {
-				query = query.Where("status = ?", "pending") // Default to pending for faculty
+				query = query.Where("status IN ?", OpenStatuses) // Default to open leaves for faculty
 			}
 			if leaveType != "" {
 				query = query.Where("leave_type = ?", leaveType)
 			}
 
-			err = query.Preload("Student").Preload("Approver").Order("created_at DESC").Find(&leaves).Error
+			err = findLeavesPage(query, true, page, limit, &leaves, &total)
 		} else {
-			// Admin can see all leaves
+			// Admin can see all leaves, and deleted ones on request
 			query := db.DB
-			if status != "" {
-				query = query.Where("status = ?", status)
+			if includeDeleted {
+				query = query.Unscoped()
+			}
+			if clientIP != "" {
+				query = query.Where("client_ip = ?", clientIP)
+			}
+			if len(statuses) > 0 {
+				query = query.Where("status IN ?", statuses)
 			}
 			if leaveType != "" {
 				query = query.Where("leave_type = ?", leaveType)
 			}
 
-			err = query.Preload("Student").Preload("Approver").Order("created_at DESC").Find(&leaves).Error
+			err = findLeavesPage(query, true, page, limit, &leaves, &total)
 		}
 	} else {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
@@ -225,21 +359,163 @@ func ListLeaves(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get leaves"})
 		return
 	}
+	for i := range leaves {
+		leaves[i].Deleted = leaves[i].DeletedAt.Valid
+		if role != users.RoleStudent {
+			leaves[i].setDeadline()
+		}
+	}
+
+	core.Render(c, gin.H{
+		"leaves":     leaves,
+		"pagination": core.CalculatePagination(page, limit, total),
+	}, "leaves")
+}
+
+// parseStatuses splits a comma-separated status filter, returning false if any value is not a leave status
+func parseStatuses(value string) ([]string, bool) {
+	if value == "" {
+		return nil, true
+	}
+	var statuses []string
+	for _, status := range strings.Split(value, ",") {
+		status = strings.TrimSpace(status)
+		if !slices.Contains(leaveStatuses, status) {
+			return nil, false
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, true
+}
+
+// ActiveLeave is an approved leave in effect on the requested day, with the student's contact details
+type ActiveLeave struct {
+	LeaveID       uint      `json:"leave_id"`
+	StudentID     uint      `json:"student_id"`
+	StudentName   string    `json:"student_name"`
+	StudentNumber *string   `json:"student_number,omitempty"`
+	Email         string    `json:"email"`
+	Phone         *string   `json:"phone,omitempty"`
+	Dept          string    `json:"dept"`
+	Hostel        *string   `json:"hostel,omitempty"`
+	LeaveType     string    `json:"leave_type"`
+	StartDate     time.Time `json:"start_date"`
+	EndDate       time.Time `json:"end_date"`
+	Days          int       `json:"days"`
+
+	EmergencyContact *EmergencyContact `json:"emergency_contact,omitempty"` // Wardens and admins only
+}
+
+// EmergencyContact is a student's next of kin
+type EmergencyContact struct {
+	Name  *string `json:"name,omitempty"`
+	Phone *string `json:"phone,omitempty"`
+}
+
+// emergencyContactFor returns the student's emergency contact if the role may see it and one is set
+func emergencyContactFor(role string, student *User) *EmergencyContact {
+	if role != users.RoleWarden && role != users.RoleAdmin {
+		return nil
+	}
+	if student.EmergencyContactName == nil && student.EmergencyContactPhone == nil {
+		return nil
+	}
+	return &EmergencyContact{Name: student.EmergencyContactName, Phone: student.EmergencyContactPhone}
+}
+
+// ListActiveLeaves godoc
+// @Summary List students on leave
+// @Description List approved leaves covering the given day, scoped to the warden's hostel, the faculty's department, or all for admins. Wardens and admins also get each student's emergency contact.
+// @Tags Leaves
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Day to check (YYYY-MM-DD, defaults to today)"
+// @Success 200 {object} map[string]interface{} "Active leaves"
+// @Failure 400 {object} map[string]interface{} "Invalid date or no hostel assigned"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /leaves/active [get]
+func ListActiveLeaves(c *gin.Context) {
+	role, _ := auth.CurrentRole(c)
+
+	day := timeutil.Today()
+	if value := c.Query("date"); value != "" {
+		parsed, err := timeutil.ParseDate(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected YYYY-MM-DD"})
+			return
+		}
+		day = parsed
+	}
+	start, end := timeutil.DayBounds(day)
+
+	query := db.DB.Where("status = ? AND start_date < ? AND end_date >= ?", "approved", end, start)
+	if role == users.RoleWarden || role == users.RoleFaculty {
+		approver, err := auth.CurrentUser(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
+			return
+		}
+
+		if role == users.RoleWarden {
+			if approver.Hostel == nil || *approver.Hostel == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Your account has no hostel assigned; contact admin"})
+				return
+			}
+			query = query.Where("hostel = ?", *approver.Hostel)
+		} else {
+			query = query.Where("dept = ?", approver.Dept)
+		}
+	}
+
+	var active []LeaveRequest
+	if err := query.Preload("Student").Order("end_date ASC").Find(&active).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get active leaves"})
+		return
+	}
+
+	result := make([]ActiveLeave, 0, len(active))
+	for _, leave := range active {
+		result = append(result, ActiveLeave{
+			LeaveID:       leave.ID,
+			StudentID:     leave.StudentID,
+			StudentName:   leave.Student.Name,
+			StudentNumber: leave.Student.StudentID,
+			Email:         leave.Student.Email,
+			Phone:         leave.Student.Phone,
+			Dept:          leave.Dept,
+			Hostel:        leave.Hostel,
+			LeaveType:     leave.LeaveType,
+			StartDate:     leave.StartDate,
+			EndDate:       leave.EndDate,
+			Days:          leave.Days,
+
+			EmergencyContact: emergencyContactFor(role, &leave.Student),
+		})
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"leaves": leaves,
-		"pagination": gin.H{
-			"page":  page,
-			"limit": limit,
-			"total": len(leaves),
-		},
+		"date":   start.In(timeutil.Location()).Format(timeutil.DateLayout),
+		"leaves": result,
+		"total":  len(result),
 	})
 }
 
+// findLeavesPage counts all leaves matching the query and loads the requested page
+func findLeavesPage(query *gorm.DB, withStudent bool, page, limit int, leaves *[]LeaveRequest, total *int64) error {
+	if err := query.Model(&LeaveRequest{}).Count(total).Error; err != nil {
+		return err
+	}
+	if withStudent {
+		query = query.Preload("Student")
+	}
+	return query.Preload("Approver").Order("created_at DESC").Offset((page - 1) * limit).Limit(limit).Find(leaves).Error
+}
+
 func GetLeaveDetails(c *gin.Context) {
 	leaveID := c.Param("id")
-	roleVal, _ := c.Get("role")
-	role := roleVal.(string)
 
 	var leave LeaveRequest
 	if err := db.DB.Preload("Student").Preload("Approver").First(&leave, leaveID).Error; err != nil {
@@ -247,41 +523,253 @@ func GetLeaveDetails(c *gin.Context) {
 		return
 	}
 
+	if !checkLeaveAccess(c, &leave) {
+		return
+	}
+
+	role, _ := auth.CurrentRole(c)
+	if role != users.RoleStudent {
+		leave.setDeadline()
+	}
+	details := struct {
+		LeaveRequest
+		EmergencyContact *EmergencyContact `json:"emergency_contact,omitempty"`
+		ClientIP         string            `json:"client_ip,omitempty"`
+		UserAgent        string            `json:"user_agent,omitempty"`
+	}{LeaveRequest: leave, EmergencyContact: emergencyContactFor(role, &leave.Student)}
+	if role == users.RoleAdmin {
+		details.ClientIP, details.UserAgent = leave.ClientIP, leave.UserAgent
+	}
+	c.JSON(http.StatusOK, details)
+}
+
+// GetLeaveHistory godoc
+// @Summary Get leave status history
+// @Description Get the ordered status transitions of a leave request. Admins also see the client IP and user agent each one came from.
+// @Tags Leaves
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Leave request ID"
+// @Success 200 {object} map[string]interface{} "Leave history"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Leave request not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /leaves/{id}/history [get]
+func GetLeaveHistory(c *gin.Context) {
+	leaveID := c.Param("id")
+
+	var leave LeaveRequest
+	if err := db.DB.First(&leave, leaveID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Leave request not found"})
+		return
+	}
+
+	if !checkLeaveAccess(c, &leave) {
+		return
+	}
+
+	var events []LeaveEvent
+	if err := db.DB.Preload("Actor").Where("leave_id = ?", leave.ID).Order("created_at ASC, id ASC").Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get leave history"})
+		return
+	}
+	if role, _ := auth.CurrentRole(c); role != users.RoleAdmin {
+		for i := range events {
+			events[i].ClientIP, events[i].UserAgent = "", ""
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"leave_id": leave.ID,
+		"status":   leave.Status,
+		"history":  events,
+	})
+}
+
+// conflictingLeave returns the first open or approved leave of the student overlapping the period whose
+// type the overlap policy does not let coexist with leaveType, or nil if there is none
+func conflictingLeave(studentID uint, leaveType string, start, end time.Time) (*LeaveRequest, error) {
+	var existingLeaves []LeaveRequest
+	err := db.DB.Where("student_id = ? AND status IN (?) AND start_date <= ? AND end_date >= ?",
+		studentID, []string{"pending", "needs_info", "approved"}, end, start).
+		Order("start_date ASC").Find(&existingLeaves).Error
+	if err != nil {
+		return nil, err
+	}
+
+	leaveConfig := core.GetConfig().Leave
+	for i := range existingLeaves {
+		if !leaveConfig.AllowsOverlap(leaveType, existingLeaves[i].LeaveType) {
+			return &existingLeaves[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// conflictSummary describes a conflicting leave in an error response
+func conflictSummary(existing *LeaveRequest) gin.H {
+	return gin.H{
+		"id":         existing.ID,
+		"leave_type": existing.LeaveType,
+		"status":     existing.Status,
+		"start_date": existing.StartDate,
+		"end_date":   existing.EndDate,
+	}
+}
+
+// checkLeaveAccess verifies the current user may view the leave, writing an error response if not
+func checkLeaveAccess(c *gin.Context, leave *LeaveRequest) bool {
+	role, ok := auth.CurrentRole(c)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return false
+	}
+
 	// Check permissions
 	if role == users.RoleStudent {
 		userIDVal, _ := c.Get("userID")
 		userID := userIDVal.(uint)
 		if leave.StudentID != userID {
 			c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own leave requests"})
-			return
+			return false
 		}
 	} else if role == users.RoleFaculty {
-		userIDVal, _ := c.Get("userID")
-		userID := userIDVal.(uint)
-		var approver users.User
-		if err := db.DB.First(&approver, userID).Error; err != nil {
+		approver, err := auth.CurrentUser(c)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
-			return
+			return false
 		}
 		if approver.Dept != leave.Dept {
 			c.JSON(http.StatusForbidden, gin.H{"error": "You can only view leaves from your department"})
-			return
+			return false
 		}
 	} else if role == users.RoleWarden {
-		userIDVal, _ := c.Get("userID")
-		userID := userIDVal.(uint)
-		var approver users.User
-		if err := db.DB.First(&approver, userID).Error; err != nil {
+		approver, err := auth.CurrentUser(c)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
-			return
+			return false
 		}
 		if approver.Hostel == nil || leave.Hostel == nil || *approver.Hostel != *leave.Hostel {
 			c.JSON(http.StatusForbidden, gin.H{"error": "You can only view leaves from your hostel"})
-			return
+			return false
 		}
 	}
 
-	c.JSON(http.StatusOK, leave)
+	return true
+}
+
+// checkMinimumNotice returns an error message if the leave starts sooner than its type's notice period allows
+func checkMinimumNotice(leaveType string, startDate time.Time) string {
+	// Emergencies can always be applied for on the same day
+	if leaveType == "emergency" {
+		return ""
+	}
+
+	days := core.GetConfig().Leave.MinNoticeDays[leaveType]
+	if days <= 0 {
+		return ""
+	}
+
+	earliest := timeutil.StartOfDay(timeutil.Now().AddDate(0, 0, days))
+	if timeutil.StartOfDay(startDate).Before(earliest) {
+		return fmt.Sprintf("%s leave requires at least %d day(s) notice; the earliest allowed start date is %s",
+			leaveType, days, earliest.In(timeutil.Location()).Format(timeutil.DateLayout))
+	}
+	return ""
+}
+
+// approvalScopeError returns why the approver may not decide on the leave, or "" if they may.
+// Faculty are limited to their department and wardens to their hostel.
+func approvalScopeError(role string, approver *users.User, leave *LeaveRequest) string {
+	switch role {
+	case users.RoleFaculty:
+		if approver.Dept != leave.Dept {
+			return "You can only approve leaves from your department"
+		}
+	case users.RoleWarden:
+		if approver.Hostel == nil || leave.Hostel == nil || *approver.Hostel != *leave.Hostel {
+			return "You can only approve leaves from your hostel"
+		}
+	}
+	return ""
+}
+
+// selfApprovalError returns why the approver may not decide on a leave that is theirs or that they
+// filed for someone else, or "" if they may. LEAVE_ALLOW_SELF_APPROVAL=true lifts the restriction.
+func selfApprovalError(approverID uint, leave *LeaveRequest) string {
+	if core.GetConfig().Leave.AllowSelfApproval {
+		return ""
+	}
+	if leave.StudentID == approverID {
+		return "You cannot approve or reject your own leave request"
+	}
+	if leave.AppliedBy != nil && *leave.AppliedBy == approverID {
+		return "You cannot approve or reject a leave you filed on someone else's behalf"
+	}
+	return ""
+}
+
+// ErrLeaveAlreadyProcessed is returned when a leave is no longer pending at update time
+var ErrLeaveAlreadyProcessed = errors.New("leave request already processed")
+
+// ErrLeaveVersionConflict is returned when a leave was modified after it was read
+var ErrLeaveVersionConflict = errors.New("leave request was modified concurrently")
+
+// updateIfVersion writes the given columns only if the stored row is still at the version
+// the leave was read at, bumping the version on success. It returns the rows affected.
+func updateIfVersion(query *gorm.DB, leave *LeaveRequest, columns ...string) (int64, error) {
+	expected := leave.Version
+	leave.Version = expected + 1
+	result := query.Model(leave).
+		Where("version = ?", expected).
+		Select(append(columns, "version")).
+		Updates(leave)
+	if result.Error != nil || result.RowsAffected == 0 {
+		leave.Version = expected
+	}
+	return result.RowsAffected, result.Error
+}
+
+// applyPendingDecision saves the decision only if the leave is still pending in the database,
+// so two approvers racing on the same leave cannot both succeed
+func applyPendingDecision(tx *gorm.DB, leave *LeaveRequest) error {
+	rows, err := updateIfVersion(tx.Where("status = ?", "pending"), leave, "status", "approved_by", "remarks")
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrLeaveAlreadyProcessed
+	}
+	return nil
+}
+
+// recordLeaveEvent stores a status transition in the leave history, with the request it came from.
+// An actorID of audit.SystemActorID records the event without an actor.
+func recordLeaveEvent(tx *gorm.DB, leaveID, actorID uint, fromStatus, toStatus string, remarks *string, origin requestOrigin) error {
+	event := LeaveEvent{
+		LeaveID:    leaveID,
+		FromStatus: fromStatus,
+		ToStatus:   toStatus,
+		Remarks:    remarks,
+		ClientIP:   origin.ClientIP,
+		UserAgent:  origin.UserAgent,
+	}
+	if actorID != audit.SystemActorID {
+		event.ActorID = &actorID
+	}
+	return tx.Create(&event).Error
+}
+
+// minRejectionRemarks is the shortest reason accepted when rejecting, so students always get an explanation
+const minRejectionRemarks = 10
+
+var rejectionReasonMessage = fmt.Sprintf("Remarks of at least %d characters are required when rejecting a leave", minRejectionRemarks)
+
+// hasRejectionReason reports whether remarks are long enough to explain a rejection
+func hasRejectionReason(remarks *string) bool {
+	return remarks != nil && utf8.RuneCountInString(strings.TrimSpace(*remarks)) >= minRejectionRemarks
 }
 
 func ApproveRejectLeave(c *gin.Context) {
@@ -289,13 +777,13 @@ func ApproveRejectLeave(c *gin.Context) {
 
 	var input ApproveRejectRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
 		return
 	}
 
 	// Validate the struct
 	if err := validation.ValidateStruct(input); err != nil {
-		errors := validation.FormatValidationErrors(err)
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
 		return
 	}
@@ -306,12 +794,26 @@ func ApproveRejectLeave(c *gin.Context) {
 		return
 	}
 
+	if input.Action == "info_requested" && (input.Remarks == nil || strings.TrimSpace(*input.Remarks) == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Remarks are required when requesting more information"})
+		return
+	}
+	if input.Action == "reject" && !hasRejectionReason(input.Remarks) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rejectionReasonMessage})
+		return
+	}
+
 	// Check if leave is already processed
 	if leave.Status != "pending" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Leave request has already been processed"})
 		return
 	}
 
+	if input.Version != leave.Version {
+		c.JSON(http.StatusConflict, gin.H{"error": "Leave request was modified since it was read", "current_version": leave.Version})
+		return
+	}
+
 	approverIDVal, exists := c.Get("userID")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
@@ -319,54 +821,408 @@ func ApproveRejectLeave(c *gin.Context) {
 	}
 	approverID := approverIDVal.(uint)
 
-	roleVal, _ := c.Get("role")
-	role := roleVal.(string)
+	// Applies to every role, admins included
+	if msg := selfApprovalError(approverID, &leave); msg != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	role, _ := auth.CurrentRole(c)
 
 	// Role-based approval restrictions
-	if role == users.RoleFaculty {
-		// Faculty can only approve department leaves
-		var approver users.User
-		if err := db.DB.First(&approver, approverID).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Approver not found"})
-			return
-		}
-		if approver.Dept != leave.Dept {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You can only approve leaves from your department"})
-			return
-		}
-	} else if role == users.RoleWarden {
-		// Warden can only approve hostel leaves
-		var approver users.User
-		if err := db.DB.First(&approver, approverID).Error; err != nil {
+	if role == users.RoleFaculty || role == users.RoleWarden {
+		approver, err := auth.CurrentUser(c)
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Approver not found"})
 			return
 		}
-		if approver.Hostel == nil || leave.Hostel == nil || *approver.Hostel != *leave.Hostel {
-			c.JSON(http.StatusForbidden, gin.H{"error": "You can only approve leaves from your hostel"})
+		if msg := approvalScopeError(role, approver, &leave); msg != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": msg})
 			return
 		}
 	}
 
 	// Update leave status
+	previousStatus := leave.Status
 	switch input.Action {
 	case "approve":
 		leave.Status = "approved"
 	case "reject":
 		leave.Status = "rejected"
+	case "info_requested":
+		leave.Status = "needs_info"
 	}
 
-	leave.ApprovedBy = &approverID
+	// Asking a question is not a decision, so the leave keeps no approver
+	if leave.Status != "needs_info" {
+		leave.ApprovedBy = &approverID
+	}
 	leave.Remarks = input.Remarks
 
-	if err := db.DB.Save(&leave).Error; err != nil {
+	origin := originOf(c)
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyPendingDecision(tx, &leave); err != nil {
+			return err
+		}
+		return recordLeaveEvent(tx, leave.ID, approverID, previousStatus, leave.Status, leave.Remarks, origin)
+	})
+	if errors.Is(err, ErrLeaveAlreadyProcessed) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Leave request was already processed by another approver"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update leave"})
 		return
 	}
 
-	// TODO: Send notification to student about status change
 	// Send notification to student about status change
-	// Convert local LeaveRequest to users.LeaveRequest for notification
-	userLeaveRequest := users.LeaveRequest{
+	notifyStatusChange(&leave)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Leave request updated successfully",
+		"leave_request": gin.H{
+			"id":          leave.ID,
+			"status":      leave.Status,
+			"remarks":     leave.Remarks,
+			"approved_by": leave.ApprovedBy,
+			"version":     leave.Version,
+			"updated_at":  leave.UpdatedAt,
+		},
+	})
+}
+
+// RespondToLeave godoc
+// @Summary Answer a request for more information
+// @Description Student replies to an approver's question on a leave in needs_info, optionally rewording the reason. The reply is kept in the leave history and the leave goes back to pending.
+// @Tags Leaves
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Leave request ID"
+// @Param request body RespondLeaveRequest true "Reply and optional new reason"
+// @Success 200 {object} map[string]interface{} "Leave returned to pending"
+// @Failure 400 {object} map[string]interface{} "Validation failed or leave not awaiting information"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not your leave request"
+// @Failure 404 {object} map[string]interface{} "Leave request not found"
+// @Failure 409 {object} map[string]interface{} "Leave was modified concurrently"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /leaves/{id}/respond [post]
+func RespondToLeave(c *gin.Context) {
+	var input RespondLeaveRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+
+	if err := validation.ValidateStruct(input); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+
+	var leave LeaveRequest
+	if err := db.DB.First(&leave, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Leave request not found"})
+		return
+	}
+
+	userIDVal, _ := c.Get("userID")
+	studentID := userIDVal.(uint)
+	if leave.StudentID != studentID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only respond to your own leave requests"})
+		return
+	}
+
+	if leave.Status != "needs_info" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Leave request is not awaiting more information"})
+		return
+	}
+
+	if input.Version != leave.Version {
+		c.JSON(http.StatusConflict, gin.H{"error": "Leave request was modified since it was read", "current_version": leave.Version})
+		return
+	}
+
+	leave.Status = "pending"
+	if input.Reason != nil {
+		leave.Reason = *input.Reason
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		rows, err := updateIfVersion(tx.Where("status = ?", "needs_info"), &leave, "status", "reason")
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrLeaveVersionConflict
+		}
+		return recordLeaveEvent(tx, leave.ID, studentID, "needs_info", leave.Status, &input.Response, originOf(c))
+	})
+	if errors.Is(err, ErrLeaveVersionConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Leave request was modified concurrently"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update leave"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Response submitted; the leave request is pending again",
+		"leave_request": gin.H{
+			"id":         leave.ID,
+			"status":     leave.Status,
+			"reason":     leave.Reason,
+			"version":    leave.Version,
+			"updated_at": leave.UpdatedAt,
+		},
+	})
+}
+
+// BatchApproveLeaves godoc
+// @Summary Approve or reject several leaves at once
+// @Description Approver applies one decision to a list of leaves; rejecting needs remarks of at least 10 characters. Leaves outside the caller's scope or no longer pending are skipped with a reason instead of failing the batch.
+// @Tags Leaves
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BatchApproveRequest true "Leave IDs, action and optional remarks"
+// @Success 200 {object} map[string]interface{} "Per-leave results"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /leaves/batch-approve [post]
+func BatchApproveLeaves(c *gin.Context) {
+	var input BatchApproveRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+
+	if err := validation.ValidateStruct(input); err != nil {
+		errors := validation.FormatValidationErrorPathsIn(core.Language(c), err) // e.g. leave_ids[2]
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+
+	approverIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	approverID := approverIDVal.(uint)
+
+	role, _ := auth.CurrentRole(c)
+
+	approver, err := auth.CurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Approver not found"})
+		return
+	}
+
+	if input.Action == "reject" && !hasRejectionReason(input.Remarks) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rejectionReasonMessage})
+		return
+	}
+
+	newStatus := "approved"
+	if input.Action == "reject" {
+		newStatus = "rejected"
+	}
+
+	origin := originOf(c)
+	results := make([]BatchLeaveResult, 0, len(input.LeaveIDs))
+	var processed []LeaveRequest
+	seen := make(map[uint]bool)
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		for _, id := range input.LeaveIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			var leave LeaveRequest
+			if err := tx.First(&leave, id).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					results = append(results, BatchLeaveResult{LeaveID: id, Result: "skipped", Reason: "Leave request not found"})
+					continue
+				}
+				return err
+			}
+			if msg := selfApprovalError(approverID, &leave); msg != "" {
+				results = append(results, BatchLeaveResult{LeaveID: id, Result: "skipped", Reason: msg})
+				continue
+			}
+			if msg := approvalScopeError(role, approver, &leave); msg != "" {
+				results = append(results, BatchLeaveResult{LeaveID: id, Result: "skipped", Reason: msg})
+				continue
+			}
+			if leave.Status != "pending" {
+				results = append(results, BatchLeaveResult{LeaveID: id, Result: "skipped", Reason: "Leave request has already been processed"})
+				continue
+			}
+
+			previousStatus := leave.Status
+			leave.Status = newStatus
+			leave.ApprovedBy = &approverID
+			leave.Remarks = input.Remarks
+
+			err := applyPendingDecision(tx, &leave)
+			if errors.Is(err, ErrLeaveAlreadyProcessed) {
+				results = append(results, BatchLeaveResult{LeaveID: id, Result: "skipped", Reason: "Leave request was already processed by another approver"})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := recordLeaveEvent(tx, leave.ID, approverID, previousStatus, leave.Status, leave.Remarks, origin); err != nil {
+				return err
+			}
+
+			results = append(results, BatchLeaveResult{LeaveID: id, Result: newStatus})
+			processed = append(processed, leave)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process leave requests"})
+		return
+	}
+
+	// Notify only after the batch has been committed
+	for i := range processed {
+		notifyStatusChange(&processed[i])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Batch processed",
+		"processed": len(processed),
+		"skipped":   len(results) - len(processed),
+		"results":   results,
+	})
+}
+
+// OverrideLeave godoc
+// @Summary Override a leave decision
+// @Description Admin forces a leave to approved or rejected regardless of its current stage or approval scope. Admins cannot override leaves of their own or leaves they filed for a student unless LEAVE_ALLOW_SELF_APPROVAL=true.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Leave request ID"
+// @Param request body OverrideLeaveRequest true "Override decision and justification"
+// @Success 200 {object} map[string]interface{} "Leave request overridden successfully"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Leave request not found"
+// @Failure 409 {object} map[string]interface{} "Leave request was modified since it was read"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/leaves/{id}/override [post]
+func OverrideLeave(c *gin.Context) {
+	leaveID := c.Param("id")
+
+	var input OverrideLeaveRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+
+	if err := validation.ValidateStruct(input); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+
+	var leave LeaveRequest
+	if err := db.DB.First(&leave, leaveID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Leave request not found"})
+		return
+	}
+
+	if leave.Status == input.Status {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Leave request is already " + input.Status})
+		return
+	}
+
+	if input.Version != leave.Version {
+		c.JSON(http.StatusConflict, gin.H{"error": "Leave request was modified since it was read", "current_version": leave.Version})
+		return
+	}
+
+	adminIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	adminID := adminIDVal.(uint)
+
+	if msg := selfApprovalError(adminID, &leave); msg != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	// No dept/hostel scope checks here - admins may override any leave other than their own
+	previousStatus := leave.Status
+	leave.Status = input.Status
+	leave.ApprovedBy = &adminID
+	leave.Remarks = &input.Justification
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		rows, err := updateIfVersion(tx, &leave, "status", "approved_by", "remarks")
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrLeaveVersionConflict
+		}
+		if err := recordLeaveEvent(tx, leave.ID, adminID, previousStatus, leave.Status, leave.Remarks, originOf(c)); err != nil {
+			return err
+		}
+		details := fmt.Sprintf("status %s -> %s: %s", previousStatus, leave.Status, input.Justification)
+		return audit.Record(tx, adminID, "leave_override", "leave_request", leave.ID, details)
+	})
+	if errors.Is(err, ErrLeaveVersionConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Leave request was modified since it was read"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to override leave"})
+		return
+	}
+
+	notifyStatusChange(&leave)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Leave request overridden successfully",
+		"leave_request": gin.H{
+			"id":          leave.ID,
+			"status":      leave.Status,
+			"remarks":     leave.Remarks,
+			"approved_by": leave.ApprovedBy,
+			"version":     leave.Version,
+			"updated_at":  leave.UpdatedAt,
+		},
+	})
+}
+
+// notifyStatusChange notifies the student about a leave status change
+func notifyStatusChange(leave *LeaveRequest) {
+	userLeaveRequest := notificationLeave(leave)
+	if err := notifications.NotifyLeaveStatusChange(&userLeaveRequest); err != nil {
+		// Log error but don't fail the request
+		log.Printf("Failed to send leave status notification for leave %d: %v", leave.ID, err)
+	}
+
+	dispatchLeaveDecision(leave)
+}
+
+// notificationLeave converts the local LeaveRequest to the users.LeaveRequest the notifications package takes
+func notificationLeave(leave *LeaveRequest) users.LeaveRequest {
+	return users.LeaveRequest{
 		Model:      leave.Model,
 		StudentID:  leave.StudentID,
 		LeaveType:  leave.LeaveType,
@@ -382,20 +1238,25 @@ func ApproveRejectLeave(c *gin.Context) {
 		CreatedAt:  leave.CreatedAt,
 		UpdatedAt:  leave.UpdatedAt,
 	}
+}
 
-	if err := notifications.NotifyLeaveStatusChange(&userLeaveRequest); err != nil {
-		// Log error but don't fail the request
-		// In production, you might want to use a proper logging system
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Leave request updated successfully",
-		"leave_request": gin.H{
-			"id":          leave.ID,
+// dispatchLeaveDecision tells webhook subscribers about an approval or rejection
+func dispatchLeaveDecision(leave *LeaveRequest) {
+	// Integrations only hear about decisions
+	if leave.Status == "approved" || leave.Status == "rejected" {
+		webhooks.Dispatch("leave."+leave.Status, map[string]interface{}{
+			"leave_id":    leave.ID,
+			"student_id":  leave.StudentID,
+			"leave_type":  leave.LeaveType,
 			"status":      leave.Status,
-			"remarks":     leave.Remarks,
+			"start_date":  leave.StartDate,
+			"end_date":    leave.EndDate,
+			"days":        leave.Days,
+			"dept":        leave.Dept,
+			"hostel":      leave.Hostel,
 			"approved_by": leave.ApprovedBy,
+			"remarks":     leave.Remarks,
 			"updated_at":  leave.UpdatedAt,
-		},
-	})
+		})
+	}
 }
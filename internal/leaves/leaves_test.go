@@ -0,0 +1,1460 @@
+package leaves
+
+import (
+	"bytes"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/internal/notifications"
+	"campus-backend/internal/users"
+	"campus-backend/internal/webhooks"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	// File-backed so concurrent requests share one database
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	sqlDB, _ := testDB.DB()
+	sqlDB.SetMaxOpenConns(1) // SQLite allows a single writer
+
+	testDB.AutoMigrate(&users.User{}, &LeaveRequest{}, &LeaveEvent{}, &LeaveComment{}, &LeaveSeries{}, &notifications.Notification{}, &webhooks.Webhook{})
+	db.DB = testDB
+	return testDB
+}
+
+func createTestUser(t *testing.T, role, dept string, hostel *string) users.User {
+	user := users.User{
+		Name:     role + " user",
+		Email:    role + "-" + dept + "@example.com",
+		Password: "hashed",
+		Role:     role,
+		Dept:     dept,
+		Hostel:   hostel,
+		IsActive: true,
+	}
+	if err := db.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func createPendingLeave(t *testing.T, student users.User) LeaveRequest {
+	start := time.Now().Add(48 * time.Hour)
+	leave := LeaveRequest{
+		StudentID: student.ID,
+		LeaveType: "personal",
+		Reason:    "Family function at home",
+		StartDate: start,
+		EndDate:   start.Add(24 * time.Hour),
+		Status:    "pending",
+		Dept:      student.Dept,
+		Hostel:    student.Hostel,
+		Days:      2,
+	}
+	if err := db.DB.Create(&leave).Error; err != nil {
+		t.Fatal(err)
+	}
+	return leave
+}
+
+// newTestRouter injects the given user into the context in place of JWT auth
+func newTestRouter(user users.User) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userID", user.ID)
+		c.Set("email", user.Email)
+		c.Set("role", user.Role)
+		c.Next()
+	})
+	r.POST("/leaves/apply", ApplyLeave)
+	r.POST("/leaves/apply-for/:studentId", ApplyLeaveOnBehalf)
+	r.POST("/leaves/recurring", CreateRecurringLeave)
+	r.PUT("/leaves/recurring/:id/approve", DecideLeaveSeries)
+	r.DELETE("/leaves/recurring/:id", CancelLeaveSeries)
+	r.GET("/leaves/", ListLeaves)
+	r.GET("/leaves/active", ListActiveLeaves)
+	r.GET("/leaves/stats", GetLeaveStats)
+	r.GET("/admin/leaves/pipeline", GetLeavePipeline)
+	r.GET("/students/:id/approval-chain", GetApprovalChain)
+	r.GET("/leaves/:id", GetLeaveDetails)
+	r.GET("/leaves/:id/history", GetLeaveHistory)
+	r.GET("/leaves/:id/decision", GetLeaveDecision)
+	r.GET("/leaves/:id/certificate", GetLeaveCertificate)
+	r.PUT("/leaves/:id/approve", ApproveRejectLeave)
+	r.POST("/leaves/:id/respond", RespondToLeave)
+	r.GET("/leaves/:id/comments", ListLeaveComments)
+	r.POST("/leaves/:id/comments", AddLeaveComment)
+	r.POST("/leaves/batch-approve", BatchApproveLeaves)
+	r.POST("/admin/leaves/:id/override", OverrideLeave)
+	return r
+}
+
+func TestApplyPendingDecisionRejectsStaleLeave(t *testing.T) {
+	setupTestDB(t)
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+	leave := createPendingLeave(t, student)
+
+	// Both approvers read the leave while it was pending
+	first := leave
+	second := leave
+	first.Status = "approved"
+	second.Status = "rejected"
+
+	assert.NoError(t, applyPendingDecision(db.DB, &first))
+	assert.ErrorIs(t, applyPendingDecision(db.DB, &second), ErrLeaveAlreadyProcessed)
+
+	var stored LeaveRequest
+	db.DB.First(&stored, leave.ID)
+	assert.Equal(t, "approved", stored.Status)
+}
+
+func TestConcurrentApprovals(t *testing.T) {
+	setupTestDB(t)
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+	leave := createPendingLeave(t, student)
+	router := newTestRouter(faculty)
+
+	const approvers = 5
+	codes := make([]int, approvers)
+	var wg sync.WaitGroup
+	for i := 0; i < approvers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := bytes.NewBufferString(`{"action":"approve","version":1}`)
+			req := httptest.NewRequest(http.MethodPut, "/leaves/"+uintToString(leave.ID)+"/approve", body)
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			codes[i] = rec.Code
+		}(i)
+	}
+	wg.Wait()
+
+	// Exactly one approval wins; the rest see the leave as already processed
+	successes := 0
+	for _, code := range codes {
+		if code == http.StatusOK {
+			successes++
+		} else {
+			assert.Contains(t, []int{http.StatusBadRequest, http.StatusConflict}, code)
+		}
+	}
+	assert.Equal(t, 1, successes)
+
+	var events int64
+	db.DB.Model(&LeaveEvent{}).Where("leave_id = ? AND to_status = ?", leave.ID, "approved").Count(&events)
+	assert.Equal(t, int64(1), events)
+}
+
+func TestApproveRejectsStaleVersion(t *testing.T) {
+	setupTestDB(t)
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+	leave := createPendingLeave(t, student)
+	router := newTestRouter(faculty)
+
+	// Simulate another writer touching the leave after the client read version 1
+	db.DB.Model(&leave).Update("version", 2)
+
+	body := bytes.NewBufferString(`{"action":"approve","version":1}`)
+	req := httptest.NewRequest(http.MethodPut, "/leaves/"+uintToString(leave.ID)+"/approve", body)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// The current version goes through and is bumped
+	body = bytes.NewBufferString(`{"action":"approve","version":2}`)
+	req = httptest.NewRequest(http.MethodPut, "/leaves/"+uintToString(leave.ID)+"/approve", body)
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var stored LeaveRequest
+	db.DB.First(&stored, leave.ID)
+	assert.Equal(t, "approved", stored.Status)
+	assert.Equal(t, 3, stored.Version)
+}
+
+func TestApproveRejectsSelfApproval(t *testing.T) {
+	setupTestDB(t)
+	defer func() { core.AppConfig = nil }()
+	core.LoadConfig()
+	h1 := "H1"
+	admin := createTestUser(t, users.RoleAdmin, "ADMIN", nil)
+	warden := createTestUser(t, users.RoleWarden, "ADMIN", &h1)
+	student := createTestUser(t, users.RoleStudent, "CS", &h1)
+
+	// The admin's own leave, and one the warden filed for the student
+	own := createPendingLeave(t, admin)
+	filed := createPendingLeave(t, student)
+	db.DB.Model(&filed).Update("applied_by", warden.ID)
+
+	approve := func(approver users.User, leave LeaveRequest) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/leaves/"+uintToString(leave.ID)+"/approve", bytes.NewBufferString(`{"action":"approve","version":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		newTestRouter(approver).ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := approve(admin, own)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "your own leave request")
+
+	rec = approve(warden, filed)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "on someone else's behalf")
+
+	var stored LeaveRequest
+	db.DB.First(&stored, own.ID)
+	assert.Equal(t, "pending", stored.Status)
+
+	// Someone else may still decide it
+	assert.Equal(t, http.StatusOK, approve(admin, filed).Code)
+
+	t.Setenv("LEAVE_ALLOW_SELF_APPROVAL", "true")
+	core.LoadConfig()
+	assert.Equal(t, http.StatusOK, approve(admin, own).Code)
+}
+
+func TestRequestMoreInfo(t *testing.T) {
+	setupTestDB(t)
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+	leave := createPendingLeave(t, student)
+	id := uintToString(leave.ID)
+
+	send := func(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The question is required
+	rec := send(newTestRouter(faculty), http.MethodPut, "/leaves/"+id+"/approve", `{"action":"info_requested","version":1}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = send(newTestRouter(faculty), http.MethodPut, "/leaves/"+id+"/approve", `{"action":"info_requested","remarks":"Which city is the function in?","version":1}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var stored LeaveRequest
+	db.DB.First(&stored, leave.ID)
+	assert.Equal(t, "needs_info", stored.Status)
+	assert.Nil(t, stored.ApprovedBy)
+
+	var notification notifications.Notification
+	db.DB.Where("user_id = ?", student.ID).First(&notification)
+	assert.Contains(t, notification.Message, "sent back for more information. Remarks: Which city is the function in?")
+
+	// It stays in the approver's default queue but cannot be decided until the student replies
+	rec = send(newTestRouter(faculty), http.MethodGet, "/leaves/", "")
+	assert.Contains(t, rec.Body.String(), `"status":"needs_info"`)
+	rec = send(newTestRouter(faculty), http.MethodPut, "/leaves/"+id+"/approve", `{"action":"approve","version":2}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	other := createTestUser(t, users.RoleStudent, "EE", nil)
+	rec = send(newTestRouter(other), http.MethodPost, "/leaves/"+id+"/respond", `{"response":"Pune","version":2}`)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = send(newTestRouter(student), http.MethodPost, "/leaves/"+id+"/respond", `{"response":"Pune","reason":"Cousin's wedding in Pune","version":2}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	db.DB.First(&stored, leave.ID)
+	assert.Equal(t, "pending", stored.Status)
+	assert.Equal(t, "Cousin's wedding in Pune", stored.Reason)
+	assert.Equal(t, 3, stored.Version)
+
+	var events []LeaveEvent
+	db.DB.Where("leave_id = ?", leave.ID).Order("id ASC").Find(&events)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "needs_info", events[0].ToStatus)
+		assert.Equal(t, "pending", events[1].ToStatus)
+		assert.Equal(t, "Pune", *events[1].Remarks)
+	}
+
+	// A second reply is refused once the leave is pending again
+	rec = send(newTestRouter(student), http.MethodPost, "/leaves/"+id+"/respond", `{"response":"Pune","version":3}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = send(newTestRouter(faculty), http.MethodPut, "/leaves/"+id+"/approve", `{"action":"approve","version":3}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestLeaveDecision(t *testing.T) {
+	setupTestDB(t)
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+	leave := createPendingLeave(t, student)
+	id := uintToString(leave.ID)
+
+	decision := func(user users.User) (int, LeaveDecision) {
+		rec := httptest.NewRecorder()
+		newTestRouter(user).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/leaves/"+id+"/decision", nil))
+		var resp LeaveDecision
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+
+	code, resp := decision(student)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, LeaveDecision{LeaveID: leave.ID, Status: "pending"}, resp)
+
+	req := httptest.NewRequest(http.MethodPut, "/leaves/"+id+"/approve", bytes.NewBufferString(`{"action":"reject","remarks":"Exams are scheduled that week","version":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	newTestRouter(faculty).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	code, resp = decision(student)
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, resp.Decided)
+	assert.Equal(t, "rejected", resp.Status)
+	assert.Equal(t, faculty.Name, resp.ApproverName)
+	assert.Equal(t, faculty.ID, *resp.DecidedBy)
+	assert.NotNil(t, resp.DecidedAt)
+	assert.Equal(t, "Exams are scheduled that week", *resp.Remarks)
+
+	// The notification leads with the reason
+	var notification notifications.Notification
+	db.DB.Where("user_id = ?", student.ID).First(&notification)
+	assert.True(t, strings.HasSuffix(notification.Message, "has been rejected. Reason: Exams are scheduled that week"))
+
+	code, _ = decision(faculty)
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = decision(createTestUser(t, users.RoleStudent, "EE", nil))
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = decision(createTestUser(t, users.RoleFaculty, "EE", nil))
+	assert.Equal(t, http.StatusForbidden, code)
+}
+
+func TestGetLeaveHistory(t *testing.T) {
+	setupTestDB(t)
+	defer func() { core.AppConfig = nil }()
+	core.LoadConfig()
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+
+	send := func(user users.User, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		newTestRouter(user).ServeHTTP(rec, req)
+		return rec
+	}
+
+	start := time.Now().AddDate(0, 0, 3).UTC().Format(time.RFC3339)
+	end := time.Now().AddDate(0, 0, 4).UTC().Format(time.RFC3339)
+	rec := send(student, http.MethodPost, "/leaves/apply",
+		`{"leave_type":"medical","reason":"Medical appointment in town","start_date":"`+start+`","end_date":"`+end+`"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var applied struct {
+		LeaveRequest struct {
+			ID uint `json:"id"`
+		} `json:"leave_request"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &applied)
+	id := uintToString(applied.LeaveRequest.ID)
+
+	// Sent back for details, answered, then approved
+	assert.Equal(t, http.StatusOK, send(faculty, http.MethodPut, "/leaves/"+id+"/approve", `{"action":"info_requested","remarks":"Which clinic?","version":1}`).Code)
+	assert.Equal(t, http.StatusOK, send(student, http.MethodPost, "/leaves/"+id+"/respond", `{"response":"City clinic","version":2}`).Code)
+	assert.Equal(t, http.StatusOK, send(faculty, http.MethodPut, "/leaves/"+id+"/approve", `{"action":"approve","version":3}`).Code)
+
+	rec = send(student, http.MethodGet, "/leaves/"+id+"/history", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		LeaveID uint         `json:"leave_id"`
+		Status  string       `json:"status"`
+		History []LeaveEvent `json:"history"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, applied.LeaveRequest.ID, resp.LeaveID)
+	assert.Equal(t, "approved", resp.Status)
+
+	type transition struct {
+		from, to string
+		actor    uint
+	}
+	var got []transition
+	for _, event := range resp.History {
+		if assert.NotNil(t, event.Actor) {
+			assert.Equal(t, *event.ActorID, event.Actor.ID)
+		}
+		got = append(got, transition{event.FromStatus, event.ToStatus, *event.ActorID})
+	}
+	assert.Equal(t, []transition{
+		{"", "pending", student.ID},
+		{"pending", "needs_info", faculty.ID},
+		{"needs_info", "pending", student.ID},
+		{"pending", "approved", faculty.ID},
+	}, got)
+	if assert.Len(t, resp.History, 4) {
+		assert.Equal(t, "Which clinic?", *resp.History[1].Remarks)
+		assert.Equal(t, "City clinic", *resp.History[2].Remarks)
+	}
+
+	// Visible to those who can see the leave and nobody else
+	assert.Equal(t, http.StatusOK, send(faculty, http.MethodGet, "/leaves/"+id+"/history", "").Code)
+	assert.Equal(t, http.StatusForbidden, send(createTestUser(t, users.RoleStudent, "EE", nil), http.MethodGet, "/leaves/"+id+"/history", "").Code)
+	assert.Equal(t, http.StatusForbidden, send(createTestUser(t, users.RoleFaculty, "EE", nil), http.MethodGet, "/leaves/"+id+"/history", "").Code)
+	assert.Equal(t, http.StatusNotFound, send(student, http.MethodGet, "/leaves/9999/history", "").Code)
+}
+
+func TestRecurringLeave(t *testing.T) {
+	setupTestDB(t)
+	db.DB.AutoMigrate(&audit.AuditLog{})
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+
+	send := func(user users.User, method, path, body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		newTestRouter(user).ServeHTTP(rec, req)
+		var resp map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+
+	// Four Tuesdays, the second already covered by an approved leave
+	first := timeutil.Today().AddDate(0, 0, 7)
+	for first.In(timeutil.Location()).Weekday() != time.Tuesday {
+		first = timeutil.StartOfDay(first.In(timeutil.Location()).AddDate(0, 0, 1))
+	}
+	second := timeutil.StartOfDay(first.In(timeutil.Location()).AddDate(0, 0, 7))
+	// The fourth Tuesday is a holiday
+	fourth := first.In(timeutil.Location()).AddDate(0, 0, 21)
+	t.Setenv("CAMPUS_HOLIDAYS", fourth.Format(timeutil.DateLayout)+"=Founders Day")
+	core.LoadConfig()
+	defer func() { core.AppConfig = nil }()
+	existing := LeaveRequest{StudentID: student.ID, LeaveType: "personal", Reason: "Family function at home", StartDate: second, EndDate: second, Status: "approved", Dept: "CS", Days: 1}
+	db.DB.Create(&existing)
+	body := func(weekdays string, until time.Time) string {
+		return `{"leave_type":"medical","reason":"Weekly physiotherapy session","weekdays":` + weekdays +
+			`,"start_date":"` + first.Format(time.RFC3339) + `","until":"` + until.Format(time.RFC3339) + `"}`
+	}
+
+	code, _ := send(student, http.MethodPost, "/leaves/recurring", body(`["someday"]`, first.AddDate(0, 0, 27)))
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = send(student, http.MethodPost, "/leaves/recurring", body(`["monday","tuesday"]`, first.AddDate(1, 0, 0)))
+	assert.Equal(t, http.StatusBadRequest, code, "too many occurrences")
+
+	// Weekends need no leave
+	code, resp := send(student, http.MethodPost, "/leaves/recurring", body(`["saturday","sunday"]`, first.AddDate(0, 0, 13)))
+	assert.Equal(t, http.StatusBadRequest, code)
+	if skipped, ok := resp["skipped"].([]interface{}); assert.True(t, ok) && assert.Len(t, skipped, 4) {
+		assert.Equal(t, "weekend", skipped[0].(map[string]interface{})["reason"])
+	}
+
+	code, resp = send(student, http.MethodPost, "/leaves/recurring", body(`["Tuesday"]`, first.AddDate(0, 0, 27)))
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Len(t, resp["leaves"], 2)
+	if skipped, ok := resp["skipped"].([]interface{}); assert.True(t, ok) && assert.Len(t, skipped, 2) {
+		assert.Equal(t, "overlap", skipped[0].(map[string]interface{})["reason"])
+		assert.Equal(t, float64(existing.ID), skipped[0].(map[string]interface{})["conflicting_leave_id"])
+		assert.Equal(t, "holiday", skipped[1].(map[string]interface{})["reason"])
+	}
+	seriesID := uintToString(uint(resp["series"].(map[string]interface{})["ID"].(float64)))
+
+	code, _ = send(faculty, http.MethodPut, "/leaves/recurring/"+seriesID+"/approve", `{"action":"reject"}`)
+	assert.Equal(t, http.StatusBadRequest, code, "rejections need a reason")
+	code, _ = send(createTestUser(t, users.RoleFaculty, "EE", nil), http.MethodPut, "/leaves/recurring/"+seriesID+"/approve", `{"action":"approve"}`)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, resp = send(faculty, http.MethodPut, "/leaves/recurring/"+seriesID+"/approve", `{"action":"approve"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, resp["decided"], 2)
+	var notified int64
+	db.DB.Model(&notifications.Notification{}).Where("user_id = ?", student.ID).Count(&notified)
+	assert.Equal(t, int64(1), notified)
+
+	// Occurrences are ordinary approved leaves to the attendance cross-check
+	var onLeave users.LeaveRequest
+	err := db.DB.Where("student_id = ? AND status = ? AND start_date <= ? AND end_date >= ?", student.ID, "approved", first, first).First(&onLeave).Error
+	assert.NoError(t, err)
+
+	code, _ = send(createTestUser(t, users.RoleStudent, "EE", nil), http.MethodDelete, "/leaves/recurring/"+seriesID, "")
+	assert.Equal(t, http.StatusForbidden, code)
+	code, resp = send(student, http.MethodDelete, "/leaves/recurring/"+seriesID, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(2), resp["withdrawn"])
+
+	// Each withdrawn leave's history ends with the cancellation, and the student hears about it once
+	var cancelled []LeaveEvent
+	db.DB.Where("to_status = ?", LeaveCancelled).Find(&cancelled)
+	if assert.Len(t, cancelled, 2) {
+		assert.Equal(t, "approved", cancelled[0].FromStatus)
+		assert.Equal(t, student.ID, *cancelled[0].ActorID)
+	}
+	db.DB.Model(&notifications.Notification{}).Where("user_id = ?", student.ID).Count(&notified)
+	assert.Equal(t, int64(2), notified)
+
+	var remaining int64
+	db.DB.Model(&LeaveRequest{}).Where("series_id IS NOT NULL").Count(&remaining)
+	assert.Zero(t, remaining)
+	code, _ = send(student, http.MethodDelete, "/leaves/recurring/"+seriesID, "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = send(faculty, http.MethodPut, "/leaves/recurring/"+seriesID+"/approve", `{"action":"approve"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestLeaveComments(t *testing.T) {
+	setupTestDB(t)
+	hostel, otherHostel := "H1", "H2"
+	student := createTestUser(t, users.RoleStudent, "CS", &hostel)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+	warden := createTestUser(t, users.RoleWarden, "ADMIN", &hostel)
+	outsider := createTestUser(t, users.RoleWarden, "EE", &otherHostel)
+	leave := createPendingLeave(t, student)
+	path := "/leaves/" + uintToString(leave.ID) + "/comments"
+
+	comment := func(user users.User, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		newTestRouter(user).ServeHTTP(rec, req)
+		return rec
+	}
+	notified := func(user users.User) int64 {
+		var count int64
+		db.DB.Model(&notifications.Notification{}).Where("user_id = ? AND type = ?", user.ID, "leave_comment").Count(&count)
+		return count
+	}
+
+	assert.Equal(t, http.StatusBadRequest, comment(student, `{"body":"   "}`).Code)
+	assert.Equal(t, http.StatusBadRequest, comment(student, `{"body":"`+strings.Repeat("a", 1001)+`"}`).Code)
+	assert.Equal(t, http.StatusForbidden, comment(outsider, `{"body":"Not my hostel"}`).Code)
+
+	// With no staff involved yet, the student's comment reaches everyone who can approve
+	rec := comment(student, `{"body":"I can share the invitation if needed"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, int64(1), notified(faculty))
+	assert.Equal(t, int64(1), notified(warden))
+	assert.Equal(t, int64(0), notified(outsider))
+
+	rec = comment(faculty, `{"body":"Please do"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, int64(1), notified(student))
+
+	// Once staff have joined, only they hear about the student's replies
+	assert.Equal(t, http.StatusCreated, comment(student, `{"body":"Uploaded it"}`).Code)
+	assert.Equal(t, int64(2), notified(faculty))
+	assert.Equal(t, int64(1), notified(warden))
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	rec = httptest.NewRecorder()
+	newTestRouter(warden).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		Comments []LeaveComment `json:"comments"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if assert.Len(t, resp.Comments, 3) {
+		assert.Equal(t, "I can share the invitation if needed", resp.Comments[0].Body)
+		assert.Equal(t, faculty.ID, resp.Comments[1].AuthorID)
+		assert.Equal(t, "faculty user", resp.Comments[1].Author.Name)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, path, nil)
+	rec = httptest.NewRecorder()
+	newTestRouter(outsider).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestBatchApproveSkipsOutOfScopeAndProcessed(t *testing.T) {
+	setupTestDB(t)
+	h1, h2 := "H1", "H2"
+	warden := createTestUser(t, users.RoleWarden, "ADMIN", &h1)
+	inHostel := createTestUser(t, users.RoleStudent, "CS", &h1)
+	otherHostel := createTestUser(t, users.RoleStudent, "EE", &h2)
+
+	pending := createPendingLeave(t, inHostel)
+	alreadyDone := createPendingLeave(t, inHostel)
+	db.DB.Model(&alreadyDone).Update("status", "approved")
+	outOfScope := createPendingLeave(t, otherHostel)
+
+	router := newTestRouter(warden)
+	body := bytes.NewBufferString(`{"action":"approve","leave_ids":[` +
+		uintToString(pending.ID) + `,` + uintToString(alreadyDone.ID) + `,` + uintToString(outOfScope.ID) + `,999]}`)
+	req := httptest.NewRequest(http.MethodPost, "/leaves/batch-approve", body)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		Processed int                `json:"processed"`
+		Skipped   int                `json:"skipped"`
+		Results   []BatchLeaveResult `json:"results"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 1, resp.Processed)
+	assert.Equal(t, 3, resp.Skipped)
+	assert.Len(t, resp.Results, 4)
+	assert.Equal(t, "approved", resp.Results[0].Result)
+	for _, r := range resp.Results[1:] {
+		assert.Equal(t, "skipped", r.Result)
+		assert.NotEmpty(t, r.Reason)
+	}
+
+	var stored LeaveRequest
+	db.DB.First(&stored, outOfScope.ID)
+	assert.Equal(t, "pending", stored.Status)
+
+	var notified int64
+	db.DB.Model(&notifications.Notification{}).Where("user_id = ?", inHostel.ID).Count(&notified)
+	assert.Equal(t, int64(1), notified)
+}
+
+func TestListLeavesWardenWithoutHostel(t *testing.T) {
+	setupTestDB(t)
+	warden := createTestUser(t, users.RoleWarden, "ADMIN", nil)
+	router := newTestRouter(warden)
+
+	req := httptest.NewRequest(http.MethodGet, "/leaves/", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "no hostel assigned")
+}
+
+func TestListActiveLeavesScopedToHostel(t *testing.T) {
+	setupTestDB(t)
+	h1, h2 := "H1", "H2"
+	warden := createTestUser(t, users.RoleWarden, "ADMIN", &h1)
+	inHostel := createTestUser(t, users.RoleStudent, "CS", &h1)
+	otherHostel := createTestUser(t, users.RoleStudent, "EE", &h2)
+
+	today := timeutil.Today()
+	withStatus := func(student users.User, status string, start, end time.Time) LeaveRequest {
+		leave := createPendingLeave(t, student)
+		db.DB.Model(&leave).Updates(map[string]interface{}{"status": status, "start_date": start, "end_date": end})
+		return leave
+	}
+	active := withStatus(inHostel, "approved", today.AddDate(0, 0, -1), today)
+	withStatus(inHostel, "pending", today, today.AddDate(0, 0, 1))
+	withStatus(inHostel, "approved", today.AddDate(0, 0, -3), today.AddDate(0, 0, -1))
+	withStatus(otherHostel, "approved", today, today)
+
+	router := newTestRouter(warden)
+	req := httptest.NewRequest(http.MethodGet, "/leaves/active", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		Leaves []ActiveLeave `json:"leaves"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Leaves, 1) {
+		assert.Equal(t, active.ID, resp.Leaves[0].LeaveID)
+		assert.Equal(t, inHostel.Email, resp.Leaves[0].Email)
+	}
+
+	// The earlier leave is active on an explicit past date
+	req = httptest.NewRequest(http.MethodGet, "/leaves/active?date="+today.AddDate(0, 0, -2).In(timeutil.Location()).Format(timeutil.DateLayout), nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Len(t, resp.Leaves, 1)
+}
+
+func TestEmergencyContactShownToWardens(t *testing.T) {
+	setupTestDB(t)
+	hostel := "H1"
+	warden := createTestUser(t, users.RoleWarden, "ADMIN", &hostel)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+	student := createTestUser(t, users.RoleStudent, "CS", &hostel)
+	db.DB.Model(&student).Updates(map[string]interface{}{"emergency_contact_name": "Asha Rao", "emergency_contact_phone": "+919800000000"})
+
+	today := timeutil.Today()
+	leave := createPendingLeave(t, student)
+	db.DB.Model(&leave).Updates(map[string]interface{}{"status": "approved", "start_date": today, "end_date": today})
+
+	get := func(user users.User, path string) string {
+		rec := httptest.NewRecorder()
+		newTestRouter(user).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		return rec.Body.String()
+	}
+	contact := `"emergency_contact":{"name":"Asha Rao","phone":"+919800000000"}`
+
+	assert.Contains(t, get(warden, "/leaves/active"), contact)
+	assert.Contains(t, get(warden, "/leaves/"+uintToString(leave.ID)), contact)
+	assert.NotContains(t, get(faculty, "/leaves/active"), "Asha Rao")
+	assert.NotContains(t, get(faculty, "/leaves/"+uintToString(leave.ID)), "Asha Rao")
+}
+
+func TestLeaveCertificate(t *testing.T) {
+	setupTestDB(t)
+	h1 := "H1"
+	warden := createTestUser(t, users.RoleWarden, "ADMIN", &h1)
+	student := createTestUser(t, users.RoleStudent, "CS", &h1)
+	other := createTestUser(t, users.RoleStudent, "EE", nil)
+	leave := createPendingLeave(t, student)
+	path := "/leaves/" + uintToString(leave.ID) + "/certificate"
+
+	get := func(user users.User) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		newTestRouter(user).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusBadRequest, get(student).Code)
+
+	db.DB.Model(&leave).Updates(map[string]interface{}{"status": "approved", "approved_by": warden.ID})
+
+	rec := get(student)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "attachment")
+	assert.True(t, bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF")))
+
+	assert.Equal(t, http.StatusOK, get(warden).Code)
+	assert.Equal(t, http.StatusForbidden, get(other).Code)
+}
+
+func TestGetLeaveStats(t *testing.T) {
+	setupTestDB(t)
+	csFaculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+	eeFaculty := createTestUser(t, users.RoleFaculty, "EE", nil)
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+
+	withStatus := func(leaveType, status string, days int) {
+		leave := createPendingLeave(t, student)
+		db.DB.Model(&leave).Updates(map[string]interface{}{"leave_type": leaveType, "status": status, "days": days})
+	}
+	withStatus("personal", "approved", 2)
+	withStatus("personal", "approved", 3)
+	withStatus("personal", "rejected", 1)
+	withStatus("medical", "approved", 4)
+	withStatus("medical", "pending", 2)
+
+	get := func(user users.User, query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		newTestRouter(user).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/leaves/stats"+query, nil))
+		return rec
+	}
+
+	rec := get(student, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var stats LeaveStats
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	assert.Equal(t, 5, stats.TotalLeaves)
+	assert.Equal(t, 3, stats.Approved)
+	assert.Equal(t, 1, stats.Rejected)
+	assert.Equal(t, 1, stats.Pending)
+	assert.Equal(t, 9, stats.ApprovedDays)
+	assert.Equal(t, []LeaveTypeStats{
+		{LeaveType: "medical", Total: 2, Approved: 1, Pending: 1, ApprovedDays: 4},
+		{LeaveType: "personal", Total: 3, Approved: 2, Rejected: 1, ApprovedDays: 5},
+	}, stats.ByType)
+
+	query := "?student_id=" + uintToString(student.ID)
+	assert.Equal(t, http.StatusOK, get(csFaculty, query).Code)
+	assert.Equal(t, http.StatusForbidden, get(eeFaculty, query).Code)
+	assert.Equal(t, http.StatusBadRequest, get(csFaculty, "").Code)
+	assert.Equal(t, http.StatusNotFound, get(csFaculty, "?student_id="+uintToString(eeFaculty.ID)).Code)
+}
+
+func TestGetLeavePipeline(t *testing.T) {
+	setupTestDB(t)
+	h1, h2 := "H1", "H2"
+	admin := createTestUser(t, users.RoleAdmin, "ADMIN", nil)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+	warden := createTestUser(t, users.RoleWarden, "ADMIN", &h1)
+	csStudent := createTestUser(t, users.RoleStudent, "CS", &h1)
+	eeStudent := createTestUser(t, users.RoleStudent, "EE", &h2)
+
+	routed := createPendingLeave(t, csStudent)
+	unroutable := createPendingLeave(t, eeStudent)
+	flagged := createPendingLeave(t, csStudent)
+	db.DB.Model(&routed).UpdateColumn("created_at", time.Now().AddDate(0, 0, -5))
+	db.DB.Model(&unroutable).UpdateColumn("created_at", time.Now().AddDate(0, 0, -3))
+	db.DB.Model(&flagged).Updates(map[string]interface{}{"flagged_at": time.Now(), "flag_reason": "Start date passed"})
+	decided := createPendingLeave(t, eeStudent)
+	db.DB.Model(&decided).Update("status", "approved")
+	awaitingStudent := createPendingLeave(t, csStudent)
+	db.DB.Model(&awaitingStudent).Update("status", "needs_info")
+
+	get := func(query string) []PipelineLeave {
+		rec := httptest.NewRecorder()
+		newTestRouter(admin).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/leaves/pipeline"+query, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var resp struct {
+			Leaves []PipelineLeave `json:"leaves"`
+		}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp.Leaves
+	}
+
+	leaves := get("")
+	if assert.Len(t, leaves, 4) {
+		assert.Equal(t, routed.ID, leaves[0].LeaveID)
+		assert.Equal(t, StageAwaitingApproval, leaves[0].Stage)
+		assert.Equal(t, 5, leaves[0].PendingDays)
+		assert.ElementsMatch(t, []uint{faculty.ID, warden.ID}, []uint{leaves[0].Approvers[0].ID, leaves[0].Approvers[1].ID})
+
+		assert.Equal(t, unroutable.ID, leaves[1].LeaveID)
+		assert.Equal(t, StageUnroutable, leaves[1].Stage)
+		assert.Empty(t, leaves[1].Approvers)
+
+		assert.Equal(t, flagged.ID, leaves[2].LeaveID)
+		assert.Equal(t, StageFlagged, leaves[2].Stage)
+
+		assert.Equal(t, awaitingStudent.ID, leaves[3].LeaveID)
+		assert.Equal(t, StageAwaitingStudent, leaves[3].Stage)
+	}
+
+	leaves = get("?older_than_days=4")
+	if assert.Len(t, leaves, 1) {
+		assert.Equal(t, routed.ID, leaves[0].LeaveID)
+	}
+	assert.Len(t, get("?dept=EE"), 1)
+}
+
+func TestGetApprovalChain(t *testing.T) {
+	setupTestDB(t)
+	hostel := "H1"
+	student := createTestUser(t, users.RoleStudent, "CS", &hostel)
+	dayScholar := createTestUser(t, users.RoleStudent, "EE", nil)
+	warden := createTestUser(t, users.RoleWarden, "ADMIN", &hostel)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+	admin := createTestUser(t, users.RoleAdmin, "ADMIN", nil)
+
+	get := func(user users.User, id uint) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		newTestRouter(user).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/students/"+uintToString(id)+"/approval-chain", nil))
+		return rec
+	}
+
+	rec := get(student, student.ID)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Routable bool           `json:"routable"`
+		Chain    []ApprovalStep `json:"chain"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.True(t, resp.Routable)
+	if assert.Len(t, resp.Chain, 3) {
+		assert.Equal(t, users.RoleWarden, resp.Chain[0].Role)
+		assert.Equal(t, warden.ID, resp.Chain[0].Approvers[0].ID)
+		assert.Equal(t, users.RoleFaculty, resp.Chain[1].Role)
+		assert.Equal(t, faculty.ID, resp.Chain[1].Approvers[0].ID)
+		assert.Equal(t, users.RoleAdmin, resp.Chain[2].Role)
+		assert.Equal(t, 3, resp.Chain[2].Step)
+	}
+
+	assert.Equal(t, http.StatusForbidden, get(student, dayScholar.ID).Code)
+	assert.Equal(t, http.StatusNotFound, get(admin, faculty.ID).Code)
+
+	// No hostel and no EE faculty: only admins can act
+	rec = get(faculty, dayScholar.ID)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.False(t, resp.Routable)
+	if assert.Len(t, resp.Chain, 2) {
+		assert.Empty(t, resp.Chain[0].Approvers)
+		assert.Equal(t, admin.ID, resp.Chain[1].Approvers[0].ID)
+	}
+}
+
+func TestApplyLeaveUsesConfiguredLeaveTypes(t *testing.T) {
+	setupTestDB(t)
+	defer func() { core.AppConfig = nil }()
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+	router := newTestRouter(student)
+
+	apply := func() *httptest.ResponseRecorder {
+		start := time.Now().AddDate(0, 0, 7).UTC().Format(time.RFC3339)
+		end := time.Now().AddDate(0, 0, 8).UTC().Format(time.RFC3339)
+		body := bytes.NewBufferString(`{"leave_type":"sports","reason":"Inter-college tournament","start_date":"` + start + `","end_date":"` + end + `"}`)
+		req := httptest.NewRequest(http.MethodPost, "/leaves/apply", body)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Not in the default set
+	core.LoadConfig()
+	rec := apply()
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "LeaveType must be one of: medical personal emergency academic")
+
+	t.Setenv("LEAVE_TYPES", "medical,personal,emergency,academic,sports")
+	core.LoadConfig()
+	assert.Equal(t, http.StatusCreated, apply().Code)
+}
+
+func TestApplyLeaveMinimumNotice(t *testing.T) {
+	setupTestDB(t)
+	defer func() { core.AppConfig = nil }()
+	t.Setenv("LEAVE_MIN_NOTICE_DAYS", "personal=2,emergency=5")
+	core.LoadConfig()
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+	router := newTestRouter(student)
+
+	apply := func(leaveType string, start time.Time) *httptest.ResponseRecorder {
+		from := start.UTC().Format(time.RFC3339)
+		to := start.Add(time.Hour).UTC().Format(time.RFC3339)
+		body := bytes.NewBufferString(`{"leave_type":"` + leaveType + `","reason":"Family function at home","start_date":"` + from + `","end_date":"` + to + `"}`)
+		req := httptest.NewRequest(http.MethodPost, "/leaves/apply", body)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Personal leave needs two days' notice, counted in campus days
+	rec := apply("personal", time.Now().AddDate(0, 0, 1))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	earliest := timeutil.StartOfDay(timeutil.Now().AddDate(0, 0, 2)).In(timeutil.Location()).Format(timeutil.DateLayout)
+	assert.Contains(t, rec.Body.String(), "personal leave requires at least 2 day(s) notice; the earliest allowed start date is "+earliest)
+	assert.Equal(t, http.StatusCreated, apply("personal", time.Now().AddDate(0, 0, 2)).Code)
+
+	// Emergencies ignore any configured notice, and types without a rule need none
+	assert.Equal(t, http.StatusCreated, apply("emergency", time.Now().Add(time.Hour)).Code)
+	assert.Equal(t, http.StatusCreated, apply("medical", time.Now().AddDate(0, 0, 1).Add(2*time.Hour)).Code)
+}
+
+func TestApplyLeaveOverlapPolicy(t *testing.T) {
+	setupTestDB(t)
+	defer func() { core.AppConfig = nil }()
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+	existing := createPendingLeave(t, student)
+	router := newTestRouter(student)
+
+	// Covers the existing leave entirely
+	apply := func(leaveType string) *httptest.ResponseRecorder {
+		start := existing.StartDate.Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+		end := existing.EndDate.Add(24 * time.Hour).UTC().Format(time.RFC3339)
+		body := bytes.NewBufferString(`{"leave_type":"` + leaveType + `","reason":"Family emergency back home","start_date":"` + start + `","end_date":"` + end + `"}`)
+		req := httptest.NewRequest(http.MethodPost, "/leaves/apply", body)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	core.LoadConfig()
+	rec := apply("emergency")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var resp struct {
+		ConflictingLeave struct {
+			ID        uint   `json:"id"`
+			LeaveType string `json:"leave_type"`
+		} `json:"conflicting_leave"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, existing.ID, resp.ConflictingLeave.ID)
+	assert.Equal(t, "personal", resp.ConflictingLeave.LeaveType)
+
+	t.Setenv("LEAVE_OVERLAP_ALLOWED", "emergency=personal|academic")
+	core.LoadConfig()
+	assert.Equal(t, http.StatusCreated, apply("emergency").Code)
+
+	// The policy is one-way, and the new emergency leave now blocks too
+	assert.Equal(t, http.StatusBadRequest, apply("personal").Code)
+	assert.Equal(t, http.StatusBadRequest, apply("emergency").Code)
+}
+
+func TestProcessStaleLeaves(t *testing.T) {
+	setupTestDB(t)
+	db.DB.AutoMigrate(&audit.AuditLog{})
+	defer func() { core.AppConfig = nil }()
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+
+	stale := createPendingLeave(t, student)
+	db.DB.Model(&stale).Update("start_date", timeutil.Today().AddDate(0, 0, -2))
+	upcoming := createPendingLeave(t, student)
+	// Waiting on the student's reply is as stale as waiting on an approver
+	unanswered := createPendingLeave(t, student)
+	db.DB.Model(&unanswered).Updates(map[string]interface{}{"status": "needs_info", "start_date": timeutil.Today().AddDate(0, 0, -2)})
+
+	// Flag mode leaves the status alone and only flags once
+	t.Setenv("LEAVE_STALE_ACTION", "flag")
+	core.LoadConfig()
+	result, err := ProcessStaleLeaves(audit.SystemActorID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []uint{stale.ID, unanswered.ID}, result.Processed)
+
+	var stored LeaveRequest
+	db.DB.First(&stored, stale.ID)
+	assert.Equal(t, "pending", stored.Status)
+	assert.NotNil(t, stored.FlaggedAt)
+	var awaiting LeaveRequest
+	db.DB.First(&awaiting, unanswered.ID)
+	assert.Equal(t, "needs_info", awaiting.Status)
+	assert.NotNil(t, awaiting.FlaggedAt)
+
+	result, err = ProcessStaleLeaves(audit.SystemActorID)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Processed)
+
+	// A grace period longer than the delay keeps the leave out of reach
+	t.Setenv("LEAVE_STALE_ACTION", "reject")
+	t.Setenv("LEAVE_STALE_GRACE_DAYS", "3")
+	core.LoadConfig()
+	result, err = ProcessStaleLeaves(audit.SystemActorID)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Processed)
+
+	// Reject mode rejects with remarks, records history and audit, and notifies
+	t.Setenv("LEAVE_STALE_GRACE_DAYS", "1")
+	core.LoadConfig()
+	result, err = ProcessStaleLeaves(audit.SystemActorID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []uint{stale.ID, unanswered.ID}, result.Processed)
+
+	db.DB.First(&stored, stale.ID)
+	assert.Equal(t, "rejected", stored.Status)
+	assert.Equal(t, "auto-rejected: not actioned in time", *stored.Remarks)
+	db.DB.First(&awaiting, unanswered.ID)
+	assert.Equal(t, "rejected", awaiting.Status)
+	assert.NoError(t, db.DB.Where("leave_id = ? AND from_status = ? AND to_status = ?", unanswered.ID, "needs_info", "rejected").First(&LeaveEvent{}).Error)
+
+	var untouched LeaveRequest
+	db.DB.First(&untouched, upcoming.ID)
+	assert.Equal(t, "pending", untouched.Status)
+
+	var event LeaveEvent
+	assert.NoError(t, db.DB.Where("leave_id = ? AND to_status = ?", stale.ID, "rejected").First(&event).Error)
+	assert.Nil(t, event.ActorID)
+
+	var audits int64
+	db.DB.Model(&audit.AuditLog{}).Where("entity_id = ?", stale.ID).Count(&audits)
+	assert.Equal(t, int64(2), audits) // flag + reject
+
+	var notified int64
+	db.DB.Model(&notifications.Notification{}).Where("user_id = ?", student.ID).Count(&notified)
+	assert.Equal(t, int64(4), notified) // flag + reject for each leave
+}
+
+func TestEscalateOverdueLeaves(t *testing.T) {
+	setupTestDB(t)
+	db.DB.AutoMigrate(&audit.AuditLog{})
+	defer func() { core.AppConfig = nil }()
+	core.LoadConfig()
+
+	hostel := "H1"
+	student := createTestUser(t, users.RoleStudent, "CS", &hostel)
+	dayScholar := createTestUser(t, users.RoleStudent, "EE", nil)
+	warden := createTestUser(t, users.RoleWarden, "ADMIN", &hostel)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+	admin := createTestUser(t, users.RoleAdmin, "ADMIN", nil)
+
+	overdue := createPendingLeave(t, student)
+	overdueNoHostel := createPendingLeave(t, dayScholar)
+	recent := createPendingLeave(t, student)
+	for _, leave := range []LeaveRequest{overdue, overdueNoHostel} {
+		db.DB.Model(&LeaveRequest{}).Where("id = ?", leave.ID).Update("created_at", time.Now().Add(-72*time.Hour))
+	}
+
+	result, err := EscalateOverdueLeaves(audit.SystemActorID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []uint{overdue.ID, overdueNoHostel.ID}, result.Escalated)
+
+	// The hostel leave sat with the warden, so faculty hear about it; the other goes to admins
+	notified := func(userID, leaveID uint) int64 {
+		var count int64
+		db.DB.Model(&notifications.Notification{}).Where("user_id = ? AND related_id = ? AND type = ?", userID, leaveID, "leave_escalation").Count(&count)
+		return count
+	}
+	assert.Equal(t, int64(1), notified(faculty.ID, overdue.ID))
+	assert.Equal(t, int64(0), notified(warden.ID, overdue.ID))
+	assert.Equal(t, int64(1), notified(admin.ID, overdueNoHostel.ID))
+
+	var stored LeaveRequest
+	db.DB.First(&stored, overdue.ID)
+	assert.Equal(t, "pending", stored.Status)
+	assert.NotNil(t, stored.EscalatedAt)
+
+	// Each leave is escalated once
+	result, err = EscalateOverdueLeaves(audit.SystemActorID)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Escalated)
+	var audits int64
+	db.DB.Model(&audit.AuditLog{}).Where("action = ?", "leave_escalate").Count(&audits)
+	assert.Equal(t, int64(2), audits)
+
+	// Approvers see the deadline; students do not
+	detail := func(user users.User, leaveID uint) map[string]interface{} {
+		w := httptest.NewRecorder()
+		newTestRouter(user).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/leaves/"+uintToString(leaveID), nil))
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+	assert.Equal(t, true, detail(faculty, overdue.ID)["is_overdue"])
+	assert.NotEmpty(t, detail(faculty, recent.ID)["deadline_at"])
+	assert.Nil(t, detail(faculty, recent.ID)["is_overdue"])
+	assert.Nil(t, detail(student, overdue.ID)["deadline_at"])
+
+	// A zero deadline turns the whole feature off
+	t.Setenv("LEAVE_APPROVAL_DEADLINE_HOURS", "0")
+	core.LoadConfig()
+	assert.Nil(t, detail(faculty, recent.ID)["deadline_at"])
+}
+
+func uintToString(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+func TestRejectRequiresRemarks(t *testing.T) {
+	setupTestDB(t)
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+	leave := createPendingLeave(t, student)
+	router := newTestRouter(faculty)
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	approvePath := "/leaves/" + uintToString(leave.ID) + "/approve"
+
+	for _, body := range []string{
+		`{"action":"reject","version":1}`,
+		`{"action":"reject","remarks":"   no   ","version":1}`,
+	} {
+		rec := send(http.MethodPut, approvePath, body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "at least 10 characters are required when rejecting")
+	}
+	rec := send(http.MethodPost, "/leaves/batch-approve", `{"action":"reject","leave_ids":[`+uintToString(leave.ID)+`]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = send(http.MethodPut, approvePath, `{"action":"reject","remarks":"Clashes with the mid-semester exams","version":1}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Approving still needs no remarks
+	other := createPendingLeave(t, student)
+	assert.Equal(t, http.StatusOK, send(http.MethodPut, "/leaves/"+uintToString(other.ID)+"/approve", `{"action":"approve","version":1}`).Code)
+}
+
+func TestListLeavesIncludeDeleted(t *testing.T) {
+	setupTestDB(t)
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+	admin := createTestUser(t, users.RoleAdmin, "ADMIN", nil)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+	kept := createPendingLeave(t, student)
+	deleted := createPendingLeave(t, student)
+	db.DB.Delete(&deleted)
+	db.DB.Delete(&student) // Its preloaded student is deleted too
+
+	list := func(user users.User, query string) (int, []LeaveRequest) {
+		rec := httptest.NewRecorder()
+		newTestRouter(user).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/leaves/"+query, nil))
+		var resp struct {
+			Leaves []LeaveRequest `json:"leaves"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp.Leaves
+	}
+
+	code, leaves := list(admin, "")
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, leaves, 1) {
+		assert.Equal(t, kept.ID, leaves[0].ID)
+		assert.False(t, leaves[0].Deleted)
+	}
+
+	code, leaves = list(admin, "?include_deleted=true")
+	assert.Equal(t, http.StatusOK, code)
+	flagged := map[uint]bool{}
+	for _, leave := range leaves {
+		flagged[leave.ID] = leave.Deleted
+		assert.Equal(t, student.ID, leave.Student.ID)
+	}
+	assert.Equal(t, map[uint]bool{kept.ID: false, deleted.ID: true}, flagged)
+
+	code, _ = list(faculty, "?include_deleted=true")
+	assert.Equal(t, http.StatusForbidden, code)
+}
+
+func TestListLeavesMultipleStatuses(t *testing.T) {
+	setupTestDB(t)
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+	admin := createTestUser(t, users.RoleAdmin, "ADMIN", nil)
+	byStatus := map[string]uint{}
+	for _, status := range []string{"pending", "needs_info", "approved", "rejected"} {
+		leave := createPendingLeave(t, student)
+		db.DB.Model(&leave).Update("status", status)
+		byStatus[status] = leave.ID
+	}
+
+	list := func(user users.User, query string) (int, []uint) {
+		rec := httptest.NewRecorder()
+		newTestRouter(user).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/leaves/"+query, nil))
+		var resp struct {
+			Leaves []LeaveRequest `json:"leaves"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		ids := []uint{}
+		for _, leave := range resp.Leaves {
+			ids = append(ids, leave.ID)
+		}
+		return rec.Code, ids
+	}
+
+	for _, user := range []users.User{student, faculty, admin} {
+		code, ids := list(user, "?status=approved,%20rejected")
+		assert.Equal(t, http.StatusOK, code, user.Role)
+		assert.ElementsMatch(t, []uint{byStatus["approved"], byStatus["rejected"]}, ids, user.Role)
+
+		_, ids = list(user, "?status=needs_info")
+		assert.Equal(t, []uint{byStatus["needs_info"]}, ids, user.Role)
+
+		code, _ = list(user, "?status=pending,cancelled")
+		assert.Equal(t, http.StatusBadRequest, code, user.Role)
+	}
+
+	// Approvers still default to open leaves
+	_, ids := list(faculty, "")
+	assert.ElementsMatch(t, []uint{byStatus["pending"], byStatus["needs_info"]}, ids)
+}
+
+func TestApplyLeaveOnBehalf(t *testing.T) {
+	setupTestDB(t)
+	db.DB.AutoMigrate(&audit.AuditLog{})
+	defer func() { core.AppConfig = nil }()
+	core.LoadConfig()
+
+	h1, h2 := "H1", "H2"
+	student := createTestUser(t, users.RoleStudent, "CS", &h1)
+	warden := createTestUser(t, users.RoleWarden, "ADMIN", &h1)
+	otherWarden := createTestUser(t, users.RoleWarden, "EE", &h2)
+
+	apply := func(user users.User, studentID uint, leaveType string, days int) *httptest.ResponseRecorder {
+		start := time.Now().AddDate(0, 0, days).UTC().Format(time.RFC3339)
+		end := time.Now().AddDate(0, 0, days+1).UTC().Format(time.RFC3339)
+		body := bytes.NewBufferString(`{"leave_type":"` + leaveType + `","reason":"Medical appointment in town","start_date":"` + start + `","end_date":"` + end + `"}`)
+		req := httptest.NewRequest(http.MethodPost, "/leaves/apply-for/"+uintToString(studentID), body)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		newTestRouter(user).ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusForbidden, apply(otherWarden, student.ID, "medical", 3).Code)
+	assert.Equal(t, http.StatusNotFound, apply(warden, warden.ID, "medical", 3).Code)
+	assert.Equal(t, http.StatusBadRequest, apply(warden, student.ID, "vacation", 3).Code)
+
+	rec := apply(warden, student.ID, "medical", 3)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var resp struct {
+		LeaveRequest struct {
+			ID        uint  `json:"id"`
+			AppliedBy *uint `json:"applied_by"`
+		} `json:"leave_request"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if assert.NotNil(t, resp.LeaveRequest.AppliedBy) {
+		assert.Equal(t, warden.ID, *resp.LeaveRequest.AppliedBy)
+	}
+
+	// Same overlap checks as the student's own application
+	assert.Equal(t, http.StatusBadRequest, apply(warden, student.ID, "medical", 3).Code)
+
+	var stored LeaveRequest
+	db.DB.First(&stored, resp.LeaveRequest.ID)
+	assert.Equal(t, student.ID, stored.StudentID)
+	assert.Equal(t, "pending", stored.Status)
+	var event LeaveEvent
+	db.DB.Where("leave_id = ?", stored.ID).First(&event)
+	assert.Equal(t, warden.ID, *event.ActorID)
+
+	var notification notifications.Notification
+	assert.NoError(t, db.DB.Where("user_id = ? AND related_id = ?", student.ID, stored.ID).First(&notification).Error)
+	assert.Contains(t, notification.Message, "on your behalf by warden user")
+	var audits int64
+	db.DB.Model(&audit.AuditLog{}).Where("action = ? AND entity_id = ?", "leave_apply_on_behalf", stored.ID).Count(&audits)
+	assert.Equal(t, int64(1), audits)
+
+	t.Setenv("LEAVE_APPLY_ON_BEHALF", "false")
+	core.LoadConfig()
+	assert.Equal(t, http.StatusForbidden, apply(warden, student.ID, "medical", 10).Code)
+}
+
+func TestLeaveRecordsRequestOrigin(t *testing.T) {
+	setupTestDB(t)
+	defer func() { core.AppConfig = nil }()
+	core.LoadConfig()
+	student := createTestUser(t, users.RoleStudent, "CS", nil)
+	faculty := createTestUser(t, users.RoleFaculty, "CS", nil)
+	admin := createTestUser(t, users.RoleAdmin, "ADMIN", nil)
+
+	send := func(user users.User, method, path, body, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "campus-app/"+user.Role)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		newTestRouter(user).ServeHTTP(rec, req)
+		return rec
+	}
+
+	start := time.Now().AddDate(0, 0, 3).UTC().Format(time.RFC3339)
+	end := time.Now().AddDate(0, 0, 4).UTC().Format(time.RFC3339)
+	rec := send(student, http.MethodPost, "/leaves/apply",
+		`{"leave_type":"medical","reason":"Medical appointment in town","start_date":"`+start+`","end_date":"`+end+`"}`, "203.0.113.7:5000")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var applied struct {
+		LeaveRequest struct {
+			ID uint `json:"id"`
+		} `json:"leave_request"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &applied)
+	id := uintToString(applied.LeaveRequest.ID)
+
+	var stored LeaveRequest
+	db.DB.First(&stored, applied.LeaveRequest.ID)
+	assert.Equal(t, "203.0.113.7", stored.ClientIP)
+	assert.Equal(t, "campus-app/student", stored.UserAgent)
+
+	// Only admins see where it came from
+	rec = send(student, http.MethodGet, "/leaves/"+id, "", "203.0.113.7:5000")
+	assert.NotContains(t, rec.Body.String(), "203.0.113.7")
+	rec = send(admin, http.MethodGet, "/leaves/"+id, "", "198.51.100.1:5000")
+	var details struct {
+		ClientIP  string `json:"client_ip"`
+		UserAgent string `json:"user_agent"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &details)
+	assert.Equal(t, "203.0.113.7", details.ClientIP)
+	assert.Equal(t, "campus-app/student", details.UserAgent)
+
+	rec = send(faculty, http.MethodPut, "/leaves/"+id+"/approve", `{"action":"approve","version":1}`, "198.51.100.20:5000")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	history := func(user users.User) []LeaveEvent {
+		var resp struct {
+			History []LeaveEvent `json:"history"`
+		}
+		json.Unmarshal(send(user, http.MethodGet, "/leaves/"+id+"/history", "", "198.51.100.1:5000").Body.Bytes(), &resp)
+		return resp.History
+	}
+	events := history(admin)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "203.0.113.7", events[0].ClientIP)
+		assert.Equal(t, "198.51.100.20", events[1].ClientIP)
+		assert.Equal(t, "campus-app/faculty", events[1].UserAgent)
+	}
+	for _, event := range history(student) {
+		assert.Empty(t, event.ClientIP)
+		assert.Empty(t, event.UserAgent)
+	}
+
+	// Admins can look up everything applied for from one address
+	list := func(query string) []LeaveRequest {
+		var resp struct {
+			Leaves []LeaveRequest `json:"leaves"`
+		}
+		json.Unmarshal(send(admin, http.MethodGet, "/leaves/?status=approved&client_ip="+query, "", "198.51.100.1:5000").Body.Bytes(), &resp)
+		return resp.Leaves
+	}
+	assert.Len(t, list("203.0.113.7"), 1)
+	assert.Empty(t, list("203.0.113.8"))
+	assert.Equal(t, http.StatusForbidden, send(faculty, http.MethodGet, "/leaves/?client_ip=203.0.113.7", "", "198.51.100.20:5000").Code)
+}
+
+func TestOverrideLeave(t *testing.T) {
+	setupTestDB(t)
+	db.DB.AutoMigrate(&audit.AuditLog{})
+	defer func() { core.AppConfig = nil }()
+	core.LoadConfig()
+	hostel := "North"
+	student := createTestUser(t, users.RoleStudent, "CS", &hostel)
+	admin := createTestUser(t, users.RoleAdmin, "ADMIN", nil)
+	leave := createPendingLeave(t, student)
+	id := uintToString(leave.ID)
+
+	override := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/leaves/"+id+"/override", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		newTestRouter(admin).ServeHTTP(rec, req)
+		return rec
+	}
+
+	// A justification is mandatory
+	assert.Equal(t, http.StatusBadRequest, override(`{"status":"approved","version":1}`).Code)
+	assert.Equal(t, http.StatusBadRequest, override(`{"status":"approved","justification":"Too short","version":1}`).Code)
+	assert.Equal(t, http.StatusBadRequest, override(`{"status":"cancelled","justification":"Approver is on sabbatical","version":1}`).Code)
+	assert.Equal(t, http.StatusBadRequest, override(`{"status":"pending","justification":"Approver is on sabbatical","version":1}`).Code)
+	assert.Equal(t, http.StatusConflict, override(`{"status":"approved","justification":"Approver is on sabbatical","version":2}`).Code)
+
+	// The admin is in neither the student's department nor hostel
+	rec := override(`{"status":"approved","justification":"Approver is on sabbatical","version":1}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var stored LeaveRequest
+	db.DB.First(&stored, leave.ID)
+	assert.Equal(t, "approved", stored.Status)
+	assert.Equal(t, admin.ID, *stored.ApprovedBy)
+	assert.Equal(t, "Approver is on sabbatical", *stored.Remarks)
+	assert.Equal(t, 2, stored.Version)
+
+	// Decided leaves can be overridden too
+	assert.Equal(t, http.StatusBadRequest, override(`{"status":"approved","justification":"Approver is on sabbatical","version":2}`).Code)
+	assert.Equal(t, http.StatusOK, override(`{"status":"rejected","justification":"Travel advisory for the region","version":2}`).Code)
+
+	var events []LeaveEvent
+	db.DB.Where("leave_id = ?", leave.ID).Order("id ASC").Find(&events)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "pending", events[0].FromStatus)
+		assert.Equal(t, "approved", events[0].ToStatus)
+		assert.Equal(t, "approved", events[1].FromStatus)
+		assert.Equal(t, "rejected", events[1].ToStatus)
+		assert.Equal(t, admin.ID, *events[1].ActorID)
+	}
+
+	var entries []audit.AuditLog
+	db.DB.Where("action = ? AND entity_id = ?", "leave_override", leave.ID).Order("id ASC").Find(&entries)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, admin.ID, entries[0].ActorID)
+		assert.Equal(t, "status pending -> approved: Approver is on sabbatical", entries[0].Details)
+		assert.Equal(t, "status approved -> rejected: Travel advisory for the region", entries[1].Details)
+	}
+
+	// The student hears about both decisions
+	var notified int64
+	db.DB.Model(&notifications.Notification{}).Where("user_id = ?", student.ID).Count(&notified)
+	assert.Equal(t, int64(2), notified)
+
+	id = "9999"
+	assert.Equal(t, http.StatusNotFound, override(`{"status":"approved","justification":"Approver is on sabbatical","version":1}`).Code)
+}
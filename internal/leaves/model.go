@@ -9,21 +9,95 @@ import (
 // LeaveRequest represents a leave request in the system
 type LeaveRequest struct {
 	gorm.Model
-	StudentID  uint      `json:"student_id" gorm:"not null;index"`
-	Student    User      `json:"student,omitempty" gorm:"foreignKey:StudentID"`
-	LeaveType  string    `json:"leave_type" gorm:"not null" validate:"required,oneof=medical personal emergency academic"`
-	Reason     string    `json:"reason" gorm:"not null" validate:"required,min=10,max=500"`
-	StartDate  time.Time `json:"start_date" gorm:"not null" validate:"required"`
-	EndDate    time.Time `json:"end_date" gorm:"not null" validate:"required"`
-	Status     string    `json:"status" gorm:"not null;default:pending" validate:"oneof=pending approved rejected"`
-	ApprovedBy *uint     `json:"approved_by,omitempty" gorm:"index"`
-	Approver   *User     `json:"approver,omitempty" gorm:"foreignKey:ApprovedBy"`
-	Remarks    *string   `json:"remarks,omitempty" validate:"max=200"`
-	Dept       string    `json:"dept" gorm:"not null"`
-	Hostel     *string   `json:"hostel,omitempty"`
-	Days       int       `json:"days" gorm:"not null"`
+	StudentID  uint       `json:"student_id" gorm:"not null;index"`
+	Student    User       `json:"student,omitempty" gorm:"foreignKey:StudentID;belongsTo"` // belongsTo: User also has a StudentID field
+	LeaveType  string     `json:"leave_type" gorm:"not null" validate:"required"`
+	Reason     string     `json:"reason" gorm:"not null" validate:"required,min=10,max=500"`
+	StartDate  time.Time  `json:"start_date" gorm:"not null" validate:"required"`
+	EndDate    time.Time  `json:"end_date" gorm:"not null" validate:"required"`
+	Status     string     `json:"status" gorm:"not null;default:pending" validate:"oneof=pending needs_info approved rejected"`
+	ApprovedBy *uint      `json:"approved_by,omitempty" gorm:"index"`
+	Approver   *User      `json:"approver,omitempty" gorm:"foreignKey:ApprovedBy"`
+	Remarks    *string    `json:"remarks,omitempty" validate:"max=200"`
+	Dept       string     `json:"dept" gorm:"not null"`
+	Hostel     *string    `json:"hostel,omitempty"`
+	Days       int        `json:"days" gorm:"not null"`
+	Version    int        `json:"version" gorm:"not null;default:1"` // Incremented on every update for optimistic locking
+	FlaggedAt  *time.Time `json:"flagged_at,omitempty"`              // Set when a pending leave needs attention
+	FlagReason *string    `json:"flag_reason,omitempty"`
+	SeriesID   *uint      `json:"series_id,omitempty" gorm:"index"`  // Set on leaves generated by a recurring series
+	AppliedBy  *uint      `json:"applied_by,omitempty" gorm:"index"` // Staff member who filed the leave for the student
+	Deleted    bool       `json:"deleted,omitempty" gorm:"-"`        // Set on soft-deleted rows listed with include_deleted
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Approval deadline, shown to approvers on pending leaves. EscalatedAt is set once the
+	// escalation job has notified the next approver.
+	EscalatedAt *time.Time `json:"escalated_at,omitempty"`
+	DeadlineAt  *time.Time `json:"deadline_at,omitempty" gorm:"-"`
+	IsOverdue   bool       `json:"is_overdue,omitempty" gorm:"-"`
+
+	// Where the application was filed from, shown to admins only
+	ClientIP  string `json:"-" gorm:"size:45;index"`
+	UserAgent string `json:"-" gorm:"size:255"`
+}
+
+// OpenStatuses are the statuses of leaves still waiting on a final decision, for queues and pending counts
+var OpenStatuses = []string{"pending", "needs_info"}
+
+// leaveStatuses are all the statuses a leave can be in
+var leaveStatuses = []string{"pending", "needs_info", "approved", "rejected"}
+
+// LeaveEvent records a status transition of a leave request
+type LeaveEvent struct {
+	gorm.Model
+	LeaveID    uint      `json:"leave_id" gorm:"not null;index"`
+	ActorID    *uint     `json:"actor_id"` // Nil for actions taken by background jobs
+	Actor      *User     `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+	FromStatus string    `json:"from_status"` // Empty for the initial application
+	ToStatus   string    `json:"to_status" gorm:"not null"`
+	Remarks    *string   `json:"remarks,omitempty"`
+	ClientIP   string    `json:"client_ip,omitempty" gorm:"size:45"` // Origin of the action; only admins see these
+	UserAgent  string    `json:"user_agent,omitempty" gorm:"size:255"`
 	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Leave series statuses
+const (
+	SeriesActive    = "active"
+	SeriesCancelled = "cancelled"
+)
+
+// LeaveCancelled is the to_status recorded in the history of a leave withdrawn with its series
+const LeaveCancelled = "cancelled"
+
+// LeaveSeries is a standing arrangement, such as a weekly appointment, that generates one single-day
+// leave request per occurrence. Each generated leave carries the series ID and is approved, rejected
+// and cross-checked against attendance like any other leave.
+type LeaveSeries struct {
+	gorm.Model
+	StudentID   uint       `json:"student_id" gorm:"not null;index"`
+	LeaveType   string     `json:"leave_type" gorm:"not null"`
+	Reason      string     `json:"reason" gorm:"not null"`
+	Weekdays    []string   `json:"weekdays" gorm:"serializer:json"` // Lowercase weekday names, e.g. ["tuesday"]
+	StartDate   time.Time  `json:"start_date" gorm:"not null"`
+	Until       time.Time  `json:"until" gorm:"not null"`
+	Status      string     `json:"status" gorm:"not null;default:active"` // active or cancelled
+	Dept        string     `json:"dept" gorm:"not null"`
+	Hostel      *string    `json:"hostel,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// LeaveComment is one message in the discussion thread of a leave request
+type LeaveComment struct {
+	gorm.Model
+	LeaveID   uint      `json:"leave_id" gorm:"not null;index"`
+	AuthorID  uint      `json:"author_id" gorm:"not null"`
+	Author    *User     `json:"author,omitempty" gorm:"foreignKey:AuthorID"`
+	Body      string    `json:"body" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // User represents a user (imported from users package)
@@ -39,4 +113,8 @@ type User struct {
 	StudentID *string    `json:"student_id,omitempty" gorm:"uniqueIndex"`
 	IsActive  bool       `json:"is_active" gorm:"default:true"`
 	LastLogin *time.Time `json:"last_login,omitempty"`
+
+	// Exposed only through emergencyContactFor
+	EmergencyContactName  *string `json:"-"`
+	EmergencyContactPhone *string `json:"-"`
 }
@@ -0,0 +1,24 @@
+package leaves
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// maxUserAgentLength caps stored user agents; anything longer is truncated
+const maxUserAgentLength = 255
+
+// requestOrigin is where an application or decision came from, kept for investigating suspicious activity.
+// The IP is gin's ClientIP, which only honours X-Forwarded-For from trusted proxies.
+type requestOrigin struct {
+	ClientIP  string
+	UserAgent string
+}
+
+// originOf reads the client IP and user agent of the request; background jobs pass the zero value instead
+func originOf(c *gin.Context) requestOrigin {
+	userAgent := []rune(c.Request.UserAgent())
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return requestOrigin{ClientIP: c.ClientIP(), UserAgent: string(userAgent)}
+}
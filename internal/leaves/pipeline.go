@@ -0,0 +1,176 @@
+package leaves
+
+import (
+	"campus-backend/internal/core"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Approval stages of an open leave
+const (
+	StageAwaitingApproval = "awaiting_approval" // At least one faculty member or warden can act on it
+	StageAwaitingStudent  = "awaiting_student"  // An approver asked for more information (needs_info)
+	StageFlagged          = "flagged"           // Marked stale and waiting for an admin
+	StageUnroutable       = "unroutable"        // No active approver covers its department or hostel
+)
+
+// PipelineApprover is someone who can act on an open leave
+type PipelineApprover struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// PipelineLeave is an open leave annotated with where it is stuck and who should act
+type PipelineLeave struct {
+	LeaveID      uint               `json:"leave_id"`
+	StudentID    uint               `json:"student_id"`
+	StudentName  string             `json:"student_name"`
+	LeaveType    string             `json:"leave_type"`
+	StartDate    time.Time          `json:"start_date"`
+	EndDate      time.Time          `json:"end_date"`
+	Dept         string             `json:"dept"`
+	Hostel       *string            `json:"hostel,omitempty"`
+	PendingSince time.Time          `json:"pending_since"`
+	PendingDays  int                `json:"pending_days"`
+	Stage        string             `json:"stage"`
+	FlagReason   *string            `json:"flag_reason,omitempty"`
+	Approvers    []PipelineApprover `json:"approvers"`
+}
+
+// GetLeavePipeline godoc
+// @Summary Pending leave pipeline
+// @Description Admin view of every open leave (pending or needs_info), oldest first, with its approval stage and the faculty and wardens who can act on it
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param older_than_days query int false "Only leaves pending at least this many days"
+// @Param dept query string false "Filter by department"
+// @Param hostel query string false "Filter by hostel"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page (clamped to the configured maximum)" default(10)
+// @Success 200 {object} map[string]interface{} "Pending leaves with routing info"
+// @Failure 400 {object} map[string]interface{} "Invalid parameters"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/leaves/pipeline [get]
+func GetLeavePipeline(c *gin.Context) {
+	query := db.DB.Model(&LeaveRequest{}).Where("status IN ?", OpenStatuses)
+
+	if value := c.Query("older_than_days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "older_than_days must be a non-negative integer"})
+			return
+		}
+		query = query.Where("created_at <= ?", timeutil.Now().AddDate(0, 0, -days))
+	}
+	if dept := c.Query("dept"); dept != "" {
+		query = query.Where("dept = ?", dept)
+	}
+	if hostel := c.Query("hostel"); hostel != "" {
+		query = query.Where("hostel = ?", hostel)
+	}
+
+	page, limit := core.PaginationParams(c)
+	var total int64
+	var leaves []LeaveRequest
+	err := query.Count(&total).Error
+	if err == nil {
+		err = query.Preload("Student").Order("created_at ASC, id ASC").Offset((page - 1) * limit).Limit(limit).Find(&leaves).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get pending leaves"})
+		return
+	}
+
+	byDept, byHostel, err := pipelineApprovers(leaves)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get approvers"})
+		return
+	}
+
+	now := timeutil.Now()
+	pipeline := make([]PipelineLeave, 0, len(leaves))
+	for _, leave := range leaves {
+		approvers := append([]PipelineApprover{}, byDept[leave.Dept]...)
+		if leave.Hostel != nil {
+			approvers = append(approvers, byHostel[*leave.Hostel]...)
+		}
+
+		stage := StageAwaitingApproval
+		if leave.FlaggedAt != nil {
+			stage = StageFlagged
+		} else if leave.Status == "needs_info" {
+			stage = StageAwaitingStudent
+		} else if len(approvers) == 0 {
+			stage = StageUnroutable
+		}
+
+		pipeline = append(pipeline, PipelineLeave{
+			LeaveID:      leave.ID,
+			StudentID:    leave.StudentID,
+			StudentName:  leave.Student.Name,
+			LeaveType:    leave.LeaveType,
+			StartDate:    leave.StartDate,
+			EndDate:      leave.EndDate,
+			Dept:         leave.Dept,
+			Hostel:       leave.Hostel,
+			PendingSince: leave.CreatedAt,
+			PendingDays:  int(now.Sub(leave.CreatedAt).Hours() / 24),
+			Stage:        stage,
+			FlagReason:   leave.FlagReason,
+			Approvers:    approvers,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"leaves":     pipeline,
+		"pagination": core.CalculatePagination(page, limit, total),
+	})
+}
+
+// pipelineApprovers loads, in one query, the active faculty for the leaves' departments and
+// wardens for their hostels, keyed by department and hostel
+func pipelineApprovers(leaves []LeaveRequest) (map[string][]PipelineApprover, map[string][]PipelineApprover, error) {
+	byDept := make(map[string][]PipelineApprover)
+	byHostel := make(map[string][]PipelineApprover)
+	if len(leaves) == 0 {
+		return byDept, byHostel, nil
+	}
+
+	depts := make([]string, 0, len(leaves))
+	hostels := []string{}
+	for _, leave := range leaves {
+		depts = append(depts, leave.Dept)
+		if leave.Hostel != nil {
+			hostels = append(hostels, *leave.Hostel)
+		}
+	}
+
+	var approvers []User
+	query := db.DB.Where("is_active = ?", true).
+		Where(db.DB.Where("role = ? AND dept IN ?", users.RoleFaculty, depts).
+			Or("role = ? AND hostel IN ?", users.RoleWarden, hostels))
+	if err := query.Order("name ASC").Find(&approvers).Error; err != nil {
+		return nil, nil, err
+	}
+
+	for _, approver := range approvers {
+		entry := PipelineApprover{ID: approver.ID, Name: approver.Name, Email: approver.Email, Role: approver.Role}
+		if approver.Role == users.RoleFaculty {
+			byDept[approver.Dept] = append(byDept[approver.Dept], entry)
+		} else if approver.Hostel != nil {
+			byHostel[*approver.Hostel] = append(byHostel[*approver.Hostel], entry)
+		}
+	}
+	return byDept, byHostel, nil
+}
@@ -0,0 +1,428 @@
+package leaves
+
+import (
+	"campus-backend/internal/audit"
+	"campus-backend/internal/auth"
+	"campus-backend/internal/calendar"
+	"campus-backend/internal/core"
+	"campus-backend/internal/notifications"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"campus-backend/pkg/validation"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxSeriesOccurrences bounds how many leaves one series may generate, e.g. a year of weekly appointments
+const maxSeriesOccurrences = 52
+
+type RecurringLeaveRequest struct {
+	LeaveType string    `json:"leave_type" binding:"required" validate:"required"` // Checked against the configured leave types
+	Reason    string    `json:"reason" binding:"required" validate:"required,min=10,max=500"`
+	Weekdays  []string  `json:"weekdays" binding:"required" validate:"required,min=1,max=7,dive,oneof=monday tuesday wednesday thursday friday saturday sunday"`
+	StartDate time.Time `json:"start_date" binding:"required" validate:"required,future_date"`
+	Until     time.Time `json:"until" binding:"required" validate:"required,gtefield=StartDate"`
+}
+
+type SeriesDecisionRequest struct {
+	Action  string  `json:"action" binding:"required" validate:"required,oneof=approve reject"`
+	Remarks *string `json:"remarks" validate:"omitempty,max=200"` // Required for reject
+}
+
+// SkippedOccurrence is a date of a series that did not get a leave
+type SkippedOccurrence struct {
+	Date             string `json:"date"`
+	Reason           string `json:"reason"` // weekend, holiday or overlap
+	ConflictingLeave *uint  `json:"conflicting_leave_id,omitempty"`
+}
+
+// seriesOccurrences lists the campus days from start to until, inclusive, falling on one of the weekdays
+func seriesOccurrences(start, until time.Time, weekdays []string) []time.Time {
+	dates := []time.Time{}
+	last := timeutil.StartOfDay(until)
+	for day := timeutil.StartOfDay(start); !day.After(last); day = timeutil.StartOfDay(day.In(timeutil.Location()).AddDate(0, 0, 1)) {
+		if slices.Contains(weekdays, strings.ToLower(day.In(timeutil.Location()).Weekday().String())) {
+			dates = append(dates, day)
+		}
+	}
+	return dates
+}
+
+// CreateRecurringLeave godoc
+// @Summary Apply for a recurring leave
+// @Description Student applies for a standing leave, such as a weekly appointment, on the given weekdays from start_date until the until date. One single-day pending leave is created per occurrence, each checked for overlaps like a normal application. Weekends, holidays and occurrences that overlap another leave are skipped and listed; the request fails if nothing is left.
+// @Tags Leaves
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body RecurringLeaveRequest true "Recurring leave"
+// @Success 201 {object} map[string]interface{} "Series created with its leaves"
+// @Failure 400 {object} map[string]interface{} "Validation failed, too many occurrences or no occurrence left"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /leaves/recurring [post]
+func CreateRecurringLeave(c *gin.Context) {
+	var input RecurringLeaveRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+	for i, weekday := range input.Weekdays {
+		input.Weekdays[i] = strings.ToLower(strings.TrimSpace(weekday))
+	}
+	if err := validation.ValidateStruct(input); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+
+	leaveConfig := core.GetConfig().Leave
+	if !leaveConfig.IsAllowedType(input.LeaveType) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Validation failed",
+			"details": gin.H{"LeaveType": "LeaveType must be one of: " + strings.Join(leaveConfig.AllowedTypes, " ")},
+		})
+		return
+	}
+
+	dates := seriesOccurrences(input.StartDate, input.Until, input.Weekdays)
+	if len(dates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "None of the weekdays fall between start_date and until"})
+		return
+	}
+	if len(dates) > maxSeriesOccurrences {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A recurring leave can have at most %d occurrences; this one has %d", maxSeriesOccurrences, len(dates))})
+		return
+	}
+	if msg := checkMinimumNotice(input.LeaveType, dates[0]); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	studentIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	studentID := studentIDVal.(uint)
+
+	var student users.User
+	if err := db.DB.First(&student, studentID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Student data not found"})
+		return
+	}
+
+	origin := originOf(c)
+	leaves := []LeaveRequest{}
+	skipped := []SkippedOccurrence{}
+	for _, day := range dates {
+		date := day.In(timeutil.Location()).Format(timeutil.DateLayout)
+		// No leave is needed on a day without classes
+		if !calendar.IsWorkingDay(day) {
+			reason := "weekend"
+			if _, holiday := calendar.Holiday(day); holiday {
+				reason = "holiday"
+			}
+			skipped = append(skipped, SkippedOccurrence{Date: date, Reason: reason})
+			continue
+		}
+		existing, err := conflictingLeave(studentID, input.LeaveType, day, day)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing leaves"})
+			return
+		}
+		if existing != nil {
+			skipped = append(skipped, SkippedOccurrence{Date: date, Reason: "overlap", ConflictingLeave: &existing.ID})
+			continue
+		}
+		leaves = append(leaves, LeaveRequest{
+			StudentID: studentID,
+			LeaveType: input.LeaveType,
+			Reason:    input.Reason,
+			StartDate: day,
+			EndDate:   day,
+			Status:    "pending",
+			Dept:      student.Dept,
+			Hostel:    student.Hostel,
+			Days:      1,
+			ClientIP:  origin.ClientIP,
+			UserAgent: origin.UserAgent,
+		})
+	}
+	if len(leaves) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Every occurrence falls on a non-working day or overlaps another leave", "skipped": skipped})
+		return
+	}
+
+	series := LeaveSeries{
+		StudentID: studentID,
+		LeaveType: input.LeaveType,
+		Reason:    input.Reason,
+		Weekdays:  input.Weekdays,
+		StartDate: timeutil.StartOfDay(input.StartDate),
+		Until:     timeutil.StartOfDay(input.Until),
+		Status:    SeriesActive,
+		Dept:      student.Dept,
+		Hostel:    student.Hostel,
+	}
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&series).Error; err != nil {
+			return err
+		}
+		for i := range leaves {
+			leaves[i].SeriesID = &series.ID
+			if err := tx.Create(&leaves[i]).Error; err != nil {
+				return err
+			}
+			if err := recordLeaveEvent(tx, leaves[i].ID, studentID, "", leaves[i].Status, nil, origin); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create recurring leave"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Recurring leave submitted successfully",
+		"series":  series,
+		"leaves":  leaves,
+		"skipped": skipped,
+	})
+}
+
+// DecideLeaveSeries godoc
+// @Summary Approve or reject a recurring leave
+// @Description Approver decides every pending leave of a series at once; leaves already decided individually, or waiting on more information, are left alone. The student gets one notification for the series. Rejecting requires remarks of at least 10 characters.
+// @Tags Leaves
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Series ID"
+// @Param request body SeriesDecisionRequest true "Decision"
+// @Success 200 {object} map[string]interface{} "Leaves decided"
+// @Failure 400 {object} map[string]interface{} "Validation failed, series cancelled or nothing pending"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Outside the approver's scope"
+// @Failure 404 {object} map[string]interface{} "Series not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /leaves/recurring/{id}/approve [put]
+func DecideLeaveSeries(c *gin.Context) {
+	var input SeriesDecisionRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+	if err := validation.ValidateStruct(input); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+	if input.Action == "reject" && !hasRejectionReason(input.Remarks) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": rejectionReasonMessage})
+		return
+	}
+
+	var series LeaveSeries
+	if err := db.DB.First(&series, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recurring leave not found"})
+		return
+	}
+	if series.Status != SeriesActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Recurring leave has been cancelled"})
+		return
+	}
+
+	var pending []LeaveRequest
+	if err := db.DB.Where("series_id = ? AND status = ?", series.ID, "pending").Order("start_date ASC").Find(&pending).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get the series' leaves"})
+		return
+	}
+	if len(pending) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No pending leaves in this series"})
+		return
+	}
+
+	approverIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	approverID := approverIDVal.(uint)
+
+	for i := range pending {
+		if msg := selfApprovalError(approverID, &pending[i]); msg != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": msg})
+			return
+		}
+	}
+
+	// Every leave of a series has the student's department and hostel, so one check covers them all
+	role, _ := auth.CurrentRole(c)
+	if role == users.RoleFaculty || role == users.RoleWarden {
+		approver, err := auth.CurrentUser(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Approver not found"})
+			return
+		}
+		if msg := approvalScopeError(role, approver, &pending[0]); msg != "" {
+			c.JSON(http.StatusForbidden, gin.H{"error": msg})
+			return
+		}
+	}
+
+	status := "approved"
+	if input.Action == "reject" {
+		status = "rejected"
+	}
+
+	decided := []LeaveRequest{}
+	origin := originOf(c)
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		for _, leave := range pending {
+			leave.Status = status
+			leave.ApprovedBy = &approverID
+			leave.Remarks = input.Remarks
+			if err := applyPendingDecision(tx, &leave); err != nil {
+				// Decided individually since it was read
+				if errors.Is(err, ErrLeaveAlreadyProcessed) {
+					continue
+				}
+				return err
+			}
+			if err := recordLeaveEvent(tx, leave.ID, approverID, "pending", leave.Status, leave.Remarks, origin); err != nil {
+				return err
+			}
+			decided = append(decided, leave)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recurring leave"})
+		return
+	}
+
+	if len(decided) > 0 {
+		// One notification spanning the series rather than one per occurrence
+		summary := notificationLeave(&decided[0])
+		summary.EndDate = decided[len(decided)-1].EndDate
+		summary.Days = len(decided)
+		if err := notifications.NotifyLeaveStatusChange(&summary); err != nil {
+			log.Printf("Failed to send leave status notification for series %d: %v", series.ID, err)
+		}
+		for i := range decided {
+			dispatchLeaveDecision(&decided[i])
+		}
+	}
+
+	ids := make([]uint, 0, len(decided))
+	for _, leave := range decided {
+		ids = append(ids, leave.ID)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Recurring leave updated successfully",
+		"series_id": series.ID,
+		"status":    status,
+		"decided":   ids,
+	})
+}
+
+// CancelLeaveSeries godoc
+// @Summary Cancel a recurring leave
+// @Description The student who applied, or an admin, ends a series. Its leaves from today on are withdrawn whatever their status, each with a cancelled entry in its history; earlier ones are kept as a record. The student gets one notification for the withdrawn leaves and the cancellation is audited.
+// @Tags Leaves
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Series ID"
+// @Success 200 {object} map[string]interface{} "Series cancelled"
+// @Failure 400 {object} map[string]interface{} "Already cancelled"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not your recurring leave"
+// @Failure 404 {object} map[string]interface{} "Series not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /leaves/recurring/{id} [delete]
+func CancelLeaveSeries(c *gin.Context) {
+	var series LeaveSeries
+	if err := db.DB.First(&series, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recurring leave not found"})
+		return
+	}
+
+	userIDVal, _ := c.Get("userID")
+	userID := userIDVal.(uint)
+	role, _ := auth.CurrentRole(c)
+	if role != users.RoleAdmin && series.StudentID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only cancel your own recurring leaves"})
+		return
+	}
+	if series.Status == SeriesCancelled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Recurring leave is already cancelled"})
+		return
+	}
+
+	today := timeutil.Today()
+	now := time.Now()
+	origin := originOf(c)
+	var upcoming []LeaveRequest
+	var withdrawn int64
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("series_id = ? AND start_date >= ?", series.ID, today).Order("start_date ASC").Find(&upcoming).Error; err != nil {
+			return err
+		}
+		if len(upcoming) > 0 {
+			ids := make([]uint, 0, len(upcoming))
+			for _, leave := range upcoming {
+				if err := recordLeaveEvent(tx, leave.ID, userID, leave.Status, LeaveCancelled, nil, origin); err != nil {
+					return err
+				}
+				ids = append(ids, leave.ID)
+			}
+			result := tx.Delete(&LeaveRequest{}, ids)
+			if result.Error != nil {
+				return result.Error
+			}
+			withdrawn = result.RowsAffected
+		}
+
+		series.Status = SeriesCancelled
+		series.CancelledAt = &now
+		if err := tx.Model(&series).Select("status", "cancelled_at").Updates(&series).Error; err != nil {
+			return err
+		}
+		details := fmt.Sprintf("cancelled recurring %s leave of student %d: %d upcoming leaves withdrawn", series.LeaveType, series.StudentID, withdrawn)
+		return audit.Record(tx, userID, "leave_series_cancel", "leave_series", series.ID, details)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel recurring leave"})
+		return
+	}
+
+	if len(upcoming) > 0 {
+		// One notification spanning the withdrawn leaves, as when the series is decided
+		summary := notificationLeave(&upcoming[0])
+		summary.Status = LeaveCancelled
+		summary.EndDate = upcoming[len(upcoming)-1].EndDate
+		summary.Days = len(upcoming)
+		summary.Remarks = nil
+		if err := notifications.NotifyLeaveStatusChange(&summary); err != nil {
+			log.Printf("Failed to send cancellation notification for series %d: %v", series.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Recurring leave cancelled successfully",
+		"series_id": series.ID,
+		"withdrawn": withdrawn,
+	})
+}
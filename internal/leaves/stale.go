@@ -0,0 +1,167 @@
+package leaves
+
+import (
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/internal/notifications"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Actions taken on open leaves that were not handled before their start date
+const (
+	StaleActionReject = "reject"
+	StaleActionFlag   = "flag"
+)
+
+const (
+	staleRejectRemarks = "auto-rejected: not actioned in time"
+	staleFlagReason    = "not actioned before start date"
+)
+
+// StaleLeaveResult summarizes one run of the stale leave check
+type StaleLeaveResult struct {
+	Action    string    `json:"action"`
+	Cutoff    time.Time `json:"cutoff"` // Pending leaves starting before this were processed
+	Processed []uint    `json:"processed"`
+	Failed    []uint    `json:"failed"`
+}
+
+// ProcessStaleLeaves rejects or flags (per config) every leave still open (pending or awaiting
+// the student's reply) after its start date plus the grace period, notifying the student and writing an audit entry for each.
+// actorID is audit.SystemActorID when run by the scheduler.
+func ProcessStaleLeaves(actorID uint) (*StaleLeaveResult, error) {
+	cfg := core.GetConfig().Leave
+	cutoff := timeutil.StartOfDay(timeutil.Today().In(timeutil.Location()).AddDate(0, 0, -cfg.StaleGraceDays))
+
+	query := db.DB.Where("status IN ? AND start_date < ?", OpenStatuses, cutoff)
+	if cfg.StaleAction == StaleActionFlag {
+		query = query.Where("flagged_at IS NULL")
+	}
+
+	var stale []LeaveRequest
+	if err := query.Order("start_date ASC").Find(&stale).Error; err != nil {
+		return nil, err
+	}
+
+	result := &StaleLeaveResult{Action: cfg.StaleAction, Cutoff: cutoff, Processed: []uint{}, Failed: []uint{}}
+	for i := range stale {
+		leave := &stale[i]
+
+		var err error
+		if cfg.StaleAction == StaleActionFlag {
+			err = flagStaleLeave(leave, actorID)
+		} else {
+			err = rejectStaleLeave(leave, actorID)
+		}
+
+		if errors.Is(err, ErrLeaveAlreadyProcessed) {
+			continue // Handled by an approver since we loaded it
+		}
+		if err != nil {
+			log.Printf("Failed to process stale leave %d: %v", leave.ID, err)
+			result.Failed = append(result.Failed, leave.ID)
+			continue
+		}
+		result.Processed = append(result.Processed, leave.ID)
+	}
+
+	return result, nil
+}
+
+// rejectStaleLeave rejects an open leave and notifies the student
+func rejectStaleLeave(leave *LeaveRequest, actorID uint) error {
+	remarks := staleRejectRemarks
+	fromStatus := leave.Status
+	leave.Status = "rejected"
+	leave.Remarks = &remarks
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		rows, err := updateIfVersion(tx.Where("status = ?", fromStatus), leave, "status", "approved_by", "remarks")
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrLeaveAlreadyProcessed
+		}
+		if err := recordLeaveEvent(tx, leave.ID, actorID, fromStatus, leave.Status, leave.Remarks, requestOrigin{}); err != nil {
+			return err
+		}
+		details := fmt.Sprintf("%s leave starting %s rejected", fromStatus, leave.StartDate.Format(timeutil.DateLayout))
+		return audit.Record(tx, actorID, "leave_auto_reject", "leave_request", leave.ID, details)
+	})
+	if err != nil {
+		return err
+	}
+
+	notifyStatusChange(leave)
+	return nil
+}
+
+// flagStaleLeave marks an open leave as needing attention and notifies the student
+func flagStaleLeave(leave *LeaveRequest, actorID uint) error {
+	now := time.Now()
+	reason := staleFlagReason
+	leave.FlaggedAt = &now
+	leave.FlagReason = &reason
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		rows, err := updateIfVersion(tx.Where("status IN ?", OpenStatuses), leave, "flagged_at", "flag_reason")
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return ErrLeaveAlreadyProcessed
+		}
+		details := fmt.Sprintf("%s leave starting %s flagged: %s", leave.Status, leave.StartDate.Format(timeutil.DateLayout), reason)
+		return audit.Record(tx, actorID, "leave_stale_flag", "leave_request", leave.ID, details)
+	})
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Your %s leave request starting %s has not been actioned yet. Please contact your approver.",
+		leave.LeaveType, leave.StartDate.In(timeutil.Location()).Format(timeutil.DateLayout))
+	if err := notifications.CreateNotification(leave.StudentID, "Leave Request Not Actioned", message, "leave_status", &leave.ID); err != nil {
+		log.Printf("Failed to notify student %d about stale leave %d: %v", leave.StudentID, leave.ID, err)
+	}
+	return nil
+}
+
+// ProcessStaleLeavesHandler godoc
+// @Summary Process stale open leaves now
+// @Description Admin runs the stale leave check immediately instead of waiting for the scheduled job
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Stale leaves processed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/leaves/process-stale [post]
+func ProcessStaleLeavesHandler(c *gin.Context) {
+	adminIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	result, err := ProcessStaleLeaves(adminIDVal.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process stale leaves"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Stale leaves processed",
+		"result":  result,
+	})
+}
@@ -0,0 +1,160 @@
+package leaves
+
+import (
+	"campus-backend/internal/auth"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LeaveTypeStats counts a student's leaves of one type
+type LeaveTypeStats struct {
+	LeaveType    string `json:"leave_type"`
+	Total        int    `json:"total"`
+	Approved     int    `json:"approved"`
+	Rejected     int    `json:"rejected"`
+	Pending      int    `json:"pending"`
+	NeedsInfo    int    `json:"needs_info"`
+	ApprovedDays int    `json:"approved_days"`
+}
+
+// LeaveStats summarizes a student's leave requests
+type LeaveStats struct {
+	StudentID    uint             `json:"student_id"`
+	StudentName  string           `json:"student_name"`
+	TotalLeaves  int              `json:"total_leaves"`
+	Approved     int              `json:"approved"`
+	Rejected     int              `json:"rejected"`
+	Pending      int              `json:"pending"`
+	NeedsInfo    int              `json:"needs_info"`
+	ApprovedDays int              `json:"approved_days"`
+	ByType       []LeaveTypeStats `json:"by_type"`
+}
+
+// GetLeaveStats godoc
+// @Summary Get leave statistics for a student
+// @Description Leave counts by status and type and total approved days. Students get their own; faculty see their department's students, wardens their hostel's, admins anyone.
+// @Tags Leaves
+// @Produce json
+// @Security BearerAuth
+// @Param student_id query int false "Student ID (required for non-students)"
+// @Success 200 {object} LeaveStats "Leave statistics"
+// @Failure 400 {object} map[string]interface{} "Invalid student_id"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Student is outside your scope"
+// @Failure 404 {object} map[string]interface{} "Student not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /leaves/stats [get]
+func GetLeaveStats(c *gin.Context) {
+	role, ok := auth.CurrentRole(c)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	var studentID uint
+	if role == users.RoleStudent {
+		userIDVal, _ := c.Get("userID")
+		studentID = userIDVal.(uint)
+	} else {
+		param := c.Query("student_id")
+		if param == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "student_id parameter is required"})
+			return
+		}
+		id, err := strconv.ParseUint(param, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student_id"})
+			return
+		}
+		studentID = uint(id)
+	}
+
+	var student User
+	if err := db.DB.Where("id = ? AND role = ?", studentID, users.RoleStudent).First(&student).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return
+	}
+
+	if role == users.RoleFaculty || role == users.RoleWarden {
+		viewer, err := auth.CurrentUser(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
+			return
+		}
+		if role == users.RoleFaculty && viewer.Dept != student.Dept {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only view students from your department"})
+			return
+		}
+		if role == users.RoleWarden && (viewer.Hostel == nil || student.Hostel == nil || *viewer.Hostel != *student.Hostel) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only view students from your hostel"})
+			return
+		}
+	}
+
+	stats, err := studentLeaveStats(student.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get leave statistics"})
+		return
+	}
+	stats.StudentName = student.Name
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// studentLeaveStats counts a student's leaves with a single query grouped by type and status
+func studentLeaveStats(studentID uint) (*LeaveStats, error) {
+	var rows []struct {
+		LeaveType string
+		Status    string
+		Count     int
+		Days      int
+	}
+	err := db.DB.Model(&LeaveRequest{}).
+		Select("leave_type, status, COUNT(*) AS count, COALESCE(SUM(days), 0) AS days").
+		Where("student_id = ?", studentID).
+		Group("leave_type, status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &LeaveStats{StudentID: studentID, ByType: []LeaveTypeStats{}}
+	byType := make(map[string]*LeaveTypeStats)
+	for _, row := range rows {
+		entry, exists := byType[row.LeaveType]
+		if !exists {
+			entry = &LeaveTypeStats{LeaveType: row.LeaveType}
+			byType[row.LeaveType] = entry
+		}
+
+		entry.Total += row.Count
+		stats.TotalLeaves += row.Count
+		switch row.Status {
+		case "approved":
+			entry.Approved += row.Count
+			entry.ApprovedDays += row.Days
+			stats.Approved += row.Count
+			stats.ApprovedDays += row.Days
+		case "rejected":
+			entry.Rejected += row.Count
+			stats.Rejected += row.Count
+		case "pending":
+			entry.Pending += row.Count
+			stats.Pending += row.Count
+		case "needs_info":
+			entry.NeedsInfo += row.Count
+			stats.NeedsInfo += row.Count
+		}
+	}
+
+	for _, entry := range byType {
+		stats.ByType = append(stats.ByType, *entry)
+	}
+	sort.Slice(stats.ByType, func(i, j int) bool { return stats.ByType[i].LeaveType < stats.ByType[j].LeaveType })
+	return stats, nil
+}
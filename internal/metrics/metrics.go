@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// requestsTotal counts handled requests per route, method and status code
+	requestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "campus_http_requests_total",
+			Help: "Total number of HTTP requests processed.",
+		},
+		[]string{"method", "route", "status"},
+	)
+
+	// requestDuration tracks request latency per route
+	requestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "campus_http_request_duration_seconds",
+			Help:    "HTTP request latency in seconds.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method", "route"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(requestsTotal, requestDuration)
+}
+
+// Middleware records request counts, durations and status codes for every route
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// Use the route template (e.g. /leaves/:id) to keep label cardinality bounded
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		status := strconv.Itoa(c.Writer.Status())
+		requestsTotal.WithLabelValues(c.Request.Method, route, status).Inc()
+		requestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RegisterDBStats exposes connection pool gauges for the given database
+func RegisterDBStats(sqlDB *sql.DB) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(sqlDB, "campus"))
+}
+
+// Handler serves the Prometheus metrics endpoint
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
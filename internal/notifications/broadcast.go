@@ -0,0 +1,153 @@
+package notifications
+
+import (
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/validation"
+	"campus-backend/pkg/workqueue"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// broadcastBatchSize is how many notifications one INSERT writes
+const broadcastBatchSize = 500
+
+// broadcastQueue delivers broadcasts after the request returns, one at a time and in order
+var broadcastQueue = workqueue.New("broadcast", 1, 20)
+
+// Broadcast audiences
+const (
+	AudienceAll    = "all"
+	AudienceRole   = "role"
+	AudienceDept   = "dept"
+	AudienceHostel = "hostel"
+)
+
+// broadcastRoles are the roles a broadcast can target; service accounts have no inbox
+var broadcastRoles = []string{users.RoleAdmin, users.RoleStudent, users.RoleFaculty, users.RoleWarden}
+
+type BroadcastRequest struct {
+	Title     string `json:"title" binding:"required" validate:"required,max=200"`
+	Message   string `json:"message" binding:"required" validate:"required,max=2000"`
+	Audience  string `json:"audience" binding:"required" validate:"required,oneof=all role dept hostel"`
+	Value     string `json:"value" validate:"required_unless=Audience all"` // Role, department or hostel
+	SendEmail bool   `json:"send_email"`
+	Confirm   bool   `json:"confirm"` // Must be true to broadcast to all users
+}
+
+// Broadcast godoc
+// @Summary Broadcast a notification
+// @Description Queues a system notification for every active user in the audience: all users, a role, a department or a hostel. Users who turned off in-app notifications are skipped. With send_email, recipients who accept email are also emailed. The notifications are created and emailed in the background, so the response only counts who will receive them. Broadcasting to all users requires confirm to be true.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BroadcastRequest true "Broadcast"
+// @Success 202 {object} map[string]interface{} "Broadcast queued"
+// @Failure 400 {object} map[string]interface{} "Invalid request or missing confirmation"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Failure 503 {object} map[string]interface{} "Too many broadcasts queued"
+// @Router /admin/notifications/broadcast [post]
+func Broadcast(c *gin.Context) {
+	var req BroadcastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+	if err := validation.ValidateStruct(req); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+	if req.Audience == AudienceRole && !slices.Contains(broadcastRoles, req.Value) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "value must be one of: admin, student, faculty, warden"})
+		return
+	}
+	if req.Audience == AudienceAll && !req.Confirm {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Broadcasting to all users requires confirm to be true"})
+		return
+	}
+
+	adminIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	adminID := adminIDVal.(uint)
+
+	query := db.DB.Where("is_active = ? AND role <> ?", true, users.RoleService)
+	switch req.Audience {
+	case AudienceRole:
+		query = query.Where("role = ?", req.Value)
+	case AudienceDept:
+		query = query.Where("dept = ?", req.Value)
+	case AudienceHostel:
+		query = query.Where("hostel = ?", req.Value)
+	}
+	var recipients []users.User
+	if err := query.Find(&recipients).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find recipients"})
+		return
+	}
+
+	notifications := []Notification{}
+	emailTo := []string{}
+	for _, recipient := range recipients {
+		if recipient.WantsChannel(users.ChannelInApp) {
+			notifications = append(notifications, Notification{UserID: recipient.ID, Title: req.Title, Message: req.Message, Type: "system"})
+		}
+		if req.SendEmail && recipient.WantsChannel(users.ChannelEmail) {
+			emailTo = append(emailTo, recipient.Email)
+		}
+	}
+
+	queued := broadcastQueue.Submit(func() { deliverBroadcast(adminID, req, len(recipients), notifications, emailTo) })
+	if !queued {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Too many broadcasts are queued, try again shortly"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":    "Broadcast queued",
+		"recipients": len(recipients),
+		"in_app":     len(notifications),
+		"email":      len(emailTo),
+	})
+}
+
+// deliverBroadcast creates the broadcast's notifications together with its audit entry, then sends its emails
+func deliverBroadcast(adminID uint, req BroadcastRequest, recipients int, notifications []Notification, emailTo []string) {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if len(notifications) > 0 {
+			if err := tx.CreateInBatches(&notifications, broadcastBatchSize).Error; err != nil {
+				return err
+			}
+		}
+		details := fmt.Sprintf("audience=%s value=%q recipients=%d created=%d", req.Audience, req.Value, recipients, len(notifications))
+		return audit.Record(tx, adminID, "notification_broadcast", "notification", 0, details)
+	})
+	if err != nil {
+		log.Printf("Failed to create broadcast %q notifications: %v", req.Title, err)
+		return
+	}
+
+	emailed := 0
+	emailService := NewEmailService()
+	for _, address := range emailTo {
+		if err := emailService.SendEmail(address, req.Title, req.Message); err != nil {
+			log.Printf("Failed to send broadcast email to %s: %v", address, err)
+			continue
+		}
+		emailed++
+	}
+	log.Printf("Broadcast %q delivered: %d notifications, %d of %d emails", req.Title, len(notifications), emailed, len(emailTo))
+}
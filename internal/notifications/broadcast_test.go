@@ -0,0 +1,95 @@
+package notifications
+
+import (
+	"bytes"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/workqueue"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroadcast(t *testing.T) {
+	setupTestDB(t)
+	db.DB.AutoMigrate(&audit.AuditLog{})
+	gin.SetMode(gin.TestMode)
+
+	hostel := "H1"
+	admin := users.User{Name: "Admin", Email: "admin@example.com", Password: "hashed", Role: users.RoleAdmin, Dept: "ADMIN", IsActive: true}
+	inHostel := users.User{Name: "Asha", Email: "asha@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", Hostel: &hostel, IsActive: true}
+	emailOnly := users.User{Name: "Ravi", Email: "ravi@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", Hostel: &hostel, IsActive: true, NotificationChannels: "email"}
+	inactive := users.User{Name: "Gone", Email: "gone@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", Hostel: &hostel, IsActive: true}
+	faculty := users.User{Name: "Meera", Email: "meera@example.com", Password: "hashed", Role: users.RoleFaculty, Dept: "EE", IsActive: true}
+	for _, u := range []*users.User{&admin, &inHostel, &emailOnly, &inactive, &faculty} {
+		db.DB.Create(u)
+	}
+	db.DB.Model(&inactive).Update("is_active", false)
+
+	r := gin.New()
+	r.POST("/admin/notifications/broadcast", func(c *gin.Context) { c.Set("userID", admin.ID) }, Broadcast)
+	broadcast := func(body map[string]interface{}) (int, map[string]interface{}) {
+		payload, _ := json.Marshal(body)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/notifications/broadcast", bytes.NewReader(payload)))
+		var resp map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+
+	// Inactive users are left out and the email-only student gets no in-app notification
+	code, resp := broadcast(map[string]interface{}{"title": "Water outage", "message": "No water 2-4pm", "audience": "hostel", "value": "H1", "send_email": true})
+	assert.Equal(t, http.StatusAccepted, code)
+	assert.Equal(t, float64(2), resp["recipients"])
+	assert.Equal(t, float64(1), resp["in_app"])
+	assert.Equal(t, float64(2), resp["email"])
+	broadcastQueue.Wait()
+
+	var notification Notification
+	db.DB.Where("user_id = ?", inHostel.ID).First(&notification)
+	assert.Equal(t, "system", notification.Type)
+	assert.Equal(t, "Water outage", notification.Title)
+
+	code, resp = broadcast(map[string]interface{}{"title": "Exams", "message": "Timetable published", "audience": "role", "value": "faculty"})
+	assert.Equal(t, http.StatusAccepted, code)
+	assert.Equal(t, float64(1), resp["in_app"])
+	assert.Equal(t, float64(0), resp["email"])
+
+	// Everyone needs an explicit confirmation
+	code, _ = broadcast(map[string]interface{}{"title": "Holiday", "message": "Campus closed", "audience": "all"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, resp = broadcast(map[string]interface{}{"title": "Holiday", "message": "Campus closed", "audience": "all", "confirm": true})
+	assert.Equal(t, http.StatusAccepted, code)
+	assert.Equal(t, float64(4), resp["recipients"])
+	assert.Equal(t, float64(3), resp["in_app"])
+
+	code, _ = broadcast(map[string]interface{}{"title": "Exams", "message": "Timetable published", "audience": "role", "value": "service"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = broadcast(map[string]interface{}{"title": "Exams", "message": "Timetable published", "audience": "dept"})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	broadcastQueue.Wait()
+	var created int64
+	db.DB.Model(&Notification{}).Where("type = ?", "system").Count(&created)
+	assert.Equal(t, int64(5), created)
+	var logged int64
+	db.DB.Model(&audit.AuditLog{}).Where("action = ?", "notification_broadcast").Count(&logged)
+	assert.Equal(t, int64(3), logged)
+
+	// With the queue full the broadcast is turned away rather than dropped silently
+	defer func(previous *workqueue.Queue) { broadcastQueue = previous }(broadcastQueue)
+	broadcastQueue = workqueue.New("broadcast", 1, 1)
+	started, release := make(chan struct{}), make(chan struct{})
+	broadcastQueue.Submit(func() { close(started); <-release })
+	<-started
+	broadcastQueue.Submit(func() {})
+	code, _ = broadcast(map[string]interface{}{"title": "Exams", "message": "Timetable published", "audience": "role", "value": "faculty"})
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	close(release)
+	broadcastQueue.Wait()
+}
@@ -1,10 +1,13 @@
 package notifications
 
 import (
+	"campus-backend/internal/core"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func GetNotifications(c *gin.Context) {
@@ -15,13 +18,9 @@ func GetNotifications(c *gin.Context) {
 	}
 	userID := userIDVal.(uint)
 
-	limitStr := c.DefaultQuery("limit", "20")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 20
-	}
+	page, limit := core.PaginationParams(c)
 
-	notifications, err := GetUserNotifications(userID, limit)
+	notifications, total, err := GetUserNotifications(userID, page, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notifications"})
 		return
@@ -30,9 +29,46 @@ func GetNotifications(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"notifications": notifications,
 		"count":         len(notifications),
+		"pagination":    core.CalculatePagination(page, limit, total),
 	})
 }
 
+func GetNotification(c *gin.Context) {
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	userID := userIDVal.(uint)
+
+	notificationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	// Notifications owned by someone else are reported as missing
+	notification, err := GetUserNotification(uint(notificationID), userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification"})
+		return
+	}
+
+	if c.Query("mark_read") == "true" && !notification.IsRead {
+		if err := MarkNotificationAsReadDB(notification.ID, userID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notification as read"})
+			return
+		}
+		notification.IsRead = true
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notification": notification})
+}
+
 func MarkNotificationAsRead(c *gin.Context) {
 	userIDVal, exists := c.Get("userID")
 	if !exists {
@@ -90,3 +126,21 @@ func GetUnreadCount(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"unread_count": count})
 }
+
+// GetNotificationStats returns the caller's notification counts by type and read state, for per-category badges
+func GetNotificationStats(c *gin.Context) {
+	userIDVal, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+	userID := userIDVal.(uint)
+
+	stats, err := GetUserNotificationStats(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -3,6 +3,8 @@ package notifications
 import (
 	"campus-backend/internal/users"
 	"campus-backend/pkg/db"
+	"campus-backend/pkg/i18n"
+	"campus-backend/pkg/timeutil"
 	"fmt"
 	"log"
 	"time"
@@ -16,7 +18,7 @@ type Notification struct {
 	User      users.User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 	Title     string     `json:"title" gorm:"not null"`
 	Message   string     `json:"message" gorm:"not null"`
-	Type      string     `json:"type" gorm:"not null"` // leave_status, attendance, system
+	Type      string     `json:"type" gorm:"not null"` // leave_status, leave_comment, leave_escalation, attendance, system
 	IsRead    bool       `json:"is_read" gorm:"default:false"`
 	RelatedID *uint      `json:"related_id,omitempty"` // ID of related leave request, etc.
 	CreatedAt time.Time  `json:"created_at"`
@@ -48,6 +50,25 @@ func CreateNotification(userID uint, title, message, notificationType string, re
 	return db.DB.Create(&notification).Error
 }
 
+// NotifyWelcome greets a newly registered user in-app. It takes the registration transaction so a
+// failure here undoes the registration instead of leaving an account behind.
+func NotifyWelcome(tx *gorm.DB, user *users.User) error {
+	if !user.WantsChannel(users.ChannelInApp) {
+		return nil
+	}
+	lang := user.PreferredLanguage()
+	notification := Notification{
+		UserID:  user.ID,
+		Title:   i18n.T(lang, "notification.welcome.title"),
+		Message: i18n.T(lang, "notification.welcome.message", user.Name),
+		Type:    "system",
+	}
+	if err := tx.Create(&notification).Error; err != nil {
+		return fmt.Errorf("failed to create welcome notification: %v", err)
+	}
+	return nil
+}
+
 func NotifyLeaveStatusChange(leaveRequest *users.LeaveRequest) error {
 	var student users.User
 	if err := db.DB.First(&student, leaveRequest.StudentID).Error; err != nil {
@@ -61,81 +82,152 @@ func NotifyLeaveStatusChange(leaveRequest *users.LeaveRequest) error {
 		}
 	}
 
-	// Create notification for student
-	title := fmt.Sprintf("Leave Request %s", leaveRequest.Status)
-	message := fmt.Sprintf("Your leave request for %s (%s to %s) has been %s",
-		leaveRequest.LeaveType,
-		leaveRequest.StartDate.Format("2006-01-02"),
-		leaveRequest.EndDate.Format("2006-01-02"),
-		leaveRequest.Status)
+	// Create notification for student, in their language
+	lang := student.PreferredLanguage()
+	title, message := leaveStatusMessage(lang, leaveRequest)
 
-	if leaveRequest.Remarks != nil {
-		message += fmt.Sprintf(". Remarks: %s", *leaveRequest.Remarks)
+	if student.WantsChannel(users.ChannelInApp) {
+		err := CreateNotification(
+			leaveRequest.StudentID,
+			title,
+			message,
+			"leave_status",
+			&leaveRequest.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create notification: %v", err)
+		}
 	}
 
-	err := CreateNotification(
-		leaveRequest.StudentID,
-		title,
-		message,
-		"leave_status",
-		&leaveRequest.ID,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create notification: %v", err)
+	// Send email notification
+	if student.WantsChannel(users.ChannelEmail) {
+		subject, body, err := renderEmail(TemplateLeaveStatus, lang, newLeaveEmail(lang, &student, leaveRequest, message))
+		if err == nil {
+			err = NewEmailService().SendEmail(student.Email, subject, body)
+		}
+		if err != nil {
+			log.Printf("Failed to send email notification: %v", err)
+		}
 	}
 
-	// Send email notification
-	emailService := NewEmailService()
-	emailSubject := fmt.Sprintf("Leave Request %s - Campus Management System", leaveRequest.Status)
-	emailBody := fmt.Sprintf(`
-Dear %s,
-
-%s
-
-Leave Details:
-- Type: %s
-- Reason: %s
-- Start Date: %s
-- End Date: %s
-- Days: %d
-
-%s
-
-Best regards,
-Campus Management System
-`,
-		student.Name,
-		message,
-		leaveRequest.LeaveType,
-		leaveRequest.Reason,
-		leaveRequest.StartDate.Format("2006-01-02"),
-		leaveRequest.EndDate.Format("2006-01-02"),
-		leaveRequest.Days,
-		func() string {
-			if leaveRequest.Remarks != nil {
-				return fmt.Sprintf("Remarks: %s", *leaveRequest.Remarks)
-			}
-			return ""
-		}(),
-	)
+	status := i18n.T(lang, "leave.status."+leaveRequest.Status)
+	startDate := leaveRequest.StartDate.Format("2006-01-02")
+	endDate := leaveRequest.EndDate.Format("2006-01-02")
+	sendSMS(newSMSSender(), &student, i18n.T(lang, "sms.leave_status", leaveRequest.LeaveType, startDate, endDate, status))
+
+	return nil
+}
+
+// leaveStatusMessage is the title and text of a leave status notification, also used in the email
+func leaveStatusMessage(lang string, leave *users.LeaveRequest) (title, message string) {
+	status := i18n.T(lang, "leave.status."+leave.Status)
+	startDate := leave.StartDate.Format("2006-01-02")
+	endDate := leave.EndDate.Format("2006-01-02")
+	title = i18n.T(lang, "notification.leave_status.title", status)
+	message = i18n.T(lang, "notification.leave_status.message", leave.LeaveType, startDate, endDate, status)
+	// A rejection's remarks are its reason, which is what the student opens the notification for
+	if leave.Remarks != nil && leave.Status == "rejected" {
+		message += i18n.T(lang, "notification.leave_status.reason", *leave.Remarks)
+	} else if leave.Remarks != nil {
+		message += i18n.T(lang, "notification.leave_status.remarks", *leave.Remarks)
+	}
+	return title, message
+}
+
+// leaveReminderMessage is the title and text of the reminder sent the day before a leave starts
+func leaveReminderMessage(lang string, leave *users.LeaveRequest) (title, message string) {
+	title = i18n.T(lang, "notification.leave_reminder.title")
+	message = i18n.T(lang, "notification.leave_reminder.message", leave.LeaveType, leave.StartDate.Format("2006-01-02"))
+	return title, message
+}
+
+// NotifyLeaveComment tells each recipient, in their language, that a comment was added to a leave.
+// Recipients who turned off in-app notifications are skipped.
+func NotifyLeaveComment(recipientIDs []uint, leaveID uint, authorName, body string) error {
+	if len(recipientIDs) == 0 {
+		return nil
+	}
+
+	var recipients []users.User
+	if err := db.DB.Where("id IN ?", recipientIDs).Find(&recipients).Error; err != nil {
+		return fmt.Errorf("failed to find recipients: %v", err)
+	}
+
+	for _, recipient := range recipients {
+		if !recipient.WantsChannel(users.ChannelInApp) {
+			continue
+		}
+		lang := recipient.PreferredLanguage()
+		title := i18n.T(lang, "notification.leave_comment.title", leaveID)
+		message := i18n.T(lang, "notification.leave_comment.message", authorName, body)
+		if err := CreateNotification(recipient.ID, title, message, "leave_comment", &leaveID); err != nil {
+			return fmt.Errorf("failed to create notification: %v", err)
+		}
+	}
+	return nil
+}
+
+// NotifyLeaveFiledForStudent tells a student, in-app and by SMS per their channels, that a staff member
+// filed a leave on their behalf
+func NotifyLeaveFiledForStudent(leave *users.LeaveRequest, staffName string) error {
+	var student users.User
+	if err := db.DB.First(&student, leave.StudentID).Error; err != nil {
+		return fmt.Errorf("failed to find student: %v", err)
+	}
+
+	lang := student.PreferredLanguage()
+	startDate := leave.StartDate.In(timeutil.Location()).Format(timeutil.DateLayout)
+	endDate := leave.EndDate.In(timeutil.Location()).Format(timeutil.DateLayout)
+	if student.WantsChannel(users.ChannelInApp) {
+		title := i18n.T(lang, "notification.leave_filed.title")
+		message := i18n.T(lang, "notification.leave_filed.message", leave.LeaveType, startDate, endDate, staffName)
+		if err := CreateNotification(student.ID, title, message, "leave_status", &leave.ID); err != nil {
+			return fmt.Errorf("failed to create notification: %v", err)
+		}
+	}
+	sendSMS(newSMSSender(), &student, i18n.T(lang, "sms.leave_filed", leave.LeaveType, startDate, endDate, staffName))
+	return nil
+}
+
+// NotifyLeaveEscalated tells each recipient, in their language, that a leave has been pending past the
+// approval deadline. Recipients who turned off in-app notifications are skipped.
+func NotifyLeaveEscalated(recipientIDs []uint, leave *users.LeaveRequest, studentName string, deadlineHours int) error {
+	if len(recipientIDs) == 0 {
+		return nil
+	}
 
-	if err := emailService.SendEmail(student.Email, emailSubject, emailBody); err != nil {
-		log.Printf("Failed to send email notification: %v", err)
+	var recipients []users.User
+	if err := db.DB.Where("id IN ?", recipientIDs).Find(&recipients).Error; err != nil {
+		return fmt.Errorf("failed to find recipients: %v", err)
 	}
 
+	startDate := leave.StartDate.In(timeutil.Location()).Format(timeutil.DateLayout)
+	for _, recipient := range recipients {
+		if !recipient.WantsChannel(users.ChannelInApp) {
+			continue
+		}
+		lang := recipient.PreferredLanguage()
+		title := i18n.T(lang, "notification.leave_escalation.title", leave.ID)
+		message := i18n.T(lang, "notification.leave_escalation.message", studentName, leave.LeaveType, startDate, deadlineHours)
+		if err := CreateNotification(recipient.ID, title, message, "leave_escalation", &leave.ID); err != nil {
+			return fmt.Errorf("failed to create notification: %v", err)
+		}
+	}
 	return nil
 }
 
 func NotifyLeaveStartingTomorrow() error {
-	tomorrow := time.Now().Add(24 * time.Hour).Truncate(24 * time.Hour)
+	// "Tomorrow" is the next day in the campus timezone
+	tomorrow, dayAfter := timeutil.DayBounds(timeutil.Tomorrow())
 
 	var leaves []users.LeaveRequest
-	err := db.DB.Where("start_date = ? AND status = ?", tomorrow, "approved").Find(&leaves).Error
+	err := db.DB.Where("start_date >= ? AND start_date < ? AND status = ?", tomorrow, dayAfter, "approved").Find(&leaves).Error
 	if err != nil {
 		return fmt.Errorf("failed to find leaves starting tomorrow: %v", err)
 	}
 
 	emailService := NewEmailService()
+	smsSender := newSMSSender()
 
 	for _, leave := range leaves {
 		var student users.User
@@ -145,65 +237,61 @@ func NotifyLeaveStartingTomorrow() error {
 		}
 
 		// Create notification
-		title := "Leave Starting Tomorrow"
-		message := fmt.Sprintf("Your approved leave for %s starts tomorrow (%s). Please ensure all arrangements are in place.",
-			leave.LeaveType, leave.StartDate.Format("2006-01-02"))
-
-		err := CreateNotification(
-			leave.StudentID,
-			title,
-			message,
-			"leave_reminder",
-			&leave.ID,
-		)
-		if err != nil {
-			log.Printf("Failed to create notification for student %d: %v", leave.StudentID, err)
-			continue
+		lang := student.PreferredLanguage()
+		title, message := leaveReminderMessage(lang, &leave)
+
+		if student.WantsChannel(users.ChannelInApp) {
+			err := CreateNotification(
+				leave.StudentID,
+				title,
+				message,
+				"leave_reminder",
+				&leave.ID,
+			)
+			if err != nil {
+				log.Printf("Failed to create notification for student %d: %v", leave.StudentID, err)
+				continue
+			}
 		}
 
 		// Send email
-		emailSubject := "Leave Starting Tomorrow - Reminder"
-		emailBody := fmt.Sprintf(`
-Dear %s,
-
-%s
-
-Leave Details:
-- Type: %s
-- Reason: %s
-- Start Date: %s
-- End Date: %s
-- Days: %d
-
-Please ensure all necessary arrangements are made before your leave begins.
-
-Best regards,
-Campus Management System
-`,
-			student.Name,
-			message,
-			leave.LeaveType,
-			leave.Reason,
-			leave.StartDate.Format("2006-01-02"),
-			leave.EndDate.Format("2006-01-02"),
-			leave.Days,
-		)
-
-		if err := emailService.SendEmail(student.Email, emailSubject, emailBody); err != nil {
-			log.Printf("Failed to send reminder email to %s: %v", student.Email, err)
+		if student.WantsChannel(users.ChannelEmail) {
+			subject, body, err := renderEmail(TemplateLeaveReminder, lang, newLeaveEmail(lang, &student, &leave, message))
+			if err == nil {
+				err = emailService.SendEmail(student.Email, subject, body)
+			}
+			if err != nil {
+				log.Printf("Failed to send reminder email to %s: %v", student.Email, err)
+			}
 		}
+
+		sendSMS(smsSender, &student, i18n.T(lang, "sms.leave_reminder", leave.LeaveType, leave.StartDate.Format("2006-01-02")))
 	}
 
 	return nil
 }
 
-func GetUserNotifications(userID uint, limit int) ([]Notification, error) {
+func GetUserNotifications(userID uint, page, limit int) ([]Notification, int64, error) {
 	var notifications []Notification
+	var total int64
+	if err := db.DB.Model(&Notification{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
 	err := db.DB.Where("user_id = ?", userID).
 		Order("created_at DESC").
+		Offset((page - 1) * limit).
 		Limit(limit).
 		Find(&notifications).Error
-	return notifications, err
+	return notifications, total, err
+}
+
+func GetUserNotification(notificationID, userID uint) (*Notification, error) {
+	var notification Notification
+	err := db.DB.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
 }
 
 func MarkNotificationAsReadDB(notificationID, userID uint) error {
@@ -225,3 +313,56 @@ func GetUnreadNotificationCount(userID uint) (int64, error) {
 		Count(&count).Error
 	return count, err
 }
+
+// TypeCount is how many of a user's notifications have one type, and how many of those are unread
+type TypeCount struct {
+	Total  int64 `json:"total"`
+	Unread int64 `json:"unread"`
+}
+
+// NotificationStats breaks a user's notifications down by read state and type
+type NotificationStats struct {
+	Total  int64                `json:"total"`
+	Unread int64                `json:"unread"`
+	Read   int64                `json:"read"`
+	ByType map[string]TypeCount `json:"by_type"`
+}
+
+// GetUserNotificationStats counts the user's notifications per type in one grouped query
+func GetUserNotificationStats(userID uint) (*NotificationStats, error) {
+	var rows []struct {
+		Type   string
+		Total  int64
+		Unread int64
+	}
+	err := db.DB.Model(&Notification{}).
+		Select("type, COUNT(*) AS total, COALESCE(SUM(CASE WHEN is_read THEN 0 ELSE 1 END), 0) AS unread").
+		Where("user_id = ?", userID).
+		Group("type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &NotificationStats{ByType: make(map[string]TypeCount, len(rows))}
+	for _, row := range rows {
+		stats.ByType[row.Type] = TypeCount{Total: row.Total, Unread: row.Unread}
+		stats.Total += row.Total
+		stats.Unread += row.Unread
+	}
+	stats.Read = stats.Total - stats.Unread
+	return stats, nil
+}
+
+// newSMSSender is swapped out in tests
+var newSMSSender = NewSMSSender
+
+// sendSMS texts the user if they opted in to SMS; users without a phone number are skipped
+func sendSMS(sender SMSSender, user *users.User, body string) {
+	if !user.WantsChannel(users.ChannelSMS) || user.Phone == nil || *user.Phone == "" {
+		return
+	}
+	if err := sender.SendSMS(*user.Phone, body); err != nil {
+		log.Printf("Failed to send SMS to user %d: %v", user.ID, err)
+	}
+}
@@ -0,0 +1,272 @@
+package notifications
+
+import (
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type recordingSMSSender struct {
+	sent map[string]string
+}
+
+func (s *recordingSMSSender) SendSMS(to, body string) error {
+	s.sent[to] = body
+	return nil
+}
+
+func setupTestDB(t *testing.T) {
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&users.User{}, &Notification{})
+	db.DB = testDB
+}
+
+func TestNotifyLeaveStatusChangeRespectsChannels(t *testing.T) {
+	setupTestDB(t)
+	sms := &recordingSMSSender{sent: map[string]string{}}
+	defer func(f func() SMSSender) { newSMSSender = f }(newSMSSender)
+	newSMSSender = func() SMSSender { return sms }
+
+	phone, empty := "+919876543210", ""
+	defaults := users.User{Name: "Defaults", Email: "defaults@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", Phone: &phone, IsActive: true}
+	smsOnly := users.User{Name: "SMS Only", Email: "sms@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", Phone: &phone, NotificationChannels: users.ChannelSMS, IsActive: true}
+	noPhone := users.User{Name: "No Phone", Email: "nophone@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", Phone: &empty, NotificationChannels: "sms,in_app", IsActive: true}
+	for _, u := range []*users.User{&defaults, &smsOnly, &noPhone} {
+		db.DB.Create(u)
+	}
+
+	notify := func(student users.User) {
+		start := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+		leave := users.LeaveRequest{StudentID: student.ID, LeaveType: "medical", Reason: "Check-up", StartDate: start, EndDate: start, Status: "approved", Days: 1}
+		leave.ID = student.ID
+		assert.NoError(t, NotifyLeaveStatusChange(&leave))
+	}
+	inApp := func(student users.User) int64 {
+		var count int64
+		db.DB.Model(&Notification{}).Where("user_id = ?", student.ID).Count(&count)
+		return count
+	}
+
+	notify(defaults)
+	assert.Equal(t, int64(1), inApp(defaults))
+	assert.Empty(t, sms.sent)
+
+	notify(smsOnly)
+	assert.Equal(t, int64(0), inApp(smsOnly))
+	assert.Contains(t, sms.sent[phone], "medical leave")
+
+	delete(sms.sent, phone)
+	notify(noPhone)
+	assert.Equal(t, int64(1), inApp(noPhone))
+	assert.Empty(t, sms.sent)
+}
+
+func TestTwilioSMSSender(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "AC123", user)
+		assert.Equal(t, "token", pass)
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "+919876543210", r.PostForm.Get("To"))
+		assert.Equal(t, "+15005550006", r.PostForm.Get("From"))
+		assert.Equal(t, "hello", r.PostForm.Get("Body"))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	sender := &TwilioSMSSender{AccountSID: "AC123", AuthToken: "token", From: "+15005550006", BaseURL: server.URL}
+	assert.NoError(t, sender.SendSMS("+919876543210", "hello"))
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer failing.Close()
+	sender.BaseURL = failing.URL
+	assert.Error(t, sender.SendSMS("+919876543210", "hello"))
+}
+
+func TestEmailTemplates(t *testing.T) {
+	defer LoadTemplates("")
+
+	remarks := "Get well soon"
+	start := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	student := users.User{Name: "Asha"}
+	leave := users.LeaveRequest{LeaveType: "medical", Reason: "Check-up", StartDate: start, EndDate: start.AddDate(0, 0, 1), Days: 2, Status: "approved", Remarks: &remarks}
+	data := newLeaveEmail("en", &student, &leave, "Your leave request has been approved")
+
+	subject, body, err := renderEmail(TemplateLeaveStatus, "en", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Leave Request approved - Campus Management System", subject)
+	assert.Contains(t, body, "Dear Asha,\n\nYour leave request has been approved\n")
+	assert.Contains(t, body, "- Start Date: 2026-10-20\n- End Date: 2026-10-21\n- Days: 2\n")
+	assert.Contains(t, body, "Remarks: Get well soon")
+
+	leave.Status = "rejected"
+	_, body, err = renderEmail(TemplateLeaveStatus, "en", newLeaveEmail("en", &student, &leave, "Your leave request has been rejected"))
+	assert.NoError(t, err)
+	assert.Contains(t, body, "Reason for rejection: Get well soon")
+
+	// Overrides replace only the templates they provide
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "leave_status.tmpl"), []byte(`{{define "subject"}}Leave {{.Status}}{{end}}Hi {{.StudentName}}, your leave was {{.Status}}.`), 0o644)
+	assert.NoError(t, LoadTemplates(dir))
+
+	subject, body, err = renderEmail(TemplateLeaveStatus, "en", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Leave approved", subject)
+	assert.Equal(t, "Hi Asha, your leave was approved.", body)
+
+	subject, _, err = renderEmail(TemplateLeaveReminder, "en", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Leave Starting Tomorrow - Reminder", subject)
+
+	// A broken override is rejected and the loaded templates are kept
+	os.WriteFile(filepath.Join(dir, "leave_status.tmpl"), []byte(`Hi {{.StudentName`), 0o644)
+	assert.Error(t, LoadTemplates(dir))
+	os.WriteFile(filepath.Join(dir, "leave_status.tmpl"), []byte(`No subject`), 0o644)
+	assert.Error(t, LoadTemplates(dir))
+
+	subject, _, _ = renderEmail(TemplateLeaveStatus, "en", data)
+	assert.Equal(t, "Leave approved", subject)
+}
+
+func TestNotificationsUseStudentLanguage(t *testing.T) {
+	setupTestDB(t)
+	sms := &recordingSMSSender{sent: map[string]string{}}
+	defer func(f func() SMSSender) { newSMSSender = f }(newSMSSender)
+	newSMSSender = func() SMSSender { return sms }
+
+	phone := "+919876543210"
+	student := users.User{Name: "Asha", Email: "asha@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", Phone: &phone, NotificationChannels: "in_app,sms", Language: "hi", IsActive: true}
+	db.DB.Create(&student)
+
+	start := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	leave := users.LeaveRequest{StudentID: student.ID, LeaveType: "medical", Reason: "Check-up", StartDate: start, EndDate: start, Status: "rejected", Days: 1}
+	leave.ID = 1
+	assert.NoError(t, NotifyLeaveStatusChange(&leave))
+
+	var notification Notification
+	db.DB.Where("user_id = ?", student.ID).First(&notification)
+	assert.Equal(t, "अवकाश अनुरोध अस्वीकृत", notification.Title)
+	assert.Equal(t, "2026-10-20 से 2026-10-20 तक के आपके medical अवकाश अनुरोध को अस्वीकृत कर दिया गया है", notification.Message)
+	assert.Contains(t, sms.sent[phone], "अस्वीकृत")
+
+	data := newLeaveEmail("hi", &student, &leave, notification.Message)
+	subject, body, err := renderEmail(TemplateLeaveStatus, "hi", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "अवकाश अनुरोध अस्वीकृत - कैंपस प्रबंधन प्रणाली", subject)
+	assert.Contains(t, body, "प्रिय Asha,")
+
+	// Languages without a template get the English one
+	subject, _, err = renderEmail(TemplateLeaveStatus, "fr", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Leave Request rejected - Campus Management System", subject)
+}
+
+func TestGetNotificationStats(t *testing.T) {
+	setupTestDB(t)
+	gin.SetMode(gin.TestMode)
+
+	for _, n := range []struct {
+		userID uint
+		kind   string
+		read   bool
+	}{
+		{1, "leave_status", false},
+		{1, "leave_status", true},
+		{1, "leave_reminder", false},
+		{1, "system", true},
+		{2, "system", false}, // Someone else's
+	} {
+		db.DB.Create(&Notification{UserID: n.userID, Title: "Title", Message: "Message", Type: n.kind, IsRead: n.read})
+	}
+
+	r := gin.New()
+	r.GET("/notifications/stats", func(c *gin.Context) { c.Set("userID", uint(1)) }, GetNotificationStats)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/notifications/stats", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var stats NotificationStats
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	assert.Equal(t, NotificationStats{
+		Total:  4,
+		Unread: 2,
+		Read:   2,
+		ByType: map[string]TypeCount{
+			"leave_status":   {Total: 2, Unread: 1},
+			"leave_reminder": {Total: 1, Unread: 1},
+			"system":         {Total: 1, Unread: 0},
+		},
+	}, stats)
+}
+
+func TestGetNotification(t *testing.T) {
+	setupTestDB(t)
+	gin.SetMode(gin.TestMode)
+
+	mine := Notification{UserID: 1, Title: "Leave approved", Message: "Your leave has been approved", Type: "leave_status"}
+	theirs := Notification{UserID: 2, Title: "Hello", Message: "Welcome", Type: "system"}
+	db.DB.Create(&mine)
+	db.DB.Create(&theirs)
+
+	r := gin.New()
+	r.GET("/notifications/:id", func(c *gin.Context) { c.Set("userID", uint(1)) }, GetNotification)
+	get := func(path string) (int, Notification) {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var resp struct {
+			Notification Notification `json:"notification"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp.Notification
+	}
+	isRead := func(id uint) bool {
+		var stored Notification
+		db.DB.First(&stored, id)
+		return stored.IsRead
+	}
+	path := "/notifications/" + strconv.Itoa(int(mine.ID))
+
+	// Fetching alone leaves it unread
+	code, got := get(path)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, mine.ID, got.ID)
+	assert.Equal(t, "Leave approved", got.Title)
+	assert.False(t, got.IsRead)
+	assert.False(t, isRead(mine.ID))
+
+	code, got = get(path + "?mark_read=true")
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, got.IsRead)
+	assert.True(t, isRead(mine.ID))
+
+	// Someone else's notification is indistinguishable from a missing one
+	code, _ = get("/notifications/" + strconv.Itoa(int(theirs.ID)) + "?mark_read=true")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.False(t, isRead(theirs.ID))
+	code, _ = get("/notifications/9999")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = get("/notifications/abc")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
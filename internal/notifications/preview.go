@@ -0,0 +1,80 @@
+package notifications
+
+import (
+	"campus-backend/internal/core"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/i18n"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreviewEmail godoc
+// @Summary Preview a leave email
+// @Description Renders the email a leave would trigger, with the leave's real data and the templates currently loaded, without sending anything. leave_status shows the email for the leave's current status. The student's language is used unless lang is given.
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param type query string true "Template (leave_status or leave_reminder)"
+// @Param leave_id query int true "Leave ID"
+// @Param lang query string false "Language to render in, e.g. hi"
+// @Success 200 {object} map[string]interface{} "Rendered subject and body"
+// @Failure 400 {object} map[string]interface{} "Invalid parameters"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Leave not found"
+// @Failure 500 {object} map[string]interface{} "Template failed to render"
+// @Router /admin/notifications/preview [get]
+func PreviewEmail(c *gin.Context) {
+	name := c.Query("type")
+	if !slices.Contains(templateNames, name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be one of: " + strings.Join(templateNames, ", ")})
+		return
+	}
+	lang := c.Query("lang")
+	if lang != "" && !i18n.Supported(lang) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lang must be one of: " + strings.Join(i18n.Languages(), ", ")})
+		return
+	}
+
+	var leave users.LeaveRequest
+	if err := db.DB.First(&leave, c.Query("leave_id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Leave request not found"})
+		return
+	}
+	var student users.User
+	if err := db.DB.First(&student, leave.StudentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Student not found"})
+		return
+	}
+	if lang == "" {
+		lang = student.PreferredLanguage()
+	}
+
+	var message string
+	if name == TemplateLeaveReminder {
+		_, message = leaveReminderMessage(lang, &leave)
+	} else {
+		_, message = leaveStatusMessage(lang, &leave)
+	}
+	subject, body, err := renderEmail(name, lang, newLeaveEmail(lang, &student, &leave, message))
+	if err != nil {
+		// Usually a template override referring to a field that does not exist
+		log.Printf("Failed to render %s preview for leave %d: %v", name, leave.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render the template", "details": core.PublicError(err, "Check the template override for fields that do not exist")})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"type":     name,
+		"leave_id": leave.ID,
+		"to":       student.Email,
+		"language": lang,
+		"subject":  subject,
+		"body":     body,
+	})
+}
@@ -0,0 +1,64 @@
+package notifications
+
+import (
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewEmail(t *testing.T) {
+	setupTestDB(t)
+	db.DB.AutoMigrate(&users.LeaveRequest{})
+	gin.SetMode(gin.TestMode)
+
+	student := users.User{Name: "Asha", Email: "asha@example.com", Password: "hashed", Role: users.RoleStudent, Dept: "CS", Language: "hi", IsActive: true}
+	db.DB.Create(&student)
+	remarks := "Get well soon"
+	start := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	leave := users.LeaveRequest{StudentID: student.ID, LeaveType: "medical", Reason: "Check-up at the hospital", StartDate: start, EndDate: start.AddDate(0, 0, 1), Days: 2, Status: "approved", Remarks: &remarks, Dept: "CS"}
+	db.DB.Create(&leave)
+
+	leaveID := strconv.FormatUint(uint64(leave.ID), 10)
+
+	r := gin.New()
+	r.GET("/admin/notifications/preview", PreviewEmail)
+	preview := func(query string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/notifications/preview?"+query, nil))
+		var resp map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+
+	code, resp := preview("type=leave_status&lang=en&leave_id=" + leaveID)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "asha@example.com", resp["to"])
+	assert.Equal(t, "Leave Request approved - Campus Management System", resp["subject"])
+	assert.Contains(t, resp["body"], "Dear Asha,")
+	assert.Contains(t, resp["body"], "Remarks: Get well soon")
+
+	// Without lang, the student's language is used
+	code, resp = preview("type=leave_reminder&leave_id=" + leaveID)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "hi", resp["language"])
+
+	code, _ = preview("type=welcome&leave_id=" + leaveID)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = preview("type=leave_status&lang=fr&leave_id=" + leaveID)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = preview("type=leave_status&leave_id=999")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// Previews never notify the student
+	var count int64
+	db.DB.Model(&Notification{}).Count(&count)
+	assert.Zero(t, count)
+}
@@ -0,0 +1,78 @@
+package notifications
+
+import (
+	"campus-backend/internal/core"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SMSSender delivers a text message to a phone number in E.164 format
+type SMSSender interface {
+	SendSMS(to, body string) error
+}
+
+// NewSMSSender returns the sender selected by SMS_PROVIDER
+func NewSMSSender() SMSSender {
+	cfg := core.GetConfig().SMS
+	if cfg.Provider == "twilio" {
+		return &TwilioSMSSender{
+			AccountSID: cfg.TwilioAccountSID,
+			AuthToken:  cfg.TwilioAuthToken,
+			From:       cfg.FromNumber,
+			Client:     &http.Client{Timeout: 10 * time.Second},
+		}
+	}
+	return &LogSMSSender{}
+}
+
+// LogSMSSender only logs messages, for development and tests
+type LogSMSSender struct{}
+
+func (s *LogSMSSender) SendSMS(to, body string) error {
+	log.Printf("Sending SMS to %s: %s", to, body)
+	return nil
+}
+
+// TwilioSMSSender sends messages through the Twilio Messages API
+type TwilioSMSSender struct {
+	AccountSID string
+	AuthToken  string
+	From       string
+	Client     *http.Client
+	BaseURL    string // Defaults to https://api.twilio.com
+}
+
+func (s *TwilioSMSSender) SendSMS(to, body string) error {
+	baseURL := s.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.twilio.com"
+	}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", baseURL, url.PathEscape(s.AccountSID))
+
+	form := url.Values{"To": {to}, "From": {s.From}, "Body": {body}}
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.AccountSID, s.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("twilio returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package notifications
+
+import (
+	"bytes"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/i18n"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Email template names; each is a file <name>.tmpl whose body is the email and whose
+// "subject" block is the subject line. <name>.<lang>.tmpl, e.g. leave_status.hi.tmpl,
+// translates it; languages without one get the English template.
+const (
+	TemplateLeaveStatus   = "leave_status"
+	TemplateLeaveReminder = "leave_reminder"
+)
+
+var templateNames = []string{TemplateLeaveStatus, TemplateLeaveReminder}
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// emailTemplates starts with the built-in wording; LoadTemplates can replace it at startup
+var emailTemplates = mustParseDefaults()
+
+// LeaveEmail is the data passed to the leave email templates
+type LeaveEmail struct {
+	StudentName string
+	Message     string // Same text as the in-app notification
+	LeaveType   string
+	Reason      string
+	StartDate   string // YYYY-MM-DD
+	EndDate     string
+	Days        int
+	Status      string // pending, needs_info, approved or rejected
+	StatusText  string // Status in the student's language
+	Remarks     string
+}
+
+func newLeaveEmail(lang string, student *users.User, leave *users.LeaveRequest, message string) LeaveEmail {
+	data := LeaveEmail{
+		StudentName: student.Name,
+		Message:     message,
+		LeaveType:   leave.LeaveType,
+		Reason:      leave.Reason,
+		StartDate:   leave.StartDate.Format("2006-01-02"),
+		EndDate:     leave.EndDate.Format("2006-01-02"),
+		Days:        leave.Days,
+		Status:      leave.Status,
+		StatusText:  i18n.T(lang, "leave.status."+leave.Status),
+	}
+	if leave.Remarks != nil {
+		data.Remarks = *leave.Remarks
+	}
+	return data
+}
+
+func mustParseDefaults() map[string]*template.Template {
+	parsed, err := parseTemplates("")
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// LoadTemplates parses the email templates, using <dir>/<file> where it exists and the
+// built-in template otherwise. An empty dir restores the built-in templates.
+func LoadTemplates(dir string) error {
+	parsed, err := parseTemplates(dir)
+	if err != nil {
+		return err
+	}
+	emailTemplates = parsed
+	return nil
+}
+
+func parseTemplates(dir string) (map[string]*template.Template, error) {
+	parsed := make(map[string]*template.Template)
+	for _, name := range templateNames {
+		for _, lang := range i18n.Languages() {
+			key := templateKey(name, lang)
+			file := key + ".tmpl"
+			source, err := defaultTemplates.ReadFile("templates/" + file)
+			if err != nil && lang == i18n.DefaultLanguage {
+				return nil, err
+			}
+			if dir != "" {
+				override, readErr := os.ReadFile(filepath.Join(dir, file))
+				if readErr == nil {
+					source, err = override, nil
+				} else if !os.IsNotExist(readErr) {
+					return nil, readErr
+				}
+			}
+			if err != nil {
+				continue // No translation; the English template is used
+			}
+
+			tmpl, err := template.New(key).Option("missingkey=error").Parse(string(source))
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", file, err)
+			}
+			if tmpl.Lookup("subject") == nil {
+				return nil, fmt.Errorf("%s has no subject block", file)
+			}
+			parsed[key] = tmpl
+		}
+	}
+	return parsed, nil
+}
+
+// templateKey is the file name, without extension, of a template in a language
+func templateKey(name, lang string) string {
+	if lang == i18n.DefaultLanguage {
+		return name
+	}
+	return name + "." + lang
+}
+
+// renderEmail executes the named template in lang, or in English when it has no translation,
+// returning the subject and body
+func renderEmail(name, lang string, data interface{}) (subject, body string, err error) {
+	tmpl, ok := emailTemplates[templateKey(name, lang)]
+	if !ok {
+		tmpl, ok = emailTemplates[name]
+	}
+	if !ok {
+		return "", "", fmt.Errorf("unknown email template %q", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return "", "", err
+	}
+	subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	return subject, buf.String(), nil
+}
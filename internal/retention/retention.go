@@ -0,0 +1,149 @@
+package retention
+
+import (
+	"campus-backend/internal/attendance"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/internal/notifications"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PurgeResult counts the records older than the retention window
+type PurgeResult struct {
+	Months        int       `json:"months"`
+	Cutoff        time.Time `json:"cutoff"` // Records before this are purged
+	DryRun        bool      `json:"dry_run"`
+	Attendance    int64     `json:"attendance"`
+	Notifications int64     `json:"notifications"` // Read notifications only
+}
+
+// Cutoff returns the start of the day months ago in campus time, moved back to the
+// active term's start if that is earlier, so the current term is always kept
+func Cutoff(months int, activeTermStart string) (time.Time, error) {
+	cutoff := timeutil.StartOfDay(timeutil.Today().In(timeutil.Location()).AddDate(0, -months, 0))
+	if activeTermStart != "" {
+		termStart, err := timeutil.ParseDate(activeTermStart)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid active term start %q: %w", activeTermStart, err)
+		}
+		if termStart.Before(cutoff) {
+			cutoff = termStart
+		}
+	}
+	return cutoff, nil
+}
+
+// Purge hard-deletes attendance and read notifications older than the given number of months,
+// or only counts them when dryRun is set
+func Purge(months int, dryRun bool) (*PurgeResult, error) {
+	cutoff, err := Cutoff(months, core.GetConfig().Retention.ActiveTermStart)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PurgeResult{Months: months, Cutoff: cutoff, DryRun: dryRun}
+	attendanceQuery := func(tx *gorm.DB) *gorm.DB {
+		return tx.Unscoped().Model(&attendance.Attendance{}).Where("date < ?", cutoff)
+	}
+	notificationQuery := func(tx *gorm.DB) *gorm.DB {
+		return tx.Unscoped().Model(&notifications.Notification{}).Where("is_read = ? AND created_at < ?", true, cutoff)
+	}
+
+	if dryRun {
+		if err := attendanceQuery(db.DB).Count(&result.Attendance).Error; err != nil {
+			return nil, err
+		}
+		if err := notificationQuery(db.DB).Count(&result.Notifications).Error; err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		deleted := attendanceQuery(tx).Delete(&attendance.Attendance{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		result.Attendance = deleted.RowsAffected
+
+		deleted = notificationQuery(tx).Delete(&notifications.Notification{})
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		result.Notifications = deleted.RowsAffected
+
+		details := fmt.Sprintf("purged records before %s: %d attendance records, %d read notifications",
+			cutoff.In(timeutil.Location()).Format(timeutil.DateLayout), result.Attendance, result.Notifications)
+		return audit.Record(tx, audit.SystemActorID, "retention_purge", "retention", 0, details)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Purged records no longer count towards lifetime stats
+	if result.Attendance > 0 {
+		if _, err := attendance.RecomputeSummaries(); err != nil {
+			log.Printf("Retention: failed to recompute attendance summaries: %v", err)
+		}
+	}
+
+	log.Printf("Retention: purged %d attendance records and %d read notifications before %s",
+		result.Attendance, result.Notifications, cutoff.Format(time.RFC3339))
+	return result, nil
+}
+
+// PurgeExpired runs Purge with the configured retention window; it does nothing if retention is off
+func PurgeExpired() error {
+	months := core.GetConfig().Retention.Months
+	if months < 1 {
+		return nil
+	}
+	_, err := Purge(months, false)
+	return err
+}
+
+// PreviewPurge godoc
+// @Summary Preview the retention purge
+// @Description Admin sees how many attendance records and read notifications the retention job would delete, without deleting anything. Records from the active term are never counted.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param months query int false "Retention window to preview (defaults to RETENTION_MONTHS)"
+// @Success 200 {object} PurgeResult "Records that would be purged"
+// @Failure 400 {object} map[string]interface{} "Retention disabled or invalid months"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/retention/preview [get]
+func PreviewPurge(c *gin.Context) {
+	months := core.GetConfig().Retention.Months
+	if value := c.Query("months"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "months must be a positive integer"})
+			return
+		}
+		months = parsed
+	}
+	if months < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Data retention is disabled; pass months to preview a retention window"})
+		return
+	}
+
+	result, err := Purge(months, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to preview purge"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,107 @@
+package retention
+
+import (
+	"campus-backend/internal/attendance"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/internal/notifications"
+	"campus-backend/internal/users"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupTestDB(t *testing.T) {
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&users.User{}, &attendance.Attendance{}, &notifications.Notification{}, &audit.AuditLog{})
+	db.DB = testDB
+}
+
+func TestPurge(t *testing.T) {
+	setupTestDB(t)
+	t.Setenv("RETENTION_MONTHS", "6")
+	core.LoadConfig()
+	defer func() { core.AppConfig = nil }()
+
+	today := timeutil.Today()
+	for _, date := range []time.Time{today.AddDate(0, -8, 0), today.AddDate(0, -7, 0), today.AddDate(0, -2, 0)} {
+		db.DB.Create(&attendance.Attendance{StudentID: 1, Date: date, Status: "present", Present: true, MarkedBy: 2})
+	}
+	// Only read notifications past the window are purged
+	for _, n := range []notifications.Notification{
+		{UserID: 1, Title: "Old read", Message: "m", Type: "system", IsRead: true, CreatedAt: today.AddDate(0, -9, 0)},
+		{UserID: 1, Title: "Old unread", Message: "m", Type: "system", CreatedAt: today.AddDate(0, -9, 0)},
+		{UserID: 1, Title: "Recent read", Message: "m", Type: "system", IsRead: true, CreatedAt: today.AddDate(0, -1, 0)},
+	} {
+		db.DB.Create(&n)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin/retention/preview", PreviewPurge)
+	preview := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/retention/preview"+query, nil))
+		return rec
+	}
+
+	rec := preview("")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var result PurgeResult
+	json.Unmarshal(rec.Body.Bytes(), &result)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, int64(2), result.Attendance)
+	assert.Equal(t, int64(1), result.Notifications)
+
+	rec = preview("?months=12")
+	json.Unmarshal(rec.Body.Bytes(), &result)
+	assert.Equal(t, int64(0), result.Attendance)
+	assert.Equal(t, http.StatusBadRequest, preview("?months=0").Code)
+
+	// The preview deleted nothing
+	var count int64
+	db.DB.Model(&attendance.Attendance{}).Count(&count)
+	assert.Equal(t, int64(3), count)
+
+	assert.NoError(t, PurgeExpired())
+	db.DB.Unscoped().Model(&attendance.Attendance{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+	db.DB.Unscoped().Model(&notifications.Notification{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+
+	var entry audit.AuditLog
+	assert.NoError(t, db.DB.Where("action = ?", "retention_purge").First(&entry).Error)
+	assert.Contains(t, entry.Details, "2 attendance records, 1 read notifications")
+}
+
+func TestCutoffKeepsActiveTerm(t *testing.T) {
+	today := timeutil.Today()
+	termStart := today.AddDate(0, -10, 0)
+
+	cutoff, err := Cutoff(6, termStart.In(timeutil.Location()).Format(timeutil.DateLayout))
+	assert.NoError(t, err)
+	assert.True(t, cutoff.Equal(termStart))
+
+	// A term that started inside the window does not move the cutoff
+	cutoff, err = Cutoff(6, today.AddDate(0, -1, 0).In(timeutil.Location()).Format(timeutil.DateLayout))
+	assert.NoError(t, err)
+	assert.True(t, cutoff.Equal(timeutil.StartOfDay(today.AddDate(0, -6, 0))))
+
+	_, err = Cutoff(6, "not-a-date")
+	assert.Error(t, err)
+}
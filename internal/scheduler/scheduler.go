@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job is a named task run at a fixed interval
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func() error
+}
+
+// Scheduler runs background jobs until its context is cancelled
+type Scheduler struct {
+	jobs []Job
+}
+
+// New creates an empty scheduler
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add registers a job. Jobs with a non-positive interval are ignored.
+func (s *Scheduler) Add(job Job) {
+	if job.Interval <= 0 {
+		log.Printf("Scheduler: job %s disabled (interval %s)", job.Name, job.Interval)
+		return
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Start launches one goroutine per job and returns immediately
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go run(ctx, job)
+	}
+}
+
+// run executes the job on every tick; a failing run is logged and retried on the next tick
+func run(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	log.Printf("Scheduler: job %s running every %s", job.Name, job.Interval)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job.Run(); err != nil {
+				log.Printf("Scheduler: job %s failed: %v", job.Name, err)
+			}
+		}
+	}
+}
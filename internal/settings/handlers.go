@@ -0,0 +1,173 @@
+package settings
+
+import (
+	"bytes"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/pkg/db"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UpdateSettingsRequest struct {
+	Settings map[string]json.RawMessage `json:"settings" binding:"required"` // null resets a setting to its env value
+}
+
+// SettingView is a setting's effective value alongside its env default
+type SettingView struct {
+	Key         string      `json:"key"`
+	Description string      `json:"description"`
+	Value       interface{} `json:"value"`
+	Default     interface{} `json:"default"`
+	Overridden  bool        `json:"overridden"`
+	TermID      *uint       `json:"term_id,omitempty"` // Set when the value comes from the active term
+	UpdatedBy   *uint       `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time  `json:"updated_at,omitempty"`
+}
+
+// GetSettings godoc
+// @Summary List runtime settings
+// @Description Admin views the campus-policy settings that can be changed without a redeploy, with their current and env values. Values set by the term active today are marked with its term_id.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Settings"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/settings [get]
+func GetSettings(c *gin.Context) {
+	views, err := settingViews()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"settings": views, "active_term_id": ActiveTermID()})
+}
+
+// UpdateSettings godoc
+// @Summary Update runtime settings
+// @Description Admin overrides campus-policy settings, e.g. {"settings": {"attendance.low_threshold": 80}}. A null value restores the env value. All values are validated before any is saved, and changes apply immediately.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateSettingsRequest true "Settings to change"
+// @Success 200 {object} map[string]interface{} "Settings updated"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/settings [put]
+func UpdateSettings(c *gin.Context) {
+	var req UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+	if len(req.Settings) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No settings given"})
+		return
+	}
+	if problems := validate(req.Settings); len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": problems})
+		return
+	}
+
+	adminIDVal, _ := c.Get("userID")
+	adminID := adminIDVal.(uint)
+
+	changed := make([]string, 0, len(req.Settings))
+	for key := range req.Settings {
+		changed = append(changed, key)
+	}
+	sort.Strings(changed)
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		details := make([]string, 0, len(changed))
+		for _, key := range changed {
+			raw := req.Settings[key]
+			if isNull(raw) {
+				if err := tx.Delete(&Setting{}, "key = ?", key).Error; err != nil {
+					return err
+				}
+				details = append(details, key+" reset")
+				continue
+			}
+
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, raw); err != nil {
+				return err
+			}
+			setting := Setting{Key: key, Value: compact.String(), UpdatedBy: adminID}
+			if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&setting).Error; err != nil {
+				return err
+			}
+			details = append(details, key+"="+setting.Value)
+		}
+		return audit.Record(tx, adminID, "settings_update", "setting", 0, strings.Join(details, "; "))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save settings"})
+		return
+	}
+
+	if err := Load(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Settings saved but could not be reloaded"})
+		return
+	}
+
+	views, err := settingViews()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get settings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Settings updated", "settings": views})
+}
+
+func settingViews() ([]SettingView, error) {
+	var stored []Setting
+	if err := db.DB.Find(&stored).Error; err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]Setting, len(stored))
+	for _, setting := range stored {
+		byKey[setting.Key] = setting
+	}
+
+	var term Term
+	if id := ActiveTermID(); id != 0 {
+		if err := db.DB.First(&term, id).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	current, env := core.GetConfig(), defaults()
+	views := make([]SettingView, 0, len(definitions))
+	for _, key := range keys() {
+		def := definitions[key]
+		view := SettingView{
+			Key:         key,
+			Description: def.Description,
+			Value:       def.get(current),
+			Default:     def.get(env),
+		}
+		if setting, ok := byKey[key]; ok {
+			view.Overridden = true
+			view.UpdatedBy = &setting.UpdatedBy
+			view.UpdatedAt = &setting.UpdatedAt
+		}
+		if _, ok := term.Settings[key]; ok {
+			view.TermID = &term.ID
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
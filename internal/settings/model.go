@@ -0,0 +1,11 @@
+package settings
+
+import "time"
+
+// Setting is an admin override of one campus-policy config value, stored as JSON
+type Setting struct {
+	Key       string    `json:"key" gorm:"primaryKey;size:100"`
+	Value     string    `json:"value" gorm:"not null"`
+	UpdatedBy uint      `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
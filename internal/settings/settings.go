@@ -0,0 +1,342 @@
+package settings
+
+import (
+	"campus-backend/internal/core"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// definition describes a config value admins may override at runtime
+type definition struct {
+	Description string
+	get         func(cfg *core.Config) interface{}
+	set         func(cfg *core.Config, raw json.RawMessage) error // Validates, then assigns
+}
+
+// definitions lists the overridable settings. Only campus policy belongs here: secrets,
+// connection details and values read once at startup (e.g. job intervals) stay env-only.
+var definitions = map[string]definition{
+	"auth.password_min_length": intSetting("Minimum password length", 6, 72,
+		func(cfg *core.Config) *int { return &cfg.Auth.PasswordMinLength }),
+	"auth.password_require_upper": boolSetting("Whether passwords need an uppercase letter",
+		func(cfg *core.Config) *bool { return &cfg.Auth.PasswordRequireUpper }),
+	"auth.password_require_lower": boolSetting("Whether passwords need a lowercase letter",
+		func(cfg *core.Config) *bool { return &cfg.Auth.PasswordRequireLower }),
+	"auth.password_require_digit": boolSetting("Whether passwords need a digit",
+		func(cfg *core.Config) *bool { return &cfg.Auth.PasswordRequireDigit }),
+	"auth.password_require_symbol": boolSetting("Whether passwords need a symbol",
+		func(cfg *core.Config) *bool { return &cfg.Auth.PasswordRequireSymbol }),
+	"attendance.low_threshold": intSetting("Attendance percentage below which a student is at risk", 0, 100,
+		func(cfg *core.Config) *int { return &cfg.Attendance.LowThreshold }),
+	"attendance.dept_thresholds": intMapSetting("Per-department attendance thresholds, e.g. {\"CS\": 80}", 0, 100,
+		func(cfg *core.Config) *map[string]int { return &cfg.Attendance.DeptThresholds }),
+	"campus.holidays": holidaysSetting("Campus holidays by date, e.g. {\"2026-10-02\": \"Gandhi Jayanti\"}",
+		func(cfg *core.Config) *map[string]string { return &cfg.Campus.Holidays }),
+	"leave.allowed_types": listSetting("Leave types students may apply for",
+		func(cfg *core.Config) *[]string { return &cfg.Leave.AllowedTypes }),
+	"leave.min_notice_days": intMapSetting("Days of advance notice per leave type, e.g. {\"personal\": 2}", 0, 365,
+		func(cfg *core.Config) *map[string]int { return &cfg.Leave.MinNoticeDays }),
+	"leave.overlap_allowed": listMapSetting("Existing leave types each leave type may overlap, e.g. {\"emergency\": [\"personal\"]}",
+		func(cfg *core.Config) *map[string][]string { return &cfg.Leave.OverlapAllowed }),
+	"leave.stale_action": choiceSetting("What to do with pending leaves past their start date", []string{"reject", "flag"},
+		func(cfg *core.Config) *string { return &cfg.Leave.StaleAction }),
+	"leave.stale_grace_days": intSetting("Days after the start date before a pending leave counts as stale", 0, 365,
+		func(cfg *core.Config) *int { return &cfg.Leave.StaleGraceDays }),
+}
+
+var (
+	mu sync.Mutex
+	// base is the env configuration that overrides are applied on top of
+	base *core.Config
+	// activeTermID is the term whose settings were applied by the last Load, 0 if none
+	activeTermID uint
+)
+
+// Load applies the stored overrides to the configuration. Call it at startup after migrating,
+// and again whenever the settings or terms change; it always starts from the env values.
+//
+// Settings are layered: env values, then /admin/settings overrides, then the settings of the
+// term covering today. Each term's holidays replace the others for dates inside it, so the
+// calendar of a past or future term follows that term. The latest term to have started also
+// sets Retention.ActiveTermStart, in place of RETENTION_ACTIVE_TERM_START.
+func Load() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if base == nil {
+		base = core.GetConfig()
+	}
+
+	var stored []Setting
+	if err := db.DB.Find(&stored).Error; err != nil {
+		return err
+	}
+	var terms []Term
+	if err := db.DB.Order("start_date ASC").Find(&terms).Error; err != nil {
+		return err
+	}
+
+	next := *base
+	for _, setting := range stored {
+		applySetting(&next, setting.Key, json.RawMessage(setting.Value))
+	}
+
+	today := timeutil.Today()
+	holidays := maps.Clone(next.Campus.Holidays)
+	if holidays == nil {
+		holidays = map[string]string{}
+	}
+	activeTermID = 0
+	for i := range terms {
+		term := &terms[i]
+		if raw, ok := term.Settings["campus.holidays"]; ok {
+			term.mergeHolidays(holidays, raw)
+		}
+		if term.StartDate.After(today) {
+			continue
+		}
+		next.Retention.ActiveTermStart = term.StartDate.In(timeutil.Location()).Format(timeutil.DateLayout)
+		if !term.EndDate.Before(today) {
+			activeTermID = term.ID
+			for key, raw := range term.Settings {
+				if key != "campus.holidays" {
+					applySetting(&next, key, raw)
+				}
+			}
+		}
+	}
+	next.Campus.Holidays = holidays
+
+	core.SetConfig(&next)
+	return nil
+}
+
+// ActiveTermID returns the term whose settings are in effect, 0 outside any term
+func ActiveTermID() uint {
+	mu.Lock()
+	defer mu.Unlock()
+	return activeTermID
+}
+
+// applySetting sets one stored value on cfg, logging and skipping it if it is unknown or invalid
+func applySetting(cfg *core.Config, key string, raw json.RawMessage) {
+	def, ok := definitions[key]
+	if !ok {
+		log.Printf("Ignoring unknown setting %q", key)
+		return
+	}
+	if err := def.set(cfg, raw); err != nil {
+		log.Printf("Ignoring invalid setting %q: %v", key, err)
+	}
+}
+
+// validate checks raw values for the given keys, returning an error message per bad key
+func validate(values map[string]json.RawMessage) map[string]string {
+	problems := make(map[string]string)
+	scratch := *core.GetConfig()
+	for key, raw := range values {
+		def, ok := definitions[key]
+		if !ok {
+			problems[key] = "unknown setting"
+			continue
+		}
+		if isNull(raw) {
+			continue // Resets to the env value
+		}
+		if err := def.set(&scratch, raw); err != nil {
+			problems[key] = err.Error()
+		}
+	}
+	return problems
+}
+
+// defaults returns the env value of every setting
+func defaults() *core.Config {
+	mu.Lock()
+	defer mu.Unlock()
+	if base == nil {
+		return core.GetConfig()
+	}
+	return base
+}
+
+func keys() []string {
+	list := make([]string, 0, len(definitions))
+	for key := range definitions {
+		list = append(list, key)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func isNull(raw json.RawMessage) bool {
+	return len(raw) == 0 || string(raw) == "null"
+}
+
+func intSetting(description string, min, max int, field func(*core.Config) *int) definition {
+	return definition{
+		Description: description,
+		get:         func(cfg *core.Config) interface{} { return *field(cfg) },
+		set: func(cfg *core.Config, raw json.RawMessage) error {
+			var value int
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return fmt.Errorf("must be an integer")
+			}
+			if err := checkRange(value, min, max); err != nil {
+				return err
+			}
+			*field(cfg) = value
+			return nil
+		},
+	}
+}
+
+func intMapSetting(description string, min, max int, field func(*core.Config) *map[string]int) definition {
+	return definition{
+		Description: description,
+		get:         func(cfg *core.Config) interface{} { return *field(cfg) },
+		set: func(cfg *core.Config, raw json.RawMessage) error {
+			var value map[string]int
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return fmt.Errorf("must be an object of integers")
+			}
+			for name, n := range value {
+				if strings.TrimSpace(name) == "" {
+					return fmt.Errorf("names must not be empty")
+				}
+				if err := checkRange(n, min, max); err != nil {
+					return fmt.Errorf("%s: %v", name, err)
+				}
+			}
+			if value == nil {
+				value = map[string]int{}
+			}
+			*field(cfg) = value
+			return nil
+		},
+	}
+}
+
+func boolSetting(description string, field func(*core.Config) *bool) definition {
+	return definition{
+		Description: description,
+		get:         func(cfg *core.Config) interface{} { return *field(cfg) },
+		set: func(cfg *core.Config, raw json.RawMessage) error {
+			var value bool
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return fmt.Errorf("must be true or false")
+			}
+			*field(cfg) = value
+			return nil
+		},
+	}
+}
+
+func holidaysSetting(description string, field func(*core.Config) *map[string]string) definition {
+	return definition{
+		Description: description,
+		get:         func(cfg *core.Config) interface{} { return *field(cfg) },
+		set: func(cfg *core.Config, raw json.RawMessage) error {
+			var value map[string]string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return fmt.Errorf("must be an object of holiday names")
+			}
+			for date, name := range value {
+				if _, err := time.Parse(timeutil.DateLayout, date); err != nil {
+					return fmt.Errorf("%s: dates must be YYYY-MM-DD", date)
+				}
+				if strings.TrimSpace(name) == "" {
+					return fmt.Errorf("%s: name must not be empty", date)
+				}
+			}
+			if value == nil {
+				value = map[string]string{}
+			}
+			*field(cfg) = value
+			return nil
+		},
+	}
+}
+
+func listSetting(description string, field func(*core.Config) *[]string) definition {
+	return definition{
+		Description: description,
+		get:         func(cfg *core.Config) interface{} { return *field(cfg) },
+		set: func(cfg *core.Config, raw json.RawMessage) error {
+			var value []string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return fmt.Errorf("must be a list of strings")
+			}
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				if item = strings.TrimSpace(item); item != "" && !slices.Contains(items, item) {
+					items = append(items, item)
+				}
+			}
+			if len(items) == 0 {
+				return fmt.Errorf("must not be empty")
+			}
+			*field(cfg) = items
+			return nil
+		},
+	}
+}
+
+func listMapSetting(description string, field func(*core.Config) *map[string][]string) definition {
+	return definition{
+		Description: description,
+		get:         func(cfg *core.Config) interface{} { return *field(cfg) },
+		set: func(cfg *core.Config, raw json.RawMessage) error {
+			var value map[string][]string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return fmt.Errorf("must be an object of string lists")
+			}
+			for name, items := range value {
+				if strings.TrimSpace(name) == "" {
+					return fmt.Errorf("names must not be empty")
+				}
+				for _, item := range items {
+					if strings.TrimSpace(item) == "" {
+						return fmt.Errorf("%s: items must not be empty", name)
+					}
+				}
+			}
+			if value == nil {
+				value = map[string][]string{}
+			}
+			*field(cfg) = value
+			return nil
+		},
+	}
+}
+
+func choiceSetting(description string, choices []string, field func(*core.Config) *string) definition {
+	return definition{
+		Description: description,
+		get:         func(cfg *core.Config) interface{} { return *field(cfg) },
+		set: func(cfg *core.Config, raw json.RawMessage) error {
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil || !slices.Contains(choices, value) {
+				return fmt.Errorf("must be one of: %s", strings.Join(choices, ", "))
+			}
+			*field(cfg) = value
+			return nil
+		},
+	}
+}
+
+func checkRange(value, min, max int) error {
+	if value < min || value > max {
+		return fmt.Errorf("must be between %d and %d", min, max)
+	}
+	return nil
+}
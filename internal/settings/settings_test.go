@@ -0,0 +1,102 @@
+package settings
+
+import (
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/pkg/db"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupTestDB(t *testing.T) {
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&Setting{}, &Term{}, &audit.AuditLog{})
+	db.DB = testDB
+}
+
+func TestUpdateSettings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	core.LoadConfig()
+	defer func() { core.AppConfig, base = nil, nil }()
+	base = nil
+	assert.NoError(t, Load())
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userID", uint(1)) })
+	router.GET("/admin/settings", GetSettings)
+	router.PUT("/admin/settings", UpdateSettings)
+	put := func(body string) (int, map[string]string) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/admin/settings", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		var resp struct {
+			Details map[string]string `json:"details"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Details
+	}
+
+	code, _ := put(`{"settings": {"attendance.low_threshold": 80, "leave.stale_action": "flag", "attendance.dept_thresholds": {"CS": 85}}}`)
+	assert.Equal(t, http.StatusOK, code)
+	cfg := core.GetConfig()
+	assert.Equal(t, 80, cfg.Attendance.LowThreshold)
+	assert.Equal(t, "flag", cfg.Leave.StaleAction)
+	assert.Equal(t, 85.0, cfg.Attendance.ThresholdFor("CS"))
+
+	// Nothing is saved when any value is invalid
+	code, details := put(`{"settings": {"attendance.low_threshold": 150, "leave.stale_grace_days": 2, "jwt.secret": "x", "leave.allowed_types": []}}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "must be between 0 and 100", details["attendance.low_threshold"])
+	assert.Equal(t, "unknown setting", details["jwt.secret"])
+	assert.Equal(t, "must not be empty", details["leave.allowed_types"])
+	assert.NotContains(t, details, "leave.stale_grace_days")
+	assert.Equal(t, 80, core.GetConfig().Attendance.LowThreshold)
+	assert.Equal(t, 0, core.GetConfig().Leave.StaleGraceDays)
+
+	// null restores the env value
+	code, _ = put(`{"settings": {"attendance.low_threshold": null}}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 75, core.GetConfig().Attendance.LowThreshold)
+
+	var audited int64
+	db.DB.Model(&audit.AuditLog{}).Where("action = ?", "settings_update").Count(&audited)
+	assert.Equal(t, int64(2), audited)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/settings", nil)
+	router.ServeHTTP(w, req)
+	var list struct {
+		Settings []SettingView `json:"settings"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	byKey := map[string]SettingView{}
+	for _, view := range list.Settings {
+		byKey[view.Key] = view
+	}
+	assert.True(t, byKey["leave.stale_action"].Overridden)
+	assert.Equal(t, "flag", byKey["leave.stale_action"].Value)
+	assert.Equal(t, "reject", byKey["leave.stale_action"].Default)
+	assert.False(t, byKey["attendance.low_threshold"].Overridden)
+
+	// Overrides survive a restart
+	core.LoadConfig()
+	base = nil
+	assert.NoError(t, Load())
+	assert.Equal(t, "flag", core.GetConfig().Leave.StaleAction)
+}
@@ -0,0 +1,313 @@
+package settings
+
+import (
+	"bytes"
+	"campus-backend/internal/audit"
+	"campus-backend/internal/core"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Term is an academic term with its own copy of the campus-policy settings, keyed like /admin/settings.
+// See Load for how they take effect.
+type Term struct {
+	ID         uint                       `json:"id" gorm:"primaryKey"`
+	Name       string                     `json:"name" gorm:"not null;size:100"`
+	StartDate  time.Time                  `json:"start_date" gorm:"not null;index"`
+	EndDate    time.Time                  `json:"end_date" gorm:"not null;index"`
+	Settings   map[string]json.RawMessage `json:"settings" gorm:"serializer:json"`
+	ClonedFrom *uint                      `json:"cloned_from,omitempty"`
+	CreatedBy  uint                       `json:"created_by"`
+	CreatedAt  time.Time                  `json:"created_at"`
+	UpdatedAt  time.Time                  `json:"updated_at"`
+}
+
+type TermRequest struct {
+	Name      string `json:"name" binding:"required"`
+	StartDate string `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate   string `json:"end_date" binding:"required"`   // YYYY-MM-DD
+}
+
+// errTermOverlap is returned when a new term's dates overlap an existing term
+var errTermOverlap = errors.New("term dates overlap an existing term")
+
+// ListTerms godoc
+// @Summary List academic terms
+// @Description Admin lists terms with their settings, latest first, and which term is active today
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Terms"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/terms [get]
+func ListTerms(c *gin.Context) {
+	var terms []Term
+	if err := db.DB.Order("start_date DESC").Find(&terms).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get terms"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"terms": terms, "active_term_id": ActiveTermID()})
+}
+
+// CreateTerm godoc
+// @Summary Create an academic term
+// @Description Admin creates a term holding a snapshot of the current campus-policy settings (holidays, attendance thresholds, leave rules). While today falls inside the term its settings take precedence over /admin/settings, and its holidays apply to its dates on the calendar. Terms may not overlap.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body TermRequest true "Term name and dates"
+// @Success 201 {object} map[string]interface{} "Term created"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 409 {object} map[string]interface{} "Dates overlap an existing term"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/terms [post]
+func CreateTerm(c *gin.Context) {
+	term, ok := bindTerm(c)
+	if !ok {
+		return
+	}
+
+	term.Settings = make(map[string]json.RawMessage, len(definitions))
+	current := core.GetConfig()
+	for _, key := range keys() {
+		raw, err := json.Marshal(definitions[key].get(current))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read settings"})
+			return
+		}
+		term.Settings[key] = raw
+	}
+
+	saveTerm(c, &term, "term_create", fmt.Sprintf("created %s", term.Name))
+}
+
+// CloneTerm godoc
+// @Summary Clone a term's settings into a new term
+// @Description Admin starts a new term from an existing one: its settings are copied and its holidays moved by the gap between the two start dates, ready to be adjusted through PUT /admin/terms/{id}/settings. Terms may not overlap.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Term to clone"
+// @Param request body TermRequest true "New term name and dates"
+// @Success 201 {object} map[string]interface{} "Term cloned"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Term not found"
+// @Failure 409 {object} map[string]interface{} "Dates overlap an existing term"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/terms/{id}/clone [post]
+func CloneTerm(c *gin.Context) {
+	var source Term
+	if err := db.DB.First(&source, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Term not found"})
+		return
+	}
+
+	term, ok := bindTerm(c)
+	if !ok {
+		return
+	}
+
+	offsetDays := int(math.Round(term.StartDate.Sub(source.StartDate).Hours() / 24))
+	term.Settings = make(map[string]json.RawMessage, len(source.Settings))
+	for key, raw := range source.Settings {
+		term.Settings[key] = raw
+	}
+	if raw, ok := term.Settings["campus.holidays"]; ok {
+		shifted, err := shiftHolidays(raw, offsetDays)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to copy holidays"})
+			return
+		}
+		term.Settings["campus.holidays"] = shifted
+	}
+	term.ClonedFrom = &source.ID
+
+	saveTerm(c, &term, "term_clone", fmt.Sprintf("cloned %s from term %d, holidays moved %d days", term.Name, source.ID, offsetDays))
+}
+
+// UpdateTermSettings godoc
+// @Summary Update a term's settings
+// @Description Admin adjusts settings stored on a term, using the same keys and validation as /admin/settings. A null value removes the setting from the term so the /admin/settings or env value applies. Changes to the active term apply immediately.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Term ID"
+// @Param request body UpdateSettingsRequest true "Settings to change"
+// @Success 200 {object} map[string]interface{} "Term updated"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Term not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/terms/{id}/settings [put]
+func UpdateTermSettings(c *gin.Context) {
+	var term Term
+	if err := db.DB.First(&term, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Term not found"})
+		return
+	}
+
+	var req UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+	if len(req.Settings) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No settings given"})
+		return
+	}
+	if problems := validate(req.Settings); len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": problems})
+		return
+	}
+
+	if term.Settings == nil {
+		term.Settings = make(map[string]json.RawMessage)
+	}
+	for key, raw := range req.Settings {
+		if isNull(raw) {
+			delete(term.Settings, key)
+			continue
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		term.Settings[key] = compact.Bytes()
+	}
+
+	adminIDVal, _ := c.Get("userID")
+	adminID := adminIDVal.(uint)
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&term).Select("settings").Updates(&term).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, adminID, "term_settings_update", "term", term.ID, fmt.Sprintf("%d settings changed", len(req.Settings)))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save term"})
+		return
+	}
+	if err := Load(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Term saved but settings could not be reloaded"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Term updated", "term": term})
+}
+
+// bindTerm reads a term's name and dates from the request body, writing a 400 if they are invalid
+func bindTerm(c *gin.Context) (Term, bool) {
+	var req TermRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return Term{}, false
+	}
+	start, err := timeutil.ParseDate(req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format, expected YYYY-MM-DD"})
+		return Term{}, false
+	}
+	end, err := timeutil.ParseDate(req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format, expected YYYY-MM-DD"})
+		return Term{}, false
+	}
+	if end.Before(start) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must not be before start_date"})
+		return Term{}, false
+	}
+	return Term{Name: req.Name, StartDate: start, EndDate: end}, true
+}
+
+// saveTerm stores a new term unless it overlaps another, and writes the response
+func saveTerm(c *gin.Context, term *Term, action, details string) {
+	adminIDVal, _ := c.Get("userID")
+	term.CreatedBy = adminIDVal.(uint)
+
+	var conflict Term
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("start_date <= ? AND end_date >= ?", term.EndDate, term.StartDate).First(&conflict).Error
+		if err == nil {
+			return errTermOverlap
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err := tx.Create(term).Error; err != nil {
+			return err
+		}
+		return audit.Record(tx, term.CreatedBy, action, "term", term.ID, details)
+	})
+	if errors.Is(err, errTermOverlap) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Term dates overlap " + conflict.Name, "conflicting_term": conflict.ID})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save term"})
+		return
+	}
+	if err := Load(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Term saved but settings could not be reloaded"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Term saved", "term": term})
+}
+
+// mergeHolidays replaces the holidays falling inside the term with the term's own campus.holidays.
+// Term holidays outside its dates are ignored.
+func (t *Term) mergeHolidays(holidays map[string]string, raw json.RawMessage) {
+	var own map[string]string
+	if err := json.Unmarshal(raw, &own); err != nil {
+		log.Printf("Ignoring invalid holidays of term %d: %v", t.ID, err)
+		return
+	}
+	start := t.StartDate.In(timeutil.Location()).Format(timeutil.DateLayout)
+	end := t.EndDate.In(timeutil.Location()).Format(timeutil.DateLayout)
+	for date := range holidays {
+		if date >= start && date <= end {
+			delete(holidays, date)
+		}
+	}
+	for date, name := range own {
+		if date >= start && date <= end {
+			holidays[date] = name
+		}
+	}
+}
+
+// shiftHolidays moves every date of a campus.holidays value by the given number of days
+func shiftHolidays(raw json.RawMessage, days int) (json.RawMessage, error) {
+	var holidays map[string]string
+	if err := json.Unmarshal(raw, &holidays); err != nil {
+		return nil, err
+	}
+	shifted := make(map[string]string, len(holidays))
+	for date, name := range holidays {
+		day, err := time.Parse(timeutil.DateLayout, date)
+		if err != nil {
+			return nil, err
+		}
+		shifted[day.AddDate(0, 0, days).Format(timeutil.DateLayout)] = name
+	}
+	return json.Marshal(shifted)
+}
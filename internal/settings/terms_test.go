@@ -0,0 +1,141 @@
+package settings
+
+import (
+	"campus-backend/internal/core"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/timeutil"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloneTerm(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	db.DB.AutoMigrate(&Term{})
+	core.LoadConfig()
+	defer func() { core.AppConfig, base, activeTermID = nil, nil, 0 }()
+	core.AppConfig.Campus.Holidays = map[string]string{"2026-08-15": "Independence Day", "2026-10-02": "Gandhi Jayanti"}
+	core.AppConfig.Attendance.DeptThresholds = map[string]int{"CS": 80}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userID", uint(1)) })
+	router.POST("/admin/terms", CreateTerm)
+	router.POST("/admin/terms/:id/clone", CloneTerm)
+	router.PUT("/admin/terms/:id/settings", UpdateTermSettings)
+	send := func(method, path, body string) (int, Term) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		var resp struct {
+			Term Term `json:"term"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Term
+	}
+
+	code, monsoon := send("POST", "/admin/terms", `{"name": "Monsoon 2026", "start_date": "2026-07-20", "end_date": "2026-11-30"}`)
+	assert.Equal(t, http.StatusCreated, code)
+	assert.JSONEq(t, `{"CS": 80}`, string(monsoon.Settings["attendance.dept_thresholds"]))
+
+	// Overlapping dates and reversed ranges are refused
+	code, _ = send("POST", "/admin/terms/1/clone", `{"name": "Overlap", "start_date": "2026-11-01", "end_date": "2027-03-31"}`)
+	assert.Equal(t, http.StatusConflict, code)
+	code, _ = send("POST", "/admin/terms/1/clone", `{"name": "Backwards", "start_date": "2027-07-19", "end_date": "2027-07-01"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = send("POST", "/admin/terms/99/clone", `{"name": "Missing", "start_date": "2027-07-19", "end_date": "2027-11-29"}`)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// A year later, moved by 364 days so the term still starts on a Monday
+	code, next := send("POST", "/admin/terms/1/clone", `{"name": "Monsoon 2027", "start_date": "2027-07-19", "end_date": "2027-11-29"}`)
+	assert.Equal(t, http.StatusCreated, code)
+	if assert.NotNil(t, next.ClonedFrom) {
+		assert.Equal(t, monsoon.ID, *next.ClonedFrom)
+	}
+	assert.JSONEq(t, `{"2027-08-14": "Independence Day", "2027-10-01": "Gandhi Jayanti"}`, string(next.Settings["campus.holidays"]))
+	assert.JSONEq(t, `{"CS": 80}`, string(next.Settings["attendance.dept_thresholds"]))
+
+	code, next = send("PUT", "/admin/terms/2/settings", `{"settings": {"campus.holidays": {"2027-08-15": "Independence Day"}, "attendance.low_threshold": null}}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"2027-08-15": "Independence Day"}`, string(next.Settings["campus.holidays"]))
+	assert.NotContains(t, next.Settings, "attendance.low_threshold")
+	code, _ = send("PUT", "/admin/terms/2/settings", `{"settings": {"attendance.low_threshold": 150}}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// Editing the 2027 term leaves this year's holidays alone
+	assert.Equal(t, "Gandhi Jayanti", core.GetConfig().Campus.Holidays["2026-10-02"])
+}
+
+func TestActiveTermSettings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setupTestDB(t)
+	core.LoadConfig()
+	defer func() { core.AppConfig, base, activeTermID = nil, nil, 0 }()
+	base = nil
+
+	today := timeutil.Today()
+	date := func(days int) string { return today.AddDate(0, 0, days).Format(timeutil.DateLayout) }
+	holidays := func(days ...int) json.RawMessage {
+		named := map[string]string{}
+		for _, d := range days {
+			named[date(d)] = "Holiday " + date(d)
+		}
+		raw, _ := json.Marshal(named)
+		return raw
+	}
+
+	globalHolidays, _ := json.Marshal(map[string]string{date(-300): "Before terms", date(5): "Global in term"})
+	db.DB.Create(&Setting{Key: "campus.holidays", Value: string(globalHolidays)})
+	db.DB.Create(&Setting{Key: "attendance.low_threshold", Value: "70"})
+
+	past := Term{Name: "Past", StartDate: today.AddDate(0, 0, -200), EndDate: today.AddDate(0, 0, -100),
+		Settings: map[string]json.RawMessage{"campus.holidays": holidays(-150), "attendance.low_threshold": json.RawMessage("60")}}
+	active := Term{Name: "Active", StartDate: today.AddDate(0, 0, -10), EndDate: today.AddDate(0, 0, 30),
+		Settings: map[string]json.RawMessage{"campus.holidays": holidays(3, 90), "attendance.low_threshold": json.RawMessage("85")}}
+	future := Term{Name: "Future", StartDate: today.AddDate(0, 0, 60), EndDate: today.AddDate(0, 0, 150),
+		Settings: map[string]json.RawMessage{"campus.holidays": holidays(80), "attendance.low_threshold": json.RawMessage("50")}}
+	for _, term := range []*Term{&past, &active, &future} {
+		assert.NoError(t, db.DB.Create(term).Error)
+	}
+
+	assert.NoError(t, Load())
+	cfg := core.GetConfig()
+	assert.Equal(t, active.ID, ActiveTermID())
+	assert.Equal(t, 85, cfg.Attendance.LowThreshold, "the active term wins over /admin/settings")
+	assert.Equal(t, date(-10), cfg.Retention.ActiveTermStart)
+
+	// Each term's holidays govern its own dates; holidays a term lists outside them are dropped
+	assert.Equal(t, map[string]string{
+		date(-300): "Before terms",
+		date(-150): "Holiday " + date(-150),
+		date(3):    "Holiday " + date(3),
+		date(80):   "Holiday " + date(80),
+	}, cfg.Campus.Holidays)
+
+	// Editing the active term applies at once
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("userID", uint(1)) })
+	router.PUT("/admin/terms/:id/settings", UpdateTermSettings)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", fmt.Sprintf("/admin/terms/%d/settings", active.ID), strings.NewReader(`{"settings": {"attendance.low_threshold": null}}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 70, core.GetConfig().Attendance.LowThreshold, "without a term value the override applies")
+
+	// Between terms the override applies and retention protects the latest term to have started
+	db.DB.Delete(&active)
+	assert.NoError(t, Load())
+	cfg = core.GetConfig()
+	assert.Zero(t, ActiveTermID())
+	assert.Equal(t, 70, cfg.Attendance.LowThreshold)
+	assert.Equal(t, date(-200), cfg.Retention.ActiveTermStart)
+	assert.Equal(t, "Global in term", cfg.Campus.Holidays[date(5)])
+}
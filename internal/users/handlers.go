@@ -1,8 +1,13 @@
 package users
 
 import (
+	"campus-backend/internal/core"
 	"campus-backend/pkg/db"
+	"campus-backend/pkg/i18n"
+	"campus-backend/pkg/validation"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,11 +17,13 @@ import (
 // @Description Get list of all users (Admin only)
 // @Tags Users
 // @Accept json
-// @Produce json
+// @Produce json,text/csv
 // @Security BearerAuth
 // @Param role query string false "Filter by role"
+// @Param include_deleted query bool false "Include soft-deleted users, flagged with deleted: true"
 // @Param page query int false "Page number" default(1)
-// @Param limit query int false "Items per page" default(10)
+// @Param limit query int false "Items per page (clamped to the configured maximum)" default(10)
+// @Param format query string false "Response format (json or csv); Accept: text/csv also works"
 // @Success 200 {object} map[string]interface{} "List of users"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 403 {object} map[string]interface{} "Forbidden"
@@ -24,21 +31,32 @@ import (
 // @Router /users/ [get]
 func ListUsers(c *gin.Context) {
 	var users []User
+	var total int64
 	var err error
 
 	// Get query parameters for filtering
 	role := c.Query("role")
-	page := c.DefaultQuery("page", "1")
-	limit := c.DefaultQuery("limit", "10")
+	page, limit := core.PaginationParams(c)
 
-	// Build query
-	query := db.DB
+	// Build query; API-key service accounts are not listed
+	query := db.DB.Model(&User{}).Where("role <> ?", RoleService)
+	if c.Query("include_deleted") == "true" {
+		// The route is admin-only, but the check stays here so no other route can expose deleted users
+		if callerRole, _ := c.Get("role"); callerRole != RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can include deleted users"})
+			return
+		}
+		query = query.Unscoped()
+	}
 	if role != "" {
 		query = query.Where("role = ?", role)
 	}
 
 	// Execute query
-	err = query.Find(&users).Error
+	err = query.Count(&total).Error
+	if err == nil {
+		err = query.Order("id ASC").Offset((page - 1) * limit).Limit(limit).Find(&users).Error
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get users"})
 		return
@@ -47,26 +65,25 @@ func ListUsers(c *gin.Context) {
 	// Remove passwords from response
 	for i := range users {
 		users[i].Password = ""
+		users[i].Deleted = users[i].DeletedAt.Valid
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"users": users,
-		"pagination": gin.H{
-			"page":  page,
-			"limit": limit,
-			"total": len(users),
-		},
-	})
+	core.Render(c, gin.H{
+		"users":      users,
+		"pagination": core.CalculatePagination(page, limit, total),
+	}, "users")
 }
 
 // MeHandler godoc
 // @Summary Get current user profile
-// @Description Get the profile of the currently authenticated user
+// @Description Get the profile of the currently authenticated user. Responses carry an ETag; send it back in If-None-Match to get 304 when the profile is unchanged.
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} map[string]interface{} "User profile"
+// @Success 304 "Profile unchanged"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
 // @Router /users/me [get]
@@ -84,5 +101,138 @@ func MeHandler(c *gin.Context) {
 		return
 	}
 	user.Password = ""
-	c.JSON(http.StatusOK, user)
+	core.JSONWithETag(c, user)
+}
+
+type NotificationPreferencesRequest struct {
+	Channels []string `json:"channels" binding:"required" validate:"max=3,dive,oneof=email sms in_app"` // Empty turns all notifications off
+	Language *string  `json:"language,omitempty"`                                                       // "" follows Accept-Language again
+}
+
+// UpdateNotificationPreferences godoc
+// @Summary Update notification preferences
+// @Description Choose the channels (email, sms, in_app) the current user is notified on, and optionally the language (en, hi) for notifications and messages. SMS is only sent when the profile has a phone number.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body NotificationPreferencesRequest true "Channels"
+// @Success 200 {object} map[string]interface{} "Preferences updated"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/me/notification-preferences [put]
+func UpdateNotificationPreferences(c *gin.Context) {
+	var req NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+
+	if err := validation.ValidateStruct(req); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+	if req.Language != nil && *req.Language != "" && !i18n.Supported(*req.Language) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported language", "supported": i18n.Languages()})
+		return
+	}
+
+	userIDVal, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not in context"})
+		return
+	}
+
+	var user User
+	if err := db.DB.First(&user, userIDVal.(uint)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	// Drop duplicates, keeping the order given
+	channels := make([]string, 0, len(req.Channels))
+	for _, channel := range req.Channels {
+		if !slices.Contains(channels, channel) {
+			channels = append(channels, channel)
+		}
+	}
+
+	updates := map[string]interface{}{"notification_channels": strings.Join(channels, ",")}
+	if req.Language != nil {
+		updates["language"] = *req.Language
+		user.Language = *req.Language
+	}
+	if err := db.DB.Model(&user).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+		return
+	}
+
+	response := gin.H{
+		"message":  "Notification preferences updated",
+		"channels": channels,
+		"language": user.PreferredLanguage(),
+	}
+	if slices.Contains(channels, ChannelSMS) && (user.Phone == nil || *user.Phone == "") {
+		response["warning"] = "SMS is enabled but your profile has no phone number, so no SMS will be sent"
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type EmergencyContactRequest struct {
+	Name  *string `json:"name" validate:"omitempty,min=2,max=100"`
+	Phone *string `json:"phone" validate:"required_with=Name,omitempty,phone"`
+}
+
+// UpdateEmergencyContact godoc
+// @Summary Update emergency contact
+// @Description Set or clear the current user's next-of-kin contact, which wardens see while the student is on leave. A name needs a phone number; send both empty to clear.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body EmergencyContactRequest true "Emergency contact"
+// @Success 200 {object} map[string]interface{} "Emergency contact updated"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /users/me/emergency-contact [put]
+func UpdateEmergencyContact(c *gin.Context) {
+	var req EmergencyContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+
+	// Blank values clear the field
+	req.Name = validation.TrimOptional(req.Name)
+	req.Phone = validation.TrimOptional(req.Phone)
+
+	if err := validation.ValidateStruct(req); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+
+	userIDVal, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not in context"})
+		return
+	}
+
+	updates := map[string]interface{}{
+		"emergency_contact_name":  req.Name,
+		"emergency_contact_phone": req.Phone,
+	}
+	if err := db.DB.Model(&User{}).Where("id = ?", userIDVal.(uint)).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update emergency contact"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":                 "Emergency contact updated",
+		"emergency_contact_name":  req.Name,
+		"emergency_contact_phone": req.Phone,
+	})
 }
@@ -0,0 +1,99 @@
+package users
+
+import (
+	"campus-backend/pkg/db"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestListUsersIncludeDeleted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testDB.AutoMigrate(&User{})
+	db.DB = testDB
+
+	kept := User{Name: "Kept", Email: "kept@example.com", Password: "hashed", Role: RoleStudent, Dept: "CS", IsActive: true}
+	removed := User{Name: "Removed", Email: "removed@example.com", Password: "hashed", Role: RoleStudent, Dept: "CS", IsActive: true}
+	db.DB.Create(&kept)
+	db.DB.Create(&removed)
+	db.DB.Delete(&removed)
+	db.DB.Create(&User{Name: "API key", Email: "service@example.com", Password: "hashed", Role: RoleService, Dept: "Service", IsActive: true}) // Never listed
+
+	list := func(role, query string) (int, map[uint]bool) {
+		r := gin.New()
+		r.GET("/users/", func(c *gin.Context) { c.Set("role", role) }, ListUsers)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/"+query, nil))
+		var resp struct {
+			Users []User `json:"users"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		deleted := map[uint]bool{}
+		for _, user := range resp.Users {
+			deleted[user.ID] = user.Deleted
+		}
+		return rec.Code, deleted
+	}
+
+	code, listed := list(RoleAdmin, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[uint]bool{kept.ID: false}, listed)
+
+	code, listed = list(RoleAdmin, "?include_deleted=true")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[uint]bool{kept.ID: false, removed.ID: true}, listed)
+
+	code, _ = list(RoleFaculty, "?include_deleted=true")
+	assert.Equal(t, http.StatusForbidden, code)
+}
+
+func TestListDepartmentsAndHostels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testDB.AutoMigrate(&User{})
+	db.DB = testDB
+
+	h1, h2, blank := "H1", "H2", ""
+	for _, user := range []User{
+		{Name: "A", Email: "a@example.com", Password: "hashed", Role: RoleStudent, Dept: "EE", Hostel: &h2, IsActive: true},
+		{Name: "B", Email: "b@example.com", Password: "hashed", Role: RoleStudent, Dept: "CS", Hostel: &h1, IsActive: true},
+		{Name: "C", Email: "c@example.com", Password: "hashed", Role: RoleStudent, Dept: "CS", Hostel: &h1, IsActive: true},
+		{Name: "D", Email: "d@example.com", Password: "hashed", Role: RoleFaculty, Dept: "ME", Hostel: &blank, IsActive: true},
+		{Name: "Key", Email: "key@example.com", Password: "hashed", Role: RoleService, Dept: "SERVICE", IsActive: true},
+	} {
+		db.DB.Create(&user)
+	}
+	gone := User{Name: "Gone", Email: "gone@example.com", Password: "hashed", Role: RoleStudent, Dept: "CVS", IsActive: true}
+	db.DB.Create(&gone)
+	db.DB.Model(&gone).Update("is_active", false)
+
+	r := gin.New()
+	r.GET("/meta/departments", ListDepartments)
+	r.GET("/meta/hostels", ListHostels)
+	list := func(path string) map[string][]string {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("ETag"))
+		var resp map[string][]string
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return resp
+	}
+
+	assert.Equal(t, []string{"CS", "EE", "ME"}, list("/meta/departments")["departments"])
+	assert.Equal(t, []string{"H1", "H2"}, list("/meta/hostels")["hostels"])
+}
@@ -0,0 +1,58 @@
+package users
+
+import (
+	"campus-backend/internal/core"
+	"campus-backend/pkg/db"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// distinctValues returns the sorted, non-empty values of a users column among active accounts.
+// Service accounts are left out since their department is a placeholder.
+func distinctValues(column string) ([]string, error) {
+	values := []string{}
+	err := db.DB.Model(&User{}).
+		Where("is_active = ? AND role <> ?", true, RoleService).
+		Where(column+" IS NOT NULL AND "+column+" <> ''").
+		Distinct(column).Order(column).Pluck(column, &values).Error
+	return values, err
+}
+
+// ListDepartments godoc
+// @Summary List departments
+// @Description Returns the distinct departments of active users, for registration forms and filters. Responses carry an ETag.
+// @Tags Meta
+// @Produce json
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Success 200 {object} map[string]interface{} "Departments"
+// @Success 304 "Departments unchanged"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /meta/departments [get]
+func ListDepartments(c *gin.Context) {
+	departments, err := distinctValues("dept")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get departments"})
+		return
+	}
+	core.JSONWithETag(c, gin.H{"departments": departments})
+}
+
+// ListHostels godoc
+// @Summary List hostels
+// @Description Returns the distinct hostels of active users, for registration forms and filters. Responses carry an ETag.
+// @Tags Meta
+// @Produce json
+// @Param If-None-Match header string false "ETag from a previous response"
+// @Success 200 {object} map[string]interface{} "Hostels"
+// @Success 304 "Hostels unchanged"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /meta/hostels [get]
+func ListHostels(c *gin.Context) {
+	hostels, err := distinctValues("hostel")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get hostels"})
+		return
+	}
+	core.JSONWithETag(c, gin.H{"hostels": hostels})
+}
@@ -1,6 +1,8 @@
 package users
 
 import (
+	"campus-backend/pkg/i18n"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -19,22 +21,57 @@ type User struct {
 	StudentID *string    `json:"student_id,omitempty" gorm:"uniqueIndex"`
 	IsActive  bool       `json:"is_active" gorm:"default:true"`
 	LastLogin *time.Time `json:"last_login,omitempty"`
+	Deleted   bool       `json:"deleted,omitempty" gorm:"-"` // Set on soft-deleted rows listed with include_deleted
+
+	// Next of kin, shown to wardens handling the student's leaves
+	EmergencyContactName  *string `json:"emergency_contact_name,omitempty"`
+	EmergencyContactPhone *string `json:"emergency_contact_phone,omitempty"`
+
+	// Comma-separated channels the user wants notifications on
+	NotificationChannels string `json:"notification_channels" gorm:"not null;default:'email,in_app'"`
+	// Language for validation messages and notifications; empty follows Accept-Language
+	Language string `json:"language,omitempty"`
 
 	// Relationships - these connect to other tables
 	LeaveRequests []LeaveRequest `json:"leave_requests,omitempty" gorm:"foreignKey:StudentID"`
 	Attendance    []Attendance   `json:"attendance,omitempty" gorm:"foreignKey:StudentID"`
 }
 
+// Notification channels
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelInApp = "in_app"
+)
+
+// WantsChannel reports whether the user has opted in to notifications on the channel
+func (u *User) WantsChannel(channel string) bool {
+	for _, c := range strings.Split(u.NotificationChannels, ",") {
+		if strings.TrimSpace(c) == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// PreferredLanguage returns the language to notify the user in
+func (u *User) PreferredLanguage() string {
+	if i18n.Supported(u.Language) {
+		return u.Language
+	}
+	return i18n.DefaultLanguage
+}
+
 // LeaveRequest struct - represents a leave request
 type LeaveRequest struct {
 	gorm.Model
 	StudentID  uint      `json:"student_id" gorm:"not null;index"`
-	Student    User      `json:"student,omitempty" gorm:"foreignKey:StudentID"`
-	LeaveType  string    `json:"leave_type" gorm:"not null" validate:"required,oneof=medical personal emergency academic"`
+	Student    User      `json:"student,omitempty" gorm:"foreignKey:StudentID;belongsTo"` // Not has-one via User.StudentID
+	LeaveType  string    `json:"leave_type" gorm:"not null" validate:"required"`
 	Reason     string    `json:"reason" gorm:"not null" validate:"required,min=10,max=500"`
 	StartDate  time.Time `json:"start_date" gorm:"not null" validate:"required"`
 	EndDate    time.Time `json:"end_date" gorm:"not null" validate:"required"`
-	Status     string    `json:"status" gorm:"not null;default:pending" validate:"oneof=pending approved rejected"`
+	Status     string    `json:"status" gorm:"not null;default:pending" validate:"oneof=pending needs_info approved rejected"`
 	ApprovedBy *uint     `json:"approved_by,omitempty" gorm:"index"`
 	Approver   *User     `json:"approver,omitempty" gorm:"foreignKey:ApprovedBy"`
 	Remarks    *string   `json:"remarks,omitempty" validate:"max=200"`
@@ -49,7 +86,7 @@ type LeaveRequest struct {
 type Attendance struct {
 	gorm.Model
 	StudentID uint      `json:"student_id" gorm:"not null;index"`
-	Student   User      `json:"student,omitempty" gorm:"foreignKey:StudentID"`
+	Student   User      `json:"student,omitempty" gorm:"foreignKey:StudentID;belongsTo"`
 	Date      time.Time `json:"date" gorm:"not null;index"`
 	Present   bool      `json:"present" gorm:"not null"`
 	MarkedBy  uint      `json:"marked_by" gorm:"not null"`
@@ -5,4 +5,5 @@ const (
 	RoleStudent = "student"
 	RoleWarden  = "warden"
 	RoleFaculty = "faculty"
+	RoleService = "service" // Synthetic role for API key callers
 )
@@ -0,0 +1,225 @@
+package webhooks
+
+import (
+	"campus-backend/internal/core"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/validation"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required" validate:"required,url,max=500"`
+	Events []string `json:"events" binding:"required" validate:"required,min=1,dive,oneof=leave.approved leave.rejected"`
+	Secret string   `json:"secret,omitempty" validate:"omitempty,min=16,max=100"` // Generated when omitted
+}
+
+type UpdateWebhookRequest struct {
+	URL      *string  `json:"url,omitempty" validate:"omitempty,url,max=500"`
+	Events   []string `json:"events,omitempty" validate:"omitempty,min=1,dive,oneof=leave.approved leave.rejected"`
+	IsActive *bool    `json:"is_active,omitempty"`
+}
+
+// CreateWebhook godoc
+// @Summary Create a webhook
+// @Description Admin subscribes a URL to leave events. Deliveries are signed with the secret, which is only returned once.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateWebhookRequest true "Webhook URL, events and optional secret"
+// @Success 201 {object} map[string]interface{} "Webhook created"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/webhooks [post]
+func CreateWebhook(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+
+	if err := validation.ValidateStruct(req); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+
+	adminIDVal, _ := c.Get("userID")
+	adminID := adminIDVal.(uint)
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := GenerateSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate webhook secret"})
+			return
+		}
+		secret = generated
+	}
+
+	hook := Webhook{
+		URL:       req.URL,
+		Secret:    secret,
+		Events:    strings.Join(req.Events, ","),
+		IsActive:  true,
+		CreatedBy: adminID,
+	}
+	if err := db.DB.Create(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Webhook created. Store the secret now, it will not be shown again.",
+		"secret":  secret,
+		"webhook": hook,
+	})
+}
+
+// ListWebhooks godoc
+// @Summary List webhooks
+// @Description Admin lists webhook subscriptions (without secrets)
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "List of webhooks"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/webhooks [get]
+func ListWebhooks(c *gin.Context) {
+	var hooks []Webhook
+	if err := db.DB.Order("created_at DESC").Find(&hooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": hooks})
+}
+
+// UpdateWebhook godoc
+// @Summary Update a webhook
+// @Description Admin changes a webhook's URL or events, or pauses it with is_active
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param request body UpdateWebhookRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{} "Webhook updated"
+// @Failure 400 {object} map[string]interface{} "Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Webhook not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/webhooks/{id} [put]
+func UpdateWebhook(c *gin.Context) {
+	var req UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": core.PublicError(err, "Invalid request body")})
+		return
+	}
+
+	if err := validation.ValidateStruct(req); err != nil {
+		errors := validation.FormatValidationErrorsIn(core.Language(c), err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "details": errors})
+		return
+	}
+
+	var hook Webhook
+	if err := db.DB.First(&hook, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	if req.URL != nil {
+		hook.URL = *req.URL
+	}
+	if len(req.Events) > 0 {
+		hook.Events = strings.Join(req.Events, ",")
+	}
+	if req.IsActive != nil {
+		hook.IsActive = *req.IsActive
+	}
+	if err := db.DB.Save(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook updated", "webhook": hook})
+}
+
+// DeleteWebhook godoc
+// @Summary Delete a webhook
+// @Description Admin removes a webhook subscription; queued retries for it still finish
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} map[string]interface{} "Webhook deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Webhook not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/webhooks/{id} [delete]
+func DeleteWebhook(c *gin.Context) {
+	var hook Webhook
+	if err := db.DB.First(&hook, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	if err := db.DB.Delete(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+// ListWebhookDeliveries godoc
+// @Summary List webhook delivery attempts
+// @Description Admin reviews a webhook's delivery attempts, newest first
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page (clamped to the configured maximum)" default(10)
+// @Success 200 {object} map[string]interface{} "Delivery attempts"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Webhook not found"
+// @Failure 500 {object} map[string]interface{} "Internal server error"
+// @Router /admin/webhooks/{id}/deliveries [get]
+func ListWebhookDeliveries(c *gin.Context) {
+	var hook Webhook
+	if err := db.DB.Unscoped().First(&hook, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	page, limit := core.PaginationParams(c)
+	query := db.DB.Model(&WebhookDelivery{}).Where("webhook_id = ?", hook.ID)
+
+	var total int64
+	var deliveries []WebhookDelivery
+	err := query.Count(&total).Error
+	if err == nil {
+		err = query.Order("created_at DESC, id DESC").Offset((page - 1) * limit).Limit(limit).Find(&deliveries).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+		"pagination": core.CalculatePagination(page, limit, total),
+	})
+}
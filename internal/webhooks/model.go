@@ -0,0 +1,48 @@
+package webhooks
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook events
+const (
+	EventLeaveApproved = "leave.approved"
+	EventLeaveRejected = "leave.rejected"
+)
+
+// Webhook is an outgoing subscription; payloads are signed with its secret
+type Webhook struct {
+	gorm.Model
+	URL       string `json:"url" gorm:"not null"`
+	Secret    string `json:"-" gorm:"not null"`      // HMAC key, shown only when the webhook is created
+	Events    string `json:"events" gorm:"not null"` // Comma-separated list of events
+	IsActive  bool   `json:"is_active" gorm:"default:true"`
+	CreatedBy uint   `json:"created_by" gorm:"not null"` // Admin who created the webhook
+}
+
+// Subscribes reports whether the webhook wants the given event
+func (w *Webhook) Subscribes(event string) bool {
+	for _, e := range strings.Split(w.Events, ",") {
+		if strings.TrimSpace(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery records one attempt to deliver an event; retries share a DeliveryID
+type WebhookDelivery struct {
+	gorm.Model
+	WebhookID  uint      `json:"webhook_id" gorm:"not null;index"`
+	DeliveryID string    `json:"delivery_id" gorm:"not null;index"`
+	Event      string    `json:"event" gorm:"not null"`
+	Attempt    int       `json:"attempt" gorm:"not null"`
+	StatusCode int       `json:"status_code"` // Zero when no response was received
+	Error      *string   `json:"error,omitempty"`
+	Success    bool      `json:"success"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,142 @@
+package webhooks
+
+import (
+	"bytes"
+	"campus-backend/pkg/db"
+	"campus-backend/pkg/workqueue"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Delivery tuning; variables so tests can shorten them
+var (
+	maxAttempts    = 4
+	retryBaseDelay = 2 * time.Second // Doubled after every failed attempt
+	httpClient     = &http.Client{Timeout: 10 * time.Second}
+
+	// A few workers share all deliveries, so a burst of events or a slow subscriber cannot
+	// start an unbounded number of goroutines
+	deliveries = workqueue.New("webhooks", 4, 1000)
+)
+
+// Payload is the JSON body POSTed to subscribers
+type Payload struct {
+	DeliveryID string      `json:"delivery_id"`
+	Event      string      `json:"event"`
+	CreatedAt  time.Time   `json:"created_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Sign returns the X-Signature header value for a body: hex HMAC-SHA256 keyed with the webhook secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatch queues the event for every active subscriber; the delivery workers send it in the background.
+// Failures are logged and recorded as deliveries, and a full queue is logged; they never fail the caller.
+func Dispatch(event string, data interface{}) {
+	var hooks []Webhook
+	if err := db.DB.Where("is_active = ?", true).Find(&hooks).Error; err != nil {
+		log.Printf("Failed to load webhooks for %s: %v", event, err)
+		return
+	}
+
+	for i := range hooks {
+		if !hooks[i].Subscribes(event) {
+			continue
+		}
+		payload := Payload{DeliveryID: newDeliveryID(), Event: event, CreatedAt: time.Now(), Data: data}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			log.Printf("Failed to encode webhook payload for %s: %v", event, err)
+			continue
+		}
+		hook := hooks[i]
+		if !deliveries.Submit(func() { deliver(hook, payload, body) }) {
+			log.Printf("Dropped webhook %d delivery %s for %s: delivery queue is full", hook.ID, payload.DeliveryID, event)
+		}
+	}
+}
+
+// deliver POSTs the body until the subscriber answers 2xx or attempts run out, recording each attempt
+func deliver(hook Webhook, payload Payload, body []byte) bool {
+	delay := retryBaseDelay
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		record := WebhookDelivery{WebhookID: hook.ID, DeliveryID: payload.DeliveryID, Event: payload.Event, Attempt: attempt}
+
+		start := time.Now()
+		statusCode, err := post(hook, payload, body)
+		record.DurationMs = time.Since(start).Milliseconds()
+		record.StatusCode = statusCode
+		if err != nil {
+			msg := err.Error()
+			record.Error = &msg
+		} else {
+			record.Success = true
+		}
+
+		if dbErr := db.DB.Create(&record).Error; dbErr != nil {
+			log.Printf("Failed to record webhook delivery %s: %v", payload.DeliveryID, dbErr)
+		}
+		if record.Success {
+			return true
+		}
+
+		if attempt < maxAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+
+	log.Printf("Webhook %d gave up on %s delivery %s after %d attempts", hook.ID, payload.Event, payload.DeliveryID, maxAttempts)
+	return false
+}
+
+// post makes a single delivery attempt; a non-2xx response is an error
+func post(hook Webhook, payload Payload, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature", Sign(hook.Secret, body))
+	req.Header.Set("X-Webhook-Event", payload.Event)
+	req.Header.Set("X-Webhook-Delivery", payload.DeliveryID)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// GenerateSecret creates a random signing secret
+func GenerateSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
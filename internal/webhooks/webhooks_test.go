@@ -0,0 +1,129 @@
+package webhooks
+
+import (
+	"campus-backend/pkg/db"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupTestDB(t *testing.T) {
+	testDB, err := gorm.Open(sqlite.Open(t.TempDir()+"/test.db"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal("Failed to connect to test database")
+	}
+	testDB.AutoMigrate(&Webhook{}, &WebhookDelivery{})
+	db.DB = testDB
+}
+
+func TestDeliverSignsAndRetries(t *testing.T) {
+	setupTestDB(t)
+	defer func(delay time.Duration) { retryBaseDelay = delay }(retryBaseDelay)
+	retryBaseDelay = time.Millisecond
+
+	hook := Webhook{URL: "", Secret: "test-secret-value", Events: EventLeaveApproved, IsActive: true, CreatedBy: 1}
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, Sign(hook.Secret, body), r.Header.Get("X-Signature"))
+		assert.Equal(t, EventLeaveApproved, r.Header.Get("X-Webhook-Event"))
+
+		var payload Payload
+		assert.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, EventLeaveApproved, payload.Event)
+
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	hook.URL = server.URL
+	db.DB.Create(&hook)
+
+	payload := Payload{DeliveryID: newDeliveryID(), Event: EventLeaveApproved, CreatedAt: time.Now(), Data: map[string]interface{}{"leave_id": 7}}
+	body, _ := json.Marshal(payload)
+	assert.True(t, deliver(hook, payload, body))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	var deliveries []WebhookDelivery
+	db.DB.Where("delivery_id = ?", payload.DeliveryID).Order("attempt ASC").Find(&deliveries)
+	if assert.Len(t, deliveries, 3) {
+		assert.False(t, deliveries[0].Success)
+		assert.Equal(t, http.StatusServiceUnavailable, deliveries[0].StatusCode)
+		assert.NotNil(t, deliveries[0].Error)
+		assert.True(t, deliveries[2].Success)
+		assert.Equal(t, http.StatusNoContent, deliveries[2].StatusCode)
+	}
+}
+
+func TestDeliverGivesUp(t *testing.T) {
+	setupTestDB(t)
+	defer func(delay time.Duration) { retryBaseDelay = delay }(retryBaseDelay)
+	retryBaseDelay = time.Millisecond
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	hook := Webhook{URL: server.URL, Secret: "test-secret-value", Events: EventLeaveRejected, IsActive: true, CreatedBy: 1}
+	db.DB.Create(&hook)
+
+	payload := Payload{DeliveryID: newDeliveryID(), Event: EventLeaveRejected, CreatedAt: time.Now()}
+	body, _ := json.Marshal(payload)
+	assert.False(t, deliver(hook, payload, body))
+
+	var attempts int64
+	db.DB.Model(&WebhookDelivery{}).Where("webhook_id = ? AND success = ?", hook.ID, false).Count(&attempts)
+	assert.Equal(t, int64(maxAttempts), attempts)
+}
+
+func TestSubscribes(t *testing.T) {
+	hook := Webhook{Events: "leave.approved, leave.rejected"}
+	assert.True(t, hook.Subscribes(EventLeaveApproved))
+	assert.True(t, hook.Subscribes(EventLeaveRejected))
+	assert.False(t, hook.Subscribes("leave.pending"))
+}
+
+func TestDispatchQueuesSubscribers(t *testing.T) {
+	setupTestDB(t)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	for _, events := range []string{EventLeaveApproved, EventLeaveApproved + "," + EventLeaveRejected, EventLeaveRejected} {
+		db.DB.Create(&Webhook{URL: server.URL, Secret: "test-secret-value", Events: events, IsActive: true, CreatedBy: 1})
+	}
+
+	Dispatch(EventLeaveApproved, map[string]interface{}{"leave_id": 7})
+	deliveries.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// A payload that cannot be encoded is skipped for every hook without delivering anything
+	Dispatch(EventLeaveRejected, map[string]interface{}{"bad": func() {}})
+	deliveries.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	var recorded int64
+	db.DB.Model(&WebhookDelivery{}).Where("success = ?", true).Count(&recorded)
+	assert.Equal(t, int64(2), recorded)
+}
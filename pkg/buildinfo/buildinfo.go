@@ -0,0 +1,48 @@
+// Package buildinfo reports which build of the server is running. Release builds inject the
+// values with -ldflags, e.g.
+//
+//	go build -ldflags "-X campus-backend/pkg/buildinfo.Version=v1.4.0 -X campus-backend/pkg/buildinfo.Commit=$(git rev-parse HEAD) -X campus-backend/pkg/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/server
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time with -ldflags "-X campus-backend/pkg/buildinfo.<Name>=<value>"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // Built from a tree with uncommitted changes; only known without -ldflags
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the injected build values. A plain go build leaves Commit unset, so it falls back
+// to the VCS revision the Go toolchain embeds in the binary.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+	if info.Commit != "" {
+		return info
+	}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
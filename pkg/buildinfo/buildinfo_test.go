@@ -0,0 +1,21 @@
+package buildinfo
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	defer func(version, commit, buildTime string) {
+		Version, Commit, BuildTime = version, commit, buildTime
+	}(Version, Commit, BuildTime)
+
+	info := Get()
+	assert.Equal(t, "dev", info.Version)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+
+	Version, Commit, BuildTime = "v1.4.0", "0123abc", "2026-10-14T08:00:00Z"
+	assert.Equal(t, Info{Version: "v1.4.0", Commit: "0123abc", BuildTime: "2026-10-14T08:00:00Z", GoVersion: runtime.Version()}, Get())
+}
@@ -60,7 +60,7 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("database.name", "campus_db")
 	viper.SetDefault("database.port", "5432")
 	viper.SetDefault("server.port", "8080")
-	viper.SetDefault("server.gin_mode", "debug")
+	viper.SetDefault("server.gin_mode", "release")
 	viper.SetDefault("jwt.secret", "your-super-secret-jwt-key")
 	viper.SetDefault("email.smtp_host", "smtp.gmail.com")
 	viper.SetDefault("email.smtp_port", "587")
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"campus-backend/pkg/timeutil"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Periods accepted by DateBucket
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// DateBucket returns a SQL expression labelling column with the campus-time period it falls in:
+// "YYYY-MM-DD" for days, the Monday starting the week as "YYYY-MM-DD" for weeks, and "YYYY-MM" for
+// months. All sort chronologically as strings. Timestamps are stored in UTC, so they are shifted to campus time
+// first; SQLite has no timezone database and uses the campus zone's current UTC offset.
+func DateBucket(tx *gorm.DB, period, column string) (string, error) {
+	if period != PeriodDay && period != PeriodWeek && period != PeriodMonth {
+		return "", fmt.Errorf("unknown period %q", period)
+	}
+
+	switch name := tx.Dialector.Name(); name {
+	case "postgres":
+		zone := strings.ReplaceAll(timeutil.Location().String(), "'", "''")
+		local := fmt.Sprintf("(%s AT TIME ZONE '%s')", column, zone)
+		if period == PeriodDay {
+			return fmt.Sprintf("TO_CHAR(%s, 'YYYY-MM-DD')", local), nil
+		}
+		if period == PeriodWeek {
+			return fmt.Sprintf("TO_CHAR(DATE_TRUNC('week', %s), 'YYYY-MM-DD')", local), nil
+		}
+		return fmt.Sprintf("TO_CHAR(DATE_TRUNC('month', %s), 'YYYY-MM')", local), nil
+	case "sqlite":
+		_, offset := time.Now().In(timeutil.Location()).Zone()
+		shift := fmt.Sprintf("'%+d seconds'", offset)
+		if period == PeriodDay {
+			return fmt.Sprintf("date(%s, %s)", column, shift), nil
+		}
+		if period == PeriodWeek {
+			// Forward to the week's Sunday (or stay on it), then back to its Monday
+			return fmt.Sprintf("date(%s, %s, 'weekday 0', '-6 days')", column, shift), nil
+		}
+		return fmt.Sprintf("strftime('%%Y-%%m', %s, %s)", column, shift), nil
+	default:
+		return "", fmt.Errorf("date grouping is not supported on %s", name)
+	}
+}
@@ -1,9 +1,11 @@
 package db
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
@@ -14,6 +16,15 @@ import (
 // Global database variable
 var DB *gorm.DB
 
+// sqliteBusyTimeoutMs is how long a SQLite connection waits for a lock before failing with "database is locked"
+const sqliteBusyTimeoutMs = 5000
+
+// sqliteDSN builds the SQLite connection string: WAL journaling lets reads proceed during a write,
+// and the busy timeout makes concurrent writers wait instead of failing
+func sqliteDSN(path string) string {
+	return fmt.Sprintf("%s?_journal_mode=WAL&_busy_timeout=%d", path, sqliteBusyTimeoutMs)
+}
+
 // Connect function - connects to database
 func Connect() {
 	// Load environment variables from .env file
@@ -24,12 +35,16 @@ func Connect() {
 
 	if dbType == "sqlite" || dbType == "" {
 		// Use SQLite for development (easier setup)
-		database, err := gorm.Open(sqlite.Open("campus.db"), &gorm.Config{})
+		path := os.Getenv("SQLITE_PATH")
+		if path == "" {
+			path = "campus.db"
+		}
+		database, err := gorm.Open(sqlite.Open(sqliteDSN(path)), &gorm.Config{})
 		if err != nil {
 			log.Fatal("Failed to connect to SQLite database:", err)
 		}
 		DB = database
-		log.Println("✅ Connected to SQLite database")
+		log.Printf("✅ Connected to SQLite database at %s", path)
 	} else {
 		// Use PostgreSQL for production
 		dsn := fmt.Sprintf(
@@ -48,3 +63,29 @@ func Connect() {
 		log.Println("✅ Connected to PostgreSQL database")
 	}
 }
+
+// Migrate creates or updates the tables for the given models on DB, one model at a time
+// so a failure names the model whose table could not be migrated
+func Migrate(models ...interface{}) error {
+	if DB == nil {
+		return fmt.Errorf("database is not connected")
+	}
+	for _, model := range models {
+		if err := DB.AutoMigrate(model); err != nil {
+			return fmt.Errorf("migrating %T: %w", model, err)
+		}
+	}
+	return nil
+}
+
+// IsUniqueViolation reports whether err is a unique constraint violation from SQLite or PostgreSQL
+func IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "UNIQUE constraint failed") || strings.Contains(msg, "SQLSTATE 23505")
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func TestMigrate(t *testing.T) {
+	defer func() { DB = nil }()
+
+	DB = nil
+	assert.Error(t, Migrate(&widget{}))
+
+	testDB, err := gorm.Open(sqlite.Open(sqliteDSN(t.TempDir()+"/test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	DB = testDB
+
+	assert.NoError(t, Migrate(&widget{}))
+	assert.True(t, DB.Migrator().HasTable(&widget{}))
+
+	var journalMode string
+	DB.Raw("PRAGMA journal_mode").Scan(&journalMode)
+	assert.Equal(t, "wal", journalMode)
+
+	// Failures name the model that could not be migrated
+	sqlDB, _ := DB.DB()
+	sqlDB.Close()
+	err = Migrate(&widget{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "*db.widget")
+	}
+}
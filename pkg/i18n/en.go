@@ -0,0 +1,58 @@
+package i18n
+
+var english = map[string]string{
+	// Validation errors; the first argument is the field name
+	"validation.required":            "%s is required",
+	"validation.required_if":         "%s is required when %s is %s",
+	"validation.email":               "%s must be a valid email address",
+	"validation.min":                 "%s must be at least %s characters long",
+	"validation.max":                 "%s must be at most %s characters long",
+	"validation.oneof":               "%s must be one of: %s",
+	"validation.date_range":          "End date must be after start date",
+	"validation.future_date":         "Date cannot be in the past",
+	"validation.leave_duration":      "Leave duration cannot exceed 30 days",
+	"validation.phone_e164":          "%s must be a valid phone number in international format, e.g. +919876543210",
+	"validation.phone":               "%s must be a valid phone number",
+	"validation.invalid":             "%s is invalid",
+	"validation.password":            "%s must contain %s",
+	"validation.password.min_length": "at least %s characters",
+	"validation.password.upper":      "an uppercase letter",
+	"validation.password.lower":      "a lowercase letter",
+	"validation.password.digit":      "a digit",
+	"validation.password.symbol":     "a symbol",
+
+	"leave.status.pending":    "pending",
+	"leave.status.approved":   "approved",
+	"leave.status.rejected":   "rejected",
+	"leave.status.needs_info": "sent back for more information",
+	"leave.status.cancelled":  "cancelled",
+
+	// Leave status change: status; then type, start date, end date, status
+	"notification.leave_status.title":   "Leave Request %s",
+	"notification.leave_status.message": "Your leave request for %s (%s to %s) has been %s",
+	"notification.leave_status.remarks": ". Remarks: %s",
+	"notification.leave_status.reason":  ". Reason: %s",
+	"sms.leave_status":                  "Campus: your %s leave (%s to %s) was %s.",
+
+	// Welcome after registration: name
+	"notification.welcome.title":   "Welcome to Campus",
+	"notification.welcome.message": "Hi %s, your account is ready. You will find leave updates and announcements here.",
+
+	// Leave filed by staff for the student: type, start date, end date, staff name
+	"notification.leave_filed.title":   "Leave Request Filed For You",
+	"notification.leave_filed.message": "A %s leave request (%s to %s) was filed on your behalf by %s",
+	"sms.leave_filed":                  "Campus: %[4]s filed a %[1]s leave (%[2]s to %[3]s) for you.",
+
+	// Leave comment: leave ID; then author name, comment
+	"notification.leave_comment.title":   "New comment on leave request #%d",
+	"notification.leave_comment.message": "%s commented: %s",
+
+	// Leave escalation: leave ID; then student name, type, start date, hours
+	"notification.leave_escalation.title":   "Leave request #%d is overdue",
+	"notification.leave_escalation.message": "%s's %s leave starting %s has been pending for more than %d hours and needs a decision",
+
+	// Leave reminder: type, start date
+	"notification.leave_reminder.title":   "Leave Starting Tomorrow",
+	"notification.leave_reminder.message": "Your approved leave for %s starts tomorrow (%s). Please ensure all arrangements are in place.",
+	"sms.leave_reminder":                  "Campus: reminder, your %s leave starts tomorrow (%s).",
+}
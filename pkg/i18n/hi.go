@@ -0,0 +1,51 @@
+package i18n
+
+var hindi = map[string]string{
+	"validation.required":            "%s आवश्यक है",
+	"validation.required_if":         "%[2]s %[3]s होने पर %[1]s आवश्यक है",
+	"validation.email":               "%s एक मान्य ईमेल पता होना चाहिए",
+	"validation.min":                 "%s कम से कम %s अक्षरों का होना चाहिए",
+	"validation.max":                 "%s अधिकतम %s अक्षरों का होना चाहिए",
+	"validation.oneof":               "%s इनमें से एक होना चाहिए: %s",
+	"validation.date_range":          "समाप्ति तिथि आरंभ तिथि के बाद होनी चाहिए",
+	"validation.future_date":         "तिथि अतीत की नहीं हो सकती",
+	"validation.leave_duration":      "अवकाश की अवधि 30 दिनों से अधिक नहीं हो सकती",
+	"validation.phone_e164":          "%s अंतरराष्ट्रीय प्रारूप में एक मान्य फ़ोन नंबर होना चाहिए, जैसे +919876543210",
+	"validation.phone":               "%s एक मान्य फ़ोन नंबर होना चाहिए",
+	"validation.invalid":             "%s अमान्य है",
+	"validation.password":            "%s में %s होना चाहिए",
+	"validation.password.min_length": "कम से कम %s अक्षर",
+	"validation.password.upper":      "एक बड़ा अक्षर",
+	"validation.password.lower":      "एक छोटा अक्षर",
+	"validation.password.digit":      "एक अंक",
+	"validation.password.symbol":     "एक चिह्न",
+
+	"leave.status.pending":    "लंबित",
+	"leave.status.approved":   "स्वीकृत",
+	"leave.status.rejected":   "अस्वीकृत",
+	"leave.status.needs_info": "अधिक जानकारी के लिए वापस",
+	"leave.status.cancelled":  "रद्द",
+
+	"notification.leave_status.title":   "अवकाश अनुरोध %s",
+	"notification.leave_status.message": "%[2]s से %[3]s तक के आपके %[1]s अवकाश अनुरोध को %[4]s कर दिया गया है",
+	"notification.leave_status.remarks": "। टिप्पणी: %s",
+	"notification.leave_status.reason":  "। कारण: %s",
+	"sms.leave_status":                  "कैंपस: आपका %[1]s अवकाश (%[2]s से %[3]s) %[4]s कर दिया गया।",
+
+	"notification.welcome.title":   "कैंपस में आपका स्वागत है",
+	"notification.welcome.message": "नमस्ते %s, आपका खाता तैयार है। अवकाश से जुड़ी जानकारी और घोषणाएँ आपको यहीं मिलेंगी।",
+
+	"notification.leave_filed.title":   "आपके लिए अवकाश अनुरोध दर्ज किया गया",
+	"notification.leave_filed.message": "%[4]s ने आपकी ओर से %[2]s से %[3]s तक का %[1]s अवकाश अनुरोध दर्ज किया है",
+	"sms.leave_filed":                  "कैंपस: %[4]s ने आपके लिए %[1]s अवकाश (%[2]s से %[3]s) दर्ज किया।",
+
+	"notification.leave_comment.title":   "अवकाश अनुरोध #%d पर नई टिप्पणी",
+	"notification.leave_comment.message": "%s ने टिप्पणी की: %s",
+
+	"notification.leave_escalation.title":   "अवकाश अनुरोध #%d की समय-सीमा निकल गई",
+	"notification.leave_escalation.message": "%[3]s से आरंभ होने वाला %[1]s का %[2]s अवकाश %[4]d घंटे से अधिक समय से लंबित है और निर्णय की प्रतीक्षा में है",
+
+	"notification.leave_reminder.title":   "कल से अवकाश आरंभ",
+	"notification.leave_reminder.message": "%s के लिए आपका स्वीकृत अवकाश कल (%s) से आरंभ हो रहा है। कृपया सभी व्यवस्थाएँ सुनिश्चित कर लें।",
+	"sms.leave_reminder":                  "कैंपस: अनुस्मारक, आपका %s अवकाश कल (%s) से आरंभ हो रहा है।",
+}
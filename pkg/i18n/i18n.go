@@ -0,0 +1,73 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is used when no supported language is requested and for keys a catalog lacks
+const DefaultLanguage = "en"
+
+// catalogs maps a language to its messages. Messages are fmt formats; translations that
+// reorder arguments use explicit indexes such as %[2]s.
+var catalogs = map[string]map[string]string{
+	"en": english,
+	"hi": hindi,
+}
+
+// T returns the message for key in lang, falling back to English and then to the key itself
+func T(lang, key string, args ...interface{}) string {
+	format, ok := catalogs[lang][key]
+	if !ok {
+		format, ok = catalogs[DefaultLanguage][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Supported reports whether lang has a catalog
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Languages returns the supported language codes, sorted
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Match picks the supported language the client prefers from an Accept-Language header
+// such as "hi-IN,hi;q=0.9,en;q=0.8", comparing primary subtags. Returns DefaultLanguage
+// when nothing matches.
+func Match(acceptLanguage string) string {
+	best, bestQ := DefaultLanguage, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if Supported(primary) && q > bestQ {
+			best, bestQ = primary, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,41 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	cases := map[string]string{
+		"":                        "en",
+		"hi":                      "hi",
+		"hi-IN,hi;q=0.9,en;q=0.8": "hi",
+		"fr-FR,en;q=0.5,hi;q=0.7": "hi",
+		"en-GB, hi;q=0.2":         "en",
+		"fr, de":                  "en",
+		"hi;q=abc, en;q=0.1":      "en",
+	}
+	for header, want := range cases {
+		assert.Equal(t, want, Match(header), header)
+	}
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "Name is required", T("en", "validation.required", "Name"))
+	assert.Equal(t, "Name आवश्यक है", T("hi", "validation.required", "Name"))
+	assert.Equal(t, "Role warden होने पर Hostel आवश्यक है", T("hi", "validation.required_if", "Hostel", "Role", "warden"))
+
+	// Unknown languages and missing keys fall back to English, then to the key
+	assert.Equal(t, "Name is required", T("fr", "validation.required", "Name"))
+	assert.Equal(t, "no.such.key", T("hi", "no.such.key"))
+}
+
+func TestCatalogsCoverEnglishKeys(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for key := range catalog {
+			_, ok := english[key]
+			assert.True(t, ok, "%s has key %q that English lacks", lang, key)
+		}
+	}
+}
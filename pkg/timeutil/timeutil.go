@@ -0,0 +1,70 @@
+package timeutil
+
+import (
+	"time"
+)
+
+// DateLayout is the date format accepted in query parameters
+const DateLayout = "2006-01-02"
+
+// Campus timezone used for all day-boundary calculations (defaults to UTC)
+var location = time.UTC
+
+// SetLocation sets the campus timezone by IANA name, e.g. "Asia/Kolkata"
+func SetLocation(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return err
+	}
+	location = loc
+	return nil
+}
+
+// Location returns the campus timezone
+func Location() *time.Location {
+	return location
+}
+
+// Now returns the current time in the campus timezone
+func Now() time.Time {
+	return time.Now().In(location)
+}
+
+// StartOfDay returns midnight (campus time) of the day containing t, expressed in UTC for storage
+func StartOfDay(t time.Time) time.Time {
+	local := t.In(location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location).UTC()
+}
+
+// DayBounds returns the start of the campus day containing t and the start of the next day, in UTC
+func DayBounds(t time.Time) (start, end time.Time) {
+	start = StartOfDay(t)
+	end = StartOfDay(start.In(location).AddDate(0, 0, 1))
+	return start, end
+}
+
+// Today returns the start of the current campus day in UTC
+func Today() time.Time {
+	return StartOfDay(time.Now())
+}
+
+// Tomorrow returns the start of the next campus day in UTC
+func Tomorrow() time.Time {
+	_, end := DayBounds(time.Now())
+	return end
+}
+
+// IsWeekend reports whether t falls on a Saturday or Sunday, campus time
+func IsWeekend(t time.Time) bool {
+	day := t.In(location).Weekday()
+	return day == time.Saturday || day == time.Sunday
+}
+
+// ParseDate parses a YYYY-MM-DD date as midnight campus time, expressed in UTC
+func ParseDate(value string) (time.Time, error) {
+	t, err := time.ParseInLocation(DateLayout, value, location)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,67 @@
+package timeutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func useLocation(t *testing.T, name string) {
+	previous := location
+	assert.NoError(t, SetLocation(name))
+	t.Cleanup(func() { location = previous })
+}
+
+func TestStartOfDayUsesCampusTimezone(t *testing.T) {
+	useLocation(t, "Asia/Kolkata") // UTC+05:30
+
+	// 23:00 UTC is already 04:30 the next morning on campus
+	lateUTC := time.Date(2025, 3, 10, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 10, 18, 30, 0, 0, time.UTC), StartOfDay(lateUTC))
+
+	// 18:29 UTC is 23:59 campus time, still the same campus day as 00:00 campus time
+	beforeMidnight := time.Date(2025, 3, 10, 18, 29, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 9, 18, 30, 0, 0, time.UTC), StartOfDay(beforeMidnight))
+
+	// 18:30 UTC is exactly campus midnight
+	atMidnight := time.Date(2025, 3, 10, 18, 30, 0, 0, time.UTC)
+	assert.Equal(t, atMidnight, StartOfDay(atMidnight))
+}
+
+func TestStartOfDayDefaultsToUTC(t *testing.T) {
+	useLocation(t, "UTC")
+
+	ts := time.Date(2025, 3, 10, 23, 59, 59, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), StartOfDay(ts))
+}
+
+func TestDayBoundsAcrossDSTChange(t *testing.T) {
+	useLocation(t, "America/New_York")
+
+	// 2025-03-09 is only 23 hours long in New York
+	start, end := DayBounds(time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2025, 3, 9, 5, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC), end)
+	assert.Equal(t, 23*time.Hour, end.Sub(start))
+}
+
+func TestParseDate(t *testing.T) {
+	useLocation(t, "Asia/Kolkata")
+
+	parsed, err := ParseDate("2025-03-11")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 10, 18, 30, 0, 0, time.UTC), parsed)
+
+	_, err = ParseDate("11/03/2025")
+	assert.Error(t, err)
+}
+
+func TestIsWeekendUsesCampusTimezone(t *testing.T) {
+	useLocation(t, "Asia/Kolkata")
+
+	// Friday 20:00 UTC is already Saturday in Kolkata
+	assert.True(t, IsWeekend(time.Date(2025, 3, 14, 20, 0, 0, 0, time.UTC)))
+	assert.False(t, IsWeekend(time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)))
+	assert.False(t, IsWeekend(time.Date(2025, 3, 16, 19, 0, 0, 0, time.UTC))) // Monday locally
+}
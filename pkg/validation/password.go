@@ -0,0 +1,104 @@
+package validation
+
+import (
+	"campus-backend/pkg/i18n"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// PasswordPolicy lists the rules the "password" tag enforces
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// DefaultPasswordPolicy applies until SetPasswordPolicySource is called
+var DefaultPasswordPolicy = PasswordPolicy{MinLength: 8, RequireUpper: true, RequireLower: true, RequireDigit: true}
+
+var passwordPolicy = func() PasswordPolicy { return DefaultPasswordPolicy }
+
+// SetPasswordPolicySource sets where the "password" tag reads its policy from. It is called
+// on every check, so the policy can change at runtime. A nil source restores the default.
+func SetPasswordPolicySource(source func() PasswordPolicy) {
+	if source == nil {
+		source = func() PasswordPolicy { return DefaultPasswordPolicy }
+	}
+	passwordPolicy = source
+}
+
+// CurrentPasswordPolicy returns the policy passwords are checked against
+func CurrentPasswordPolicy() PasswordPolicy {
+	return passwordPolicy()
+}
+
+// Password rules, doubling as the i18n key suffixes for their messages
+const (
+	PasswordRuleMinLength = "min_length"
+	PasswordRuleUpper     = "upper"
+	PasswordRuleLower     = "lower"
+	PasswordRuleDigit     = "digit"
+	PasswordRuleSymbol    = "symbol"
+)
+
+// Violations lists the rules a password breaks, in a fixed order
+func (p PasswordPolicy) Violations(password string) []string {
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	var rules []string
+	if utf8.RuneCountInString(password) < p.MinLength {
+		rules = append(rules, PasswordRuleMinLength)
+	}
+	if p.RequireUpper && !hasUpper {
+		rules = append(rules, PasswordRuleUpper)
+	}
+	if p.RequireLower && !hasLower {
+		rules = append(rules, PasswordRuleLower)
+	}
+	if p.RequireDigit && !hasDigit {
+		rules = append(rules, PasswordRuleDigit)
+	}
+	if p.RequireSymbol && !hasSymbol {
+		rules = append(rules, PasswordRuleSymbol)
+	}
+	return rules
+}
+
+// validatePassword checks a password against the current policy
+func validatePassword(fl validator.FieldLevel) bool {
+	return len(passwordPolicy().Violations(fl.Field().String())) == 0
+}
+
+// passwordMessage names every rule the password breaks, e.g. "Password must contain at least
+// 8 characters, an uppercase letter"
+func passwordMessage(lang, field, password string) string {
+	policy := passwordPolicy()
+	rules := policy.Violations(password)
+	parts := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		if rule == PasswordRuleMinLength {
+			parts = append(parts, i18n.T(lang, "validation.password."+rule, strconv.Itoa(policy.MinLength)))
+			continue
+		}
+		parts = append(parts, i18n.T(lang, "validation.password."+rule))
+	}
+	return i18n.T(lang, "validation.password", field, strings.Join(parts, ", "))
+}
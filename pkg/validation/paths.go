@@ -0,0 +1,41 @@
+package validation
+
+import (
+	"campus-backend/pkg/i18n"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// jsonFieldName names a struct field by its JSON key, falling back to the Go name
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
+
+// FormatValidationErrorPaths is FormatValidationErrorPathsIn in English
+func FormatValidationErrorPaths(err error) map[string]string {
+	return FormatValidationErrorPathsIn(i18n.DefaultLanguage, err)
+}
+
+// FormatValidationErrorPathsIn formats validation errors keyed by the JSON path of the failing
+// value, e.g. "entries[3].student_id", so clients of bulk requests can point at the exact item.
+// Messages name the same path.
+func FormatValidationErrorPathsIn(lang string, err error) map[string]string {
+	errors := make(map[string]string)
+	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		for _, e := range validationErrors {
+			// The namespace starts with the Go type name of the validated struct
+			path := e.Namespace()
+			if _, rest, ok := strings.Cut(path, "."); ok {
+				path = rest
+			}
+			errors[path] = fieldMessage(lang, e, path)
+		}
+	}
+	return errors
+}
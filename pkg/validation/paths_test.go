@@ -0,0 +1,46 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bulkEntry struct {
+	StudentID uint    `json:"student_id" validate:"required"`
+	Status    string  `json:"status" validate:"oneof=present absent"`
+	Phone     *string `json:"phone,omitempty" validate:"omitempty,phone"`
+}
+
+type bulkRequest struct {
+	Dept    string      `json:"dept" validate:"required"`
+	Entries []bulkEntry `json:"entries" validate:"required,min=1,dive"`
+	Tags    []string    `json:"tags" validate:"dive,max=5"`
+}
+
+func TestFormatValidationErrorPaths(t *testing.T) {
+	phone := "12345"
+	req := bulkRequest{
+		Entries: []bulkEntry{
+			{StudentID: 1, Status: "present"},
+			{StudentID: 2, Status: "present", Phone: &phone},
+			{StudentID: 3, Status: "present"},
+			{Status: "late"},
+		},
+		Tags: []string{"ok", "too long"},
+	}
+
+	errors := FormatValidationErrorPaths(ValidateStruct(req))
+	assert.Equal(t, map[string]string{
+		"dept":                  "dept is required",
+		"entries[1].phone":      "entries[1].phone must be a valid phone number in international format, e.g. +919876543210",
+		"entries[3].student_id": "entries[3].student_id is required",
+		"entries[3].status":     "entries[3].status must be one of: present absent",
+		"tags[1]":               "tags[1] must be at most 5 characters long",
+	}, errors)
+
+	// The flat format keeps Go field names
+	flat := FormatValidationErrors(ValidateStruct(req))
+	assert.Equal(t, "Dept is required", flat["Dept"])
+	assert.Contains(t, flat, "StudentID")
+}
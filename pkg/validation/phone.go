@@ -0,0 +1,41 @@
+package validation
+
+import (
+	"campus-backend/pkg/i18n"
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// e164 matches international numbers such as +919876543210
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+var phonePattern = e164
+
+// SetPhonePattern replaces the E.164 check used by the "phone" tag with a custom regular expression.
+// An empty pattern restores E.164.
+func SetPhonePattern(pattern string) error {
+	if pattern == "" {
+		phonePattern = e164
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	phonePattern = re
+	return nil
+}
+
+// validatePhone checks a phone number against the configured pattern; pair it with omitempty for optional fields
+func validatePhone(fl validator.FieldLevel) bool {
+	return phonePattern.MatchString(fl.Field().String())
+}
+
+// phoneMessage describes the expected format for error messages
+func phoneMessage(lang, field string) string {
+	if phonePattern == e164 {
+		return i18n.T(lang, "validation.phone_e164", field)
+	}
+	return i18n.T(lang, "validation.phone", field)
+}
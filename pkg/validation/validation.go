@@ -1,7 +1,9 @@
 package validation
 
 import (
-	"fmt"
+	"campus-backend/pkg/i18n"
+	"campus-backend/pkg/timeutil"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -16,6 +18,11 @@ func init() {
 	validate.RegisterValidation("date_range", validateDateRange)
 	validate.RegisterValidation("future_date", validateFutureDate)
 	validate.RegisterValidation("leave_duration", validateLeaveDuration)
+	validate.RegisterValidation("phone", validatePhone)
+	validate.RegisterValidation("password", validatePassword)
+	
+	// Name fields as clients send them, for the paths reported by FormatValidationErrorPathsIn
+	validate.RegisterTagNameFunc(jsonFieldName)
 }
 
 // ValidateStruct validates a struct using the validator
@@ -23,6 +30,18 @@ func ValidateStruct(s interface{}) error {
 	return validate.Struct(s)
 }
 
+// TrimOptional trims an optional field, turning blank values into nil
+func TrimOptional(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*s)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
 // validateDateRange ensures end date is after start date
 func validateDateRange(fl validator.FieldLevel) bool {
 	startDate := fl.Parent().FieldByName("StartDate")
@@ -49,8 +68,8 @@ func validateFutureDate(fl validator.FieldLevel) bool {
 		return false
 	}
 	
-	// Allow today's date but not past dates
-	return !date.Before(time.Now().Truncate(24 * time.Hour))
+	// Allow today's date (in the campus timezone) but not past dates
+	return !date.Before(timeutil.Today())
 }
 
 // validateLeaveDuration ensures leave duration is reasonable (max 30 days)
@@ -73,37 +92,50 @@ func validateLeaveDuration(fl validator.FieldLevel) bool {
 	return duration <= 30*24*time.Hour && duration >= 0
 }
 
-// FormatValidationErrors formats validation errors into a readable format
+// FormatValidationErrors formats validation errors into a readable format, in English
 func FormatValidationErrors(err error) map[string]string {
+	return FormatValidationErrorsIn(i18n.DefaultLanguage, err)
+}
+
+// FormatValidationErrorsIn formats validation errors in the given language
+func FormatValidationErrorsIn(lang string, err error) map[string]string {
 	errors := make(map[string]string)
 	
 	if validationErrors, ok := err.(validator.ValidationErrors); ok {
 		for _, e := range validationErrors {
-			field := e.Field()
-			tag := e.Tag()
-			
-			switch tag {
-			case "required":
-				errors[field] = fmt.Sprintf("%s is required", field)
-			case "email":
-				errors[field] = fmt.Sprintf("%s must be a valid email address", field)
-			case "min":
-				errors[field] = fmt.Sprintf("%s must be at least %s characters long", field, e.Param())
-			case "max":
-				errors[field] = fmt.Sprintf("%s must be at most %s characters long", field, e.Param())
-			case "oneof":
-				errors[field] = fmt.Sprintf("%s must be one of: %s", field, e.Param())
-			case "date_range":
-				errors[field] = "End date must be after start date"
-			case "future_date":
-				errors[field] = "Date cannot be in the past"
-			case "leave_duration":
-				errors[field] = "Leave duration cannot exceed 30 days"
-			default:
-				errors[field] = fmt.Sprintf("%s is invalid", field)
-			}
+			// StructField is the Go name; Field would be the JSON name registered in init
+			field := e.StructField()
+			errors[field] = fieldMessage(lang, e, field)
 		}
 	}
 	
 	return errors
 }
+
+// fieldMessage describes a validation failure, naming the field as given
+func fieldMessage(lang string, e validator.FieldError, field string) string {
+	tag := e.Tag()
+	
+	switch tag {
+	case "required":
+		return i18n.T(lang, "validation.required", field)
+	case "required_if":
+		// Param is "<OtherField> <value>", e.g. "Role warden"
+		if parts := strings.Fields(e.Param()); len(parts) == 2 {
+			return i18n.T(lang, "validation.required_if", field, parts[0], parts[1])
+		}
+		return i18n.T(lang, "validation.required", field)
+	case "email":
+		return i18n.T(lang, "validation.email", field)
+	case "min", "max", "oneof":
+		return i18n.T(lang, "validation."+tag, field, e.Param())
+	case "date_range", "future_date", "leave_duration":
+		return i18n.T(lang, "validation."+tag)
+	case "phone":
+		return phoneMessage(lang, field)
+	case "password":
+		return passwordMessage(lang, field, e.Value().(string))
+	default:
+		return i18n.T(lang, "validation.invalid", field)
+	}
+}
@@ -0,0 +1,61 @@
+package workqueue
+
+import (
+	"log"
+	"sync"
+)
+
+// Queue runs tasks in the background on a fixed number of workers, so a burst of work waits
+// in a bounded buffer instead of starting a goroutine per task
+type Queue struct {
+	name    string
+	workers int
+	tasks   chan func()
+	start   sync.Once
+	pending sync.WaitGroup
+}
+
+// New creates a queue holding up to capacity waiting tasks. Workers start on the first Submit.
+func New(name string, workers, capacity int) *Queue {
+	return &Queue{name: name, workers: max(workers, 1), tasks: make(chan func(), capacity)}
+}
+
+// Submit queues the task and reports whether it was accepted; a full queue rejects it without running it
+func (q *Queue) Submit(task func()) bool {
+	q.start.Do(q.startWorkers)
+	q.pending.Add(1)
+	select {
+	case q.tasks <- task:
+		return true
+	default:
+		q.pending.Done()
+		log.Printf("Queue %s: full, task rejected", q.name)
+		return false
+	}
+}
+
+// Wait blocks until every accepted task has finished
+func (q *Queue) Wait() {
+	q.pending.Wait()
+}
+
+func (q *Queue) startWorkers() {
+	for i := 0; i < q.workers; i++ {
+		go func() {
+			for task := range q.tasks {
+				q.run(task)
+			}
+		}()
+	}
+}
+
+// run executes one task; a panic is logged so the worker keeps serving the queue
+func (q *Queue) run(task func()) {
+	defer q.pending.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Queue %s: task panicked: %v", q.name, r)
+		}
+	}()
+	task()
+}
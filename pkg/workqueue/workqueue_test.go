@@ -0,0 +1,54 @@
+package workqueue
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueueBoundsWorkers(t *testing.T) {
+	q := New("test", 2, 10)
+
+	var running, peak, done atomic.Int32
+	release := make(chan struct{})
+	for i := 0; i < 6; i++ {
+		assert.True(t, q.Submit(func() {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			<-release
+			running.Add(-1)
+			done.Add(1)
+		}))
+	}
+	close(release)
+	q.Wait()
+
+	assert.Equal(t, int32(6), done.Load())
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestQueueRejectsWhenFull(t *testing.T) {
+	q := New("test", 1, 1)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	assert.True(t, q.Submit(func() { close(started); <-release }))
+	<-started
+	assert.True(t, q.Submit(func() {}))  // Waits in the buffer
+	assert.False(t, q.Submit(func() {})) // Nowhere to put it
+	close(release)
+	q.Wait()
+
+	// A panicking task does not take its worker down
+	assert.True(t, q.Submit(func() { panic("boom") }))
+	ran := false
+	assert.True(t, q.Submit(func() { ran = true }))
+	q.Wait()
+	assert.True(t, ran)
+}